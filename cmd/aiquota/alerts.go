@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/alert"
+	"github.com/eduardolat/aiquota/pkg/aiquota"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// alertSinks are the sink names accepted by alert rules.
+var alertSinks = []string{"desktop", "slack", "discord", "ntfy", "telegram", "pushover", "gotify", "email", "webhook"}
+
+// alertFlags holds the flags that enable alert sinks.
+type alertFlags struct {
+	notify         bool
+	slackWebhook   string
+	discordWebhook string
+	ntfy           alert.Ntfy
+	telegram       alert.Telegram
+	pushover       alert.Pushover
+	gotify         alert.Gotify
+	email          alert.Email
+	webhook        alert.Webhook
+	emailTo        string
+	levels         percentList
+
+	// sinkLevels override levels for the sinks that support it.
+	sinkLevels map[string]*percentList
+
+	// budgets are alerted on like windows. Commands that read the config
+	// file set them.
+	budgets *budgetSet
+}
+
+func addAlertFlags(flags *flag.FlagSet) *alertFlags {
+	a := &alertFlags{
+		levels:     slices.Clone(percentList(alert.DefaultLevels)),
+		sinkLevels: map[string]*percentList{"pushover": {}, "gotify": {}},
+	}
+	flags.BoolVar(&a.notify, "notify", false, "send a desktop notification when a window crosses an alert level")
+	flags.StringVar(&a.slackWebhook, "slack-webhook", os.Getenv("AIQUOTA_SLACK_WEBHOOK"), "post alerts to this Slack incoming webhook URL (default $AIQUOTA_SLACK_WEBHOOK)")
+	flags.StringVar(&a.discordWebhook, "discord-webhook", os.Getenv("AIQUOTA_DISCORD_WEBHOOK"), "post alerts to this Discord webhook URL; given to the report command, post the full report instead (default $AIQUOTA_DISCORD_WEBHOOK)")
+	flags.StringVar(&a.ntfy.Topic, "ntfy-topic", os.Getenv("AIQUOTA_NTFY_TOPIC"), "publish alerts to this ntfy topic (default $AIQUOTA_NTFY_TOPIC)")
+	flags.StringVar(&a.ntfy.Server, "ntfy-server", alert.DefaultNtfyServer, "ntfy server for --ntfy-topic")
+	flags.StringVar(&a.ntfy.Priority, "ntfy-priority", "", "ntfy priority of alerts, from min to urgent (default high at 100%, otherwise the server default)")
+	flags.StringVar(&a.ntfy.Token, "ntfy-token", os.Getenv("AIQUOTA_NTFY_TOKEN"), "access token for a protected ntfy topic (default $AIQUOTA_NTFY_TOKEN)")
+	flags.StringVar(&a.telegram.Token, "telegram-token", os.Getenv("AIQUOTA_TELEGRAM_TOKEN"), "send alerts through this Telegram bot token (default $AIQUOTA_TELEGRAM_TOKEN)")
+	flags.StringVar(&a.telegram.ChatID, "telegram-chat", os.Getenv("AIQUOTA_TELEGRAM_CHAT"), "Telegram chat ID that receives the alerts (default $AIQUOTA_TELEGRAM_CHAT)")
+	flags.StringVar(&a.pushover.Token, "pushover-token", os.Getenv("AIQUOTA_PUSHOVER_TOKEN"), "send alerts through this Pushover application token (default $AIQUOTA_PUSHOVER_TOKEN)")
+	flags.StringVar(&a.pushover.User, "pushover-user", os.Getenv("AIQUOTA_PUSHOVER_USER"), "Pushover user or group key that receives the alerts (default $AIQUOTA_PUSHOVER_USER)")
+	flags.Var(a.sinkLevels["pushover"], "pushover-levels", "alert levels for Pushover, overriding --notify-levels")
+	flags.StringVar(&a.gotify.URL, "gotify-url", os.Getenv("AIQUOTA_GOTIFY_URL"), "send alerts to this Gotify server (default $AIQUOTA_GOTIFY_URL)")
+	flags.StringVar(&a.gotify.Token, "gotify-token", os.Getenv("AIQUOTA_GOTIFY_TOKEN"), "Gotify application token (default $AIQUOTA_GOTIFY_TOKEN)")
+	flags.Var(a.sinkLevels["gotify"], "gotify-levels", "alert levels for Gotify, overriding --notify-levels")
+	flags.StringVar(&a.email.Host, "smtp-host", os.Getenv("AIQUOTA_SMTP_HOST"), "email alerts through this SMTP server (default $AIQUOTA_SMTP_HOST)")
+	flags.IntVar(&a.email.Port, "smtp-port", 587, "SMTP server port")
+	flags.StringVar(&a.email.TLS, "smtp-tls", alert.TLSStartTLS, "SMTP transport security: starttls, tls or none")
+	flags.StringVar(&a.email.Username, "smtp-user", os.Getenv("AIQUOTA_SMTP_USER"), "SMTP username, no authentication when empty (default $AIQUOTA_SMTP_USER)")
+	flags.StringVar(&a.email.Password, "smtp-password", os.Getenv("AIQUOTA_SMTP_PASSWORD"), "SMTP password (default $AIQUOTA_SMTP_PASSWORD)")
+	flags.StringVar(&a.email.From, "email-from", os.Getenv("AIQUOTA_EMAIL_FROM"), "sender address of alert emails (default $AIQUOTA_EMAIL_FROM)")
+	flags.StringVar(&a.emailTo, "email-to", os.Getenv("AIQUOTA_EMAIL_TO"), "comma-separated recipients of alert emails (default $AIQUOTA_EMAIL_TO)")
+	flags.BoolVar(&a.email.HTML, "email-html", false, "add an HTML version to alert and summary emails")
+	flags.StringVar(&a.webhook.URL, "webhook-url", os.Getenv("AIQUOTA_WEBHOOK_URL"), "post alert events with the report JSON to this URL (default $AIQUOTA_WEBHOOK_URL)")
+	flags.StringVar(&a.webhook.Secret, "webhook-secret", os.Getenv("AIQUOTA_WEBHOOK_SECRET"), "sign --webhook-url bodies with HMAC-SHA256 using this secret (default $AIQUOTA_WEBHOOK_SECRET)")
+	flags.Var(&a.levels, "notify-levels", "comma-separated used percents that trigger alerts")
+
+	return a
+}
+
+// sinks returns the enabled alert sinks keyed by the namespace of their
+// tracker state. The desktop sink needs --notify; the others are enabled by
+// their settings.
+func (a *alertFlags) sinks() map[string]alert.Sink {
+	sinks := map[string]alert.Sink{}
+	for _, name := range alertSinks {
+		if name == "desktop" && !a.notify {
+			continue
+		}
+
+		if sink, ok := a.sink(name); ok {
+			sinks[name] = sink
+		}
+	}
+
+	return sinks
+}
+
+// sink returns the named sink, and false when the settings it needs are
+// missing or the name is unknown.
+func (a *alertFlags) sink(name string) (alert.Sink, bool) {
+	switch name {
+	case "desktop":
+		return alert.Desktop{}, true
+	case "slack":
+		return alert.Slack{WebhookURL: a.slackWebhook}, a.slackWebhook != ""
+	case "discord":
+		return alert.Discord{WebhookURL: a.discordWebhook}, a.discordWebhook != ""
+	case "ntfy":
+		return a.ntfy, a.ntfy.Topic != ""
+	case "telegram":
+		return a.telegram, a.telegram.Token != "" && a.telegram.ChatID != ""
+	case "pushover":
+		return a.pushover, a.pushover.Token != "" && a.pushover.User != ""
+	case "gotify":
+		return a.gotify, a.gotify.URL != "" && a.gotify.Token != ""
+	case "email":
+		email := a.email
+		for to := range strings.SplitSeq(a.emailTo, ",") {
+			if to = strings.TrimSpace(to); to != "" {
+				email.To = append(email.To, to)
+			}
+		}
+		return email, email.Host != "" && email.From != "" && len(email.To) > 0
+	case "webhook":
+		return a.webhook, a.webhook.URL != ""
+	default:
+		return nil, false
+	}
+}
+
+// dispatch evaluates the results against each enabled sink and delivers new
+// events. Alerting is best effort: failures are reported on stderr.
+func (a *alertFlags) dispatch(ctx context.Context, results []provider.Result) {
+	if err := a.deliver(ctx, results); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// deliverEvents delivers events that need no tracking, such as usage spikes, to
+// every enabled sink. Failures are reported on stderr.
+func (a *alertFlags) deliverEvents(ctx context.Context, events []alert.Event) {
+	if err := alert.SendAll(ctx, slices.Collect(maps.Values(a.sinks())), events); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// deliver evaluates the results against each enabled sink, delivers new
+// events and joins the errors.
+func (a *alertFlags) deliver(ctx context.Context, results []provider.Result) error {
+	var errs []error
+	for namespace, sink := range a.sinks() {
+		if err := a.send(ctx, namespace, sink, results); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// levelsFor returns the alert levels of a sink: its own override when set,
+// otherwise --notify-levels.
+func (a *alertFlags) levelsFor(name string) []float64 {
+	if levels := a.sinkLevels[name]; levels != nil && len(*levels) > 0 {
+		return *levels
+	}
+
+	return a.levels
+}
+
+func (a *alertFlags) send(ctx context.Context, namespace string, sink alert.Sink, results []provider.Result) error {
+	tracker, err := alert.NewTracker(namespace, a.levelsFor(namespace))
+	if err != nil {
+		return err
+	}
+
+	// Sinks that receive the whole report are also told about failures.
+	reportSink, full := sink.(alert.ReportSink)
+	tracker.Failures = full
+
+	// Budgets are evaluated as extra windows, but the report sent to full
+	// sinks stays the fetched one.
+	events, err := tracker.Evaluate(append(slices.Clone(results), a.budgets.results(results, time.Now())...))
+	if err != nil {
+		return err
+	}
+
+	if full {
+		if len(events) == 0 {
+			return nil
+		}
+
+		if err := reportSink.SendWithResults(ctx, events, results); err != nil {
+			return fmt.Errorf("%s: %w", sink.Name(), httpclient.RedactError(err))
+		}
+
+		return nil
+	}
+
+	return alert.SendAll(ctx, []alert.Sink{sink}, events)
+}
+
+// runAlert fetches once and delivers alerts without printing a report, for
+// use from cron.
+func runAlert(parent context.Context, args []string) error {
+	flags := flag.NewFlagSet("aiquota alert", flag.ContinueOnError)
+	fetch := addFetchFlags(flags)
+	alerts := addAlertFlags(flags)
+	cfg, err := parseFlagsConfig(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if alerts.budgets, err = newBudgetSet(cfg); err != nil {
+		return err
+	}
+
+	if len(alerts.sinks()) == 0 {
+		return fmt.Errorf("no alert sink enabled, use --notify, --slack-webhook, --discord-webhook, --ntfy-topic, --telegram-token, --pushover-token, --gotify-url, --smtp-host or --webhook-url")
+	}
+
+	creds, err := fetch.credentials()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := fetch.context(parent)
+	defer cancel()
+
+	results, err := fetchQuotas(ctx, creds, fetch)
+	if err != nil {
+		return err
+	}
+
+	fetch.record(results)
+	for _, warning := range aiquota.Warnings(results) {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	// The --timeout deadline bounds fetching, not delivery.
+	return alerts.deliver(parent, results)
+}
+
+// percentList parses a comma-separated list of percents such as "75,90,100".
+type percentList []float64
+
+func (l *percentList) String() string {
+	if l == nil {
+		return ""
+	}
+
+	parts := make([]string, 0, len(*l))
+	for _, value := range *l {
+		parts = append(parts, formatPercent(value))
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func (l *percentList) Set(value string) error {
+	var parsed percentList
+	for part := range strings.SplitSeq(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		percent, err := parsePercent(part)
+		if err != nil {
+			return err
+		}
+
+		parsed = append(parsed, percent)
+	}
+
+	if len(parsed) == 0 {
+		return fmt.Errorf("at least one level is required")
+	}
+
+	*l = parsed
+	return nil
+}
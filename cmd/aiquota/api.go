@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/aiquota"
+	"github.com/eduardolat/aiquota/pkg/provider"
+	"github.com/eduardolat/aiquota/pkg/providers"
+)
+
+// quotaCache holds the latest fetch results shared by the background
+// refresh and the JSON API. Requests for results older than ttl fetch again,
+// unless ttl is zero. fetch only queries the providers; the history, alerts
+// and other side effects belong in scheduled, which only the scheduled
+// refresh runs, so API requests never record samples or fire alerts.
+type quotaCache struct {
+	ttl       time.Duration
+	fetch     func(ctx context.Context) []provider.Result
+	scheduled func(ctx context.Context, results []provider.Result)
+
+	mu      sync.Mutex
+	results []provider.Result
+	at      time.Time
+}
+
+// refresh fetches unconditionally, stores the results and runs scheduled
+// on them.
+func (c *quotaCache) refresh(ctx context.Context) ([]provider.Result, time.Time) {
+	c.mu.Lock()
+	results, at := c.store(ctx)
+	c.mu.Unlock()
+
+	if c.scheduled != nil {
+		c.scheduled(ctx, results)
+	}
+
+	return results, at
+}
+
+// get returns the cached results, fetching first when they are stale.
+// Concurrent callers wait for a single fetch.
+func (c *quotaCache) get(ctx context.Context) ([]provider.Result, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.at.IsZero() && (c.ttl <= 0 || time.Since(c.at) < c.ttl) {
+		return c.results, c.at
+	}
+
+	return c.store(ctx)
+}
+
+// store fetches and stores the results, dated when the fetch started like
+// the reports of one-shot runs.
+func (c *quotaCache) store(ctx context.Context) ([]provider.Result, time.Time) {
+	at := time.Now()
+	c.results = c.fetch(ctx)
+	c.at = at
+	return c.results, c.at
+}
+
+// ProviderReport is the machine-readable form of a single provider, served
+// by /v1/quota/{provider}.
+type ProviderReport struct {
+	Timestamp string `json:"timestamp"`
+	Provider  string `json:"provider"`
+	Name      string `json:"name"`
+	Quota     any    `json:"quota"`
+	Error     string `json:"error,omitempty"`
+}
+
+// apiHandler serves the JSON API. Fetches run on ctx rather than the request
+// context, so a client disconnecting does not cache a cancelled fetch.
+type apiHandler struct {
+	ctx   context.Context
+	cache *quotaCache
+}
+
+func (h *apiHandler) register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", h.health)
+	mux.HandleFunc("GET /v1/quota", h.quota)
+	mux.HandleFunc("GET /v1/quota/{provider}", h.providerQuota)
+}
+
+func (h *apiHandler) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *apiHandler) quota(w http.ResponseWriter, r *http.Request) {
+	results, at := h.cache.get(h.ctx)
+	writeJSON(w, http.StatusOK, aiquota.NewReport(at, results))
+}
+
+func (h *apiHandler) providerQuota(w http.ResponseWriter, r *http.Request) {
+	id := strings.ToLower(r.PathValue("provider"))
+	if _, ok := providers.Get(id); !ok {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("unknown provider %q", id))
+		return
+	}
+
+	results, at := h.cache.get(h.ctx)
+	for _, result := range results {
+		if result.Provider.ID() != id {
+			continue
+		}
+
+		report := ProviderReport{
+			Timestamp: at.UTC().Format(timestampLayout),
+			Provider:  id,
+			Name:      result.Provider.Name(),
+			Quota:     result.Quota,
+		}
+
+		status := http.StatusOK
+		if result.Err != nil {
+			report.Quota = nil
+			report.Error = result.Err.Error()
+			status = http.StatusBadGateway
+		}
+
+		writeJSON(w, status, report)
+		return
+	}
+
+	writeJSONError(w, http.StatusNotFound, fmt.Sprintf("provider %q is not enabled", id))
+}
+
+// requireToken rejects requests without the bearer token, except health
+// checks. An empty token disables authentication.
+func requireToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="aiquota"`)
+			writeJSONError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write response: %v\n", err)
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+func TestQuotaCacheRunsScheduledOnlyOnRefresh(t *testing.T) {
+	var fetches, scheduled int
+	cache := &quotaCache{
+		ttl: time.Nanosecond,
+		fetch: func(context.Context) []provider.Result {
+			fetches++
+			return nil
+		},
+		scheduled: func(context.Context, []provider.Result) { scheduled++ },
+	}
+
+	cache.refresh(t.Context())
+	time.Sleep(time.Millisecond)
+
+	// The expired results make get fetch again, without recording the
+	// samples or dispatching alerts a second time.
+	cache.get(t.Context())
+	if fetches != 2 || scheduled != 1 {
+		t.Errorf("fetches = %d, scheduled = %d, want 2 and 1", fetches, scheduled)
+	}
+}
+
+func TestQuotaCacheDatesResultsWhenTheFetchStarts(t *testing.T) {
+	var started time.Time
+	cache := &quotaCache{fetch: func(context.Context) []provider.Result {
+		started = time.Now()
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}}
+
+	if _, at := cache.refresh(t.Context()); at.After(started) {
+		t.Errorf("results dated %s, after the fetch started at %s", at, started)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"math/bits"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/varavelio/tinta"
+)
+
+// asciiOnly is set by --ascii, for dumb terminals, serial consoles and
+// Windows consoles without a Unicode font. It is set while the flags are
+// parsed, like activeTheme.
+var asciiOnly bool
+
+// asciiRunes replaces the glyphs reports draw with a single ASCII character
+// each, so box padding stays aligned. Accented letters of the translations
+// lose their accent.
+var asciiRunes = map[rune]rune{
+	'┌': '+', '┐': '+', '└': '+', '┘': '+',
+	'╭': '+', '╮': '+', '╰': '+', '╯': '+',
+	'╔': '+', '╗': '+', '╚': '+', '╝': '+',
+	'─': '-', '│': '|', '═': '=', '║': '|', '┤': '|',
+	'█': '#', '░': '.',
+	'▁': '_', '▂': '_', '▃': '-', '▄': '-', '▅': '=', '▆': '=', '▇': '#',
+	'·': '-', '✓': '+', '✗': 'x',
+	'á': 'a', 'é': 'e', 'í': 'i', 'ó': 'o', 'ú': 'u', 'ü': 'u', 'ñ': 'n',
+	'Á': 'A', 'É': 'E', 'Í': 'I', 'Ó': 'O', 'Ú': 'U', 'Ü': 'U', 'Ñ': 'N',
+	'¿': '?', '¡': '!',
+}
+
+// brailleASCII is the character of a braille cell by its number of dots.
+var brailleASCII = []rune(" ...:::##")
+
+// asciiSpinnerFrames replace spinnerFrames with --ascii.
+var asciiSpinnerFrames = []string{"|", "/", "-", `\`}
+
+// asciiText returns s in plain ASCII with --ascii, and s unchanged
+// otherwise. Braille chart cells become a character as dense as their dots,
+// and anything else outside ASCII becomes "?".
+func asciiText(s string) string {
+	if !asciiOnly {
+		return s
+	}
+
+	s = strings.ReplaceAll(s, "…", "...")
+	return strings.Map(func(r rune) rune {
+		if a, ok := asciiRunes[r]; ok {
+			return a
+		}
+
+		switch {
+		case r < utf8.RuneSelf:
+			return r
+		case r >= 0x2800 && r <= 0x28ff:
+			return brailleASCII[bits.OnesCount(uint(r-0x2800))]
+		default:
+			return '?'
+		}
+	}, s)
+}
+
+// boxBorder returns border, or its ASCII version with --ascii.
+func boxBorder(border tinta.Border) tinta.Border {
+	return tinta.Border{
+		TopLeft:     asciiText(border.TopLeft),
+		TopRight:    asciiText(border.TopRight),
+		BottomLeft:  asciiText(border.BottomLeft),
+		BottomRight: asciiText(border.BottomRight),
+		Horizontal:  asciiText(border.Horizontal),
+		Vertical:    asciiText(border.Vertical),
+	}
+}
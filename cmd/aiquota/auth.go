@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/eduardolat/aiquota/internal/i18n"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/providers"
+	"golang.org/x/term"
+)
+
+// runAuth manages provider tokens stored in the OS keychain.
+func runAuth(args []string) error {
+	flags := flag.NewFlagSet("aiquota auth", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), i18n.Sprintf("Usage: aiquota auth set|delete <provider>\n\nProviders: %s\n", strings.Join(credentials.KeychainProviders(), ", ")))
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return fmt.Errorf("expected a command and a provider")
+	}
+
+	id := strings.ToLower(flags.Arg(1))
+	p, ok := providers.Get(id)
+	if !ok {
+		return fmt.Errorf("unknown provider %q, expected one of %s", id, strings.Join(providerIDs(), ", "))
+	}
+
+	if !slices.Contains(credentials.KeychainProviders(), id) {
+		return fmt.Errorf("%s tokens cannot be stored in the keychain", p.Name())
+	}
+
+	switch flags.Arg(0) {
+	case "set":
+		token, err := readToken(p.Name())
+		if err != nil {
+			return err
+		}
+
+		if err := credentials.SetKeychainToken(id, token); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(os.Stderr, i18n.Sprintf("Stored %s token in the keychain.", p.Name()))
+		return nil
+	case "delete":
+		if err := credentials.DeleteKeychainToken(id); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(os.Stderr, i18n.Sprintf("Deleted %s token from the keychain.", p.Name()))
+		return nil
+	default:
+		flags.Usage()
+		return fmt.Errorf("unknown auth command %q", flags.Arg(0))
+	}
+}
+
+// readToken prompts for a token without echo on a terminal, or reads the
+// first line of stdin otherwise, so the token never appears in the shell
+// history.
+func readToken(name string) (string, error) {
+	var token string
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprint(os.Stderr, i18n.Sprintf("%s token: ", name))
+		raw, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token: %w", err)
+		}
+		token = string(raw)
+	} else {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("failed to read token from stdin: %w", err)
+		}
+		token = line
+	}
+
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", fmt.Errorf("empty token")
+	}
+
+	return token, nil
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/config"
+	"github.com/eduardolat/aiquota/internal/i18n"
+	"github.com/eduardolat/aiquota/internal/pricing"
+	"github.com/eduardolat/aiquota/pkg/provider"
+	"github.com/eduardolat/aiquota/pkg/providers"
+	"github.com/varavelio/tinta"
+)
+
+// Budget units, named after the config settings that set them.
+const (
+	budgetRequests = "requests"
+	budgetTokens   = "tokens"
+	budgetDollars  = "dollars"
+)
+
+// budget is a validated [[budget]] table.
+type budget struct {
+	name     string
+	provider string
+	window   string
+	unit     string
+	amount   float64
+}
+
+// budgetSet holds the configured budgets and the prices used to measure
+// dollar budgets of providers that only report tokens.
+type budgetSet struct {
+	budgets []budget
+	prices  pricing.Table
+}
+
+// newBudgetSet validates the [[budget]] tables of the config file.
+func newBudgetSet(cfg config.Config) (*budgetSet, error) {
+	set := &budgetSet{prices: priceTable(cfg.Pricing)}
+	seen := map[string]bool{}
+
+	for i, configured := range cfg.Budgets {
+		label := cmp.Or(configured.Name, fmt.Sprintf("%d", i+1))
+		if _, ok := providers.Get(configured.Provider); !ok {
+			return nil, fmt.Errorf("budget %s: unknown provider %q", label, configured.Provider)
+		}
+
+		b := budget{name: configured.Name, provider: configured.Provider, window: configured.Window}
+		for _, amount := range []struct {
+			unit  string
+			value *float64
+		}{
+			{budgetRequests, configured.Requests},
+			{budgetTokens, configured.Tokens},
+			{budgetDollars, configured.Dollars},
+		} {
+			if amount.value == nil {
+				continue
+			}
+			if b.unit != "" {
+				return nil, fmt.Errorf("budget %s sets both %s and %s, expected one of them", label, b.unit, amount.unit)
+			}
+			if *amount.value <= 0 {
+				return nil, fmt.Errorf("budget %s: %s must be greater than zero", label, amount.unit)
+			}
+			b.unit, b.amount = amount.unit, *amount.value
+		}
+
+		switch b.unit {
+		case "":
+			return nil, fmt.Errorf("budget %s sets none of requests, tokens or dollars", label)
+		case budgetRequests:
+			if b.window == "" {
+				return nil, fmt.Errorf("budget %s counts requests, set window to the ID of the window that counts them", label)
+			}
+		case budgetTokens:
+			b.window = cmp.Or(b.window, "tokens")
+		case budgetDollars:
+			b.window = cmp.Or(b.window, "cost")
+		}
+
+		key := b.provider + "/" + b.window
+		if seen[key] {
+			return nil, fmt.Errorf("budget %s: %s already has a budget for window %s", label, b.provider, b.window)
+		}
+		seen[key] = true
+
+		set.budgets = append(set.budgets, b)
+	}
+
+	return set, nil
+}
+
+// budgetStatus is how much of a budget the current month used.
+type budgetStatus struct {
+	budget
+	windowName string
+	used       float64
+	// pace compares the usage with an even spread over the month: 1.2 means
+	// 20% ahead of budget pace. It is zero early in the month.
+	pace    float64
+	resetAt time.Time
+}
+
+func (s budgetStatus) usedPercent() float64 {
+	return s.used / s.amount * 100
+}
+
+// title names the budget in alerts.
+func (s budgetStatus) title() string {
+	return i18n.Sprintf("%s budget", cmp.Or(s.name, s.windowName))
+}
+
+// minBudgetElapsed is how much of the month must pass before the pace is
+// shown, as the first hours say little about the month.
+const minBudgetElapsed = 12 * time.Hour
+
+// evaluate measures every budget whose provider answered. Budgets whose
+// window the provider does not report are skipped.
+func (s *budgetSet) evaluate(results []provider.Result, now time.Time) []budgetStatus {
+	if s == nil || len(s.budgets) == 0 {
+		return nil
+	}
+
+	now = now.UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+	elapsed := now.Sub(monthStart)
+
+	var costs map[string]pricing.Estimate
+	var statuses []budgetStatus
+	for _, b := range s.budgets {
+		index := -1
+		for i, result := range results {
+			if result.Err == nil && result.Provider.ID() == b.provider {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			continue
+		}
+
+		status := budgetStatus{budget: b, resetAt: monthEnd}
+		found := false
+		for _, window := range results[index].Quota.Windows() {
+			if window.ID == b.window && window.Used != nil {
+				status.used, status.windowName, found = *window.Used, window.Name, true
+				break
+			}
+		}
+
+		if !found && b.unit == budgetDollars && b.window == "cost" {
+			if costs == nil {
+				costs = estimateCosts(results, s.prices, now)
+			}
+			estimate, ok := costs[b.provider]
+			status.used, status.windowName, found = estimate.CostUSD, i18n.T("Estimated cost"), ok
+		}
+		if !found {
+			continue
+		}
+
+		if elapsed >= minBudgetElapsed {
+			expected := b.amount * float64(elapsed) / float64(monthEnd.Sub(monthStart))
+			status.pace = status.used / expected
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// budgetQuota carries budget consumption as windows, so the alert trackers
+// evaluate budgets like any other window.
+type budgetQuota struct {
+	windows []provider.Window
+}
+
+func (q budgetQuota) Windows() []provider.Window {
+	return q.windows
+}
+
+// results returns one result per provider with measured budgets, whose
+// windows are named budget_<window>. They are only evaluated for alerts and
+// never rendered.
+func (s *budgetSet) results(results []provider.Result, now time.Time) []provider.Result {
+	statuses := s.evaluate(results, now)
+	if len(statuses) == 0 {
+		return nil
+	}
+
+	var budgeted []provider.Result
+	index := map[string]int{}
+	for _, status := range statuses {
+		i, ok := index[status.provider]
+		if !ok {
+			p, _ := providers.Get(status.provider)
+			i = len(budgeted)
+			index[status.provider] = i
+			budgeted = append(budgeted, provider.Result{Provider: p, Quota: budgetQuota{}})
+		}
+
+		quota := budgeted[i].Quota.(budgetQuota)
+		quota.windows = append(quota.windows, provider.Window{
+			ID:          "budget_" + status.window,
+			Name:        status.title(),
+			UsedPercent: new(status.usedPercent()),
+			Used:        new(status.used),
+			Limit:       new(status.amount),
+			ResetAt:     status.resetAt.Format(time.RFC3339),
+		})
+		budgeted[i].Quota = quota
+	}
+
+	return budgeted
+}
+
+// formatBudget renders the budget line of a provider box, such as
+// "Budget: $32.10 of $50.00 (64%), 20% ahead of pace" or "Budget: 180 of
+// 250 requests (72%), on pace".
+func formatBudget(status budgetStatus) string {
+	used, amount := formatNumber(status.used), formatNumber(status.amount)+" "+i18n.T(status.unit)
+	if status.unit == budgetDollars {
+		used, amount = formatMoney(status.used), formatMoney(status.amount)
+	}
+
+	title := i18n.T("Budget:")
+	if status.name != "" {
+		title = i18n.Sprintf("Budget %s:", status.name)
+	}
+
+	line := i18n.Sprintf(
+		"%s %s of %s (%s)",
+		tinta.Text().Bold().String(title),
+		used,
+		amount,
+		colorPercent(status.usedPercent()),
+	)
+
+	switch deviation := math.Round((status.pace - 1) * 100); {
+	case status.used >= status.amount:
+		line += ", " + themeText(activeTheme.high).Bold().String(i18n.T("over budget"))
+	case status.pace == 0:
+	case deviation >= 5:
+		line += ", " + themeText(activeTheme.medium).String(i18n.Sprintf("%s%% ahead of pace", formatPercent(deviation)))
+	case deviation <= -5:
+		line += ", " + i18n.Sprintf("%s%% under pace", formatPercent(-deviation))
+	default:
+		line += ", " + i18n.T("on pace")
+	}
+
+	return line
+}
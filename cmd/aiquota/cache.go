@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+	"github.com/eduardolat/aiquota/pkg/providers"
+)
+
+// cachedResponses is a fetch answered from the response cache.
+type cachedResponses struct {
+	providers []provider.Provider
+	replay    *httpclient.Replay
+	creds     credentials.Credentials
+	// at is when the oldest of the responses was received.
+	at time.Time
+}
+
+// cachedFetch describes the last live fetch, in fetch.json next to the
+// cached responses: the providers it queried, and its credentials redacted
+// so a replay renders account details and settings without loading them.
+type cachedFetch struct {
+	Providers   []string                `json:"providers"`
+	Credentials credentials.Credentials `json:"credentials"`
+}
+
+// responseCacheDir returns the directory of the response cache,
+// $XDG_CACHE_HOME/aiquota/responses on Linux. The cache keeps the responses
+// of the last successful fetch of each provider in the --dump-raw format,
+// one directory per provider dated with the time of the fetch. --cached and
+// --max-age replay them, so shell prompts and status bars that refresh every
+// few seconds do not query the provider APIs each time. Every live fetch
+// refreshes it.
+func responseCacheDir() (string, error) {
+	dir, err := helpers.CacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "responses"), nil
+}
+
+// cachedResponses returns the cached responses of the providers selected
+// with --provider, or else of those the last live fetch queried, or nil when
+// the fetch should query the providers. It runs before credentials are
+// loaded, so a cache hit never reads them. --cached serves every provider in
+// the cache, however old, and fails when none is; --max-age only serves them
+// when every provider was fetched within that age.
+func (f *fetchFlags) cachedResponses() (*cachedResponses, error) {
+	if !f.cached && f.maxAge <= 0 {
+		return nil, nil
+	}
+
+	dir, err := responseCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	// Caches written before fetch.json existed are only served by --cached,
+	// with placeholder credentials.
+	last := cachedFetch{Credentials: credentials.Placeholder()}
+	content, readErr := os.ReadFile(filepath.Join(dir, "fetch.json"))
+	switch {
+	case readErr == nil:
+		if err := json.Unmarshal(content, &last); err != nil {
+			return nil, fmt.Errorf("invalid response cache %s: %w", dir, err)
+		}
+	case !f.cached:
+		return nil, nil
+	}
+
+	selected := []string(f.only)
+	if len(selected) == 0 && readErr == nil {
+		selected = last.Providers
+	}
+
+	cached := &cachedResponses{creds: last.Credentials}
+	for _, p := range providers.All() {
+		if selected != nil && !slices.Contains(selected, p.ID()) {
+			continue
+		}
+
+		info, err := os.Stat(filepath.Join(dir, p.ID()))
+		switch {
+		case err != nil && f.cached:
+			continue
+		case err != nil, !f.cached && time.Since(info.ModTime()) >= f.maxAge:
+			return nil, nil
+		}
+
+		cached.providers = append(cached.providers, p)
+		if cached.at.IsZero() || info.ModTime().Before(cached.at) {
+			cached.at = info.ModTime()
+		}
+	}
+
+	if len(cached.providers) == 0 && !f.cached {
+		return nil, nil
+	}
+	if len(cached.providers) == 0 {
+		return nil, fmt.Errorf("no cached responses found in %s, run aiquota once without --cached", dir)
+	}
+
+	if cached.replay, err = httpclient.NewReplay(dir); err != nil {
+		return nil, err
+	}
+
+	return cached, nil
+}
+
+// startResponseDump returns a dump into a new directory next to the
+// response cache, which saveResponses moves into it once the fetch is done.
+// The cache is private to the user, so unlike --dump-raw it keeps the
+// responses unredacted and replays render the same account details.
+// Caching is best effort, so it returns a nil dump when the directory cannot
+// be created and the fetch goes on uncached.
+func startResponseDump() (*httpclient.Dump, string) {
+	dir, err := responseCacheDir()
+	if err != nil || os.MkdirAll(filepath.Dir(dir), 0o700) != nil {
+		return nil, ""
+	}
+
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), "responses-")
+	if err != nil {
+		return nil, ""
+	}
+
+	dump, err := httpclient.NewPrivateDump(tmp)
+	if err != nil {
+		os.RemoveAll(tmp)
+		return nil, ""
+	}
+
+	return dump, tmp
+}
+
+// saveResponses replaces the cached responses of the providers that
+// answered with those dumped to tmp, dated at, records the fetch made with
+// creds in fetch.json and removes tmp. Providers that failed keep their
+// previous responses, and failures to write are ignored like in
+// startResponseDump.
+func saveResponses(tmp string, results []provider.Result, creds credentials.Credentials, at time.Time) {
+	defer os.RemoveAll(tmp)
+
+	dir, err := responseCacheDir()
+	if err != nil || os.MkdirAll(dir, 0o700) != nil {
+		return
+	}
+
+	last := cachedFetch{Credentials: creds.Redacted()}
+	for _, result := range results {
+		last.Providers = append(last.Providers, result.Provider.ID())
+	}
+	if content, err := json.Marshal(last); err == nil {
+		_ = writeFileAtomic(filepath.Join(dir, "fetch.json"), content, 0o600)
+	}
+
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+
+		from, to := filepath.Join(tmp, result.Provider.ID()), filepath.Join(dir, result.Provider.ID())
+		if _, err := os.Stat(from); err != nil {
+			continue
+		}
+
+		if os.RemoveAll(to) != nil || os.Rename(from, to) != nil {
+			continue
+		}
+		os.Chtimes(to, at, at)
+	}
+}
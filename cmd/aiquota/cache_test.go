@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/codex"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+func TestCachedReportMatchesLiveReport(t *testing.T) {
+	isolateReport(t, false)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"email":"dev@example.com","plan_type":"plus"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	report := func(results []provider.Result) string {
+		t.Helper()
+
+		var buf bytes.Buffer
+		if err := printReport(&buf, formatText, results, &outputFlags{noDiff: true}, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	dump, tmp := startResponseDump()
+	if dump == nil {
+		t.Fatal("failed to start the response dump")
+	}
+	creds := credentials.Credentials{CodexAPIKey: new("codex-token")}
+	live := provider.FetchAll(httpclient.WithDump(context.Background(), dump), creds, []provider.Provider{codex.Provider{BaseURL: server.URL}}, provider.FetchOptions{})
+	saveResponses(tmp, live, creds, time.Now())
+
+	server.Close()
+	fetch := &fetchFlags{cached: true}
+	cached, err := fetch.cachedResponses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := provider.FetchAll(httpclient.WithReplay(context.Background(), cached.replay), cached.creds, cached.providers, provider.FetchOptions{})
+
+	want := report(live)
+	if !strings.Contains(want, "dev@example.com") {
+		t.Fatalf("live report lacks the account email:\n%s", want)
+	}
+	if got := report(replayed); got != want {
+		t.Errorf("cached report differs from the live report\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/internal/i18n"
+	"github.com/eduardolat/aiquota/pkg/provider"
+	"github.com/varavelio/tinta"
+)
+
+// compactReport renders one line per provider for --compact, such as
+// "Copilot 28% resets 4d 12h" or "Codex P:61% S:12%". Providers with several
+// fixed windows label each with the initials of its ID and leave out resets
+// to keep the line short. Providers with a window per model or region only
+// show their most used window.
+func compactReport(results []provider.Result) string {
+	lines := make([]string, 0, len(results))
+	for _, result := range results {
+		name := tinta.Text().Bold().String(result.Provider.Name())
+		if result.Err != nil {
+			lines = append(lines, name+" "+themeText(activeTheme.high).String("✗ "+progressError(result.Err)))
+			continue
+		}
+
+		windows := result.Quota.Windows()
+		labels, labeled := windowLabels(windows)
+		switch {
+		case len(windows) == 0:
+			lines = append(lines, name+" "+tinta.Text().Dim().String(i18n.T("no data")))
+		case len(windows) == 1:
+			lines = append(lines, name+" "+compactWindow(windows[0]))
+		case labeled:
+			parts := []string{name}
+			for i, window := range windows {
+				parts = append(parts, labels[i]+":"+compactUsage(window))
+			}
+			lines = append(lines, strings.Join(parts, " "))
+		default:
+			window, ok := mostUsedWindow(result.Quota)
+			if !ok {
+				window = windows[0]
+			}
+			lines = append(lines, name+" "+compactWindow(window)+" "+tinta.Text().Dim().String("("+i18n.T(window.Name)+")"))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// compactWindow is the usage of a window followed by when it resets.
+func compactWindow(window provider.Window) string {
+	text := compactUsage(window)
+	if reset := helpers.FormatTimeUntil(window.ResetAt); reset != "unknown" {
+		text += " " + i18n.Sprintf("resets %s", i18n.T(reset))
+	}
+
+	return text
+}
+
+// compactUsage is the used percent of a window, or the remaining or used
+// amount for windows without a limit.
+func compactUsage(window provider.Window) string {
+	switch {
+	case window.UsedPercent != nil:
+		return colorPercent(*window.UsedPercent)
+	case window.Remaining != nil:
+		return i18n.Sprintf("%s left", formatNumber(*window.Remaining))
+	case window.Used != nil:
+		return i18n.Sprintf("%s used", formatNumber(*window.Used))
+	default:
+		return i18n.T("n/a")
+	}
+}
+
+// windowLabels abbreviates window IDs such as "code_review" to "CR". It
+// fails when an ID is not a plain snake_case word, as with per-model
+// windows, or when two windows would share a label.
+func windowLabels(windows []provider.Window) ([]string, bool) {
+	labels := make([]string, 0, len(windows))
+	for _, window := range windows {
+		var label strings.Builder
+		for word := range strings.SplitSeq(window.ID, "_") {
+			if word == "" || strings.IndexFunc(word, func(r rune) bool { return r < 'a' || r > 'z' }) >= 0 {
+				return nil, false
+			}
+			label.WriteString(strings.ToUpper(word[:1]))
+		}
+
+		if slices.Contains(labels, label.String()) {
+			return nil, false
+		}
+		labels = append(labels, label.String())
+	}
+
+	return labels, true
+}
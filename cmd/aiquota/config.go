@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/eduardolat/aiquota/internal/config"
+)
+
+// parseFlags adds the --config flag, parses args and then fills every flag
+// not given on the command line from the config file, so explicit flags
+// always win over configured defaults.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	_, err := parseFlagsConfig(flags, args)
+	return err
+}
+
+// parseFlagsConfig is parseFlags for commands that also need the settings
+// without a flag, such as alert rules.
+func parseFlagsConfig(flags *flag.FlagSet, args []string) (config.Config, error) {
+	configPath := flags.String("config", "", "path to config.toml (default $AIQUOTA_CONFIG, then $XDG_CONFIG_HOME/aiquota/config.toml)")
+	if err := flags.Parse(args); err != nil {
+		return config.Config{}, err
+	}
+
+	path, required := *configPath, *configPath != ""
+	if !required {
+		var err error
+		if path, err = config.DefaultPath(); err != nil {
+			return config.Config{}, err
+		}
+	}
+
+	cfg, err := config.Load(path, required)
+	if err != nil {
+		return config.Config{}, err
+	}
+
+	values := cfg.FlagValues()
+	explicit := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	for name, value := range values {
+		if explicit[name] || flags.Lookup(name) == nil {
+			continue
+		}
+
+		// The format setting is the report format. Commands whose --format
+		// means something else do not take it.
+		if _, report := flags.Lookup(name).Value.(*reportFormat); name == "format" && !report {
+			continue
+		}
+
+		if err := flags.Set(name, value); err != nil {
+			return config.Config{}, fmt.Errorf("invalid %s in config file %s: %w", name, path, err)
+		}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/config"
+	"github.com/eduardolat/aiquota/internal/i18n"
+	"github.com/eduardolat/aiquota/internal/pricing"
+	"github.com/eduardolat/aiquota/pkg/provider"
+	"github.com/varavelio/tinta"
+)
+
+// priceTable returns the default prices with the [pricing] overrides of the
+// config file applied.
+func priceTable(configured map[string]config.PriceConfig) pricing.Table {
+	overrides := pricing.Table{}
+	for model, price := range configured {
+		model = strings.ToLower(model)
+		merged := pricing.Defaults[model]
+		if price.Input != nil {
+			merged.Input = *price.Input
+		}
+		if price.CachedInput != nil {
+			merged.CachedInput = *price.CachedInput
+		}
+		if price.Output != nil {
+			merged.Output = *price.Output
+		}
+		if price.Request != nil {
+			merged.Request = *price.Request
+		}
+		overrides[model] = merged
+	}
+
+	return pricing.Defaults.With(overrides)
+}
+
+// estimateCosts prices the results whose quotas report token usage, keyed by
+// provider ID.
+func estimateCosts(results []provider.Result, table pricing.Table, now time.Time) map[string]pricing.Estimate {
+	costs := map[string]pricing.Estimate{}
+	for _, result := range results {
+		reporter, ok := result.Quota.(provider.TokenReporter)
+		if result.Err != nil || !ok {
+			continue
+		}
+
+		usage, start, end := reporter.TokenUsage()
+		if estimate, ok := table.EstimateUsage(usage, start, end, now); ok {
+			costs[result.Provider.ID()] = estimate
+		}
+	}
+
+	return costs
+}
+
+// formatCost renders the estimated cost line of a provider box.
+func formatCost(estimate pricing.Estimate) string {
+	line := tinta.Text().Bold().String(i18n.T("Estimated cost:")) + " " + formatMoney(estimate.CostUSD)
+	if estimate.ProjectedUSD != nil {
+		line += i18n.Sprintf(", %s projected for the month", formatMoney(*estimate.ProjectedUSD))
+	}
+	if len(estimate.Unpriced) > 0 {
+		line += " " + tinta.Text().Dim().String(i18n.Sprintf("(no price for %s)", strings.Join(estimate.Unpriced, ", ")))
+	}
+
+	return line
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// csvLogHeader names the columns of the --log-csv file.
+var csvLogHeader = []string{"timestamp", "provider", "window", "used_percent", "remaining", "reset_at", "error"}
+
+// appendCSVLog appends one row per provider to the log at path, writing the
+// header first when the file is new. A .tsv extension switches the
+// separator to tabs.
+func appendCSVLog(path string, at time.Time, results []provider.Result) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create log directory: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open CSV log: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to open CSV log: %w", err)
+	}
+
+	writer := csv.NewWriter(file)
+	if strings.EqualFold(filepath.Ext(path), ".tsv") {
+		writer.Comma = '\t'
+	}
+
+	if info.Size() == 0 {
+		if err := writer.Write(csvLogHeader); err != nil {
+			return fmt.Errorf("failed to write CSV log: %w", err)
+		}
+	}
+
+	timestamp := at.UTC().Format(timestampLayout)
+	for _, result := range results {
+		if err := writer.Write(csvLogRow(timestamp, result)); err != nil {
+			return fmt.Errorf("failed to write CSV log: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV log: %w", err)
+	}
+
+	return nil
+}
+
+// csvLogRow describes a provider by its most used window, or its first
+// window when none reports a percent, such as prepaid balances.
+func csvLogRow(timestamp string, result provider.Result) []string {
+	row := []string{timestamp, result.Provider.ID(), "", "", "", "", ""}
+	if result.Err != nil {
+		row[6] = result.Err.Error()
+		return row
+	}
+
+	window, ok := mostUsedWindow(result.Quota)
+	if !ok {
+		windows := result.Quota.Windows()
+		if len(windows) == 0 {
+			return row
+		}
+		window = windows[0]
+	}
+
+	row[2] = window.ID
+	if window.UsedPercent != nil {
+		row[3] = strconv.FormatFloat(*window.UsedPercent, 'f', -1, 64)
+	}
+
+	switch {
+	case window.Remaining != nil:
+		row[4] = strconv.FormatFloat(*window.Remaining, 'f', -1, 64)
+	case window.Used != nil && window.Limit != nil:
+		row[4] = strconv.FormatFloat(*window.Limit-*window.Used, 'f', -1, 64)
+	}
+
+	if window.ResetAt != "unknown" {
+		row[5] = window.ResetAt
+	}
+
+	return row
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/alert"
+	"github.com/eduardolat/aiquota/internal/config"
+	"github.com/eduardolat/aiquota/internal/influx"
+	"github.com/eduardolat/aiquota/internal/otlp"
+	"github.com/eduardolat/aiquota/internal/prometheus"
+	"github.com/eduardolat/aiquota/pkg/provider"
+	"github.com/eduardolat/aiquota/pkg/providers"
+	"github.com/robfig/cron/v3"
+)
+
+// runDaemon polls providers on a schedule, records every snapshot in the
+// history, evaluates the configured alert rules and serves the metrics and
+// JSON API endpoints.
+func runDaemon(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("aiquota daemon", flag.ContinueOnError)
+	spec := flags.String("schedule", "*/5 * * * *", "when to poll providers, as a cron expression, @hourly-style descriptor or @every duration")
+	influxURL := flags.String("influx-url", os.Getenv("AIQUOTA_INFLUX_URL"), "push every snapshot to this InfluxDB write URL, such as http://localhost:8086/api/v2/write?org=home&bucket=aiquota (default $AIQUOTA_INFLUX_URL)")
+	influxToken := flags.String("influx-token", os.Getenv("AIQUOTA_INFLUX_TOKEN"), "InfluxDB API token for --influx-url (default $AIQUOTA_INFLUX_TOKEN)")
+	otlpExport := flags.Bool("otlp", os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT") != "", "export metrics to the OpenTelemetry collector set by the OTEL_EXPORTER_OTLP_* variables (default true when an endpoint is set)")
+	spikeFactor := flags.Float64("spike-factor", 3, "alert when a window is used this many times faster than its trailing 6h average (0 disables)")
+	spikeMinRate := flags.Float64("spike-min-rate", 10, "ignore usage spikes slower than this many percent per hour")
+	summarySpec := flags.String("email-summary", "", "when to email a report summary, as a cron expression such as '0 8 * * *'; needs the email sink settings")
+	server := addServerFlags(flags, ":9108")
+	fetch := addFetchFlags(flags)
+	alerts := addAlertFlags(flags)
+	ping := addPingFlag(flags)
+	cfg, err := parseFlagsConfig(flags, args)
+	if err != nil {
+		return err
+	}
+
+	schedule, err := cron.ParseStandard(*spec)
+	if err != nil {
+		return fmt.Errorf("invalid schedule %q: %w", *spec, err)
+	}
+
+	if server.cacheTTL < 0 {
+		return fmt.Errorf("cache-ttl must not be negative")
+	}
+
+	if *spikeFactor < 0 || *spikeMinRate < 0 {
+		return fmt.Errorf("spike-factor and spike-min-rate must not be negative")
+	}
+
+	rules, err := newAlertRules(cfg.Alerts, alerts)
+	if err != nil {
+		return err
+	}
+
+	if alerts.budgets, err = newBudgetSet(cfg); err != nil {
+		return err
+	}
+
+	var summary cron.Schedule
+	if *summarySpec != "" {
+		if summary, err = cron.ParseStandard(*summarySpec); err != nil {
+			return fmt.Errorf("invalid email summary schedule %q: %w", *summarySpec, err)
+		}
+		if _, ok := alerts.sink("email"); !ok {
+			return fmt.Errorf("--email-summary needs --smtp-host, --email-from and --email-to")
+		}
+	}
+
+	creds, err := fetch.credentials()
+	if err != nil {
+		return err
+	}
+
+	enabled, err := fetch.enabled(creds)
+	if err != nil {
+		return err
+	}
+
+	var exporter *otlp.Exporter
+	if *otlpExport {
+		if exporter, err = otlp.FromEnv(); err != nil {
+			return err
+		}
+	}
+
+	spikes := &alert.SpikeDetector{Factor: *spikeFactor, MinRate: *spikeMinRate}
+	collector := prometheus.NewCollector()
+	cache := &quotaCache{
+		ttl: server.cacheTTL,
+		fetch: func(ctx context.Context) []provider.Result {
+			fetchCtx, cancel := fetch.context(ctx)
+			defer cancel()
+			opts := fetch.options()
+			if exporter != nil {
+				opts.OnResult = exporter.Observe
+			}
+			results := provider.FetchAll(fetchCtx, creds, enabled, opts)
+			collector.Update(results)
+			return results
+		},
+		scheduled: func(ctx context.Context, results []provider.Result) {
+			fetch.record(results)
+			if *influxURL != "" {
+				if err := influx.Push(ctx, *influxURL, *influxToken, time.Now(), results); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				}
+			}
+			if exporter != nil {
+				if err := exporter.Export(ctx, time.Now(), results); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				}
+			}
+			alerts.dispatch(ctx, results)
+			budgeted := append(slices.Clone(results), alerts.budgets.results(results, time.Now())...)
+			for _, rule := range rules {
+				rule.dispatch(ctx, budgeted)
+			}
+			if *spikeFactor > 0 {
+				events := spikes.Evaluate(time.Now(), results)
+				alerts.deliverEvents(ctx, events)
+				for _, rule := range rules {
+					rule.deliverEvents(ctx, events)
+				}
+			}
+			if ctx.Err() == nil {
+				ping.send(ctx, results, nil)
+			}
+		},
+	}
+
+	cache.refresh(ctx)
+
+	go every(ctx, schedule, func() { cache.refresh(ctx) })
+	if summary != nil {
+		go every(ctx, summary, func() { alerts.sendSummary(ctx, cache) })
+	}
+
+	if server.listen == "" {
+		<-ctx.Done()
+		return nil
+	}
+
+	return server.serve(ctx, collector, cache)
+}
+
+// every calls fn at each activation of schedule until ctx is done.
+func every(ctx context.Context, schedule cron.Schedule, fn func()) {
+	for {
+		timer := time.NewTimer(time.Until(schedule.Next(time.Now())))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			fn()
+		}
+	}
+}
+
+// sendSummary emails the cached report, rendered as the plain text report
+// and, with --email-html, the HTML report. Failures are reported on stderr.
+func (a *alertFlags) sendSummary(ctx context.Context, cache *quotaCache) {
+	sink, _ := a.sink("email")
+	email := sink.(alert.Email)
+	results, at := cache.get(ctx)
+
+	var page bytes.Buffer
+	if err := printHTML(&page, at, results); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: email summary: %v\n", err)
+		return
+	}
+
+	subject := "AI quota summary " + at.Format("2006-01-02")
+	if err := email.SendMessage(ctx, subject, plainReport(results)+"\n", page.String()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: email summary: %v\n", err)
+	}
+}
+
+// alertRule is a configured [[alert]] rule with its sinks resolved.
+type alertRule struct {
+	name      string
+	providers []string
+	windows   []string
+	levels    []float64
+	sinks     []alert.Sink
+}
+
+// newAlertRules validates the configured rules. Sinks take their settings
+// from the alert flags, which the config file also fills.
+func newAlertRules(configured []config.AlertRule, alerts *alertFlags) ([]alertRule, error) {
+	rules := make([]alertRule, 0, len(configured))
+	names := map[string]bool{}
+
+	for i, rule := range configured {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("alert rule %d has no name", i+1)
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("duplicate alert rule %q", rule.Name)
+		}
+		names[rule.Name] = true
+
+		for _, id := range rule.Providers {
+			if _, ok := providers.Get(id); !ok {
+				return nil, fmt.Errorf("alert rule %q: unknown provider %q", rule.Name, id)
+			}
+		}
+
+		levels := rule.Levels
+		if len(levels) == 0 {
+			levels = alert.DefaultLevels
+		}
+
+		if len(rule.Sinks) == 0 {
+			return nil, fmt.Errorf("alert rule %q has no sinks", rule.Name)
+		}
+
+		var sinks []alert.Sink
+		for _, name := range rule.Sinks {
+			if !slices.Contains(alertSinks, name) {
+				return nil, fmt.Errorf("alert rule %q: unknown sink %q, expected one of %s", rule.Name, name, strings.Join(alertSinks, ", "))
+			}
+
+			sink, ok := alerts.sink(name)
+			if !ok {
+				return nil, fmt.Errorf("alert rule %q uses %s, but the %s sink is not configured", rule.Name, name, name)
+			}
+			sinks = append(sinks, sink)
+		}
+
+		rules = append(rules, alertRule{
+			name:      rule.Name,
+			providers: rule.Providers,
+			windows:   rule.Windows,
+			levels:    levels,
+			sinks:     sinks,
+		})
+	}
+
+	return rules, nil
+}
+
+// dispatch evaluates the matching windows and delivers new events to the
+// rule's sinks. Every rule keeps its own state, so rules do not silence each
+// other or the --notify style flags. Failures are reported on stderr.
+func (r alertRule) dispatch(ctx context.Context, results []provider.Result) {
+	var matched []provider.Result
+	for _, result := range results {
+		if result.Err != nil || (len(r.providers) > 0 && !slices.Contains(r.providers, result.Provider.ID())) {
+			continue
+		}
+
+		if len(r.windows) > 0 {
+			result.Quota = windowFilter{Quota: result.Quota, ids: r.windows}
+		}
+		matched = append(matched, result)
+	}
+
+	tracker, err := alert.NewTracker("rule-"+r.name, r.levels)
+	if err == nil {
+		var events []alert.Event
+		if events, err = tracker.Evaluate(matched); err == nil {
+			err = alert.SendAll(ctx, r.sinks, events)
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: alert rule %q: %v\n", r.name, err)
+	}
+}
+
+// deliverEvents delivers the events of the rule's providers and windows to its
+// sinks. Failures are reported on stderr.
+func (r alertRule) deliverEvents(ctx context.Context, events []alert.Event) {
+	matched := slices.DeleteFunc(slices.Clone(events), func(event alert.Event) bool {
+		return (len(r.providers) > 0 && !slices.Contains(r.providers, event.ProviderID)) ||
+			(len(r.windows) > 0 && !slices.Contains(r.windows, event.WindowID))
+	})
+
+	if err := alert.SendAll(ctx, r.sinks, matched); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: alert rule %q: %v\n", r.name, err)
+	}
+}
+
+// windowFilter narrows a quota to the windows with the given IDs.
+type windowFilter struct {
+	provider.Quota
+	ids []string
+}
+
+func (f windowFilter) Windows() []provider.Window {
+	return slices.DeleteFunc(f.Quota.Windows(), func(window provider.Window) bool {
+		return !slices.Contains(f.ids, window.ID)
+	})
+}
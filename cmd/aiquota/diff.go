@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/internal/i18n"
+	"github.com/eduardolat/aiquota/pkg/provider"
+	"github.com/varavelio/tinta"
+)
+
+// snapshotWindow is a window as seen by the last report.
+type snapshotWindow struct {
+	At     time.Time       `json:"at"`
+	Window provider.Window `json:"window"`
+}
+
+func lastReportPath() (string, error) {
+	dir, err := helpers.StateDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "last-report.json"), nil
+}
+
+// deltaAnnotations compares the results with the windows of the previous
+// report and then stores the results as the new previous report. Windows of
+// providers that failed keep their older snapshot. The comparison is best
+// effort: a missing or unreadable snapshot yields no annotations.
+func deltaAnnotations(results []provider.Result) map[string][]string {
+	annotations := map[string][]string{}
+
+	path, err := lastReportPath()
+	if err != nil {
+		return annotations
+	}
+
+	previous := map[string]snapshotWindow{}
+	if content, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(content, &previous)
+	}
+
+	now := time.Now()
+	key := tinta.Text().Bold()
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+
+		for _, window := range result.Quota.Windows() {
+			id := annotationKey(result.Provider.ID(), window.ID)
+			if before, ok := previous[id]; ok {
+				if change, ok := describeChange(before.Window, window); ok {
+					annotations[id] = append(annotations[id], i18n.Sprintf(
+						"%s %s in the last %s",
+						key.String(i18n.T("Change:")),
+						change,
+						formatAge(now.Sub(before.At)),
+					))
+				}
+			}
+
+			previous[id] = snapshotWindow{At: now, Window: window}
+		}
+	}
+
+	if err := saveLastReport(path, previous); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	return annotations
+}
+
+// describeChange formats how a window moved, preferring absolute usage over
+// percent and remaining balance. Unchanged windows report false.
+func describeChange(before provider.Window, after provider.Window) (string, bool) {
+	var from, to *float64
+	suffix := ""
+	switch {
+	case before.Used != nil && after.Used != nil:
+		from, to = before.Used, after.Used
+	case before.UsedPercent != nil && after.UsedPercent != nil:
+		from, to, suffix = before.UsedPercent, after.UsedPercent, "%"
+	case before.Remaining != nil && after.Remaining != nil:
+		from, to, suffix = before.Remaining, after.Remaining, " remaining"
+	default:
+		return "", false
+	}
+
+	if before.ResetAt != after.ResetAt && *to < *from && suffix != " remaining" {
+		return i18n.T("reset"), true
+	}
+
+	delta := *to - *from
+	if delta == 0 {
+		return "", false
+	}
+
+	sign := ""
+	if delta > 0 {
+		sign = "+"
+	}
+
+	return sign + formatNumber(delta) + i18n.T(suffix), true
+}
+
+func formatAge(age time.Duration) string {
+	if age < time.Minute {
+		return i18n.T("minute")
+	}
+
+	return helpers.FormatDuration(age)
+}
+
+func saveLastReport(path string, windows map[string]snapshotWindow) error {
+	content, err := json.Marshal(windows)
+	if err != nil {
+		return fmt.Errorf("failed to encode last report: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		return fmt.Errorf("failed to write last report: %w", err)
+	}
+
+	return nil
+}
+
+// mergeAnnotations appends the lines of every extra map to annotations.
+func mergeAnnotations(annotations map[string][]string, extra ...map[string][]string) map[string][]string {
+	for _, more := range extra {
+		for key, lines := range more {
+			annotations[key] = append(annotations[key], lines...)
+		}
+	}
+
+	return annotations
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/internal/i18n"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+	"github.com/eduardolat/aiquota/pkg/providers"
+	"github.com/varavelio/tinta"
+)
+
+// reachTimeout bounds the reachability request sent to each endpoint.
+const reachTimeout = 5 * time.Second
+
+// reachClient sends the reachability requests through the transport of
+// httpclient.Default, so they are logged with --debug, redacted and retried
+// like quota requests, within reachTimeout.
+var reachClient = &http.Client{Transport: httpclient.Default.Transport, Timeout: reachTimeout}
+
+// tokenPrefixes are the known prefixes of provider tokens. A token without
+// any of them was most likely pasted from the wrong place.
+var tokenPrefixes = map[string][]string{
+	"copilot":       {"gho_", "ghu_", "ghp_", "github_pat_", "tid="},
+	"copilot-org":   {"ghp_", "github_pat_", "gho_"},
+	"github-models": {"ghp_", "github_pat_", "gho_", "ghu_"},
+	"anthropic":     {"sk-ant-"},
+	"anthropic-api": {"sk-ant-admin"},
+	"openrouter":    {"sk-or-"},
+	"replicate":     {"r8_"},
+	"groq":          {"gsk_"},
+	"xai":           {"xai-"},
+	"perplexity":    {"pplx-"},
+	"deepseek":      {"sk-"},
+	"moonshot":      {"sk-"},
+	"openai":        {"sk-admin-"},
+	"gemini":        {"ya29."},
+}
+
+// doctorCheck is the outcome of one diagnostic. A check with a fix failed;
+// warn marks problems aiquota works around.
+type doctorCheck struct {
+	ok     bool
+	warn   bool
+	detail string
+	fix    string
+}
+
+func pass(detail string) doctorCheck {
+	return doctorCheck{ok: true, detail: detail}
+}
+
+func runDoctor(parent context.Context, args []string) error {
+	flags := flag.NewFlagSet("aiquota doctor", flag.ContinueOnError)
+	fetch := addFetchFlags(flags)
+	output := addOutputFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	output.apply()
+
+	failures := 0
+	report := func(title string, checks []doctorCheck) {
+		fmt.Println(tinta.Text().Bold().String(title))
+		for _, check := range checks {
+			switch {
+			case !check.ok:
+				failures++
+				fmt.Println("  " + themeText(activeTheme.high).Bold().String(asciiText("✗")) + " " + check.detail)
+				fmt.Println("    " + themeText(activeTheme.medium).String(i18n.Sprintf("Fix: %s", check.fix)))
+			case check.warn:
+				fmt.Println("  " + themeText(activeTheme.medium).Bold().String("!") + " " + check.detail)
+			default:
+				fmt.Println("  " + themeText(activeTheme.low).Bold().String(asciiText("✓")) + " " + check.detail)
+			}
+		}
+		fmt.Println()
+	}
+
+	report(i18n.T("Auth file"), []doctorCheck{checkAuthFile(fetch.authFile)})
+
+	creds, err := fetch.credentials()
+	if err != nil {
+		// The auth file check already explains why.
+		creds = credentials.Credentials{}
+	}
+
+	var configured []provider.Provider
+	var unconfigured []string
+	for _, p := range providers.All() {
+		if len(fetch.only) > 0 && !slices.Contains(fetch.only, p.ID()) {
+			continue
+		}
+
+		if !p.Enabled(creds) {
+			unconfigured = append(unconfigured, p.ID())
+			continue
+		}
+
+		if baseURL, ok := fetch.baseURL.values[p.ID()]; ok {
+			if setter, ok := p.(provider.BaseURLSetter); ok {
+				p = setter.WithBaseURL(baseURL)
+			}
+		}
+
+		configured = append(configured, p)
+	}
+
+	ctx, cancel := fetch.context(parent)
+	defer cancel()
+
+	results := provider.FetchAll(ctx, creds, configured, fetch.options())
+	for i, p := range configured {
+		checks := checkToken(p.ID(), creds)
+		reachable := true
+		if endpointer, ok := p.(provider.Endpointer); ok {
+			check := checkReachable(httpclient.WithProvider(ctx, p.ID()), endpointer.Endpoint())
+			checks = append(checks, check)
+			reachable = check.ok
+		}
+
+		// The quota request would only repeat a network error.
+		if reachable {
+			checks = append(checks, checkFetch(p, results[i].Err))
+		}
+
+		report(p.Name(), checks)
+	}
+
+	if len(unconfigured) > 0 {
+		fmt.Println(tinta.Text().Dim().String(i18n.Sprintf("Not configured: %s", strings.Join(unconfigured, ", "))))
+		fmt.Println()
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("doctor found %s", helpers.Plural(failures, "1 problem", fmt.Sprintf("%d problems", failures)))
+	}
+
+	return nil
+}
+
+func checkAuthFile(override string) doctorCheck {
+	path, err := credentials.AuthFilePath(override)
+	if err != nil {
+		return doctorCheck{detail: err.Error(), fix: i18n.T("set --auth-file or AIQUOTA_AUTH_FILE to the OpenCode auth.json")}
+	}
+
+	content, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return doctorCheck{
+			ok:     true,
+			warn:   true,
+			detail: i18n.Sprintf("%s does not exist, so only CLI files, the keychain and AIQUOTA_* variables are used", path),
+		}
+	case errors.Is(err, os.ErrPermission):
+		return doctorCheck{detail: i18n.Sprintf("%s is not readable", path), fix: i18n.Sprintf("run `chmod 600 %s` as its owner", path)}
+	case err != nil:
+		return doctorCheck{detail: err.Error(), fix: i18n.Sprintf("check that %s is a regular file", path)}
+	}
+
+	var document map[string]any
+	if err := json.Unmarshal(content, &document); err != nil {
+		return doctorCheck{
+			detail: i18n.Sprintf("%s is not valid JSON: %v", path, err),
+			fix:    i18n.T("repair the file, or log in again from OpenCode to rewrite it"),
+		}
+	}
+
+	return pass(i18n.Sprintf("%s is readable and holds %d %s", path, len(document), i18n.Plural(len(document), "entry", "entries")))
+}
+
+// checkToken validates the shape of a provider token and, for JWTs and the
+// Gemini OAuth token, its expiry.
+func checkToken(id string, creds credentials.Credentials) []doctorCheck {
+	token := creds.Token(id)
+	if token == nil {
+		return nil
+	}
+
+	resetHint := i18n.Sprintf("store a fresh token with `aiquota auth set %s` or update auth.json", id)
+	if strings.TrimSpace(*token) != *token || strings.ContainsAny(*token, " \t\n\"'") {
+		return []doctorCheck{{detail: i18n.T("token contains whitespace or quotes, likely a copy and paste mistake"), fix: resetHint}}
+	}
+
+	prefixes := tokenPrefixes[id]
+	if len(prefixes) > 0 && !slices.ContainsFunc(prefixes, func(prefix string) bool { return strings.HasPrefix(*token, prefix) }) {
+		return []doctorCheck{{
+			detail: i18n.Sprintf("token does not start with %s, it may belong to another service", strings.Join(prefixes, ", ")),
+			fix:    resetHint,
+		}}
+	}
+
+	checks := []doctorCheck{pass(i18n.T("token looks valid"))}
+
+	expiry, isJWT := helpers.JWTExpiry(*token)
+	if id == "gemini" && creds.GeminiTokenExpiry != nil {
+		expiry, isJWT = *creds.GeminiTokenExpiry, true
+	}
+
+	switch {
+	case !isJWT:
+	case time.Now().Before(expiry):
+		checks = append(checks, pass(i18n.Sprintf("token expires in %s", helpers.FormatDuration(time.Until(expiry)))))
+	case id == "codex" && credentials.HasValue(creds.CodexRefreshToken):
+		checks = append(checks, doctorCheck{
+			ok:     true,
+			warn:   true,
+			detail: i18n.Sprintf("token expired %s ago and will be refreshed", helpers.FormatDuration(time.Since(expiry))),
+		})
+	case id == "gemini":
+		checks = append(checks, doctorCheck{
+			detail: i18n.Sprintf("token expired %s ago", helpers.FormatDuration(time.Since(expiry))),
+			fix:    i18n.T("run the gemini CLI once to refresh ~/.gemini/oauth_creds.json"),
+		})
+	default:
+		checks = append(checks, doctorCheck{
+			detail: i18n.Sprintf("token expired %s ago", helpers.FormatDuration(time.Since(expiry))),
+			fix:    i18n.Sprintf("log in again from OpenCode or the provider CLI, or %s", resetHint),
+		})
+	}
+
+	return checks
+}
+
+// checkReachable sends a plain request to the endpoint. Any HTTP response,
+// even an error status, means the network path works.
+func checkReachable(ctx context.Context, endpoint string) doctorCheck {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, endpoint, nil)
+	if err != nil {
+		return doctorCheck{detail: i18n.Sprintf("invalid endpoint %s: %v", endpoint, err), fix: i18n.T("correct the --base-url or base_url setting")}
+	}
+
+	start := time.Now()
+	response, err := reachClient.Do(req)
+	if err != nil {
+		return doctorCheck{
+			detail: i18n.Sprintf("%s is unreachable: %v", endpoint, err),
+			fix:    i18n.T("check the network, DNS and any HTTPS_PROXY setting, or the --base-url override"),
+		}
+	}
+	response.Body.Close()
+
+	return pass(i18n.Sprintf("%s is reachable (%s)", endpoint, time.Since(start).Round(time.Millisecond)))
+}
+
+// checkFetch turns the error of a quota request into a suggestion.
+func checkFetch(p provider.Provider, err error) doctorCheck {
+	if err == nil {
+		return pass(i18n.T("quota request succeeded"))
+	}
+
+	message := err.Error()
+	fix := i18n.Sprintf("run `aiquota --provider %s` to see the full response", p.ID())
+	switch {
+	case errors.Is(err, provider.ErrTimeout):
+		fix = i18n.Sprintf("the provider is slow, raise --provider-timeout %s=30s", p.ID())
+	case errors.Is(err, provider.ErrInterrupted):
+		fix = i18n.T("the check was interrupted, run `aiquota doctor` again")
+	case strings.Contains(message, "Status: 401"), strings.Contains(message, "Status: 403"):
+		fix = i18n.Sprintf("the token was rejected, it may be revoked or lack permissions; store a new one with `aiquota auth set %s`", p.ID())
+	case strings.Contains(message, "Status: 404"):
+		fix = i18n.T("the endpoint does not exist, check the --base-url override or the account plan")
+	case strings.Contains(message, "Status: 429"):
+		fix = i18n.T("the account is rate limited, wait a minute or lower how often aiquota runs")
+	case strings.Contains(message, "Status: 5"):
+		fix = i18n.T("the provider is having problems, try again later")
+	}
+
+	return doctorCheck{detail: i18n.Sprintf("quota request failed: %s", message), fix: fix}
+}
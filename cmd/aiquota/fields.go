@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/eduardolat/aiquota/pkg/aiquota"
+)
+
+// reportFields is the value of --fields: dotted paths into the report, such
+// as copilot.requestsRemaining. Paths that do not start with timestamp,
+// warnings or providers are read under providers.
+type reportFields [][]string
+
+func (f *reportFields) String() string {
+	if f == nil {
+		return ""
+	}
+
+	paths := make([]string, 0, len(*f))
+	for _, path := range *f {
+		paths = append(paths, strings.Join(path, "."))
+	}
+
+	return strings.Join(paths, ",")
+}
+
+func (f *reportFields) Set(value string) error {
+	for field := range strings.SplitSeq(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		path := strings.Split(field, ".")
+		if strings.Contains(field, "..") || path[0] == "" || path[len(path)-1] == "" {
+			return fmt.Errorf("invalid field %q, expected a dotted path such as copilot.requestsRemaining", field)
+		}
+
+		switch path[0] {
+		case "timestamp", "warnings", "providers":
+		default:
+			path = append([]string{"providers"}, path...)
+		}
+
+		*f = append(*f, path)
+	}
+
+	return nil
+}
+
+// selectFields returns the report document keeping only the fields, in the
+// shape of the full report. A path through a list selects the rest of the
+// path in every item, and paths missing from the report are left out, such
+// as those of a provider that failed.
+func selectFields(report aiquota.Report, fields reportFields) (any, error) {
+	document, err := reportDocument(report)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected any = map[string]any{}
+	for _, path := range fields {
+		if value, ok := selectPath(document, path); ok {
+			selected = mergeSelected(selected, value)
+		}
+	}
+
+	return dropMissing(selected), nil
+}
+
+// selectPath returns value reduced to path. Lists keep an item per item,
+// nil where the path is missing, so selections of several paths merge item
+// by item.
+func selectPath(value any, path []string) (any, bool) {
+	if len(path) == 0 {
+		return value, true
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		key, item, ok := lookupField(value, path[0])
+		if !ok {
+			return nil, false
+		}
+
+		selected, ok := selectPath(item, path[1:])
+		if !ok {
+			return nil, false
+		}
+
+		return map[string]any{key: selected}, true
+	case []any:
+		items, found := make([]any, len(value)), false
+		for i, item := range value {
+			if selected, ok := selectPath(item, path); ok {
+				items[i], found = selected, true
+			}
+		}
+
+		return items, found
+	default:
+		return nil, false
+	}
+}
+
+// lookupField finds key in m, falling back to a case-insensitive match so
+// requestsremaining finds requestsRemaining.
+func lookupField(m map[string]any, key string) (string, any, bool) {
+	if item, ok := m[key]; ok {
+		return key, item, true
+	}
+
+	for name, item := range m {
+		if strings.EqualFold(name, key) {
+			return name, item, true
+		}
+	}
+
+	return "", nil, false
+}
+
+// mergeSelected merges two selections of the same document.
+func mergeSelected(a any, b any) any {
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok {
+			return a
+		}
+
+		for key, item := range b {
+			if existing, ok := a[key]; ok {
+				a[key] = mergeSelected(existing, item)
+			} else {
+				a[key] = item
+			}
+		}
+
+		return a
+	case []any:
+		b, ok := b.([]any)
+		if !ok {
+			return a
+		}
+
+		for i := range min(len(a), len(b)) {
+			switch {
+			case a[i] == nil:
+				a[i] = b[i]
+			case b[i] != nil:
+				a[i] = mergeSelected(a[i], b[i])
+			}
+		}
+
+		return a
+	case nil:
+		return b
+	default:
+		return a
+	}
+}
+
+// dropMissing removes the list items no path was found in.
+func dropMissing(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, item := range value {
+			value[key] = dropMissing(item)
+		}
+	case []any:
+		items := make([]any, 0, len(value))
+		for _, item := range value {
+			if item != nil {
+				items = append(items, dropMissing(item))
+			}
+		}
+
+		return items
+	}
+
+	return value
+}
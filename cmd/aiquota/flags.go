@@ -0,0 +1,434 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/internal/statsd"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+	"github.com/eduardolat/aiquota/pkg/providers"
+)
+
+// fetchFlags holds the flags shared by every command that queries providers.
+type fetchFlags struct {
+	authFile        string
+	timeout         time.Duration
+	providerTimeout providerValues[time.Duration]
+	noHistory       bool
+	logCSV          string
+	only            providerList
+	baseURL         providerValues[string]
+	retries         int
+	retryBackoff    time.Duration
+	dumpRaw         string
+	fromDump        string
+	cached          bool
+	maxAge          time.Duration
+	statsd          statsd.Client
+
+	// progress shows the progress line on stderr while fetching. Commands
+	// that print a single report set it; it has no flag.
+	progress bool
+
+	dump       *httpclient.Dump
+	replay     *httpclient.Replay
+	validators *httpclient.Validators
+
+	// cache is the cache hit found by credentials, served to the first
+	// fetch, and live the credentials of live fetches, loaded once.
+	cache *cachedResponses
+	live  *credentials.Credentials
+
+	// fetchedAt is when the last fetch started, the one timestamp every
+	// record of the run carries, and fromCache tells that its responses were
+	// replayed from the response cache, fetchedAt being their age.
+	fetchedAt time.Time
+	fromCache bool
+}
+
+func addFetchFlags(flags *flag.FlagSet) *fetchFlags {
+	f := &fetchFlags{
+		providerTimeout: newProviderValues(parsePositiveDuration, time.Duration.String),
+		baseURL:         newProviderValues(parseBaseURL, func(value string) string { return value }),
+	}
+	flags.StringVar(&f.authFile, "auth-file", "", "path to the OpenCode auth.json (default $AIQUOTA_AUTH_FILE, then $XDG_DATA_HOME/opencode/auth.json)")
+	flags.DurationVar(&f.timeout, "timeout", 0, "overall deadline for fetching all providers (0 disables it)")
+	flags.BoolVar(&f.noHistory, "no-history", false, "do not record this fetch in the usage history")
+	flags.StringVar(&f.logCSV, "log-csv", "", "append one row per provider to this CSV file, or tab-separated for a .tsv path")
+	flags.Var(&f.only, "provider", "only query these providers, comma-separated or repeated (default every provider with credentials)")
+	flags.Var(&f.providerTimeout, "provider-timeout", fmt.Sprintf(
+		"per-provider fetch timeout, as a duration or provider=duration pairs, comma-separated (default %s)",
+		provider.DefaultTimeout,
+	))
+	flags.Var(&f.baseURL, "base-url", "override provider API base URLs, as provider=url pairs, comma-separated")
+	flags.IntVar(&f.retries, "retries", httpclient.DefaultPolicy.Retries, "retries for requests failing with a network error, 429 or 5xx")
+	flags.DurationVar(&f.retryBackoff, "retry-backoff", httpclient.DefaultPolicy.Backoff, "wait before the first retry, doubled for each further retry unless the server sends Retry-After")
+	flags.StringVar(&f.dumpRaw, "dump-raw", "", "write every provider response, with credentials redacted, to files in this directory")
+	flags.StringVar(&f.fromDump, "from-dump", "", "render the report from responses saved with --dump-raw instead of querying providers")
+	flags.BoolVar(&f.cached, "cached", false, "render the report from the responses of the last successful fetch, however old, instead of querying providers")
+	flags.DurationVar(&f.maxAge, "max-age", 0, "reuse the responses of the last successful fetch when every provider was fetched within this long, instead of querying providers again")
+	flags.StringVar(&f.statsd.Addr, "statsd", os.Getenv("AIQUOTA_STATSD"), "emit quota gauges to this StatsD or DogStatsD host:port after each fetch (default $AIQUOTA_STATSD)")
+	flags.BoolVar(&f.statsd.Tags, "statsd-tags", true, "send provider and window as DogStatsD tags; false puts them in the metric name for plain StatsD")
+	addLogFlags(flags)
+	addLangFlag(flags)
+	addTimeFlags(flags)
+	addThemeFlags(flags)
+
+	return f
+}
+
+// credentials reads the credentials of the fetch and opens the --dump-raw
+// and --from-dump directories. Replays use placeholder credentials, so
+// recorded responses render on machines without the original tokens, and a
+// --cached or --max-age cache hit the redacted credentials saved with the
+// cache, without reading any credential source. Live fetches keep the
+// validators of their responses in the cache directory, so repeated fetches
+// are conditional where the provider supports it.
+func (f *fetchFlags) credentials() (credentials.Credentials, error) {
+	if f.dumpRaw != "" && f.fromDump != "" {
+		return credentials.Credentials{}, fmt.Errorf("--dump-raw and --from-dump cannot be combined")
+	}
+	if f.fromDump != "" && (f.cached || f.maxAge > 0) {
+		return credentials.Credentials{}, fmt.Errorf("--cached and --max-age cannot be combined with --from-dump")
+	}
+	if f.maxAge < 0 {
+		return credentials.Credentials{}, fmt.Errorf("max-age must not be negative")
+	}
+
+	if f.fromDump != "" {
+		replay, err := httpclient.NewReplay(f.fromDump)
+		if err != nil {
+			return credentials.Credentials{}, err
+		}
+
+		f.replay = replay
+		return credentials.Placeholder(), nil
+	}
+
+	cached, err := f.cachedResponses()
+	if err != nil {
+		return credentials.Credentials{}, err
+	}
+	if cached != nil {
+		f.cache = cached
+		return cached.creds, nil
+	}
+
+	return f.liveCredentials()
+}
+
+// liveCredentials reads the credentials of live fetches, once, and opens the
+// --dump-raw directory.
+func (f *fetchFlags) liveCredentials() (credentials.Credentials, error) {
+	if f.live != nil {
+		return *f.live, nil
+	}
+
+	if f.dumpRaw != "" {
+		dump, err := httpclient.NewDump(f.dumpRaw)
+		if err != nil {
+			return credentials.Credentials{}, err
+		}
+		f.dump = dump
+	}
+
+	if dir, err := helpers.CacheDir(); err == nil {
+		f.validators = httpclient.NewValidators(filepath.Join(dir, "etags"))
+	}
+
+	// Copilot falls back to the GitHub CLI login when no other source has
+	// its token. It may be the only credential, so a missing auth.json waits
+	// for it.
+	creds, err := credentials.GetCredentials(f.authFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return creds, err
+	}
+
+	if !credentials.HasValue(creds.CopilotAPIKey) {
+		creds.CopilotAPIKey = credentials.GitHubCLIToken()
+	}
+	if err != nil && creds.CopilotAPIKey == nil {
+		return creds, err
+	}
+
+	f.live = &creds
+	return creds, nil
+}
+
+// enabled returns the providers to query: those selected with --provider, or
+// every provider with credentials. Selecting a provider without credentials
+// is an error. --base-url overrides are applied to the returned providers.
+// Replays query the providers found in the dump instead.
+func (f *fetchFlags) enabled(creds credentials.Credentials) ([]provider.Provider, error) {
+	if f.replay != nil {
+		return f.replayed()
+	}
+
+	if f.baseURL.fallback != nil {
+		return nil, fmt.Errorf("--base-url expects provider=url pairs")
+	}
+
+	if f.retries < 0 || f.retryBackoff < 0 {
+		return nil, fmt.Errorf("retries and retry-backoff must not be negative")
+	}
+
+	var selected []provider.Provider
+	if len(f.only) == 0 {
+		selected = provider.Enabled(providers.All(), creds)
+		if len(selected) == 0 {
+			return nil, fmt.Errorf("no provider credentials found in auth.json or the environment")
+		}
+	} else {
+		for _, p := range providers.All() {
+			if !slices.Contains(f.only, p.ID()) {
+				continue
+			}
+
+			if !p.Enabled(creds) {
+				return nil, fmt.Errorf("no credentials found for %s", p.Name())
+			}
+
+			selected = append(selected, p)
+		}
+	}
+
+	for i, p := range selected {
+		baseURL, ok := f.baseURL.values[p.ID()]
+		if !ok {
+			continue
+		}
+
+		setter, ok := p.(provider.BaseURLSetter)
+		if !ok {
+			return nil, fmt.Errorf("%s does not support a custom base URL", p.Name())
+		}
+
+		selected[i] = setter.WithBaseURL(baseURL)
+	}
+
+	return selected, nil
+}
+
+func (f *fetchFlags) options() provider.FetchOptions {
+	opts := provider.FetchOptions{
+		Timeouts:   f.providerTimeout.values,
+		Retry:      &httpclient.Policy{Retries: f.retries, Backoff: f.retryBackoff},
+		Dump:       f.dump,
+		Replay:     f.replay,
+		Validators: f.validators,
+	}
+	if f.providerTimeout.fallback != nil {
+		opts.Timeout = *f.providerTimeout.fallback
+	}
+
+	return opts
+}
+
+// replayed returns the providers with responses in the --from-dump
+// directory, limited to --provider when given.
+func (f *fetchFlags) replayed() ([]provider.Provider, error) {
+	ids := f.replay.Providers()
+
+	var selected []provider.Provider
+	for _, p := range providers.All() {
+		if slices.Contains(ids, p.ID()) && (len(f.only) == 0 || slices.Contains(f.only, p.ID())) {
+			selected = append(selected, p)
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no provider responses found in %s", f.fromDump)
+	}
+
+	return selected, nil
+}
+
+// record stores the results in the usage history unless disabled, appends
+// them to the --log-csv file and emits them to --statsd when those are set.
+// Replayed and cached results are old data and are never recorded.
+func (f *fetchFlags) record(results []provider.Result) {
+	if f.replay != nil || f.fromCache {
+		return
+	}
+
+	now := f.fetchedAt
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if !f.noHistory {
+		recordHistory(now, results)
+	}
+
+	if f.logCSV != "" {
+		if err := appendCSVLog(f.logCSV, now, results); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	if f.statsd.Addr != "" {
+		if err := f.statsd.Send(results); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+}
+
+// context applies the overall deadline, when one is set, to ctx.
+func (f *fetchFlags) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if f.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, f.timeout)
+}
+
+// providerList is a set of provider IDs given comma-separated or by
+// repeating the flag.
+type providerList []string
+
+func (l *providerList) String() string {
+	if l == nil {
+		return ""
+	}
+
+	return strings.Join(*l, ",")
+}
+
+func (l *providerList) Set(value string) error {
+	for id := range strings.SplitSeq(value, ",") {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if id == "" {
+			continue
+		}
+
+		if _, ok := providers.Get(id); !ok {
+			return fmt.Errorf("unknown provider %q, expected one of %s", id, strings.Join(providerIDs(), ", "))
+		}
+
+		if !slices.Contains(*l, id) {
+			*l = append(*l, id)
+		}
+	}
+
+	return nil
+}
+
+func providerIDs() []string {
+	var ids []string
+	for _, p := range providers.All() {
+		ids = append(ids, p.ID())
+	}
+
+	return ids
+}
+
+// providerValues parses values like "15s" or "codex=20s,zai=5s". A bare
+// value applies to every provider without an explicit entry. The flag may be
+// repeated.
+type providerValues[T any] struct {
+	parse    func(string) (T, error)
+	format   func(T) string
+	fallback *T
+	values   map[string]T
+}
+
+func newProviderValues[T any](parse func(string) (T, error), format func(T) string) providerValues[T] {
+	return providerValues[T]{parse: parse, format: format, values: map[string]T{}}
+}
+
+// get returns the value configured for a provider, if any.
+func (v *providerValues[T]) get(id string) (T, bool) {
+	if value, ok := v.values[id]; ok {
+		return value, true
+	}
+
+	if v.fallback != nil {
+		return *v.fallback, true
+	}
+
+	var zero T
+	return zero, false
+}
+
+func (v *providerValues[T]) String() string {
+	if v == nil || v.format == nil {
+		return ""
+	}
+
+	parts := []string{}
+	if v.fallback != nil {
+		parts = append(parts, v.format(*v.fallback))
+	}
+
+	for id, value := range v.values {
+		parts = append(parts, id+"="+v.format(value))
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func (v *providerValues[T]) Set(value string) error {
+	for part := range strings.SplitSeq(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		id, raw, hasID := strings.Cut(part, "=")
+		if !hasID {
+			raw = id
+		}
+
+		parsed, err := v.parse(strings.TrimSpace(raw))
+		if err != nil {
+			return err
+		}
+
+		if !hasID {
+			v.fallback = &parsed
+			continue
+		}
+
+		v.values[strings.ToLower(strings.TrimSpace(id))] = parsed
+	}
+
+	return nil
+}
+
+func parsePositiveDuration(value string) (time.Duration, error) {
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	return duration, nil
+}
+
+func parseBaseURL(value string) (string, error) {
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("invalid base URL %q, expected an http or https URL", value)
+	}
+
+	return strings.TrimRight(value, "/"), nil
+}
+
+func parsePercent(value string) (float64, error) {
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil || percent < 0 || percent > 100 {
+		return 0, fmt.Errorf("invalid percent %q, expected a number between 0 and 100", value)
+	}
+
+	return percent, nil
+}
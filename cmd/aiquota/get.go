@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// runGet prints a single value of one provider with no decoration, for shell
+// prompts where parsing JSON is overkill:
+//
+//	PS1='codex $(aiquota get codex.primary.used_percent)% \$ '
+//
+// The path starts with the provider ID, then follows either the keys of the
+// provider in the JSON report or a window ID and a window field such as
+// used_percent, remaining or reset_at. Keys match ignoring case and
+// underscores, and list items are selected by index.
+func runGet(parent context.Context, args []string) error {
+	flags := flag.NewFlagSet("aiquota get", flag.ContinueOnError)
+	fetch := addFetchFlags(flags)
+
+	// The path may come before the flags, which flag.Parse would stop at.
+	var path string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		path, args = args[0], args[1:]
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() > 0 {
+		if path != "" || flags.NArg() > 1 {
+			return fmt.Errorf("expected a single path, got %s", strings.Join(append([]string{path}, flags.Args()...), " "))
+		}
+		path = flags.Arg(0)
+	}
+	if path == "" {
+		return fmt.Errorf("expected a path such as codex.primary.used_percent")
+	}
+
+	id, rest, _ := strings.Cut(path, ".")
+	fetch.only = nil
+	if err := fetch.only.Set(id); err != nil {
+		return err
+	}
+
+	creds, err := fetch.credentials()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := fetch.context(parent)
+	defer cancel()
+
+	results, err := fetchQuotas(ctx, creds, fetch)
+	if parent.Err() != nil {
+		return errInterrupted
+	}
+	if err != nil {
+		return err
+	}
+
+	fetch.record(results)
+
+	result := results[0]
+	if result.Err != nil {
+		return fmt.Errorf("%s: %w", result.Provider.Name(), result.Err)
+	}
+
+	value, ok, err := quotaValue(result.Quota, rest)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("no value at %s", path)
+	}
+
+	text, err := formatValue(value)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(text)
+	return nil
+}
+
+// quotaValue finds path in the JSON form of quota, or else in one of its
+// windows. An empty path is the whole quota.
+func quotaValue(quota provider.Quota, path string) (any, bool, error) {
+	document, err := reportDocument(quota)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if path == "" {
+		return document, true, nil
+	}
+
+	keys := strings.Split(path, ".")
+	if value, ok := walkValue(document, keys); ok {
+		return value, true, nil
+	}
+
+	for _, window := range quota.Windows() {
+		if normalizeKey(window.ID) != normalizeKey(keys[0]) {
+			continue
+		}
+
+		document, err := reportDocument(window)
+		if err != nil {
+			return nil, false, err
+		}
+
+		value, ok := walkValue(document, keys[1:])
+		return value, ok, nil
+	}
+
+	return nil, false, nil
+}
+
+// walkValue follows keys through maps and, by index, lists.
+func walkValue(value any, keys []string) (any, bool) {
+	for _, key := range keys {
+		switch current := value.(type) {
+		case map[string]any:
+			found := false
+			for name, item := range current {
+				if normalizeKey(name) == normalizeKey(key) {
+					value, found = item, true
+					break
+				}
+			}
+			if !found {
+				return nil, false
+			}
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(current) {
+				return nil, false
+			}
+			value = current[index]
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// normalizeKey makes used_percent, usedPercent and used-percent the same key.
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+// formatValue prints numbers, strings and booleans as is, and anything else
+// as compact JSON.
+func formatValue(value any) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case float64:
+		return formatNumber(value), nil
+	case int64, bool:
+		return fmt.Sprint(value), nil
+	default:
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode value: %w", err)
+		}
+
+		return string(raw), nil
+	}
+}
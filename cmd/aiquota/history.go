@@ -0,0 +1,284 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/forecast"
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/internal/history"
+	"github.com/eduardolat/aiquota/internal/i18n"
+	"github.com/eduardolat/aiquota/pkg/provider"
+	"github.com/eduardolat/aiquota/pkg/providers"
+	"github.com/varavelio/tinta"
+)
+
+// recordHistory stores successful results in the history database. History
+// is best effort: failures are reported on stderr without failing the run.
+func recordHistory(at time.Time, results []provider.Result) {
+	path, err := history.DefaultPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+
+	store, err := history.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+	defer store.Close()
+
+	if err := store.Save(at, results); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+func runHistory(args []string) error {
+	flags := flag.NewFlagSet("aiquota history", flag.ContinueOnError)
+	days := flags.Int("days", 7, "number of days to show")
+	var only providerList
+	flags.Var(&only, "provider", "only show these providers, comma-separated or repeated")
+	output := addOutputFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	output.apply()
+
+	if *days <= 0 {
+		return fmt.Errorf("days must be greater than zero")
+	}
+
+	path, err := history.DefaultPath()
+	if err != nil {
+		return err
+	}
+
+	store, err := history.Open(path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	records, err := store.Since(time.Now().AddDate(0, 0, -*days), "")
+	if err != nil {
+		return err
+	}
+
+	if len(only) > 0 {
+		records = slices.DeleteFunc(records, func(record history.Record) bool {
+			return !slices.Contains(only, record.Provider)
+		})
+	}
+
+	if len(records) == 0 {
+		period := i18n.T("the last day")
+		if *days != 1 {
+			period = i18n.Sprintf("the last %d days", *days)
+		}
+		fmt.Println(i18n.Sprintf("No usage history recorded in %s.", period))
+		return nil
+	}
+
+	fmt.Println(asciiText(renderHistory(records)))
+	return nil
+}
+
+// dailyUsage aggregates the samples of one window on one day. Windows
+// without a percentage are tracked by their absolute usage instead.
+type dailyUsage struct {
+	day     string
+	percent bool
+	peak    float64
+	last    float64
+	samples int
+}
+
+func renderHistory(records []history.Record) string {
+	key := tinta.Text().Bold()
+	var (
+		providerOrder []string
+		windowOrder   = map[string][]string{}
+		windowNames   = map[string]string{}
+		days          = map[string][]*dailyUsage{}
+	)
+
+	for _, record := range records {
+		if _, seen := windowOrder[record.Provider]; !seen {
+			providerOrder = append(providerOrder, record.Provider)
+			windowOrder[record.Provider] = []string{}
+		}
+
+		day := record.Timestamp.In(displayTime.location).Format("2006-01-02")
+		for _, window := range record.Windows {
+			value, percent := windowValue(window)
+			if value == nil {
+				continue
+			}
+
+			windowKey := record.Provider + "/" + window.ID
+			if _, seen := windowNames[windowKey]; !seen {
+				windowOrder[record.Provider] = append(windowOrder[record.Provider], window.ID)
+			}
+			windowNames[windowKey] = window.Name
+
+			usage := days[windowKey]
+			if len(usage) == 0 || usage[len(usage)-1].day != day {
+				usage = append(usage, &dailyUsage{day: day, percent: percent})
+				days[windowKey] = usage
+			}
+
+			current := usage[len(usage)-1]
+			current.peak = max(current.peak, *value)
+			current.last = *value
+			current.samples++
+		}
+	}
+
+	slices.SortStableFunc(providerOrder, func(a, b string) int {
+		return providerIndex(a) - providerIndex(b)
+	})
+
+	sections := []string{}
+	for _, id := range providerOrder {
+		name := id
+		if p, ok := providers.Get(id); ok {
+			name = p.Name()
+		}
+
+		lines := []string{providerHeading(id, name)}
+		for _, windowID := range windowOrder[id] {
+			windowKey := id + "/" + windowID
+			lines = append(lines, "", key.String(windowNames[windowKey]))
+			for _, usage := range days[windowKey] {
+				lines = append(lines, fmt.Sprintf(
+					"%s  %s %s  %s %s  (%d %s)",
+					usage.day,
+					key.String("peak"),
+					usage.format(usage.peak),
+					key.String("last"),
+					usage.format(usage.last),
+					usage.samples,
+					helpers.Plural(usage.samples, "sample", "samples"),
+				))
+			}
+		}
+
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+
+	return strings.Join(sections, "\n\n")
+}
+
+func (u *dailyUsage) format(value float64) string {
+	if u.percent {
+		return colorPercent(value)
+	}
+
+	return formatNumber(value)
+}
+
+// windowValue returns the used percent of a window, or its absolute usage
+// for windows without a percentage.
+func windowValue(window provider.Window) (*float64, bool) {
+	if window.UsedPercent != nil {
+		return window.UsedPercent, true
+	}
+
+	return window.Used, false
+}
+
+// providerIndex returns the registry position of a provider, placing
+// unknown providers last.
+func providerIndex(id string) int {
+	all := providers.All()
+	for i, p := range all {
+		if p.ID() == id {
+			return i
+		}
+	}
+
+	return len(all)
+}
+
+// burnRateAnnotations projects when each window will be exhausted, based on
+// the samples stored in the history database.
+func burnRateAnnotations(results []provider.Result) map[string][]string {
+	annotations := map[string][]string{}
+
+	path, err := history.DefaultPath()
+	if err != nil {
+		return annotations
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return annotations
+	}
+
+	store, err := history.Open(path)
+	if err != nil {
+		return annotations
+	}
+	defer store.Close()
+
+	now := time.Now()
+	records, err := store.Since(now.Add(-forecast.Lookback), "")
+	if err != nil {
+		return annotations
+	}
+
+	samples := map[string][]forecast.Sample{}
+	for _, record := range records {
+		for _, window := range record.Windows {
+			if window.UsedPercent == nil {
+				continue
+			}
+
+			key := annotationKey(record.Provider, window.ID)
+			samples[key] = append(samples[key], forecast.Sample{
+				At:          record.Timestamp,
+				UsedPercent: *window.UsedPercent,
+				ResetAt:     window.ResetAt,
+			})
+		}
+	}
+
+	key := tinta.Text().Bold()
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+
+		for _, window := range result.Quota.Windows() {
+			if window.UsedPercent == nil {
+				continue
+			}
+
+			id := annotationKey(result.Provider.ID(), window.ID)
+			current := forecast.Sample{At: now, UsedPercent: *window.UsedPercent, ResetAt: window.ResetAt}
+			projection, ok := forecast.Project(append(samples[id], current), now)
+			if !ok {
+				continue
+			}
+
+			estimate := i18n.T("not before reset")
+			if projection.BeforeReset {
+				estimate = "~" + helpers.FormatDuration(projection.ExhaustIn)
+			}
+
+			annotations[id] = append(annotations[id], fmt.Sprintf(
+				"%s %s (%s%%/h)",
+				key.String(i18n.T("Exhausts in:")),
+				estimate,
+				formatPercent(projection.RatePerHour),
+			))
+		}
+	}
+
+	return annotations
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/internal/i18n"
+	"github.com/eduardolat/aiquota/pkg/aiquota"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// htmlPage is the data behind htmlTemplate.
+type htmlPage struct {
+	Lang      string
+	Generated string
+	Providers []htmlProvider
+	Warnings  []string
+}
+
+type htmlProvider struct {
+	Name    string
+	Windows []htmlWindow
+}
+
+type htmlWindow struct {
+	Name    string
+	Usage   string
+	Percent *float64
+	Bar     float64
+	Level   string
+	ResetIn string
+	ResetAt string
+}
+
+// printHTML writes --format html, a self-contained page with a usage bar
+// per window. Reset countdowns are rendered when the report is generated
+// and kept current by a short inline script.
+func printHTML(w io.Writer, at time.Time, results []provider.Result) error {
+	page := htmlPage{
+		Lang:      i18n.Language(),
+		Generated: i18n.Sprintf("Generated %s", formatTime(at)),
+		Warnings:  aiquota.Warnings(results),
+	}
+
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+
+		entry := htmlProvider{Name: result.Provider.Name()}
+		for _, window := range result.Quota.Windows() {
+			item := htmlWindow{
+				Name:    i18n.T(window.Name),
+				Usage:   windowUsageText(window),
+				Percent: window.UsedPercent,
+				Level:   "unknown",
+			}
+			if window.UsedPercent != nil {
+				item.Bar = helpers.ClampPercent(*window.UsedPercent)
+				item.Level = severity(*window.UsedPercent)
+			}
+			if reset := helpers.FormatTimeUntil(window.ResetAt); reset != "unknown" {
+				item.ResetIn = i18n.T(reset)
+				item.ResetAt = window.ResetAt
+			}
+
+			entry.Windows = append(entry.Windows, item)
+		}
+
+		page.Providers = append(page.Providers, entry)
+	}
+
+	if err := htmlTemplate.Execute(w, page); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+
+	return nil
+}
+
+// windowUsageText describes the amounts of a window in words.
+func windowUsageText(window provider.Window) string {
+	switch {
+	case window.Used != nil && window.Limit != nil:
+		return i18n.Sprintf("%s of %s used", formatNumber(*window.Used), formatNumber(*window.Limit))
+	case window.Remaining != nil:
+		return i18n.Sprintf("%s remaining", formatNumber(*window.Remaining))
+	case window.Used != nil:
+		return i18n.Sprintf("%s used", formatNumber(*window.Used))
+	default:
+		return ""
+	}
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": formatPercent,
+	"t":       i18n.T,
+}).Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{t "AI Quota Report"}}</title>
+<style>
+  :root { color-scheme: light dark; --bg: #f6f7f9; --card: #fff; --text: #1f2328; --dim: #656d76; --track: #e6e8eb; }
+  @media (prefers-color-scheme: dark) { :root { --bg: #0d1117; --card: #161b22; --text: #e6edf3; --dim: #8d96a0; --track: #30363d; } }
+  body { margin: 0; padding: 2rem; background: var(--bg); color: var(--text); font: 15px/1.5 system-ui, sans-serif; }
+  h1 { margin: 0 0 .25rem; font-size: 1.5rem; }
+  .generated { margin: 0 0 1.5rem; color: var(--dim); }
+  .providers { display: grid; gap: 1rem; grid-template-columns: repeat(auto-fill, minmax(22rem, 1fr)); }
+  .provider { padding: 1rem 1.25rem; background: var(--card); border-radius: .5rem; box-shadow: 0 1px 3px rgba(0, 0, 0, .12); }
+  .provider h2 { margin: 0 0 .75rem; font-size: 1.1rem; }
+  .window { margin-bottom: .9rem; }
+  .window:last-child { margin-bottom: 0; }
+  .label { display: flex; justify-content: space-between; font-weight: 600; }
+  .track { height: .5rem; margin: .3rem 0; overflow: hidden; background: var(--track); border-radius: .25rem; }
+  .bar { height: 100%; }
+  .normal .bar { background: #2da44e; }
+  .warning .bar { background: #d4a72c; }
+  .critical .bar { background: #cf222e; }
+  .details { display: flex; justify-content: space-between; color: var(--dim); font-size: .85rem; }
+  .empty { color: var(--dim); }
+  .warnings { margin-top: 1.5rem; padding: 1rem 1.25rem; border-left: .25rem solid #cf222e; background: var(--card); border-radius: .25rem; }
+  .warnings h2 { margin: 0 0 .5rem; font-size: 1.1rem; }
+  .warnings ul { margin: 0; padding-left: 1.25rem; }
+</style>
+</head>
+<body>
+<h1>{{t "AI Quota Report"}}</h1>
+<p class="generated">{{.Generated}}</p>
+<div class="providers">
+{{- range .Providers}}
+  <section class="provider">
+    <h2>{{.Name}}</h2>
+    {{- range .Windows}}
+    <div class="window {{.Level}}">
+      <div class="label"><span>{{.Name}}</span>{{with .Percent}}<span>{{percent .}}%</span>{{end}}</div>
+      {{- if .Percent}}
+      <div class="track"><div class="bar" style="width: {{.Bar}}%"></div></div>
+      {{- end}}
+      <div class="details"><span>{{.Usage}}</span>{{if .ResetAt}}<span>{{t "resets in"}} <time datetime="{{.ResetAt}}" data-reset>{{.ResetIn}}</time></span>{{end}}</div>
+    </div>
+    {{- else}}
+    <p class="empty">{{t "No quota windows."}}</p>
+    {{- end}}
+  </section>
+{{- end}}
+</div>
+{{- if .Warnings}}
+<section class="warnings">
+  <h2>{{t "Warnings"}}</h2>
+  <ul>
+    {{- range .Warnings}}
+    <li>{{.}}</li>
+    {{- end}}
+  </ul>
+</section>
+{{- end}}
+<script>
+  // Mirrors helpers.FormatDuration so countdowns match the rest of aiquota.
+  function until(target) {
+    if (target <= Date.now()) return {{t "now"}};
+    const minutes = Math.floor((target - Date.now()) / 60000);
+    const days = Math.floor(minutes / 1440), hours = Math.floor(minutes % 1440 / 60);
+    if (days > 0) return days + "d " + hours + "h";
+    if (hours > 0) return hours + "h " + minutes % 60 + "m";
+    return minutes % 60 + "m";
+  }
+  function tick() {
+    for (const el of document.querySelectorAll("[data-reset]")) el.textContent = until(Date.parse(el.dateTime));
+  }
+  tick();
+  setInterval(tick, 30000);
+</script>
+</body>
+</html>
+`))
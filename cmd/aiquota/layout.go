@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/term"
+)
+
+const (
+	// columnGap is the space between boxes laid out side by side.
+	columnGap = 2
+	// frameWidth is what the outer frame adds to the width of the boxes:
+	// two borders and the right padding.
+	frameWidth = 3
+)
+
+// terminalWidth returns the width of f, or $COLUMNS when f is not a
+// terminal, such as with --color always into a pager. It is 0 when neither
+// is known. It is a variable so tests can stub it like isTerminal.
+var terminalWidth = func(f *os.File) int {
+	if width, _, err := term.GetSize(int(f.Fd())); err == nil && width > 0 {
+		return width
+	}
+
+	width, _ := strconv.Atoi(os.Getenv("COLUMNS"))
+	return max(width, 0)
+}
+
+// columns lays the provider boxes out in rows of as many as fit the
+// terminal width, all as wide as the widest box. A box per row, the layout
+// of narrow terminals and plain mode, returns boxes as is.
+func (r *reportRenderer) columns(boxes []string) []string {
+	if r.plain || r.width <= 0 || len(boxes) < 2 {
+		return boxes
+	}
+
+	widest := 0
+	for _, box := range boxes {
+		widest = max(widest, lipgloss.Width(box))
+	}
+
+	perRow := min((r.width-frameWidth+columnGap)/(widest+columnGap), len(boxes))
+	if perRow < 2 {
+		return boxes
+	}
+
+	rows := []string{}
+	for start := 0; start < len(boxes); start += perRow {
+		rows = append(rows, joinColumns(boxes[start:min(start+perRow, len(boxes))], widest))
+	}
+
+	return rows
+}
+
+// joinColumns draws boxes next to each other, padding all but the last to
+// width. Shorter boxes end early.
+func joinColumns(boxes []string, width int) string {
+	split := make([][]string, len(boxes))
+	height := 0
+	for i, box := range boxes {
+		split[i] = strings.Split(strings.TrimRight(box, "\n"), "\n")
+		height = max(height, len(split[i]))
+	}
+
+	lines := make([]string, height)
+	for y := range height {
+		var line strings.Builder
+		for i, boxLines := range split {
+			cell := ""
+			if y < len(boxLines) {
+				cell = boxLines[y]
+			}
+
+			line.WriteString(cell)
+			if i < len(split)-1 {
+				line.WriteString(strings.Repeat(" ", width-lipgloss.Width(cell)+columnGap))
+			}
+		}
+		lines[y] = strings.TrimRight(line.String(), " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+)
+
+// logLevel is the level of the stderr logger. Only warnings are logged
+// unless -v or --debug lowers it.
+var logLevel = new(slog.LevelVar)
+
+// setupLogging installs the stderr logger used by the HTTP client and the
+// provider fetches.
+func setupLogging() {
+	logLevel.Set(slog.LevelWarn)
+	handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})
+	slog.SetDefault(slog.New(redactHandler{handler}))
+}
+
+// redactHandler passes log messages and attribute values through
+// httpclient.RedactText, as logged errors can echo response bodies.
+type redactHandler struct {
+	slog.Handler
+}
+
+func (h redactHandler) Handle(ctx context.Context, record slog.Record) error {
+	clean := slog.NewRecord(record.Time, record.Level, httpclient.RedactText(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		clean.AddAttrs(redactAttr(attr))
+		return true
+	})
+
+	return h.Handler.Handle(ctx, clean)
+}
+
+func (h redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clean := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		clean[i] = redactAttr(attr)
+	}
+
+	return redactHandler{h.Handler.WithAttrs(clean)}
+}
+
+func (h redactHandler) WithGroup(name string) slog.Handler {
+	return redactHandler{h.Handler.WithGroup(name)}
+}
+
+func redactAttr(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, httpclient.RedactText(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		attrs := make([]any, len(group))
+		for i, member := range group {
+			attrs[i] = redactAttr(member)
+		}
+		return slog.Group(attr.Key, attrs...)
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			return slog.String(attr.Key, httpclient.RedactText(err.Error()))
+		}
+	}
+
+	return attr
+}
+
+// addLogFlags adds -v/--verbose, which logs every request with its status,
+// latency and retries, and --debug, which also logs the request and
+// response headers. Credentials are redacted either way.
+func addLogFlags(flags *flag.FlagSet) {
+	verbose := func(string) error {
+		logLevel.Set(min(logLevel.Level(), slog.LevelInfo))
+		return nil
+	}
+
+	flags.BoolFunc("v", "log every request, its status, latency and retries to stderr", verbose)
+	flags.BoolFunc("verbose", "same as -v", verbose)
+	flags.BoolFunc("debug", "like -v, and also log request and response headers", func(string) error {
+		logLevel.Set(slog.LevelDebug)
+		return nil
+	})
+}
@@ -2,328 +2,281 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
 	"os"
-	"strconv"
+	"os/signal"
 	"strings"
-	"sync"
+	"syscall"
+	"text/template"
 	"time"
 
-	"github.com/eduardolat/aiquota/internal/codex"
-	"github.com/eduardolat/aiquota/internal/copilot"
-	"github.com/eduardolat/aiquota/internal/credentials"
-	"github.com/eduardolat/aiquota/internal/zai"
-	"github.com/varavelio/tinta"
+	"github.com/eduardolat/aiquota/internal/alert"
+	"github.com/eduardolat/aiquota/internal/i18n"
+	"github.com/eduardolat/aiquota/pkg/aiquota"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
 )
 
 func main() {
-	if err := run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	setupLogging()
+	// The locale picks the language until --lang overrides it.
+	_ = i18n.Set(i18n.Detect())
+
+	// SIGINT and SIGTERM cancel in-flight requests rather than killing the
+	// process, so commands can still print what was fetched. Once stop runs
+	// a second signal falls back to the default behavior.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := run(ctx, os.Args[1:])
+	stop()
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return
 	}
-}
 
-func run() error {
-	creds, err := credentials.GetCredentials()
-	if err != nil {
-		return err
-	}
+	fmt.Fprintf(os.Stderr, "Error: %s\n", httpclient.RedactText(err.Error()))
 
-	hasCopilot := hasCredential(creds.CopilotAPIKey)
-	hasZAI := hasCredential(creds.ZAIAPIKey)
-	hasCodex := hasCredential(creds.CodexAPIKey)
-	if !hasCopilot && !hasZAI && !hasCodex {
-		return fmt.Errorf("no provider credentials found in auth.json")
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.code)
 	}
 
-	ctx := context.Background()
-
-	var (
-		wg         sync.WaitGroup
-		mu         sync.Mutex
-		warnings   []string
-		copilotOut *copilot.Quota
-		zaiOut     *zai.Quota
-		codexOut   *codex.Quota
-	)
-
-	if hasCopilot {
-		wg.Go(func() {
-			quota, err := copilot.GetQuota(ctx, creds)
-			mu.Lock()
-			defer mu.Unlock()
-			if err != nil {
-				warnings = append(warnings, "GitHub Copilot: "+err.Error())
-				return
-			}
-			copilotOut = &quota
-		})
-	}
-
-	if hasZAI {
-		wg.Go(func() {
-			quota, err := zai.GetQuota(ctx, creds)
-			mu.Lock()
-			defer mu.Unlock()
-			if err != nil {
-				warnings = append(warnings, "Z.ai: "+err.Error())
-				return
-			}
-			zaiOut = &quota
-		})
-	}
-
-	if hasCodex {
-		wg.Go(func() {
-			quota, err := codex.GetQuota(ctx, creds)
-			mu.Lock()
-			defer mu.Unlock()
-			if err != nil {
-				warnings = append(warnings, "OpenAI Codex: "+err.Error())
-				return
-			}
-			codexOut = &quota
-		})
-	}
-
-	wg.Wait()
+	os.Exit(1)
+}
 
-	if copilotOut == nil && zaiOut == nil && codexOut == nil {
-		return fmt.Errorf("could not fetch quota data from any provider")
+// exitInterrupted is the conventional exit code of a process stopped by
+// SIGINT.
+const exitInterrupted = 130
+
+// errInterrupted is returned by commands stopped by a signal, after they
+// printed whatever they had already fetched.
+var errInterrupted = &exitError{code: exitInterrupted, err: errors.New("interrupted")}
+
+// run dispatches to a subcommand. Ctx is canceled on SIGINT or SIGTERM.
+func run(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "watch":
+			return runWatch(ctx, args[1:])
+		case "serve":
+			return runServe(ctx, args[1:])
+		case "history":
+			return runHistory(args[1:])
+		case "trend":
+			return runTrend(args[1:])
+		case "summary":
+			return runSummary(args[1:])
+		case "tui":
+			return runTUI(ctx, args[1:])
+		case "tmux":
+			return runTmux(ctx, args[1:])
+		case "alert":
+			return runAlert(ctx, args[1:])
+		case "next-reset":
+			return runNextReset(ctx, args[1:])
+		case "get":
+			return runGet(ctx, args[1:])
+		case "reset":
+			return runReset(ctx, args[1:])
+		case "report":
+			return runReport(ctx, args[1:])
+		case "daemon":
+			return runDaemon(ctx, args[1:])
+		case "auth":
+			return runAuth(args[1:])
+		case "doctor":
+			return runDoctor(ctx, args[1:])
+		}
 	}
 
-	printReport(copilotOut, zaiOut, codexOut, warnings)
-
-	return nil
+	return runReport(ctx, args)
 }
 
-func hasCredential(value *string) bool {
-	return value != nil && strings.TrimSpace(*value) != ""
-}
+// runReport prints the quota report. It is the default command.
+func runReport(parent context.Context, args []string) (err error) {
+	flags := flag.NewFlagSet("aiquota", flag.ContinueOnError)
+	format := formatText
+	flags.Var(&format, "format", "output format: "+strings.Join(reportFormats, ", "))
+	jsonOutput := flags.Bool("json", false, "print the report as a single JSON document, same as --format json")
+	templateText := flags.String("template", "", "Go text/template for --format template; each provider is keyed by its ID in CamelCase, such as .Copilot, .CopilotOrg or .GithubModels, e.g. '{{.Copilot.RequestsRemaining}}'")
+	var fields reportFields
+	flags.Var(&fields, "fields", "only print these dotted paths of the JSON, YAML or TOML report, comma-separated, e.g. copilot.requestsRemaining")
+	out := flags.String("out", "", "write the report to this file instead of stdout, replacing it atomically")
+	failAt := newProviderValues(parsePercent, formatPercent)
+	flags.Var(&failAt, "fail-at", "exit with status 2 when any window reaches this used percent, as a percent or provider=percent pairs")
+	fetch := addFetchFlags(flags)
+	alerts := addAlertFlags(flags)
+	output := addOutputFlags(flags)
+	ping := addPingFlag(flags)
+	cfg, err := parseFlagsConfig(flags, args)
+	if err != nil {
+		return err
+	}
 
-func printReport(copilotOut *copilot.Quota, zaiOut *zai.Quota, codexOut *codex.Quota, warnings []string) {
-	sections := []string{tinta.Text().BrightCyan().Bold().String("AI QUOTA REPORT"), ""}
+	if err := output.configure(cfg); err != nil {
+		return err
+	}
+	alerts.budgets = output.budgets
 
-	if copilotOut != nil {
-		sections = append(sections, printCopilotReport(copilotOut))
+	if *jsonOutput {
+		format = formatJSON
 	}
 
-	if zaiOut != nil {
-		sections = append(sections, printZAIReport(zaiOut))
+	if len(fields) > 0 && format != formatJSON && format != formatYAML && format != formatTOML {
+		return fmt.Errorf("--fields requires --format json, yaml or toml")
 	}
 
-	if codexOut != nil {
-		sections = append(sections, printCodexReport(codexOut))
+	var tmpl *template.Template
+	if format == formatTemplate {
+		var err error
+		if tmpl, err = parseReportTemplate(*templateText); err != nil {
+			return err
+		}
 	}
 
-	if len(warnings) > 0 {
-		sections = append(sections, printWarnings(warnings))
+	// A report written to a file never goes to a terminal, so the text
+	// format drops colors and boxes as it does when stdout is redirected.
+	if *out != "" {
+		output.plain = true
 	}
 
-	outer := tinta.Box().
-		BorderDouble().
-		BrightCyan().
-		PaddingLeft(0).
-		PaddingRight(1).
-		PaddingBottom(0).
-		CenterFirstLine()
-	fmt.Println(outer.String(strings.TrimSpace(strings.Join(sections, "\n"))))
+	output.apply()
+	fetch.progress = true
 
-	fmt.Println()
-}
+	// The ping tells whether quotas could be checked, so a --fail-at breach
+	// still counts as a successful run. Interrupted runs do not ping.
+	var results []provider.Result
+	defer func() {
+		var exitErr *exitError
+		if parent.Err() != nil {
+			return
+		}
+		if errors.As(err, &exitErr) && exitErr.code == exitThresholdExceeded {
+			ping.send(parent, results, nil)
+			return
+		}
+		ping.send(parent, results, err)
+	}()
 
-func printCopilotReport(out *copilot.Quota) string {
-	key := tinta.Text().Bold()
-	heading := tinta.Text().BrightBlue().Bold().String("GitHub Copilot")
-	box := tinta.Box().
-		BorderSimple().
-		Blue().
-		DisableTop().
-		DisableBottom().
-		DisableRight().
-		PaddingLeft(1).
-		PaddingRight(0)
-
-	content := strings.Join([]string{
-		heading,
-		"",
-		fmt.Sprintf("%s %s (%s)", key.String("Account:"), out.AccountUser, out.AccountType),
-		"",
-		fmt.Sprintf("%s %d / %d", key.String("Requests:"), out.RequestsUsed, out.RequestsTotal),
-		fmt.Sprintf("%s %s", key.String("Used:"), colorPercent(out.RequestsUsedPercent)),
-	}, "\n")
-
-	if reset := formatReset(out.ResetIn, out.ResetAt); reset != "" {
-		content += "\n" + fmt.Sprintf("%s %s", key.String("Reset in:"), reset)
+	creds, err := fetch.credentials()
+	if err != nil {
+		return err
 	}
 
-	return box.String(content)
-}
+	ctx, cancel := fetch.context(parent)
+	defer cancel()
 
-func printZAIReport(out *zai.Quota) string {
-	key := tinta.Text().Bold()
-	heading := tinta.Text().BrightYellow().Bold().String("Z.ai")
-	box := tinta.Box().
-		BorderSimple().
-		Yellow().
-		DisableTop().
-		DisableBottom().
-		DisableRight().
-		PaddingLeft(1).
-		PaddingRight(0)
-
-	sections := []string{
-		heading,
-		"",
-		fmt.Sprintf("%s %s (%s)", key.String("Account:"), out.AccountID, out.AccountType),
-		"",
-		key.String("Token Quota"),
-		fmt.Sprintf("%s %s", key.String("Used:"), colorPercent(out.TokenQuota.UsedPercent)),
+	results, err = fetchQuotas(ctx, creds, fetch)
+	if parent.Err() != nil && err != nil {
+		return errInterrupted
 	}
-
-	if reset := formatReset(out.TokenQuota.ResetIn, out.TokenQuota.ResetAt); reset != "" {
-		sections = append(sections, fmt.Sprintf("%s %s", key.String("Reset in:"), reset))
+	if err != nil {
+		return err
 	}
 
-	sections = append(sections,
-		"",
-		key.String("MCP Quota"),
-		fmt.Sprintf("%s %s", key.String("Used:"), colorPercent(out.MCPQuota.UsedPercent)),
-	)
-
-	if reset := formatReset(out.MCPQuota.ResetIn, out.MCPQuota.ResetAt); reset != "" {
-		sections = append(sections, fmt.Sprintf("%s %s", key.String("Reset in:"), reset))
-	}
+	fetch.record(results)
+	output.fetchedAt, output.fromCache = fetch.fetchedAt, fetch.fromCache
 
-	if len(out.MCPQuota.Details) > 0 {
-		sections = append(sections, "", key.String("MCP Details"))
-		for _, detail := range out.MCPQuota.Details {
-			sections = append(sections, fmt.Sprintf("- %s: %s", detail.ModelCode, formatNumber(detail.Usage)))
+	// When interrupted, print the providers that already answered and skip
+	// alerts and webhooks, which would only fail on the canceled context.
+	if parent.Err() != nil {
+		if err := writeReport(*out, format, results, output, tmpl, fields); err != nil {
+			return err
 		}
-	}
 
-	return box.String(strings.Join(sections, "\n"))
-}
-
-func printCodexReport(out *codex.Quota) string {
-	key := tinta.Text().Bold()
-	section := tinta.Text().Bold()
-	heading := tinta.Text().BrightMagenta().Bold().String("OpenAI Codex")
-	box := tinta.Box().
-		BorderSimple().
-		Magenta().
-		DisableTop().
-		DisableBottom().
-		DisableRight().
-		PaddingLeft(1).
-		PaddingRight(0)
-
-	sections := []string{
-		heading,
-		"",
-		fmt.Sprintf("%s %s (%s)", key.String("Account:"), out.AccountEmail, out.AccountType),
-		"",
-		formatRateLimitWindow("Rate Limit Primary Window", out.RateLimitPrimaryWindow, key, section),
-		"",
-		formatRateLimitWindow("Rate Limit Secondary Window", out.RateLimitSecondaryWindow, key, section),
-		"",
-		formatRateLimitWindow("Code Review Primary Window", out.CodeReviewPrimaryWindow, key, section),
+		return errInterrupted
 	}
 
-	return box.String(strings.Join(sections, "\n"))
-}
+	// Given on the command line, --discord-webhook asks for the full report
+	// rather than threshold alerts. A webhook from the environment or the
+	// config file keeps alerting, so every run does not post a report.
+	var discordWebhook string
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "discord-webhook" {
+			discordWebhook, alerts.discordWebhook = alerts.discordWebhook, ""
+		}
+	})
+	alerts.dispatch(ctx, results)
 
-func printWarnings(warnings []string) string {
-	title := tinta.Text().BrightRed().Bold().String("Warnings")
-	body := []string{title, tinta.Text().Red().String("Some providers could not be queried:")}
-	for _, warning := range warnings {
-		body = append(body, tinta.Text().Yellow().Sprintf("- %s", warning))
+	if err := writeReport(*out, format, results, output, tmpl, fields); err != nil {
+		return err
 	}
 
-	box := tinta.Box().BorderSimple().Red().PaddingX(2).PaddingY(1)
-	return box.String(strings.Join(body, "\n"))
-}
-
-func formatRateLimitWindow(name string, window codex.RateLimitWindow, key *tinta.TextStyle, section *tinta.TextStyle) string {
-	lines := []string{section.String(name)}
-
-	if window.UsedPercent == nil || window.ResetAt == nil || window.ResetIn == nil {
-		lines = append(lines,
-			fmt.Sprintf("%s %s", key.String("Usage:"), "unavailable"),
-			fmt.Sprintf("%s %s", key.String("Reset in:"), "unavailable"),
-		)
-
-		return strings.Join(lines, "\n")
+	if discordWebhook != "" {
+		if err := (alert.Discord{WebhookURL: discordWebhook}).SendReport(ctx, results); err != nil {
+			return err
+		}
 	}
 
-	lines = append(lines, fmt.Sprintf("%s %s", key.String("Used:"), colorPercent(*window.UsedPercent)))
-	if reset := formatReset(*window.ResetIn, *window.ResetAt); reset != "" {
-		lines = append(lines, fmt.Sprintf("%s %s", key.String("Reset in:"), reset))
+	if breaches := thresholdBreaches(results, &failAt); len(breaches) > 0 {
+		return &exitError{
+			code: exitThresholdExceeded,
+			err:  fmt.Errorf("quota threshold exceeded: %s", strings.Join(breaches, "; ")),
+		}
 	}
 
-	return strings.Join(lines, "\n")
+	return nil
 }
 
-func formatResetAt(value string) string {
-	if value == "" || value == "unknown" {
-		return "unknown"
-	}
-
-	timeValue, err := time.Parse(time.RFC3339, value)
-	if err != nil {
-		return value
+// fetchQuotas queries the providers selected by the fetch flags, or replays
+// their cached responses with --cached or --max-age. It fails only when no
+// provider is configured or none of them returned data.
+func fetchQuotas(ctx context.Context, creds credentials.Credentials, fetch *fetchFlags) ([]provider.Result, error) {
+	// Repeated fetches, as in watch, look the cache up again.
+	cached := fetch.cache
+	fetch.cache = nil
+
+	var err error
+	if cached == nil && fetch.replay == nil {
+		if cached, err = fetch.cachedResponses(); err != nil {
+			return nil, err
+		}
 	}
 
-	return timeValue.UTC().Format("2006-01-02 15:04:05")
-}
-
-func formatReset(resetIn string, resetAt string) string {
-	formattedResetAt := formatResetAt(resetAt)
-	trimmedResetIn := strings.TrimSpace(resetIn)
-
-	if trimmedResetIn == "" || strings.EqualFold(trimmedResetIn, "unknown") {
-		if formattedResetAt == "unknown" {
-			return ""
+	// Credentials from an earlier cache hit are redacted, so a miss loads
+	// the real ones.
+	if cached == nil && fetch.replay == nil && fetch.live == nil {
+		if creds, err = fetch.liveCredentials(); err != nil {
+			return nil, err
 		}
-
-		return formattedResetAt
 	}
 
-	if formattedResetAt == "unknown" {
-		return trimmedResetIn
+	var enabled []provider.Provider
+	if cached != nil {
+		enabled = cached.providers
+	} else if enabled, err = fetch.enabled(creds); err != nil {
+		return nil, err
 	}
 
-	return fmt.Sprintf("%s - %s", trimmedResetIn, formattedResetAt)
-}
-
-func formatPercent(value float64) string {
-	formatted := strconv.FormatFloat(value, 'f', 2, 64)
-	formatted = strings.TrimRight(formatted, "0")
-	formatted = strings.TrimRight(formatted, ".")
-	if formatted == "" {
-		return "0"
+	opts := fetch.options()
+	fetch.fetchedAt, fetch.fromCache = time.Now(), false
+	var dumped string
+	switch {
+	case cached != nil:
+		opts.Replay = cached.replay
+		fetch.fetchedAt, fetch.fromCache = cached.at, true
+	case opts.Dump == nil && opts.Replay == nil:
+		opts.Dump, dumped = startResponseDump()
 	}
-
-	return formatted
-}
-
-func formatNumber(value float64) string {
-	if value == float64(int64(value)) {
-		return strconv.FormatInt(int64(value), 10)
+	if fetch.progress && isTerminal(os.Stderr) && logLevel.Level() >= slog.LevelWarn {
+		status := startProgress(os.Stderr, enabled)
+		opts.OnResult = status.finish
+		defer status.stop()
 	}
 
-	return formatPercent(value)
-}
-
-func colorPercent(value float64) string {
-	percent := formatPercent(value) + "%"
+	results := provider.FetchAll(ctx, creds, enabled, opts)
+	if dumped != "" {
+		saveResponses(dumped, results, creds, fetch.fetchedAt)
+	}
 
-	switch {
-	case value >= 75:
-		return tinta.Text().BrightRed().Bold().String(percent)
-	case value >= 50:
-		return tinta.Text().BrightYellow().Bold().String(percent)
-	default:
-		return tinta.Text().BrightGreen().Bold().String(percent)
+	for _, result := range results {
+		if result.Err == nil {
+			return results, nil
+		}
 	}
+
+	// Every provider failed, so their errors are the only report.
+	return nil, fmt.Errorf("could not fetch quota data from any provider\n  %s", strings.Join(aiquota.Warnings(results), "\n  "))
 }
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/internal/i18n"
+	"github.com/eduardolat/aiquota/pkg/aiquota"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// markdownReport renders the report for --format markdown: a table per
+// provider, ready to paste into issues, pull requests or wikis, followed by
+// failed providers as a blockquote.
+func markdownReport(at time.Time, results []provider.Result) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", i18n.T("AI Quota Report"))
+	fmt.Fprintf(&b, "_%s._\n", i18n.Sprintf("Generated %s", formatTime(at)))
+
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n## %s\n\n", markdownEscape(result.Provider.Name()))
+
+		windows := result.Quota.Windows()
+		if len(windows) == 0 {
+			fmt.Fprintf(&b, "_%s_\n", i18n.T("No quota windows."))
+			continue
+		}
+
+		fmt.Fprintf(
+			&b,
+			"| %s | %s | %s | %s | %s | %s |\n",
+			i18n.T("Window"), i18n.T("Used"), i18n.T("Limit"), i18n.T("Remaining"), i18n.T("Used %"), i18n.T("Resets"),
+		)
+		b.WriteString("| --- | ---: | ---: | ---: | ---: | --- |\n")
+		for _, window := range windows {
+			fmt.Fprintf(
+				&b,
+				"| %s | %s | %s | %s | %s | %s |\n",
+				markdownEscape(i18n.T(window.Name)),
+				markdownNumber(window.Used),
+				markdownNumber(window.Limit),
+				markdownNumber(window.Remaining),
+				markdownPercent(window.UsedPercent),
+				cmp.Or(formatReset(helpers.FormatTimeUntil(window.ResetAt), window.ResetAt), "-"),
+			)
+		}
+	}
+
+	if warnings := aiquota.Warnings(results); len(warnings) > 0 {
+		fmt.Fprintf(&b, "\n## %s\n\n", i18n.T("Warnings"))
+		fmt.Fprintf(&b, "> %s\n>\n", i18n.T("Some providers could not be queried:"))
+		for _, warning := range warnings {
+			b.WriteString("> - " + markdownEscape(warning) + "\n")
+		}
+	}
+
+	return b.String()
+}
+
+func markdownNumber(value *float64) string {
+	if value == nil {
+		return "-"
+	}
+
+	return formatNumber(*value)
+}
+
+func markdownPercent(value *float64) string {
+	if value == nil {
+		return "-"
+	}
+
+	return formatPercent(*value) + "%"
+}
+
+// markdownEscaper escapes the characters that would break a table cell or
+// start inline formatting.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"|", `\|`,
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+	"\n", " ",
+)
+
+func markdownEscape(text string) string {
+	return markdownEscaper.Replace(text)
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/config"
+	"github.com/eduardolat/aiquota/internal/i18n"
+	"github.com/eduardolat/aiquota/internal/pricing"
+	"github.com/eduardolat/aiquota/pkg/provider"
+	"github.com/varavelio/tinta"
+)
+
+// outputFlags holds the flags that control how reports are drawn.
+type outputFlags struct {
+	color   colorMode
+	noColor bool
+	plain   bool
+	noDiff  bool
+	compact bool
+	bars    int
+	sort    reportSort
+	labels  providerValues[string]
+	groups  providerValues[string]
+
+	// prices and budgets come from the config file, see configure.
+	prices  pricing.Table
+	budgets *budgetSet
+
+	// fetchedAt and fromCache come from the fetch flags of the run, so the
+	// report carries the same timestamp as the history and the CSV log. They
+	// have no flag.
+	fetchedAt time.Time
+	fromCache bool
+}
+
+func addOutputFlags(flags *flag.FlagSet) *outputFlags {
+	o := &outputFlags{
+		color:  colorAuto,
+		labels: newProviderValues(parseName, func(value string) string { return value }),
+		groups: newProviderValues(parseName, func(value string) string { return value }),
+		prices: pricing.Defaults,
+	}
+	flags.Var(&o.color, "color", "when to use colors: auto, always or never")
+	flags.BoolVar(&o.noColor, "no-color", false, "disable colors, same as --color never (also set by NO_COLOR)")
+	flags.BoolVar(&o.plain, "plain", false, "print indented plain text without colors or box drawing (default when stdout is not a terminal)")
+	flags.BoolVar(&o.noDiff, "no-diff", false, "do not show changes since the previous report")
+	flags.BoolVar(&o.compact, "compact", false, "print one line per provider, for shell prompts and small panes")
+	flags.IntVar(&o.bars, "bar-width", 10, "width in cells of the usage bars in provider boxes, 0 hides them")
+	flags.Var(&o.sort, "sort", "order of the providers in the report: used, name or reset (default the provider order)")
+	flags.BoolVar(&asciiOnly, "ascii", false, "draw boxes, bars and symbols in plain ASCII, for dumb terminals and serial consoles")
+	flags.Var(&o.labels, "label", "show a label next to provider names, as provider=label pairs, comma-separated")
+	flags.Var(&o.groups, "group", "group providers in the report, as provider=group pairs, comma-separated; a bare group name applies to the other providers")
+	addLangFlag(flags)
+	addTimeFlags(flags)
+	addThemeFlags(flags)
+
+	return o
+}
+
+// addLangFlag adds --lang, which selects the language of reports and alerts
+// over the one of the locale. Commands with several flag groups add it once.
+func addLangFlag(flags *flag.FlagSet) {
+	if flags.Lookup("lang") != nil {
+		return
+	}
+
+	flags.Func("lang", "output language: "+strings.Join(i18n.Languages, ", ")+" (default from $LC_ALL, $LC_MESSAGES or $LANG)", i18n.Set)
+}
+
+// configure applies the config file settings that have no flag.
+func (o *outputFlags) configure(cfg config.Config) error {
+	budgets, err := newBudgetSet(cfg)
+	if err != nil {
+		return err
+	}
+
+	o.prices, o.budgets = priceTable(cfg.Pricing), budgets
+	return nil
+}
+
+// apply configures color output. It must run after the flags are parsed and
+// before anything is rendered. tinta already honors NO_COLOR and disables
+// colors when stdout is not a terminal.
+func (o *outputFlags) apply() {
+	tinta.ForceColors(!o.isPlain() && o.colors(os.Stdout))
+	stderrColors = o.colors(os.Stderr)
+}
+
+// colors reports whether output written to f is colored. Each stream is
+// decided on its own, so a redirected stdout keeps the progress line on
+// stderr colored and the other way around. --color always and never apply
+// to every stream, and auto follows the environment variables tinta honors
+// and whether f is a terminal.
+func (o *outputFlags) colors(f *os.File) bool {
+	switch {
+	case o.noColor || o.color == colorNever:
+		return false
+	case o.color == colorAlways:
+		return true
+	case os.Getenv("NO_COLOR") != "" || os.Getenv("NO_COLORS") != "" || os.Getenv("DISABLE_COLORS") != "":
+		return false
+	case os.Getenv("FORCE_COLOR") != "" || os.Getenv("CLICOLOR_FORCE") != "":
+		return true
+	case os.Getenv("CLICOLOR") == "0" || strings.EqualFold(os.Getenv("TERM"), "dumb"):
+		return false
+	default:
+		return isTerminal(f)
+	}
+}
+
+// isPlain reports whether reports are drawn without boxes, either on request
+// or because stdout is redirected. --color always and FORCE_COLOR keep the
+// full report when piping into a pager.
+func (o *outputFlags) isPlain() bool {
+	if o.plain {
+		return true
+	}
+
+	return !isTerminal(os.Stdout) && o.color != colorAlways && os.Getenv("FORCE_COLOR") == ""
+}
+
+// render draws the terminal report in the selected style.
+func (o *outputFlags) render(results []provider.Result) string {
+	results = sortResults(results, o.sort)
+	if o.compact {
+		return asciiText(compactReport(results))
+	}
+
+	annotations := burnRateAnnotations(results)
+	if !o.noDiff {
+		annotations = mergeAnnotations(deltaAnnotations(results), annotations)
+	}
+
+	var cachedAt time.Time
+	if o.fromCache {
+		cachedAt = o.fetchedAt
+	}
+
+	renderer := &reportRenderer{
+		annotations: annotations,
+		plain:       o.isPlain(),
+		barWidth:    o.bars,
+		width:       terminalWidth(os.Stdout),
+		labels:      o.labels.values,
+		groups:      &o.groups,
+		costs:       estimateCosts(results, o.prices, time.Now()),
+		budgets:     o.budgets.evaluate(results, time.Now()),
+		cachedAt:    cachedAt,
+	}
+
+	return renderer.render(results)
+}
+
+// timestamp returns when the report data was fetched, or now for reports
+// whose command does not say.
+func (o *outputFlags) timestamp() time.Time {
+	if o.fetchedAt.IsZero() {
+		return time.Now()
+	}
+
+	return o.fetchedAt
+}
+
+// parseName accepts any non-empty label or group name.
+func parseName(value string) (string, error) {
+	if value == "" {
+		return "", fmt.Errorf("expected a non-empty name")
+	}
+
+	return value, nil
+}
+
+// colorMode is the value of --color.
+type colorMode string
+
+const (
+	colorAuto   colorMode = "auto"
+	colorAlways colorMode = "always"
+	colorNever  colorMode = "never"
+)
+
+func (m *colorMode) String() string {
+	if m == nil {
+		return ""
+	}
+
+	return string(*m)
+}
+
+func (m *colorMode) Set(value string) error {
+	switch mode := colorMode(strings.ToLower(value)); mode {
+	case colorAuto, colorAlways, colorNever:
+		*m = mode
+		return nil
+	default:
+		return fmt.Errorf("invalid color mode %q, expected auto, always or never", value)
+	}
+}
+
+// isTerminal reports whether f is attached to a terminal. It is a variable
+// so tests can stub the standard streams.
+var isTerminal = func(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// indent prefixes every non-empty line of s with prefix.
+func indent(s string, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
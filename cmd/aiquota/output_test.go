@@ -0,0 +1,102 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/varavelio/tinta"
+)
+
+// stubTerminals makes isTerminal report the given answers for stdout and
+// stderr for the rest of the test.
+func stubTerminals(t *testing.T, stdout bool, stderr bool) {
+	t.Helper()
+
+	original := isTerminal
+	isTerminal = func(f *os.File) bool {
+		switch f {
+		case os.Stdout:
+			return stdout
+		case os.Stderr:
+			return stderr
+		default:
+			return false
+		}
+	}
+	t.Cleanup(func() {
+		isTerminal = original
+		tinta.ForceColors(false)
+		stderrColors = false
+	})
+}
+
+// clearColorEnv unsets the environment variables that override color
+// detection, so the test does not depend on the shell running it.
+func clearColorEnv(t *testing.T) {
+	t.Helper()
+
+	for _, name := range []string{"NO_COLOR", "NO_COLORS", "DISABLE_COLORS", "FORCE_COLOR", "CLICOLOR_FORCE", "CLICOLOR"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("TERM", "xterm-256color")
+}
+
+func TestApplyDetectsEachStream(t *testing.T) {
+	tests := []struct {
+		name         string
+		stdoutTTY    bool
+		stderrTTY    bool
+		color        colorMode
+		stdoutColors bool
+		stderrColors bool
+	}{
+		{"both terminals", true, true, colorAuto, true, true},
+		{"stdout piped", false, true, colorAuto, false, true},
+		{"stderr redirected", true, false, colorAuto, true, false},
+		{"both redirected", false, false, colorAuto, false, false},
+		{"always", false, false, colorAlways, true, true},
+		{"never", true, true, colorNever, false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clearColorEnv(t)
+			stubTerminals(t, test.stdoutTTY, test.stderrTTY)
+
+			output := &outputFlags{color: test.color}
+			output.apply()
+
+			if got := strings.Contains(tinta.Text().Red().String("x"), "\x1b["); got != test.stdoutColors {
+				t.Errorf("stdout colors = %v, want %v", got, test.stdoutColors)
+			}
+			if stderrColors != test.stderrColors {
+				t.Errorf("stderr colors = %v, want %v", stderrColors, test.stderrColors)
+			}
+		})
+	}
+}
+
+func TestApplyHonorsNoColor(t *testing.T) {
+	clearColorEnv(t)
+	t.Setenv("NO_COLOR", "1")
+	stubTerminals(t, true, true)
+
+	output := &outputFlags{color: colorAuto}
+	output.apply()
+
+	if strings.Contains(tinta.Text().Red().String("x"), "\x1b[") || stderrColors {
+		t.Errorf("NO_COLOR left colors enabled")
+	}
+}
+
+func TestProgressStatusStyled(t *testing.T) {
+	status := progressStatus{mark: "✓", color: "bright-green", detail: "320ms"}
+
+	if got, want := status.styled(true), "\x1b[92m✓\x1b[0m 320ms"; got != want {
+		t.Errorf("styled(true) = %q, want %q", got, want)
+	}
+	if got, want := status.styled(false), "✓ 320ms"; got != want {
+		t.Errorf("styled(false) = %q, want %q", got, want)
+	}
+}
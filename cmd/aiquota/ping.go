@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// pingTimeout bounds a ping, so a slow monitoring service does not hold up
+// the run it reports on.
+const pingTimeout = 10 * time.Second
+
+// pingURL is the --ping flag: a healthchecks.io style check URL that is
+// requested after every run, with /fail appended when the run failed.
+type pingURL struct {
+	url *url.URL
+}
+
+func addPingFlag(flags *flag.FlagSet) *pingURL {
+	ping := &pingURL{}
+	flags.Var(ping, "ping", "after every run, request this healthchecks.io style URL on success and URL/fail when a provider or the run failed")
+	return ping
+}
+
+func (p *pingURL) String() string {
+	if p == nil || p.url == nil {
+		return ""
+	}
+
+	return p.url.String()
+}
+
+func (p *pingURL) Set(value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("expected an http or https URL")
+	}
+
+	p.url = parsed
+	return nil
+}
+
+// send reports the outcome of a run, whose failures are runErr and the
+// providers that returned an error. The failures are the request body, which
+// healthchecks.io shows as the ping log. A failed ping is reported on stderr.
+func (p *pingURL) send(ctx context.Context, results []provider.Result, runErr error) {
+	if p.url == nil {
+		return
+	}
+
+	var fetched, failures []string
+	if runErr != nil {
+		failures = append(failures, runErr.Error())
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", result.Provider.Name(), result.Err))
+		} else {
+			fetched = append(fetched, result.Provider.ID())
+		}
+	}
+
+	target := *p.url
+	body := "fetched " + strings.Join(fetched, ", ") + "\n"
+	if len(failures) > 0 {
+		target.Path = strings.TrimSuffix(target.Path, "/") + "/fail"
+		target.RawPath = ""
+		body = strings.Join(failures, "\n") + "\n"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	if err := postPing(ctx, target.String(), body); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+func postPing(ctx context.Context, target string, body string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, strings.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create ping request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	response, err := httpclient.Default.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send ping: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("failed to send ping. Status: %d", response.StatusCode)
+	}
+
+	return nil
+}
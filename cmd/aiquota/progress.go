@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/eduardolat/aiquota/pkg/provider"
+	"golang.org/x/term"
+)
+
+// stderrColors tells whether the progress line on stderr is colored. It is
+// decided apart from stdout by outputFlags.apply, so `aiquota | jq` keeps
+// it colored and `aiquota 2>log` writes no escape codes.
+var stderrColors bool
+
+// spinnerFrames animate the providers still in flight.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// progress draws a single status line such as
+// "Copilot ✓ 320ms · Z.ai ⠋ · Codex ✗ timeout" while providers are fetched,
+// redrawing it in place until stop clears it.
+type progress struct {
+	out       *os.File
+	providers []provider.Provider
+
+	mu     sync.Mutex
+	status map[string]progressStatus
+	frame  int
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// startProgress starts drawing the status of providers on out, which must
+// be a terminal.
+func startProgress(out *os.File, providers []provider.Provider) *progress {
+	p := &progress{
+		out:       out,
+		providers: providers,
+		status:    map[string]progressStatus{},
+		done:      make(chan struct{}),
+	}
+
+	p.wg.Go(func() {
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			p.draw()
+			select {
+			case <-p.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+
+	return p
+}
+
+// finish marks the provider of result as done. It is safe to call from the
+// fetching goroutines.
+func (p *progress) finish(result provider.Result, elapsed time.Duration) {
+	status := progressStatus{mark: "✓", color: activeTheme.low, detail: elapsed.Round(time.Millisecond).String()}
+	if result.Err != nil {
+		status = progressStatus{mark: "✗", color: activeTheme.high, detail: progressError(result.Err)}
+	}
+
+	p.mu.Lock()
+	p.status[result.Provider.ID()] = status
+	p.mu.Unlock()
+}
+
+// stop stops drawing and clears the status line.
+func (p *progress) stop() {
+	close(p.done)
+	p.wg.Wait()
+	fmt.Fprint(p.out, "\r\x1b[K")
+}
+
+func (p *progress) draw() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	width := 80
+	if w, _, err := term.GetSize(int(p.out.Fd())); err == nil && w > 0 {
+		width = w
+	}
+
+	frames := spinnerFrames
+	if asciiOnly {
+		frames = asciiSpinnerFrames
+	}
+	spinner := progressStatus{mark: frames[p.frame%len(frames)], color: activeTheme.title}
+	p.frame++
+
+	// Segments that do not fit are replaced by an ellipsis, so the line
+	// never wraps and "\r" keeps redrawing it in place.
+	var line strings.Builder
+	used := 0
+	for i, pr := range p.providers {
+		status, ok := p.status[pr.ID()]
+		if !ok {
+			status = spinner
+		}
+
+		separator := ""
+		if i > 0 {
+			separator = asciiText(" · ")
+		}
+
+		ellipsis := asciiText(" …")
+		visible := utf8.RuneCountInString(separator + pr.Name() + " " + asciiText(status.plain()))
+		if used+visible > width-utf8.RuneCountInString(ellipsis) {
+			line.WriteString(ellipsis)
+			break
+		}
+
+		line.WriteString(separator + pr.Name() + " " + asciiText(status.styled(stderrColors)))
+		used += visible
+	}
+
+	fmt.Fprint(p.out, "\r\x1b[K"+line.String())
+}
+
+// progressStatus is the state of one provider in the status line.
+type progressStatus struct {
+	mark   string
+	color  themeColor
+	detail string
+}
+
+func (s progressStatus) plain() string {
+	return strings.TrimSpace(s.mark + " " + s.detail)
+}
+
+func (s progressStatus) styled(colors bool) string {
+	mark := s.mark
+	if colors {
+		mark = s.color.ansi(mark)
+	}
+
+	return strings.TrimSpace(mark + " " + s.detail)
+}
+
+// progressError is the short form of a fetch error shown in the status line.
+func progressError(err error) string {
+	switch {
+	case errors.Is(err, provider.ErrTimeout):
+		return "timeout"
+	case errors.Is(err, provider.ErrInterrupted):
+		return "interrupted"
+	default:
+		return "error"
+	}
+}
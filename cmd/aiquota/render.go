@@ -0,0 +1,1393 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/internal/i18n"
+	"github.com/eduardolat/aiquota/internal/pricing"
+	"github.com/eduardolat/aiquota/pkg/aiquota"
+	"github.com/eduardolat/aiquota/pkg/anthropic"
+	"github.com/eduardolat/aiquota/pkg/azure"
+	"github.com/eduardolat/aiquota/pkg/bedrock"
+	"github.com/eduardolat/aiquota/pkg/codex"
+	"github.com/eduardolat/aiquota/pkg/cohere"
+	"github.com/eduardolat/aiquota/pkg/copilot"
+	"github.com/eduardolat/aiquota/pkg/cursor"
+	"github.com/eduardolat/aiquota/pkg/deepseek"
+	"github.com/eduardolat/aiquota/pkg/fireworks"
+	"github.com/eduardolat/aiquota/pkg/gemini"
+	"github.com/eduardolat/aiquota/pkg/githubmodels"
+	"github.com/eduardolat/aiquota/pkg/groq"
+	"github.com/eduardolat/aiquota/pkg/mistral"
+	"github.com/eduardolat/aiquota/pkg/moonshot"
+	"github.com/eduardolat/aiquota/pkg/openai"
+	"github.com/eduardolat/aiquota/pkg/openrouter"
+	"github.com/eduardolat/aiquota/pkg/perplexity"
+	"github.com/eduardolat/aiquota/pkg/provider"
+	"github.com/eduardolat/aiquota/pkg/replicate"
+	"github.com/eduardolat/aiquota/pkg/together"
+	"github.com/eduardolat/aiquota/pkg/xai"
+	"github.com/eduardolat/aiquota/pkg/zai"
+	"github.com/varavelio/tinta"
+)
+
+// reportRenderer draws the terminal report.
+type reportRenderer struct {
+	// annotations holds extra lines keyed by annotationKey, shown under the
+	// matching provider window.
+	annotations map[string][]string
+
+	// plain replaces boxes with indentation.
+	plain bool
+
+	// barWidth is the number of cells in the usage bars, or 0 without bars.
+	barWidth int
+
+	// width is the terminal width, which lays boxes out in columns when
+	// several fit, or 0 to stack them.
+	width int
+
+	// labels holds display labels keyed by provider ID, and groups the
+	// group of each provider. Either may be nil.
+	labels map[string]string
+	groups *providerValues[string]
+
+	// costs holds the estimated cost of providers reporting token usage,
+	// keyed by provider ID.
+	costs map[string]pricing.Estimate
+
+	// budgets holds the status of the configured budgets.
+	budgets []budgetStatus
+
+	// cachedAt is when the responses of a cached report were received, shown
+	// under the title, or zero for live reports.
+	cachedAt time.Time
+
+	// label, cost and budget lines belong to the provider being drawn, and
+	// box adds them to its heading and end.
+	label       string
+	cost        *pricing.Estimate
+	budgetLines []string
+}
+
+func annotationKey(providerID string, windowID string) string {
+	return providerID + "/" + windowID
+}
+
+// notes returns the annotation lines for a provider window.
+func (r *reportRenderer) notes(providerID string, windowID string) []string {
+	if r == nil {
+		return nil
+	}
+
+	return r.annotations[annotationKey(providerID, windowID)]
+}
+
+func (r *reportRenderer) render(results []provider.Result) string {
+	sections := []string{themeText(activeTheme.title).Bold().String(i18n.T("AI QUOTA REPORT"))}
+	if !r.cachedAt.IsZero() {
+		sections = append(sections, tinta.Text().Dim().String(i18n.Sprintf("cached %s ago", helpers.FormatDuration(time.Since(r.cachedAt)))))
+	}
+	sections = append(sections, "")
+
+	for _, group := range r.group(results) {
+		if group.name != "" {
+			sections = append(sections, r.printGroupHeading(group))
+		}
+
+		boxes := []string{}
+		for _, result := range group.results {
+			if result.Err == nil {
+				boxes = append(boxes, r.renderProvider(result))
+			}
+		}
+		sections = append(sections, r.columns(boxes)...)
+	}
+
+	if warnings := aiquota.Warnings(results); len(warnings) > 0 {
+		sections = append(sections, r.printWarnings(warnings))
+	}
+
+	return r.frame(sections)
+}
+
+// frame draws the outer double box around the report sections, or joins them
+// in plain mode.
+func (r *reportRenderer) frame(sections []string) string {
+	content := asciiText(strings.TrimSpace(strings.Join(sections, "\n")))
+	if r.plain {
+		return content
+	}
+
+	outer := activeTheme.title.box(tinta.Box().Border(boxBorder(tinta.BorderDouble))).
+		PaddingLeft(0).
+		PaddingRight(1).
+		PaddingBottom(0).
+		CenterFirstLine()
+
+	return outer.String(content)
+}
+
+// renderProvider draws the box for a provider, falling back to a generic
+// window listing for providers without a dedicated layout.
+func (r *reportRenderer) renderProvider(result provider.Result) string {
+	r.label = r.labels[result.Provider.ID()]
+	if estimate, ok := r.costs[result.Provider.ID()]; ok {
+		r.cost = &estimate
+	}
+	for _, status := range r.budgets {
+		if status.provider == result.Provider.ID() {
+			r.budgetLines = append(r.budgetLines, formatBudget(status))
+		}
+	}
+	defer func() { r.label, r.cost, r.budgetLines = "", nil, nil }()
+
+	switch quota := result.Quota.(type) {
+	case *copilot.Quota:
+		return r.printCopilotReport(quota)
+	case *copilot.OrgQuota:
+		return r.printCopilotOrgReport(quota)
+	case *zai.Quota:
+		return r.printZAIReport(quota)
+	case *codex.Quota:
+		return r.printCodexReport(quota)
+	case *replicate.Quota:
+		return r.printReplicateReport(quota)
+	case *anthropic.Quota:
+		return r.printAnthropicReport(quota)
+	case *gemini.Quota:
+		return r.printGeminiReport(quota)
+	case *openrouter.Quota:
+		return r.printOpenRouterReport(quota)
+	case *cursor.Quota:
+		return r.printCursorReport(quota)
+	case *anthropic.AdminQuota:
+		return r.printAnthropicAPIReport(quota)
+	case *mistral.Quota:
+		return r.printMistralReport(quota)
+	case *deepseek.Quota:
+		return r.printDeepSeekReport(quota)
+	case *groq.Quota:
+		return r.printGroqReport(quota)
+	case *xai.Quota:
+		return r.printXAIReport(quota)
+	case *together.Quota:
+		return r.printTogetherReport(quota)
+	case *fireworks.Quota:
+		return r.printFireworksReport(quota)
+	case *cohere.Quota:
+		return r.printCohereReport(quota)
+	case *perplexity.Quota:
+		return r.printPerplexityReport(quota)
+	case *moonshot.Quota:
+		return r.printMoonshotReport(quota)
+	case *openai.Quota:
+		return r.printOpenAIReport(quota)
+	case *azure.Quota:
+		return r.printAzureReport(quota)
+	case *bedrock.Quota:
+		return r.printBedrockReport(quota)
+	case *githubmodels.Quota:
+		return r.printGitHubModelsReport(quota)
+	default:
+		return r.printGenericReport(result.Provider, result.Quota)
+	}
+}
+
+func (r *reportRenderer) printCopilotReport(out *copilot.Quota) string {
+	key := tinta.Text().Bold()
+	heading := providerHeading("copilot", "GitHub Copilot")
+	box := providerBox("copilot")
+
+	sections := []string{
+		heading,
+		"",
+		fmt.Sprintf("%s %s (%s)", key.String(i18n.T("Account:")), out.AccountUser, out.AccountType),
+	}
+
+	if line := formatTokenExpiry(out.TokenExpiresAt, key); line != "" {
+		sections = append(sections, line)
+	}
+
+	if reset := formatReset(out.ResetIn, out.ResetAt); reset != "" {
+		sections = append(sections, fmt.Sprintf("%s %s", key.String(i18n.T("Reset in:")), reset))
+	}
+
+	for _, snapshot := range out.Snapshots {
+		sections = append(sections, "", key.String(i18n.T(snapshot.Name)))
+		if snapshot.Unlimited {
+			sections = append(sections, fmt.Sprintf("%s %s", key.String(i18n.T("Requests:")), i18n.T("unlimited")))
+		} else {
+			sections = append(sections,
+				fmt.Sprintf("%s %d / %d", key.String(i18n.T("Requests:")), snapshot.Used, snapshot.Entitlement),
+				fmt.Sprintf("%s %s", key.String(i18n.T("Used:")), r.usedPercent(snapshot.UsedPercent)),
+			)
+		}
+
+		if snapshot.OverageCount > 0 || snapshot.OveragePermitted {
+			permitted := i18n.T("not permitted")
+			if snapshot.OveragePermitted {
+				permitted = i18n.T("permitted")
+			}
+			sections = append(sections, fmt.Sprintf("%s %d (%s)", key.String(i18n.T("Overage:")), snapshot.OverageCount, permitted))
+		}
+
+		if snapshot.ID == "premium_interactions" && out.OveragePermitted {
+			paid := i18n.Sprintf("%s this cycle", formatMoney(out.OverageCostUSD))
+			if !out.OverageBilled {
+				paid += tinta.Text().Dim().String(i18n.Sprintf(" (estimated at %s per request)", formatMoney(copilot.OveragePrice)))
+			}
+			sections = append(sections, fmt.Sprintf("%s %s", key.String(i18n.T("Paid usage:")), paid))
+		}
+
+		windowID := snapshot.ID
+		if windowID == "premium_interactions" {
+			windowID = "premium"
+		}
+		sections = append(sections, r.notes("copilot", windowID)...)
+	}
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+// maxSeatLines bounds how many seats the report lists. The JSON report
+// always has all of them.
+const maxSeatLines = 20
+
+func (r *reportRenderer) printCopilotOrgReport(out *copilot.OrgQuota) string {
+	key := tinta.Text().Bold()
+	heading := providerHeading("copilot-org", "GitHub Copilot Organization")
+	box := providerBox("copilot-org")
+
+	sections := []string{
+		heading,
+		"",
+		fmt.Sprintf("%s %s (%s)", key.String(i18n.T("Organization:")), out.Organization, out.PlanType),
+		i18n.Sprintf("%s %d / %d active this cycle", key.String(i18n.T("Seats:")), out.SeatsActive, out.SeatsTotal),
+	}
+
+	if window := out.Windows()[0]; window.UsedPercent != nil {
+		sections = append(sections, fmt.Sprintf("%s %s", key.String(i18n.T("Active:")), colorPercent(*window.UsedPercent)))
+	}
+	if out.PendingInvitation > 0 || out.PendingCancellation > 0 {
+		sections = append(sections, i18n.Sprintf("%s %d invited, %d cancelling", key.String(i18n.T("Pending:")), out.PendingInvitation, out.PendingCancellation))
+	}
+	sections = append(sections, r.notes("copilot-org", "active_seats")...)
+
+	if len(out.Seats) > 0 {
+		sections = append(sections, "", key.String(i18n.T("Last activity")))
+		for i, seat := range out.Seats {
+			if i == maxSeatLines {
+				sections = append(sections, tinta.Text().Dim().String(i18n.Sprintf("… and %d more, see --format json", len(out.Seats)-maxSeatLines)))
+				break
+			}
+
+			activity := i18n.T("never")
+			if at, err := time.Parse(time.RFC3339, seat.LastActivityAt); err == nil {
+				activity = i18n.Sprintf("%s ago", formatAge(time.Since(at)))
+				if seat.LastActivityEditor != "" {
+					activity += i18n.Sprintf(" in %s", seat.LastActivityEditor)
+				}
+			}
+			sections = append(sections, fmt.Sprintf("- %s: %s", seat.Login, activity))
+		}
+	}
+
+	if out.SeatsPartial {
+		sections = append(sections, tinta.Text().Dim().String(i18n.T("Seat list truncated, the organization has more seats")))
+	}
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+func (r *reportRenderer) printZAIReport(out *zai.Quota) string {
+	key := tinta.Text().Bold()
+	heading := providerHeading("zai", "Z.ai")
+	box := providerBox("zai")
+
+	sections := []string{
+		heading,
+		"",
+		fmt.Sprintf("%s %s (%s)", key.String(i18n.T("Account:")), out.AccountID, out.AccountType),
+		"",
+	}
+
+	sections = append(sections, r.formatZAIWindow("tokens", "Token Quota", out.TokenQuota, key)...)
+	if out.PromptQuota != nil {
+		sections = append(sections, "")
+		sections = append(sections, r.formatZAIWindow("prompts", "Prompt Quota", *out.PromptQuota, key)...)
+	}
+	sections = append(sections, "")
+	sections = append(sections, r.formatZAIWindow("mcp", "MCP Quota", out.MCPQuota.QuotaWindow, key)...)
+
+	if len(out.MCPQuota.Details) > 0 {
+		sections = append(sections, "", key.String(i18n.T("MCP Details")))
+		for _, detail := range out.MCPQuota.Details {
+			sections = append(sections, fmt.Sprintf("- %s: %s", detail.ModelCode, formatNumber(detail.Usage)))
+		}
+	}
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+func (r *reportRenderer) formatZAIWindow(windowID string, title string, window zai.QuotaWindow, key *tinta.TextStyle) []string {
+	lines := []string{
+		key.String(i18n.T(title)),
+		fmt.Sprintf("%s %s", key.String(i18n.T("Used:")), r.usedPercent(window.UsedPercent)),
+	}
+
+	if window.Used != nil && window.Limit != nil {
+		lines = append(lines, fmt.Sprintf("%s %s / %s", key.String(i18n.T("Count:")), formatNumber(*window.Used), formatNumber(*window.Limit)))
+	}
+	if window.Remaining != nil {
+		lines = append(lines, fmt.Sprintf("%s %s", key.String(i18n.T("Remaining:")), formatNumber(*window.Remaining)))
+	}
+	if reset := formatReset(window.ResetIn, window.ResetAt); reset != "" {
+		lines = append(lines, fmt.Sprintf("%s %s", key.String(i18n.T("Reset in:")), reset))
+	}
+
+	return append(lines, r.notes("zai", windowID)...)
+}
+
+func (r *reportRenderer) printCodexReport(out *codex.Quota) string {
+	key := tinta.Text().Bold()
+	section := tinta.Text().Bold()
+	heading := providerHeading("codex", "OpenAI Codex")
+	box := providerBox("codex")
+
+	sections := []string{
+		heading,
+		"",
+		fmt.Sprintf("%s %s (%s)", key.String(i18n.T("Account:")), out.AccountEmail, out.AccountType),
+	}
+
+	if line := formatTokenExpiry(out.TokenExpiresAt, key); line != "" {
+		sections = append(sections, line)
+	}
+
+	sections = append(sections,
+		"",
+		r.formatCodexWindow("primary", "Rate Limit Primary Window", out.RateLimitPrimaryWindow, key, section),
+		"",
+		r.formatCodexWindow("secondary", "Rate Limit Secondary Window", out.RateLimitSecondaryWindow, key, section),
+		"",
+		r.formatCodexWindow("code_review", "Code Review Primary Window", out.CodeReviewPrimaryWindow, key, section),
+	)
+
+	for _, additional := range out.AdditionalWindows {
+		sections = append(sections, "", r.formatCodexWindow(additional.ID, additional.Name, additional.RateLimitWindow, key, section))
+	}
+
+	if credits := out.Credits; credits != nil && (credits.HasCredits || credits.Unlimited) {
+		lines := []string{section.String(i18n.T("Credits"))}
+		switch {
+		case credits.Unlimited:
+			lines = append(lines, fmt.Sprintf("%s %s", key.String(i18n.T("Balance:")), i18n.T("unlimited")))
+		case credits.Granted != nil && credits.Used != nil:
+			lines = append(lines, fmt.Sprintf("%s %s / %s", key.String(i18n.T("Used:")), formatNumber(*credits.Used), formatNumber(*credits.Granted)))
+		}
+		if credits.Remaining != nil && !credits.Unlimited {
+			lines = append(lines, fmt.Sprintf("%s %s", key.String(i18n.T("Remaining:")), formatNumber(*credits.Remaining)))
+		}
+		lines = append(lines, r.notes("codex", "credits")...)
+		sections = append(sections, "", strings.Join(lines, "\n"))
+	}
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+func (r *reportRenderer) printAnthropicReport(out *anthropic.Quota) string {
+	key := tinta.Text().Bold()
+	section := tinta.Text().Bold()
+	heading := providerHeading("anthropic", "Anthropic Claude")
+	box := providerBox("anthropic")
+
+	sections := []string{
+		heading,
+		"",
+		fmt.Sprintf("%s %s", key.String(i18n.T("Account:")), out.AccountType),
+		"",
+		r.formatAnthropicWindow("five_hour", "5-Hour Window", out.FiveHourWindow, key, section),
+		"",
+		r.formatAnthropicWindow("seven_day", "Weekly Window", out.SevenDayWindow, key, section),
+	}
+
+	if out.SevenDayOpusWindow.UsedPercent != nil {
+		sections = append(sections, "", r.formatAnthropicWindow("seven_day_opus", "Weekly Opus Window", out.SevenDayOpusWindow, key, section))
+	}
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+func (r *reportRenderer) printReplicateReport(out *replicate.Quota) string {
+	key := tinta.Text().Bold()
+	heading := providerHeading("replicate", "Replicate")
+	box := providerBox("replicate")
+
+	predictions := formatNumber(float64(out.Predictions))
+	if out.PredictionsPartial {
+		predictions += "+"
+	}
+
+	content := strings.Join([]string{
+		heading,
+		"",
+		fmt.Sprintf("%s %s (%s)", key.String(i18n.T("Account:")), out.AccountUser, out.AccountType),
+		"",
+		fmt.Sprintf("%s %s", key.String(i18n.T("Predictions:")), predictions),
+		fmt.Sprintf("%s %s", key.String(i18n.T("Period since:")), i18n.T(formatResetAt(out.PeriodStart))),
+	}, "\n")
+
+	if reset := formatReset(out.ResetIn, out.ResetAt); reset != "" {
+		content += "\n" + fmt.Sprintf("%s %s", key.String(i18n.T("Reset in:")), reset)
+	}
+
+	return r.box(box, content)
+}
+
+func (r *reportRenderer) printGeminiReport(out *gemini.Quota) string {
+	key := tinta.Text().Bold()
+	section := tinta.Text().Bold()
+	heading := providerHeading("gemini", "Google Gemini")
+	box := providerBox("gemini")
+
+	sections := []string{
+		heading,
+		"",
+		fmt.Sprintf("%s %s (%s)", key.String(i18n.T("Account:")), out.Project, out.AccountType),
+	}
+
+	if len(out.Models) == 0 {
+		sections = append(sections, "", fmt.Sprintf("%s %s", key.String(i18n.T("Daily Requests:")), i18n.T("unavailable")))
+	}
+
+	for _, model := range out.Models {
+		lines := []string{section.String(model.ModelID)}
+		used := r.usedPercent(model.UsedPercent)
+		if model.Remaining != nil {
+			used += i18n.Sprintf(" (%s remaining)", formatNumber(float64(*model.Remaining)))
+		}
+
+		lines = append(lines, fmt.Sprintf("%s %s", key.String(i18n.T("Used:")), used))
+		if reset := formatReset(model.ResetIn, model.ResetAt); reset != "" {
+			lines = append(lines, fmt.Sprintf("%s %s", key.String(i18n.T("Reset in:")), reset))
+		}
+
+		lines = append(lines, r.notes("gemini", model.ModelID)...)
+
+		sections = append(sections, "", strings.Join(lines, "\n"))
+	}
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+func (r *reportRenderer) printOpenRouterReport(out *openrouter.Quota) string {
+	key := tinta.Text().Bold()
+	section := tinta.Text().Bold()
+	heading := providerHeading("openrouter", "OpenRouter")
+	box := providerBox("openrouter")
+
+	sections := []string{
+		heading,
+		"",
+		fmt.Sprintf("%s %s (%s)", key.String(i18n.T("Account:")), out.AccountLabel, out.AccountType),
+		"",
+		section.String(i18n.T("Credits")),
+		fmt.Sprintf("%s %s / %s", key.String(i18n.T("Usage:")), formatMoney(out.TotalUsage), formatMoney(out.TotalCredits)),
+		fmt.Sprintf("%s %s", key.String(i18n.T("Remaining:")), formatMoney(out.RemainingCredits)),
+		fmt.Sprintf("%s %s", key.String(i18n.T("Used:")), r.usedPercent(out.UsedPercent)),
+	}
+	sections = append(sections, r.notes("openrouter", "credits")...)
+
+	if out.KeyLimit != nil {
+		sections = append(sections,
+			"",
+			section.String(i18n.T("API Key Limit")),
+			fmt.Sprintf("%s %s / %s", key.String(i18n.T("Usage:")), formatMoney(out.KeyUsage), formatMoney(*out.KeyLimit)),
+		)
+
+		if out.KeyUsedPercent != nil {
+			sections = append(sections, fmt.Sprintf("%s %s", key.String(i18n.T("Used:")), r.usedPercent(*out.KeyUsedPercent)))
+		}
+
+		sections = append(sections, r.notes("openrouter", "key_limit")...)
+	}
+
+	if out.RateLimitRequests > 0 {
+		sections = append(sections,
+			"",
+			i18n.Sprintf("%s %d requests / %s", key.String(i18n.T("Rate limit:")), out.RateLimitRequests, out.RateLimitInterval),
+		)
+	}
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+func (r *reportRenderer) printCursorReport(out *cursor.Quota) string {
+	key := tinta.Text().Bold()
+	heading := providerHeading("cursor", "Cursor")
+	box := providerBox("cursor")
+
+	requests := formatNumber(float64(out.RequestsUsed))
+	if out.RequestsLimit != nil {
+		requests += " / " + formatNumber(float64(*out.RequestsLimit))
+	} else {
+		requests += " (" + i18n.T("unlimited") + ")"
+	}
+
+	sections := []string{
+		heading,
+		"",
+		fmt.Sprintf("%s %s (%s)", key.String(i18n.T("Account:")), out.AccountEmail, out.AccountType),
+		"",
+		fmt.Sprintf("%s %s", key.String(i18n.T("Premium requests:")), requests),
+	}
+
+	if out.RequestsUsedPercent != nil {
+		sections = append(sections, fmt.Sprintf("%s %s", key.String(i18n.T("Used:")), r.usedPercent(*out.RequestsUsedPercent)))
+	}
+
+	if out.RequestsRemaining != nil {
+		sections = append(sections, fmt.Sprintf("%s %s", key.String(i18n.T("Remaining:")), formatNumber(float64(*out.RequestsRemaining))))
+	}
+
+	if reset := formatReset(out.ResetIn, out.ResetAt); reset != "" {
+		sections = append(sections, fmt.Sprintf("%s %s", key.String(i18n.T("Reset in:")), reset))
+	}
+
+	sections = append(sections, r.notes("cursor", "premium")...)
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+func (r *reportRenderer) printAnthropicAPIReport(out *anthropic.AdminQuota) string {
+	key := tinta.Text().Bold()
+	heading := providerHeading("anthropic-api", "Anthropic API")
+	box := providerBox("anthropic-api")
+
+	sections := []string{
+		heading,
+		"",
+		fmt.Sprintf("%s %s", key.String(i18n.T("Cost this month:")), formatMoney(out.CostUSD)),
+	}
+	sections = append(sections, r.notes("anthropic-api", "cost")...)
+	sections = append(sections,
+		fmt.Sprintf("%s %s", key.String(i18n.T("Tokens this month:")), formatNumber(float64(out.TotalTokens))),
+		i18n.Sprintf(
+			"%s %s in / %s cache read / %s out",
+			key.String(i18n.T("Split:")),
+			formatNumber(float64(out.InputTokens)),
+			formatNumber(float64(out.CacheReadTokens)),
+			formatNumber(float64(out.OutputTokens)),
+		),
+	)
+	sections = append(sections, r.notes("anthropic-api", "tokens")...)
+
+	if reset := formatReset(out.ResetIn, out.ResetAt); reset != "" {
+		sections = append(sections, fmt.Sprintf("%s %s", key.String(i18n.T("Reset in:")), reset))
+	}
+
+	if len(out.Models) > 0 {
+		sections = append(sections, "", key.String(i18n.T("Models")))
+		for _, model := range out.Models {
+			sections = append(sections, fmt.Sprintf("- %s: %s", model.Model, formatNumber(float64(model.InputTokens+model.CacheReadTokens+model.OutputTokens))))
+		}
+	}
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+func (r *reportRenderer) printMistralReport(out *mistral.Quota) string {
+	key := tinta.Text().Bold()
+	heading := providerHeading("mistral", "Mistral")
+	box := providerBox("mistral")
+
+	tokens := formatNumber(float64(out.TotalTokens))
+	if out.TokenLimit != nil {
+		tokens += " / " + formatNumber(float64(*out.TokenLimit))
+	}
+
+	sections := []string{
+		heading,
+		"",
+		fmt.Sprintf("%s %s", key.String(i18n.T("Tokens this month:")), tokens),
+		i18n.Sprintf("%s %s in / %s out", key.String(i18n.T("Split:")), formatNumber(float64(out.InputTokens)), formatNumber(float64(out.OutputTokens))),
+	}
+
+	if out.UsedPercent != nil {
+		sections = append(sections, fmt.Sprintf("%s %s", key.String(i18n.T("Used:")), r.usedPercent(*out.UsedPercent)))
+	}
+
+	if reset := formatReset(out.ResetIn, out.ResetAt); reset != "" {
+		sections = append(sections, fmt.Sprintf("%s %s", key.String(i18n.T("Reset in:")), reset))
+	}
+
+	sections = append(sections, r.notes("mistral", "tokens")...)
+
+	if len(out.Models) > 0 {
+		sections = append(sections, "", key.String(i18n.T("Models")))
+		for _, model := range out.Models {
+			sections = append(sections, fmt.Sprintf("- %s: %s", model.Model, formatNumber(float64(model.InputTokens+model.OutputTokens))))
+		}
+	}
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+func (r *reportRenderer) printDeepSeekReport(out *deepseek.Quota) string {
+	key := tinta.Text().Bold()
+	section := tinta.Text().Bold()
+	heading := providerHeading("deepseek", "DeepSeek")
+	box := providerBox("deepseek")
+
+	status := themeText(activeTheme.low).Bold().String(i18n.T("available"))
+	if !out.IsAvailable {
+		status = themeText(activeTheme.high).Bold().String(i18n.T("insufficient balance"))
+	}
+
+	sections := []string{
+		heading,
+		"",
+		fmt.Sprintf("%s %s", key.String(i18n.T("Status:")), status),
+	}
+
+	for _, balance := range out.Balances {
+		sections = append(sections,
+			"",
+			section.String(i18n.Sprintf("Balance (%s)", balance.Currency)),
+			fmt.Sprintf("%s %s", key.String(i18n.T("Available:")), formatAmount(balance.TotalBalance, balance.Currency)),
+			fmt.Sprintf("%s %s", key.String(i18n.T("Topped up:")), formatAmount(balance.ToppedUpBalance, balance.Currency)),
+			fmt.Sprintf("%s %s", key.String(i18n.T("Granted:")), formatAmount(balance.GrantedBalance, balance.Currency)),
+		)
+	}
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+func (r *reportRenderer) printGroqReport(out *groq.Quota) string {
+	key := tinta.Text().Bold()
+	heading := providerHeading("groq", "Groq")
+	box := providerBox("groq")
+
+	sections := []string{heading}
+	for _, model := range out.Models {
+		sections = append(sections, "", key.String(model.Model))
+		for _, limit := range []struct {
+			id    string
+			label string
+			limit groq.Limit
+		}{
+			{"requests", "Requests/day:", model.RequestsPerDay},
+			{"tokens", "Tokens/min:", model.TokensPerMinute},
+		} {
+			line := fmt.Sprintf(
+				"%s %s / %s (%s)",
+				key.String(i18n.T(limit.label)),
+				formatNumber(float64(limit.limit.Limit-limit.limit.Remaining)),
+				formatNumber(float64(limit.limit.Limit)),
+				colorPercent(limit.limit.UsedPercent),
+			)
+			if reset := formatReset(limit.limit.ResetIn, limit.limit.ResetAt); reset != "" {
+				line += i18n.Sprintf(", resets in %s", reset)
+			}
+
+			sections = append(sections, line)
+			sections = append(sections, r.notes("groq", model.Model+"_"+limit.id)...)
+		}
+	}
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+func (r *reportRenderer) printXAIReport(out *xai.Quota) string {
+	key := tinta.Text().Bold()
+	section := tinta.Text().Bold()
+	heading := providerHeading("xai", "xAI")
+	box := providerBox("xai")
+
+	sections := []string{heading}
+	if out.KeyName != "" {
+		sections = append(sections, fmt.Sprintf("%s %s", key.String(i18n.T("API key:")), out.KeyName))
+	}
+	if out.KeyBlocked {
+		sections = append(sections, themeText(activeTheme.high).Bold().String(i18n.T("API key is blocked or disabled")))
+	}
+
+	switch {
+	case out.BalanceUSD != nil:
+		sections = append(sections,
+			"",
+			section.String(i18n.T("Credit Balance")),
+			fmt.Sprintf("%s %s", key.String(i18n.T("Available:")), formatAmount(*out.BalanceUSD, "USD")),
+		)
+		sections = append(sections, r.notes("xai", "balance")...)
+	case out.BalanceStatus != "":
+		sections = append(sections, "", section.String(i18n.T("Credit Balance")), tinta.Text().Dim().String(out.BalanceStatus))
+	}
+
+	for _, model := range out.Models {
+		sections = append(sections, "", key.String(model.Model))
+		for _, limit := range []struct {
+			id    string
+			label string
+			limit xai.Limit
+		}{
+			{"requests", "Requests/min:", model.RequestsPerMinute},
+			{"tokens", "Tokens/min:", model.TokensPerMinute},
+		} {
+			line := fmt.Sprintf(
+				"%s %s / %s (%s)",
+				key.String(i18n.T(limit.label)),
+				formatNumber(float64(limit.limit.Limit-limit.limit.Remaining)),
+				formatNumber(float64(limit.limit.Limit)),
+				colorPercent(limit.limit.UsedPercent),
+			)
+			if reset := formatReset(limit.limit.ResetIn, limit.limit.ResetAt); reset != "" {
+				line += i18n.Sprintf(", resets in %s", reset)
+			}
+
+			sections = append(sections, line)
+			sections = append(sections, r.notes("xai", model.Model+"_"+limit.id)...)
+		}
+	}
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+func (r *reportRenderer) printTogetherReport(out *together.Quota) string {
+	key := tinta.Text().Bold()
+	section := tinta.Text().Bold()
+	heading := providerHeading("together", "Together AI")
+	box := providerBox("together")
+
+	sections := []string{heading}
+	if out.BalanceUSD != nil {
+		sections = append(sections,
+			"",
+			section.String(i18n.T("Credit Balance")),
+			fmt.Sprintf("%s %s", key.String(i18n.T("Available:")), formatAmount(*out.BalanceUSD, "USD")),
+		)
+		sections = append(sections, r.notes("together", "balance")...)
+	}
+
+	for _, model := range out.Models {
+		sections = append(sections, "", key.String(model.Model))
+		for _, limit := range []struct {
+			id    string
+			label string
+			limit together.Limit
+		}{
+			{"requests", "Requests:", model.Requests},
+			{"tokens", "Tokens:", model.Tokens},
+		} {
+			line := fmt.Sprintf(
+				"%s %s / %s (%s)",
+				key.String(i18n.T(limit.label)),
+				formatNumber(limit.limit.Limit-limit.limit.Remaining),
+				formatNumber(limit.limit.Limit),
+				colorPercent(limit.limit.UsedPercent),
+			)
+			if reset := formatReset(limit.limit.ResetIn, limit.limit.ResetAt); reset != "" {
+				line += i18n.Sprintf(", resets in %s", reset)
+			}
+
+			sections = append(sections, line)
+			sections = append(sections, r.notes("together", model.Model+"_"+limit.id)...)
+		}
+	}
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+func (r *reportRenderer) printFireworksReport(out *fireworks.Quota) string {
+	key := tinta.Text().Bold()
+	section := tinta.Text().Bold()
+	heading := providerHeading("fireworks", "Fireworks AI")
+	box := providerBox("fireworks")
+
+	sections := []string{
+		heading,
+		fmt.Sprintf("%s %s", key.String(i18n.T("Account:")), out.AccountID),
+		"",
+		section.String(i18n.T("Credit Balance")),
+		fmt.Sprintf("%s %s", key.String(i18n.T("Available:")), formatAmount(out.CreditBalance, out.Currency)),
+	}
+	sections = append(sections, r.notes("fireworks", "balance")...)
+
+	spend := []string{
+		section.String(i18n.T("Spend This Period")),
+		fmt.Sprintf("%s %s", key.String(i18n.T("Spent:")), formatAmount(out.PeriodSpend, out.Currency)),
+	}
+	if reset := formatReset(out.ResetIn, out.ResetAt); reset != "" {
+		spend = append(spend, fmt.Sprintf("%s %s", key.String(i18n.T("Reset in:")), reset))
+	}
+	spend = append(spend, r.notes("fireworks", "spend")...)
+	sections = append(sections, "", strings.Join(spend, "\n"))
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+func (r *reportRenderer) printCohereReport(out *cohere.Quota) string {
+	key := tinta.Text().Bold()
+	section := tinta.Text().Bold()
+	heading := providerHeading("cohere", "Cohere")
+	box := providerBox("cohere")
+
+	keyType := i18n.T("Production")
+	if out.KeyType == cohere.KeyTrial {
+		keyType = i18n.T("Trial")
+	}
+
+	sections := []string{heading, fmt.Sprintf("%s %s", key.String(i18n.T("Key:")), keyType)}
+	if out.KeyType == cohere.KeyProduction {
+		sections = append(sections, tinta.Text().Dim().String(i18n.T("Production keys are billed per use and have no call limit")))
+	}
+
+	for _, limit := range []struct {
+		id    string
+		name  string
+		limit *cohere.CallLimit
+	}{
+		{"monthly_calls", "Trial Calls This Month", out.Monthly},
+		{"minute_calls", "Trial Calls Per Minute", out.PerMinute},
+	} {
+		if limit.limit == nil {
+			continue
+		}
+
+		lines := []string{section.String(i18n.T(limit.name))}
+		if limit.limit.Remaining != nil {
+			lines = append(lines, fmt.Sprintf(
+				"%s %s / %s (%s)",
+				key.String(i18n.T("Used:")),
+				formatNumber(float64(limit.limit.Limit-*limit.limit.Remaining)),
+				formatNumber(float64(limit.limit.Limit)),
+				colorPercent(*limit.limit.UsedPercent),
+			))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s %s", key.String(i18n.T("Limit:")), formatNumber(float64(limit.limit.Limit))))
+		}
+
+		if reset := formatReset(limit.limit.ResetIn, limit.limit.ResetAt); reset != "" {
+			lines = append(lines, fmt.Sprintf("%s %s", key.String(i18n.T("Reset in:")), reset))
+		}
+
+		lines = append(lines, r.notes("cohere", limit.id)...)
+		sections = append(sections, "", strings.Join(lines, "\n"))
+	}
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+func (r *reportRenderer) printPerplexityReport(out *perplexity.Quota) string {
+	key := tinta.Text().Bold()
+	section := tinta.Text().Bold()
+	heading := providerHeading("perplexity", "Perplexity")
+	box := providerBox("perplexity")
+
+	sections := []string{heading}
+	if out.CreditsUSD != nil {
+		sections = append(sections,
+			"",
+			section.String(i18n.T("Credits")),
+			fmt.Sprintf("%s %s", key.String(i18n.T("Remaining:")), formatAmount(*out.CreditsUSD, "USD")),
+		)
+		sections = append(sections, r.notes("perplexity", "credits")...)
+	}
+
+	for _, model := range out.Models {
+		sections = append(sections, "", key.String(model.Model))
+		for _, limit := range []struct {
+			id    string
+			label string
+			limit perplexity.Limit
+		}{
+			{"requests", "Requests/min:", model.RequestsPerMinute},
+			{"tokens", "Tokens/min:", model.TokensPerMinute},
+		} {
+			line := fmt.Sprintf(
+				"%s %s / %s (%s)",
+				key.String(i18n.T(limit.label)),
+				formatNumber(float64(limit.limit.Limit-limit.limit.Remaining)),
+				formatNumber(float64(limit.limit.Limit)),
+				colorPercent(limit.limit.UsedPercent),
+			)
+			if reset := formatReset(limit.limit.ResetIn, limit.limit.ResetAt); reset != "" {
+				line += i18n.Sprintf(", resets in %s", reset)
+			}
+
+			sections = append(sections, line)
+			sections = append(sections, r.notes("perplexity", model.Model+"_"+limit.id)...)
+		}
+	}
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+func (r *reportRenderer) printMoonshotReport(out *moonshot.Quota) string {
+	key := tinta.Text().Bold()
+	section := tinta.Text().Bold()
+	heading := providerHeading("moonshot", "Moonshot (Kimi)")
+	box := providerBox("moonshot")
+
+	sections := []string{
+		heading,
+		"",
+		section.String(i18n.Sprintf("Balance (%s)", out.Currency)),
+		fmt.Sprintf("%s %s", key.String(i18n.T("Available:")), formatAmount(out.AvailableBalance, out.Currency)),
+		fmt.Sprintf("%s %s", key.String(i18n.T("Voucher:")), formatAmount(out.VoucherBalance, out.Currency)),
+		fmt.Sprintf("%s %s", key.String(i18n.T("Cash:")), formatAmount(out.CashBalance, out.Currency)),
+	}
+	sections = append(sections, r.notes("moonshot", "balance")...)
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+func (r *reportRenderer) printOpenAIReport(out *openai.Quota) string {
+	key := tinta.Text().Bold()
+	heading := providerHeading("openai", "OpenAI API")
+	box := providerBox("openai")
+
+	cost := formatMoney(out.CostUSD)
+	if out.BudgetUSD != nil {
+		cost += " / " + formatMoney(*out.BudgetUSD)
+	}
+
+	sections := []string{heading, ""}
+	if out.Organization != "" {
+		sections = append(sections, fmt.Sprintf("%s %s", key.String(i18n.T("Organization:")), out.Organization))
+	}
+	sections = append(sections, fmt.Sprintf("%s %s", key.String(i18n.T("Cost this month:")), cost))
+
+	if out.UsedPercent != nil {
+		sections = append(sections, fmt.Sprintf("%s %s", key.String(i18n.T("Used:")), r.usedPercent(*out.UsedPercent)))
+	}
+
+	if reset := formatReset(out.ResetIn, out.ResetAt); reset != "" {
+		sections = append(sections, fmt.Sprintf("%s %s", key.String(i18n.T("Reset in:")), reset))
+	}
+
+	sections = append(sections, r.notes("openai", "cost")...)
+	sections = append(sections, i18n.Sprintf(
+		"%s %s in (%s cached) / %s out",
+		key.String(i18n.T("Tokens:")),
+		formatNumber(float64(out.InputTokens)),
+		formatNumber(float64(out.CachedInputTokens)),
+		formatNumber(float64(out.OutputTokens)),
+	))
+
+	if len(out.Models) > 0 {
+		sections = append(sections, "", key.String(i18n.T("Models")))
+		for _, model := range out.Models {
+			sections = append(sections, fmt.Sprintf("- %s: %s", model.Model, formatNumber(float64(model.InputTokens+model.OutputTokens))))
+		}
+	}
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+func (r *reportRenderer) printAzureReport(out *azure.Quota) string {
+	key := tinta.Text().Bold()
+	heading := providerHeading("azure", "Azure OpenAI")
+	box := providerBox("azure")
+
+	sections := []string{
+		heading,
+		"",
+		fmt.Sprintf("%s %s (%s)", key.String(i18n.T("Subscription:")), out.SubscriptionID, out.Location),
+	}
+
+	windows := out.Windows()
+	for i, usage := range out.Usages {
+		allocated := formatNumber(usage.Current) + " / " + formatNumber(usage.Limit)
+		if usage.Unit != "" && usage.Unit != "Count" {
+			allocated += " " + usage.Unit
+		}
+
+		sections = append(sections,
+			"",
+			key.String(i18n.T(windows[i].Name)),
+			fmt.Sprintf("%s %s", key.String(i18n.T("Allocated:")), allocated),
+			fmt.Sprintf("%s %s", key.String(i18n.T("Used:")), r.usedPercent(*windows[i].UsedPercent)),
+		)
+		sections = append(sections, r.notes("azure", windows[i].ID)...)
+	}
+
+	if len(out.Deployments) > 0 {
+		sections = append(sections, "", key.String(i18n.T("Deployments")))
+		for _, deployment := range out.Deployments {
+			limits := []string{}
+			if deployment.TokensPerMinute != nil {
+				limits = append(limits, formatNumber(float64(*deployment.TokensPerMinute))+" TPM")
+			}
+			if deployment.RequestsPerMinute != nil {
+				limits = append(limits, formatNumber(float64(*deployment.RequestsPerMinute))+" RPM")
+			}
+
+			line := fmt.Sprintf("- %s (%s, %s)", deployment.Name, deployment.Model, deployment.SKU)
+			if len(limits) > 0 {
+				line += ": " + strings.Join(limits, ", ")
+			}
+			sections = append(sections, line)
+		}
+	}
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+func (r *reportRenderer) printBedrockReport(out *bedrock.Quota) string {
+	key := tinta.Text().Bold()
+	heading := providerHeading("bedrock", "AWS Bedrock")
+	box := providerBox("bedrock")
+
+	sections := []string{heading}
+	for i, window := range out.Windows() {
+		model := out.Models[i]
+		peak := formatNumber(model.PeakRequestsPerMinute)
+		if model.RequestsPerMinuteLimit != nil {
+			peak += " / " + formatNumber(*model.RequestsPerMinuteLimit)
+		}
+
+		sections = append(sections,
+			"",
+			key.String(i18n.T(window.Name)),
+			i18n.Sprintf("%s %s requests per minute", key.String(i18n.T("Peak (15m):")), peak),
+		)
+
+		if model.UsedPercent != nil {
+			sections = append(sections, fmt.Sprintf("%s %s", key.String(i18n.T("Used:")), r.usedPercent(*model.UsedPercent)))
+		} else {
+			sections = append(sections, tinta.Text().Dim().String(i18n.T("No on-demand quota found for this model")))
+		}
+		sections = append(sections, r.notes("bedrock", window.ID)...)
+	}
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+func (r *reportRenderer) printGitHubModelsReport(out *githubmodels.Quota) string {
+	key := tinta.Text().Bold()
+	heading := providerHeading("github-models", "GitHub Models")
+	box := providerBox("github-models")
+
+	sections := []string{heading}
+	for _, model := range out.Models {
+		sections = append(sections, "", key.String(i18n.Sprintf("%s (%s tier)", model.Model, model.Tier)))
+		for _, limit := range []struct {
+			id    string
+			label string
+			limit githubmodels.Limit
+		}{
+			{"requests", "Requests:", model.Requests},
+			{"tokens", "Tokens:", model.Tokens},
+		} {
+			line := fmt.Sprintf(
+				"%s %s / %s (%s)",
+				key.String(i18n.T(limit.label)),
+				formatNumber(float64(limit.limit.Limit-limit.limit.Remaining)),
+				formatNumber(float64(limit.limit.Limit)),
+				colorPercent(limit.limit.UsedPercent),
+			)
+			if reset := formatReset(limit.limit.ResetIn, limit.limit.ResetAt); reset != "" {
+				line += i18n.Sprintf(", resets in %s", reset)
+			}
+
+			sections = append(sections, line)
+			sections = append(sections, r.notes("github-models", model.Model+"_"+limit.id)...)
+		}
+	}
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+func (r *reportRenderer) printGenericReport(p provider.Provider, quota provider.Quota) string {
+	key := tinta.Text().Bold()
+	section := tinta.Text().Bold()
+	heading := providerHeading(p.ID(), p.Name())
+	box := providerBox(p.ID())
+
+	sections := []string{heading}
+	for _, window := range quota.Windows() {
+		lines := []string{section.String(i18n.T(window.Name))}
+		if window.UsedPercent != nil {
+			lines = append(lines, fmt.Sprintf("%s %s", key.String(i18n.T("Used:")), r.usedPercent(*window.UsedPercent)))
+		} else if window.Used != nil {
+			lines = append(lines, fmt.Sprintf("%s %s", key.String(i18n.T("Used:")), formatNumber(*window.Used)))
+		} else if window.Remaining != nil {
+			lines = append(lines, fmt.Sprintf("%s %s", key.String(i18n.T("Remaining:")), formatNumber(*window.Remaining)))
+		}
+
+		if reset := formatReset(helpers.FormatTimeUntil(window.ResetAt), window.ResetAt); reset != "" {
+			lines = append(lines, fmt.Sprintf("%s %s", key.String(i18n.T("Reset in:")), reset))
+		}
+
+		lines = append(lines, r.notes(p.ID(), window.ID)...)
+
+		sections = append(sections, "", strings.Join(lines, "\n"))
+	}
+
+	return r.box(box, strings.Join(sections, "\n"))
+}
+
+// box draws content in a provider box, or in plain mode keeps the heading on
+// its own line and indents the rest.
+func (r *reportRenderer) box(box *tinta.BoxStyle, content string) string {
+	heading, rest, _ := strings.Cut(content, "\n")
+	if r.label != "" {
+		heading += " " + tinta.Text().Dim().String("· "+r.label)
+	}
+	if r.cost != nil || len(r.budgetLines) > 0 {
+		rest += "\n"
+	}
+	if r.cost != nil {
+		rest += "\n" + formatCost(*r.cost)
+	}
+	for _, line := range r.budgetLines {
+		rest += "\n" + line
+	}
+
+	if !r.plain {
+		return box.String(heading + "\n" + rest)
+	}
+
+	return heading + "\n" + indent(rest, "  ") + "\n"
+}
+
+// reportGroup is a run of providers drawn under one group heading.
+type reportGroup struct {
+	name    string
+	results []provider.Result
+}
+
+// group splits the results by their configured group, in the order each
+// group first appears. Without groups it returns a single unnamed group;
+// with groups, providers that have none come last under "other".
+func (r *reportRenderer) group(results []provider.Result) []reportGroup {
+	if r.groups == nil || (len(r.groups.values) == 0 && r.groups.fallback == nil) {
+		return []reportGroup{{results: results}}
+	}
+
+	var groups []reportGroup
+	index := map[string]int{}
+	for _, result := range results {
+		name, ok := r.groups.get(result.Provider.ID())
+		if !ok {
+			name = "other"
+		}
+
+		i, seen := index[name]
+		if !seen {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, reportGroup{name: name})
+		}
+		groups[i].results = append(groups[i].results, result)
+	}
+
+	if i, ok := index["other"]; ok && i != len(groups)-1 {
+		other := groups[i]
+		groups = append(slices.Delete(groups, i, i+1), other)
+	}
+
+	return groups
+}
+
+// printGroupHeading draws the group name with a subtotal: how many of its
+// providers answered and the most used window among them.
+func (r *reportRenderer) printGroupHeading(group reportGroup) string {
+	var answered, failed int
+	var highest *float64
+	var highestName string
+	for _, result := range group.results {
+		if result.Err != nil {
+			failed++
+			continue
+		}
+
+		answered++
+		for _, window := range result.Quota.Windows() {
+			if window.UsedPercent != nil && (highest == nil || *window.UsedPercent > *highest) {
+				highest = window.UsedPercent
+				highestName = result.Provider.Name() + " " + window.Name
+			}
+		}
+	}
+
+	summary := []string{fmt.Sprintf("%d %s", answered, i18n.Plural(answered, "provider", "providers"))}
+	if failed > 0 {
+		summary = append(summary, i18n.Sprintf("%d failed", failed))
+	}
+	if highest != nil {
+		summary = append(summary, i18n.Sprintf("highest %s%% (%s)", formatPercent(*highest), highestName))
+	}
+
+	name := group.name
+	if name == "other" {
+		name = i18n.T(name)
+	}
+
+	heading := themeText(activeTheme.group).Bold().String(strings.ToUpper(name))
+	return heading + "  " + tinta.Text().Dim().String(strings.Join(summary, " · ")) + "\n"
+}
+
+func (r *reportRenderer) printWarnings(warnings []string) string {
+	title := themeText(activeTheme.high).Bold().String(i18n.T("Warnings"))
+	body := []string{title, themeText(activeTheme.high).String(i18n.T("Some providers could not be queried:"))}
+	for _, warning := range warnings {
+		body = append(body, themeText(activeTheme.medium).Sprintf("- %s", warning))
+	}
+
+	box := activeTheme.high.box(tinta.Box().Border(boxBorder(tinta.BorderSimple))).PaddingX(2).PaddingY(1)
+	return r.box(box, strings.Join(body, "\n"))
+}
+
+func (r *reportRenderer) formatCodexWindow(
+	id string,
+	name string,
+	window codex.RateLimitWindow,
+	key *tinta.TextStyle,
+	section *tinta.TextStyle,
+) string {
+	lines := r.formatRateLimitWindow(name, window.UsedPercent, window.ResetIn, window.ResetAt, key, section)
+	return strings.Join(append([]string{lines}, r.notes("codex", id)...), "\n")
+}
+
+func (r *reportRenderer) formatAnthropicWindow(
+	id string,
+	name string,
+	window anthropic.RateLimitWindow,
+	key *tinta.TextStyle,
+	section *tinta.TextStyle,
+) string {
+	lines := r.formatRateLimitWindow(name, window.UsedPercent, window.ResetIn, window.ResetAt, key, section)
+	return strings.Join(append([]string{lines}, r.notes("anthropic", id)...), "\n")
+}
+
+func (r *reportRenderer) formatRateLimitWindow(
+	name string,
+	usedPercent *float64,
+	resetIn *string,
+	resetAt *string,
+	key *tinta.TextStyle,
+	section *tinta.TextStyle,
+) string {
+	lines := []string{section.String(i18n.T(name))}
+
+	if usedPercent == nil || resetAt == nil || resetIn == nil {
+		lines = append(lines,
+			fmt.Sprintf("%s %s", key.String(i18n.T("Usage:")), i18n.T("unavailable")),
+			fmt.Sprintf("%s %s", key.String(i18n.T("Reset in:")), i18n.T("unavailable")),
+		)
+
+		return strings.Join(lines, "\n")
+	}
+
+	lines = append(lines, fmt.Sprintf("%s %s", key.String(i18n.T("Used:")), r.usedPercent(*usedPercent)))
+	if reset := formatReset(*resetIn, *resetAt); reset != "" {
+		lines = append(lines, fmt.Sprintf("%s %s", key.String(i18n.T("Reset in:")), reset))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func formatResetAt(value string) string {
+	if value == "" || value == "unknown" {
+		return "unknown"
+	}
+
+	timeValue, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+
+	return formatTime(timeValue)
+}
+
+func formatReset(resetIn string, resetAt string) string {
+	formattedResetAt := formatResetAt(resetAt)
+	trimmedResetIn := strings.TrimSpace(resetIn)
+
+	if trimmedResetIn == "" || strings.EqualFold(trimmedResetIn, "unknown") {
+		if formattedResetAt == "unknown" {
+			return ""
+		}
+
+		return formattedResetAt
+	}
+
+	// Durations read the same in every language, only "now" is translated.
+	trimmedResetIn = i18n.T(trimmedResetIn)
+	if formattedResetAt == "unknown" {
+		return trimmedResetIn
+	}
+
+	return fmt.Sprintf("%s - %s", trimmedResetIn, formattedResetAt)
+}
+
+// formatTokenExpiry is the "Token:" line of providers whose token expires,
+// such as "Token: expires in 23m", highlighted within the last hour. It is
+// empty when the expiry is unknown.
+func formatTokenExpiry(expiresAt string, key *tinta.TextStyle) string {
+	expiry, err := time.Parse(time.RFC3339, expiresAt)
+	if err != nil {
+		return ""
+	}
+
+	left := time.Until(expiry)
+	status := i18n.Sprintf("expires in %s", helpers.FormatDuration(left))
+	switch {
+	case left <= 0:
+		status = themeText(activeTheme.high).Bold().String(i18n.Sprintf("expired %s ago", helpers.FormatDuration(-left)))
+	case left < time.Hour:
+		status = themeText(activeTheme.medium).Bold().String(status)
+	}
+
+	return fmt.Sprintf("%s %s", key.String(i18n.T("Token:")), status)
+}
+
+func formatPercent(value float64) string {
+	return helpers.FormatFloat(value)
+}
+
+func formatNumber(value float64) string {
+	if value == float64(int64(value)) {
+		return strconv.FormatInt(int64(value), 10)
+	}
+
+	return formatPercent(value)
+}
+
+func formatMoney(value float64) string {
+	return "$" + strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+// formatAmount prints a balance with its ISO currency code.
+func formatAmount(value float64, currency string) string {
+	return strconv.FormatFloat(value, 'f', 2, 64) + " " + currency
+}
+
+// usedPercent draws a used percent as a bar followed by the percent, such as
+// "█████░░░░░ 52%", or only the percent in plain mode.
+func (r *reportRenderer) usedPercent(value float64) string {
+	if r.plain || r.barWidth <= 0 {
+		return colorPercent(value)
+	}
+
+	filled := int(math.Round(helpers.ClampPercent(value) / 100 * float64(r.barWidth)))
+	bar := themeText(activeTheme.severity(value)).String(strings.Repeat("█", filled)) +
+		tinta.Text().Dim().String(strings.Repeat("░", r.barWidth-filled))
+
+	return bar + " " + colorPercent(value)
+}
+
+func colorPercent(value float64) string {
+	percent := formatPercent(value) + "%"
+
+	return themeText(activeTheme.severity(value)).Bold().String(percent)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/cohere"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/deepseek"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/moonshot"
+	"github.com/eduardolat/aiquota/pkg/openrouter"
+	"github.com/eduardolat/aiquota/pkg/provider"
+	"github.com/varavelio/tinta"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// cassetteResults fetches the providers with recorded responses in
+// testdata/cassettes. With AIQUOTA_RECORD set it queries them with the local
+// credentials instead and records new cassettes; the golden files then need
+// -update. The providers were picked because their reports do not depend on
+// the current time.
+func cassetteResults(t *testing.T) []provider.Result {
+	t.Helper()
+
+	cassette := func(id string) httpclient.Doer {
+		cassette, err := httpclient.NewCassette(filepath.Join("testdata", "cassettes"), id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cassette
+	}
+
+	creds := credentials.Placeholder()
+	if os.Getenv(httpclient.RecordEnv) != "" {
+		var err error
+		if creds, err = credentials.GetCredentials(""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	providers := []provider.Provider{
+		deepseek.Provider{HTTPClient: cassette("deepseek")},
+		moonshot.Provider{HTTPClient: cassette("moonshot")},
+		openrouter.Provider{HTTPClient: cassette("openrouter")},
+		cohere.Provider{HTTPClient: cassette("cohere")},
+	}
+
+	results := provider.FetchAll(context.Background(), creds, providers, provider.FetchOptions{})
+	for _, result := range results {
+		if result.Err != nil {
+			t.Fatalf("%s: %v", result.Provider.Name(), result.Err)
+		}
+	}
+
+	return results
+}
+
+// isolateReport keeps the environment from changing the report: colors, the
+// terminal width and annotations from the usage history. stdout reports
+// whether stdout counts as a terminal, which draws the boxes.
+func isolateReport(t *testing.T, stdout bool) {
+	t.Helper()
+
+	clearColorEnv(t)
+	stubTerminals(t, stdout, false)
+	original := terminalWidth
+	terminalWidth = func(*os.File) int { return 0 }
+	t.Cleanup(func() { terminalWidth = original })
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	tinta.ForceColors(false)
+}
+
+// assertGolden compares got with testdata/golden/name, or rewrites the file
+// with -update.
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", "golden", name)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from the golden file, rerun with -update if the change is intended\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+func TestTextReportGolden(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		stdout bool
+	}{
+		{"report.txt", true},
+		{"report_plain.txt", false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			isolateReport(t, test.stdout)
+
+			var buf bytes.Buffer
+			if err := printReport(&buf, formatText, cassetteResults(t), &outputFlags{noDiff: true}, nil, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			assertGolden(t, test.name, buf.Bytes())
+		})
+	}
+}
+
+func TestJSONReportGolden(t *testing.T) {
+	isolateReport(t, false)
+
+	output := &outputFlags{fetchedAt: time.Date(2026, time.October, 14, 9, 44, 22, 717_000_000, time.UTC)}
+	var buf bytes.Buffer
+	if err := printReport(&buf, formatJSON, cassetteResults(t), output, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	assertGolden(t, "report.json", buf.Bytes())
+}
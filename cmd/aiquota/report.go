@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/BurntSushi/toml"
+	"github.com/eduardolat/aiquota/internal/influx"
+	"github.com/eduardolat/aiquota/pkg/aiquota"
+	"github.com/eduardolat/aiquota/pkg/provider"
+	"gopkg.in/yaml.v3"
+)
+
+// timestampLayout is the layout of every timestamp aiquota prints.
+const timestampLayout = aiquota.TimestampLayout
+
+// reportFormat is the value of --format.
+type reportFormat string
+
+const (
+	formatText     reportFormat = "text"
+	formatJSON     reportFormat = "json"
+	formatYAML     reportFormat = "yaml"
+	formatTOML     reportFormat = "toml"
+	formatTemplate reportFormat = "template"
+	formatWaybar   reportFormat = "waybar"
+	formatI3blocks reportFormat = "i3blocks"
+	formatStarship reportFormat = "starship"
+	formatMarkdown reportFormat = "markdown"
+	formatHTML     reportFormat = "html"
+	formatInflux   reportFormat = "influx"
+)
+
+var reportFormats = []string{
+	string(formatText),
+	string(formatJSON),
+	string(formatYAML),
+	string(formatTOML),
+	string(formatTemplate),
+	string(formatWaybar),
+	string(formatI3blocks),
+	string(formatStarship),
+	string(formatMarkdown),
+	string(formatHTML),
+	string(formatInflux),
+}
+
+func (f *reportFormat) String() string {
+	if f == nil {
+		return ""
+	}
+
+	return string(*f)
+}
+
+func (f *reportFormat) Set(value string) error {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if !slices.Contains(reportFormats, value) {
+		return fmt.Errorf("invalid format %q, expected one of %s", value, strings.Join(reportFormats, ", "))
+	}
+
+	*f = reportFormat(value)
+	return nil
+}
+
+// writeReport prints the report to stdout, or to path when --out is given.
+// The file is replaced atomically, so a web server publishing it never
+// serves a half-written report.
+func writeReport(path string, format reportFormat, results []provider.Result, output *outputFlags, tmpl *template.Template, fields reportFields) error {
+	if path == "" {
+		return printReport(os.Stdout, format, results, output, tmpl, fields)
+	}
+
+	var buf bytes.Buffer
+	if err := printReport(&buf, format, results, output, tmpl, fields); err != nil {
+		return err
+	}
+
+	// Reports are meant to be published.
+	return writeFileAtomic(path, buf.Bytes(), 0o644)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it over path, with permissions perm.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := file.Chmod(perm); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// printReport writes the results to w in the given format. tmpl is only
+// used by formatTemplate, and fields by the JSON, YAML and TOML formats.
+func printReport(w io.Writer, format reportFormat, results []provider.Result, output *outputFlags, tmpl *template.Template, fields reportFields) error {
+	at := output.timestamp()
+	switch format {
+	case formatJSON, formatYAML, formatTOML:
+		report := aiquota.NewReport(at, results)
+		var document any = report
+		if len(fields) > 0 {
+			var err error
+			if document, err = selectFields(report, fields); err != nil {
+				return err
+			}
+		}
+
+		switch format {
+		case formatJSON:
+			return printJSON(w, document)
+		case formatYAML:
+			return printYAML(w, document)
+		default:
+			return printTOML(w, document)
+		}
+	case formatTemplate:
+		return printTemplate(w, tmpl, aiquota.NewReport(at, results))
+	case formatWaybar:
+		return printWaybar(w, results)
+	case formatI3blocks:
+		printI3blocks(w, results)
+		return nil
+	case formatStarship:
+		printStarship(w, results)
+		return nil
+	case formatMarkdown:
+		fmt.Fprint(w, markdownReport(at, results))
+		return nil
+	case formatHTML:
+		return printHTML(w, at, results)
+	case formatInflux:
+		return influx.Write(w, at, results)
+	default:
+		fmt.Fprintln(w, output.render(results))
+		if !output.compact {
+			fmt.Fprintln(w)
+		}
+		return nil
+	}
+}
+
+func printJSON(w io.Writer, report any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode JSON report: %w", err)
+	}
+
+	return nil
+}
+
+func printYAML(w io.Writer, report any) error {
+	document, err := reportDocument(report)
+	if err != nil {
+		return err
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return fmt.Errorf("failed to encode YAML report: %w", err)
+	}
+
+	return encoder.Close()
+}
+
+func printTOML(w io.Writer, report any) error {
+	document, err := reportDocument(report)
+	if err != nil {
+		return err
+	}
+
+	if err := toml.NewEncoder(w).Encode(document); err != nil {
+		return fmt.Errorf("failed to encode TOML report: %w", err)
+	}
+
+	return nil
+}
+
+// reportDocument converts the report to plain maps and slices through its
+// JSON form, so YAML and TOML use the same keys as JSON without a second
+// set of struct tags. Nulls are dropped because TOML has no null.
+func reportDocument(report any) (any, error) {
+	raw, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	var document any
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return nil, fmt.Errorf("failed to decode report: %w", err)
+	}
+
+	return normalizeDocument(document), nil
+}
+
+// normalizeDocument drops null map values and turns whole numbers, which
+// JSON decodes as floats, back into integers.
+func normalizeDocument(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, item := range value {
+			if item == nil {
+				delete(value, key)
+				continue
+			}
+
+			value[key] = normalizeDocument(item)
+		}
+	case []any:
+		for i, item := range value {
+			value[i] = normalizeDocument(item)
+		}
+	case float64:
+		if value == math.Trunc(value) && math.Abs(value) < 1<<53 {
+			return int64(value)
+		}
+	}
+
+	return value
+}
+
+// parseReportTemplate parses the --template text for --format template.
+func parseReportTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, fmt.Errorf("--format template requires --template")
+	}
+
+	tmpl, err := template.New("report").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// printTemplate executes tmpl over the report. Besides Timestamp, Warnings
+// and Providers keyed by ID, every provider that returned data is available
+// under its templateKey, so {{.Copilot.RequestsRemaining}} reads the Copilot
+// quota and {{.CopilotOrg}} the copilot-org one. Nothing is added to the
+// output, so one-liners for prompts stay on one line.
+func printTemplate(w io.Writer, tmpl *template.Template, report aiquota.Report) error {
+	data := map[string]any{
+		"Timestamp": report.Timestamp,
+		"Warnings":  report.Warnings,
+		"Providers": report.Providers,
+	}
+	for id, quota := range report.Providers {
+		data[templateKey(id)] = quota
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to execute --template: %w", err)
+	}
+
+	return nil
+}
+
+// templateKey turns a provider ID into an identifier the template dot
+// syntax can reach, capitalizing each dash-separated word: github-models
+// becomes GithubModels.
+func templateKey(id string) string {
+	var key strings.Builder
+	for word := range strings.SplitSeq(id, "-") {
+		if word != "" {
+			key.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+
+	return key.String()
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/aiquota"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// stubProvider and stubQuota stand in for real providers in report tests.
+type stubProvider struct{ id, name string }
+
+func (p stubProvider) ID() string                         { return p.id }
+func (p stubProvider) Name() string                       { return p.name }
+func (stubProvider) Enabled(credentials.Credentials) bool { return true }
+
+func (stubProvider) Fetch(context.Context, credentials.Credentials) (provider.Quota, error) {
+	return nil, nil
+}
+
+type stubQuota struct {
+	List []provider.Window `json:"windows"`
+}
+
+func (q stubQuota) Windows() []provider.Window { return q.List }
+
+func stubResults() []provider.Result {
+	return []provider.Result{
+		{Provider: stubProvider{"alpha", "Alpha"}, Quota: stubQuota{[]provider.Window{
+			{ID: "daily", Name: "Daily", UsedPercent: new(40.0), ResetAt: "2026-10-15T00:00:00Z"},
+			{ID: "weekly", Name: "Weekly", UsedPercent: new(75.5), ResetAt: "2026-10-19T00:00:00Z"},
+		}}},
+		{Provider: stubProvider{"beta", "Beta"}, Quota: stubQuota{[]provider.Window{
+			{ID: "monthly", Name: "Monthly", UsedPercent: new(10.0), ResetAt: "2026-11-01T00:00:00Z"},
+		}}},
+	}
+}
+
+var timestampPattern = regexp.MustCompile(`^\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{3}Z$`)
+
+func TestRunSharesOneTimestamp(t *testing.T) {
+	at := time.Date(2026, time.October, 14, 9, 44, 22, 717_000_000, time.UTC)
+	logPath := filepath.Join(t.TempDir(), "quota.csv")
+
+	fetch := &fetchFlags{noHistory: true, logCSV: logPath, fetchedAt: at}
+	fetch.record(stubResults())
+
+	output := &outputFlags{fetchedAt: at}
+	var buf bytes.Buffer
+	if err := printReport(&buf, formatJSON, stubResults(), output, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var report struct {
+		Timestamp string `json:"timestamp"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if !timestampPattern.MatchString(report.Timestamp) {
+		t.Errorf("report timestamp %q is not RFC3339 UTC with milliseconds", report.Timestamp)
+	}
+
+	file, err := os.Open(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d CSV rows, want a header and one per provider", len(rows))
+	}
+	for _, row := range rows[1:] {
+		if row[0] != report.Timestamp {
+			t.Errorf("%s window %s logged at %q, report at %q", row[1], row[2], row[0], report.Timestamp)
+		}
+	}
+}
+
+func TestPrintTemplateKeysDashedIDs(t *testing.T) {
+	tmpl, err := parseReportTemplate("{{.Copilot.Login}} {{.CopilotOrg.Seats}} {{.GithubModels.Models}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := aiquota.Report{Providers: map[string]any{
+		"copilot":       map[string]any{"Login": "octocat"},
+		"copilot-org":   map[string]any{"Seats": 12},
+		"github-models": map[string]any{"Models": 3},
+	}}
+
+	var out bytes.Buffer
+	if err := printTemplate(&out, tmpl, report); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "octocat 12 3"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
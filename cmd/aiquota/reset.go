@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/internal/i18n"
+	"github.com/eduardolat/aiquota/pkg/provider"
+	"github.com/varavelio/tinta"
+)
+
+// upcomingReset is a moment when one or more windows of a provider reset.
+type upcomingReset struct {
+	provider provider.Provider
+	at       time.Time
+	windows  []provider.Window
+}
+
+// upcomingResets returns the future resets of the successful results, the
+// soonest first. Windows of a provider resetting at the same second share
+// one entry.
+func upcomingResets(results []provider.Result, now time.Time) []upcomingReset {
+	var resets []upcomingReset
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+
+		byTime := map[int64]int{}
+		for _, window := range result.Quota.Windows() {
+			at, err := time.Parse(time.RFC3339, window.ResetAt)
+			if err != nil || !at.After(now) {
+				continue
+			}
+
+			if i, ok := byTime[at.Unix()]; ok {
+				resets[i].windows = append(resets[i].windows, window)
+				continue
+			}
+
+			byTime[at.Unix()] = len(resets)
+			resets = append(resets, upcomingReset{provider: result.Provider, at: at, windows: []provider.Window{window}})
+		}
+	}
+
+	slices.SortStableFunc(resets, func(a, b upcomingReset) int { return a.at.Compare(b.at) })
+	return resets
+}
+
+func (r upcomingReset) windowNames() string {
+	names := make([]string, 0, len(r.windows))
+	for _, window := range r.windows {
+		names = append(names, window.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// runReset lists the upcoming quota resets, or writes them as calendar
+// events with --ics.
+func runReset(parent context.Context, args []string) error {
+	flags := flag.NewFlagSet("aiquota reset", flag.ContinueOnError)
+	icsPath := flags.String("ics", "", "write the upcoming resets as calendar events to this .ics file, or - for stdout")
+	remind := flags.Duration("remind", 15*time.Minute, "add a reminder this long before every reset in --ics events (0 disables it)")
+	fetch := addFetchFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *remind < 0 {
+		return fmt.Errorf("remind must not be negative")
+	}
+
+	creds, err := fetch.credentials()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := fetch.context(parent)
+	defer cancel()
+
+	results, err := fetchQuotas(ctx, creds, fetch)
+	if parent.Err() != nil {
+		return errInterrupted
+	}
+	if err != nil {
+		return err
+	}
+
+	fetch.record(results)
+
+	now := time.Now()
+	resets := upcomingResets(results, now)
+
+	switch *icsPath {
+	case "":
+		printResets(os.Stdout, now, resets)
+		return nil
+	case "-":
+		_, err := io.WriteString(os.Stdout, resetCalendar(now, resets, *remind))
+		return err
+	default:
+		return writeFileAtomic(*icsPath, []byte(resetCalendar(now, resets, *remind)), 0o644)
+	}
+}
+
+func printResets(w io.Writer, now time.Time, resets []upcomingReset) {
+	if len(resets) == 0 {
+		fmt.Fprintln(w, i18n.T("No upcoming resets reported."))
+		return
+	}
+
+	key := tinta.Text().Bold()
+	for _, reset := range resets {
+		fmt.Fprintf(
+			w, "%s  %s  %s %s\n",
+			formatTime(reset.at),
+			tinta.Text().Dim().String(i18n.Sprintf("in %-10s", helpers.FormatDuration(reset.at.Sub(now)))),
+			key.String(reset.provider.Name()+":"),
+			reset.windowNames(),
+		)
+	}
+}
+
+// icsLayout is the UTC date-time form of iCalendar.
+const icsLayout = "20060102T150405Z"
+
+// icsEscaper escapes TEXT values as RFC 5545 requires.
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// resetCalendar renders the resets as an iCalendar file with one event per
+// reset. UIDs derive from the provider and reset time, so importing a newer
+// file updates events instead of duplicating them.
+func resetCalendar(now time.Time, resets []upcomingReset, remind time.Duration) string {
+	var lines []string
+	add := func(line string) { lines = append(lines, foldICSLine(line)) }
+
+	add("BEGIN:VCALENDAR")
+	add("VERSION:2.0")
+	add("PRODID:-//aiquota//quota resets//EN")
+	add("CALSCALE:GREGORIAN")
+	add("METHOD:PUBLISH")
+	add("X-WR-CALNAME:AI quota resets")
+
+	for _, reset := range resets {
+		summary := icsEscaper.Replace(reset.provider.Name() + " quota reset")
+		start := reset.at.UTC().Format(icsLayout)
+
+		add("BEGIN:VEVENT")
+		add(fmt.Sprintf("UID:%s-%d@aiquota", reset.provider.ID(), reset.at.Unix()))
+		add("DTSTAMP:" + now.UTC().Format(icsLayout))
+		add("DTSTART:" + start)
+		add("DTEND:" + start)
+		add("SUMMARY:" + summary)
+		add("DESCRIPTION:" + icsEscaper.Replace("Resets "+reset.windowNames()+"."))
+		add("TRANSP:TRANSPARENT")
+
+		if remind > 0 {
+			add("BEGIN:VALARM")
+			add("ACTION:DISPLAY")
+			add("DESCRIPTION:" + summary)
+			add("TRIGGER:" + icsDuration(remind))
+			add("END:VALARM")
+		}
+
+		add("END:VEVENT")
+	}
+
+	add("END:VCALENDAR")
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+// icsDuration formats a reminder offset before the event, in minutes when
+// it is a whole number of them.
+func icsDuration(d time.Duration) string {
+	if d%time.Minute == 0 {
+		return fmt.Sprintf("-PT%dM", int(d.Minutes()))
+	}
+
+	return fmt.Sprintf("-PT%dS", int(d.Seconds()))
+}
+
+// foldICSLine splits lines longer than 75 octets, continuing them on lines
+// that start with a space, without breaking UTF-8 sequences.
+func foldICSLine(line string) string {
+	var b strings.Builder
+	width := 75
+	for len(line) > width {
+		cut := width
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines lose one octet to the leading space.
+		width = 74
+	}
+
+	b.WriteString(line)
+	return b.String()
+}
+
+// nextResetFormats are the values of `aiquota next-reset --format`.
+var nextResetFormats = []string{"rfc3339", "epoch", "seconds", "human"}
+
+// runNextReset prints the soonest upcoming reset across the providers, or of
+// the provider named by the first argument, for scripts that wait for a
+// quota to come back:
+//
+//	sleep "$(aiquota next-reset copilot --format seconds)"
+func runNextReset(parent context.Context, args []string) error {
+	flags := flag.NewFlagSet("aiquota next-reset", flag.ContinueOnError)
+	format := flags.String("format", "rfc3339", "how to print the reset: "+strings.Join(nextResetFormats, ", "))
+	fetch := addFetchFlags(flags)
+
+	// The provider may come before the flags, which flag.Parse would stop at.
+	var only string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		only, args = args[0], args[1:]
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() > 0 {
+		if only != "" || flags.NArg() > 1 {
+			return fmt.Errorf("expected at most one provider, got %s", strings.Join(append([]string{only}, flags.Args()...), " "))
+		}
+		only = flags.Arg(0)
+	}
+
+	if !slices.Contains(nextResetFormats, *format) {
+		return fmt.Errorf("invalid format %q, expected one of %s", *format, strings.Join(nextResetFormats, ", "))
+	}
+
+	if only != "" {
+		fetch.only = nil
+		if err := fetch.only.Set(only); err != nil {
+			return err
+		}
+	}
+
+	creds, err := fetch.credentials()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := fetch.context(parent)
+	defer cancel()
+
+	results, err := fetchQuotas(ctx, creds, fetch)
+	if parent.Err() != nil {
+		return errInterrupted
+	}
+	if err != nil {
+		return err
+	}
+
+	fetch.record(results)
+
+	now := time.Now()
+	resets := upcomingResets(results, now)
+	if len(resets) == 0 {
+		return fmt.Errorf("no upcoming reset reported")
+	}
+
+	next := resets[0].at
+	switch *format {
+	case "epoch":
+		fmt.Println(next.Unix())
+	case "seconds":
+		fmt.Println(int64(math.Ceil(next.Sub(now).Seconds())))
+	case "human":
+		fmt.Println(helpers.FormatDuration(next.Sub(now)))
+	default:
+		fmt.Println(next.UTC().Format(time.RFC3339))
+	}
+
+	return nil
+}
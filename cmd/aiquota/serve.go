@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/prometheus"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// serverFlags holds the flags of the HTTP endpoints shared by serve and
+// daemon.
+type serverFlags struct {
+	listen   string
+	token    string
+	cacheTTL time.Duration
+}
+
+func addServerFlags(flags *flag.FlagSet, listen string) *serverFlags {
+	s := &serverFlags{}
+	flags.StringVar(&s.listen, "listen", listen, "address to listen on")
+	flags.StringVar(&s.token, "token", os.Getenv("AIQUOTA_SERVE_TOKEN"), "require this bearer token on every endpoint except /healthz (default $AIQUOTA_SERVE_TOKEN)")
+	flags.DurationVar(&s.cacheTTL, "cache-ttl", 60*time.Second, "maximum age of results served by the JSON API before fetching again (0 serves the latest scheduled fetch)")
+
+	return s
+}
+
+func runServe(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("aiquota serve", flag.ContinueOnError)
+	interval := flags.Duration("interval", 60*time.Second, "time between provider refreshes")
+	server := addServerFlags(flags, ":9108")
+	fetch := addFetchFlags(flags)
+	alerts := addAlertFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *interval <= 0 {
+		return fmt.Errorf("interval must be greater than zero")
+	}
+
+	if server.cacheTTL < 0 {
+		return fmt.Errorf("cache-ttl must not be negative")
+	}
+
+	creds, err := fetch.credentials()
+	if err != nil {
+		return err
+	}
+
+	enabled, err := fetch.enabled(creds)
+	if err != nil {
+		return err
+	}
+
+	collector := prometheus.NewCollector()
+	cache := &quotaCache{
+		ttl: server.cacheTTL,
+		fetch: func(ctx context.Context) []provider.Result {
+			fetchCtx, cancel := fetch.context(ctx)
+			defer cancel()
+			results := provider.FetchAll(fetchCtx, creds, enabled, fetch.options())
+			collector.Update(results)
+			return results
+		},
+		scheduled: func(ctx context.Context, results []provider.Result) {
+			fetch.record(results)
+			alerts.dispatch(ctx, results)
+		},
+	}
+
+	cache.refresh(ctx)
+
+	go func() {
+		ticker := time.NewTicker(*interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				cache.refresh(ctx)
+			}
+		}
+	}()
+
+	return server.serve(ctx, collector, cache)
+}
+
+// serve exposes /metrics and the JSON API until ctx is done.
+func (s *serverFlags) serve(ctx context.Context, collector *prometheus.Collector, cache *quotaCache) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := collector.Write(w); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to write metrics: %v\n", err)
+		}
+	})
+
+	api := &apiHandler{ctx: ctx, cache: cache}
+	api.register(mux)
+
+	server := &http.Server{
+		Addr:              s.listen,
+		Handler:           requireToken(s.token, mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Serving metrics on %s/metrics and the JSON API on %s/v1/quota\n", s.listen, s.listen)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// reportSort is the value of --sort.
+type reportSort string
+
+const (
+	sortProvider reportSort = ""
+	sortUsed     reportSort = "used"
+	sortName     reportSort = "name"
+	sortReset    reportSort = "reset"
+)
+
+func (s *reportSort) String() string {
+	if s == nil {
+		return ""
+	}
+
+	return string(*s)
+}
+
+func (s *reportSort) Set(value string) error {
+	switch order := reportSort(strings.ToLower(value)); order {
+	case sortUsed, sortName, sortReset:
+		*s = order
+		return nil
+	default:
+		return fmt.Errorf("invalid sort %q, expected used, name or reset", value)
+	}
+}
+
+// sortResults returns the results in the order of the report: the most used
+// provider first, by name, or the soonest reset first. Providers without a
+// used percent or a reset go last, and the default keeps the provider
+// order.
+func sortResults(results []provider.Result, order reportSort) []provider.Result {
+	if order == sortProvider {
+		return results
+	}
+
+	sorted := slices.Clone(results)
+	slices.SortStableFunc(sorted, func(a, b provider.Result) int {
+		switch order {
+		case sortUsed:
+			usedA, okA := resultUsed(a)
+			usedB, okB := resultUsed(b)
+			if okA != okB {
+				return compareFound(okA)
+			}
+			return cmp.Compare(usedB, usedA)
+		case sortReset:
+			resetA, okA := resultReset(a)
+			resetB, okB := resultReset(b)
+			if okA != okB {
+				return compareFound(okA)
+			}
+			return resetA.Compare(resetB)
+		default:
+			return strings.Compare(strings.ToLower(a.Provider.Name()), strings.ToLower(b.Provider.Name()))
+		}
+	})
+
+	return sorted
+}
+
+// compareFound orders results with a sort key before those without.
+func compareFound(found bool) int {
+	if found {
+		return -1
+	}
+
+	return 1
+}
+
+// resultUsed returns the highest used percent of a result.
+func resultUsed(result provider.Result) (float64, bool) {
+	if result.Err != nil {
+		return 0, false
+	}
+
+	window, ok := mostUsedWindow(result.Quota)
+	if !ok {
+		return 0, false
+	}
+
+	return *window.UsedPercent, true
+}
+
+// resultReset returns the soonest upcoming reset of a result.
+func resultReset(result provider.Result) (time.Time, bool) {
+	if result.Err != nil {
+		return time.Time{}, false
+	}
+
+	var (
+		soonest time.Time
+		found   bool
+	)
+
+	now := time.Now()
+	for _, window := range result.Quota.Windows() {
+		at, err := time.Parse(time.RFC3339, window.ResetAt)
+		if err != nil || !at.After(now) {
+			continue
+		}
+
+		if !found || at.Before(soonest) {
+			soonest, found = at, true
+		}
+	}
+
+	return soonest, found
+}
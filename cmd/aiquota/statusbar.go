@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/internal/i18n"
+	"github.com/eduardolat/aiquota/pkg/provider"
+	"github.com/varavelio/tinta"
+)
+
+// worstWindow finds the window with the highest used percent across every
+// successful result.
+func worstWindow(results []provider.Result) (provider.Provider, provider.Window, bool) {
+	var (
+		worstProvider provider.Provider
+		worst         provider.Window
+		found         bool
+	)
+
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+
+		window, ok := mostUsedWindow(result.Quota)
+		if ok && (!found || *window.UsedPercent > *worst.UsedPercent) {
+			worstProvider, worst, found = result.Provider, window, true
+		}
+	}
+
+	return worstProvider, worst, found
+}
+
+// severity names the usage band of a percent, using the same thresholds as
+// the report colors.
+func severity(percent float64) string {
+	switch {
+	case percent >= 75:
+		return "critical"
+	case percent >= 50:
+		return "warning"
+	default:
+		return "normal"
+	}
+}
+
+// statusText summarizes the worst window as "Name 42%".
+func statusText(results []provider.Result) (string, float64, bool) {
+	p, window, ok := worstWindow(results)
+	if !ok {
+		return i18n.T("AI n/a"), 0, false
+	}
+
+	return fmt.Sprintf("%s %s%%", p.Name(), formatPercent(*window.UsedPercent)), *window.UsedPercent, true
+}
+
+// waybarOutput is the custom module schema read by waybar with
+// "return-type": "json".
+type waybarOutput struct {
+	Text       string `json:"text"`
+	Tooltip    string `json:"tooltip"`
+	Class      string `json:"class"`
+	Percentage int    `json:"percentage"`
+}
+
+// printWaybar writes a single line of waybar JSON. The tooltip holds the
+// plain report, escaped because waybar renders tooltips as Pango markup.
+func printWaybar(w io.Writer, results []provider.Result) error {
+	text, percent, ok := statusText(results)
+	out := waybarOutput{
+		Text:       text,
+		Tooltip:    pangoEscaper.Replace(plainReport(results)),
+		Class:      "unknown",
+		Percentage: int(math.Round(percent)),
+	}
+
+	if ok {
+		out.Class = severity(percent)
+	}
+
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		return fmt.Errorf("failed to encode waybar output: %w", err)
+	}
+
+	return nil
+}
+
+var pangoEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// i3blocksColors maps severities to the color line of an i3blocks block.
+var i3blocksColors = map[string]string{
+	"normal":   "#A3BE8C",
+	"warning":  "#EBCB8B",
+	"critical": "#BF616A",
+}
+
+// printI3blocks writes the full text, short text and color lines that
+// i3blocks reads from a blocklet.
+func printI3blocks(w io.Writer, results []provider.Result) {
+	text, percent, ok := statusText(results)
+	fmt.Fprintln(w, text)
+	fmt.Fprintln(w, formatPercent(percent)+"%")
+	if ok {
+		fmt.Fprintln(w, i3blocksColors[severity(percent)])
+	}
+}
+
+// printStarship writes the most used window as a short segment such as
+// "X 78% 2h 5m" for a starship custom module, colored by severity with
+// --color always. Nothing is written when no window reports a used percent,
+// which hides the module:
+//
+//	[custom.aiquota]
+//	command = "aiquota --format starship --color always"
+//	when = true
+//	shell = ["sh"]
+//	unsafe_no_escape = true
+//	format = "[$output]($style) "
+//
+// Without --color always and unsafe_no_escape, the style of the module
+// applies instead. Prompts are drawn on every command, so keep the fetch
+// short with --timeout.
+func printStarship(w io.Writer, results []provider.Result) {
+	p, window, ok := worstWindow(results)
+	if !ok {
+		return
+	}
+
+	text := abbreviation(p) + " " + formatPercent(*window.UsedPercent) + "%"
+	if reset := helpers.FormatTimeUntil(window.ResetAt); reset != "unknown" {
+		text += " " + i18n.T(reset)
+	}
+
+	fmt.Fprintln(w, themeText(activeTheme.severity(*window.UsedPercent)).String(text))
+}
+
+// plainReport renders the report as plain text without colors.
+func plainReport(results []provider.Result) string {
+	tinta.ForceColors(false)
+	renderer := &reportRenderer{annotations: burnRateAnnotations(results), plain: true}
+	return renderer.render(results)
+}
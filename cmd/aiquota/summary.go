@@ -0,0 +1,340 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/history"
+	"github.com/eduardolat/aiquota/internal/i18n"
+	"github.com/eduardolat/aiquota/pkg/providers"
+	"github.com/varavelio/tinta"
+)
+
+// summaryFormats are the values of `aiquota summary --format`.
+var summaryFormats = []string{"text", "markdown"}
+
+// runSummary aggregates the history of the past week or month into peak
+// usage, daily consumption, limit hits and consumption per reset cycle.
+func runSummary(args []string) error {
+	flags := flag.NewFlagSet("aiquota summary", flag.ContinueOnError)
+	period := flags.String("period", "week", "how far back to summarize: day, week or month")
+	format := flags.String("format", "text", "output format: "+strings.Join(summaryFormats, ", "))
+	var only providerList
+	flags.Var(&only, "provider", "only summarize these providers, comma-separated or repeated")
+	output := addOutputFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	output.apply()
+
+	span, ok := historyPeriods[*period]
+	if !ok {
+		return fmt.Errorf("invalid period %q, expected day, week or month", *period)
+	}
+	if !slices.Contains(summaryFormats, *format) {
+		return fmt.Errorf("invalid format %q, expected one of %s", *format, strings.Join(summaryFormats, ", "))
+	}
+
+	path, err := history.DefaultPath()
+	if err != nil {
+		return err
+	}
+
+	store, err := history.Open(path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	now := time.Now()
+	records, err := store.Since(now.Add(-span), "")
+	if err != nil {
+		return err
+	}
+
+	if len(only) > 0 {
+		records = slices.DeleteFunc(records, func(record history.Record) bool {
+			return !slices.Contains(only, record.Provider)
+		})
+	}
+
+	if len(records) == 0 {
+		fmt.Println(i18n.Sprintf("No usage history recorded in %s.", i18n.T("the last "+*period)))
+		return nil
+	}
+
+	summary := summarizeHistory(records)
+	summary.period, summary.start, summary.end = *period, now.Add(-span), now
+
+	if *format == "markdown" {
+		fmt.Print(summary.markdown())
+		return nil
+	}
+
+	renderer := &reportRenderer{plain: output.isPlain()}
+	fmt.Println(summary.render(renderer))
+	return nil
+}
+
+// historySummary is the aggregated history of every provider window.
+type historySummary struct {
+	period    string
+	start     time.Time
+	end       time.Time
+	snapshots int
+	providers []string
+	windows   map[string][]*windowSummary
+}
+
+// windowSummary aggregates the samples of one window. Windows without a
+// percentage are tracked by their absolute usage.
+type windowSummary struct {
+	id      string
+	name    string
+	percent bool
+
+	peak float64
+	// consumed adds up the increases between samples, and the usage found
+	// after each reset.
+	consumed float64
+	// limitHits counts the times the window reached its limit.
+	limitHits int
+	resets    int
+	// cycles is the consumption of every cycle that started and ended with
+	// a reset inside the period.
+	cycles []float64
+
+	first    time.Time
+	last     time.Time
+	previous float64
+	resetAt  string
+	cycle    float64
+	full     bool
+}
+
+func summarizeHistory(records []history.Record) historySummary {
+	summary := historySummary{windows: map[string][]*windowSummary{}}
+	index := map[string]*windowSummary{}
+
+	for _, record := range records {
+		summary.snapshots++
+		if _, seen := summary.windows[record.Provider]; !seen {
+			summary.providers = append(summary.providers, record.Provider)
+			summary.windows[record.Provider] = nil
+		}
+
+		for _, window := range record.Windows {
+			value, percent := windowValue(window)
+			if value == nil {
+				continue
+			}
+
+			full := *value >= 100
+			if !percent {
+				full = window.Limit != nil && *window.Limit > 0 && *value >= *window.Limit
+			}
+
+			key := record.Provider + "/" + window.ID
+			w, ok := index[key]
+			if !ok {
+				w = &windowSummary{id: window.ID}
+				index[key] = w
+				summary.windows[record.Provider] = append(summary.windows[record.Provider], w)
+			}
+
+			w.name, w.percent = window.Name, percent
+			w.observe(record.Timestamp, *value, window.ResetAt, full)
+		}
+	}
+
+	slices.SortStableFunc(summary.providers, func(a, b string) int {
+		return providerIndex(a) - providerIndex(b)
+	})
+
+	return summary
+}
+
+// observe adds a sample. A drop in usage starts a new cycle when the reset
+// time of the previous sample has passed, or when the window reports none.
+// Other drops come from rolling windows and count as no consumption.
+func (w *windowSummary) observe(at time.Time, value float64, resetAt string, full bool) {
+	switch {
+	case w.first.IsZero():
+		w.first = at
+	case value < w.previous && w.resetPassed(at):
+		if w.resets > 0 {
+			w.cycles = append(w.cycles, w.cycle)
+		}
+		w.resets++
+		w.cycle = value
+		w.consumed += value
+	case value > w.previous:
+		w.cycle += value - w.previous
+		w.consumed += value - w.previous
+	}
+
+	if full && !w.full {
+		w.limitHits++
+	}
+
+	w.full, w.previous, w.resetAt, w.last = full, value, resetAt, at
+	w.peak = max(w.peak, value)
+}
+
+func (w *windowSummary) resetPassed(at time.Time) bool {
+	if w.resetAt == "" {
+		return true
+	}
+
+	resetAt, err := time.Parse(time.RFC3339, w.resetAt)
+	return err != nil || !resetAt.After(at)
+}
+
+// perDay is the average consumption per day over the time the window was
+// sampled, counting at least one day.
+func (w *windowSummary) perDay() float64 {
+	days := max(w.last.Sub(w.first), 24*time.Hour).Hours() / 24
+	return w.consumed / days
+}
+
+func (w *windowSummary) format(value float64) string {
+	if w.percent {
+		return formatPercent(value) + "%"
+	}
+
+	return formatNumber(value)
+}
+
+// cycleStats returns the average and largest consumption of the completed
+// reset cycles.
+func (w *windowSummary) cycleStats() (average float64, largest float64) {
+	for _, cycle := range w.cycles {
+		average += cycle
+		largest = max(largest, cycle)
+	}
+
+	return average / float64(len(w.cycles)), largest
+}
+
+func (s historySummary) providerName(id string) string {
+	if p, ok := providers.Get(id); ok {
+		return p.Name()
+	}
+
+	return id
+}
+
+// description is the line under the summary title, such as "Last week,
+// 2026-10-07 11:14 to 2026-10-14 11:14, 2016 snapshots".
+func (s historySummary) description() string {
+	return i18n.Sprintf(
+		"%s, %s to %s, %d %s",
+		i18n.T("Last "+s.period),
+		s.start.In(displayTime.location).Format("2006-01-02 15:04"),
+		s.end.In(displayTime.location).Format("2006-01-02 15:04"),
+		s.snapshots,
+		i18n.Plural(s.snapshots, "snapshot", "snapshots"),
+	)
+}
+
+// render draws the summary with the report boxes.
+func (s historySummary) render(r *reportRenderer) string {
+	key := tinta.Text().Bold()
+	sections := []string{
+		themeText(activeTheme.title).Bold().String(i18n.T("AI QUOTA SUMMARY")),
+		tinta.Text().Dim().String(s.description()),
+		"",
+	}
+
+	for _, id := range s.providers {
+		if len(s.windows[id]) == 0 {
+			continue
+		}
+
+		box := providerBox(id)
+		lines := []string{providerHeading(id, s.providerName(id))}
+		for _, w := range s.windows[id] {
+			peak := formatNumber(w.peak)
+			if w.percent {
+				peak = colorPercent(w.peak)
+			}
+
+			lines = append(lines,
+				"",
+				key.String(i18n.T(w.name)),
+				fmt.Sprintf("%s %s", key.String(i18n.T("Peak:")), peak),
+				fmt.Sprintf("%s %s", key.String(i18n.T("Per day:")), w.format(w.perDay())),
+				fmt.Sprintf("%s %d %s", key.String(i18n.T("Limit reached:")), w.limitHits, i18n.Plural(w.limitHits, "time", "times")),
+				fmt.Sprintf("%s %d", key.String(i18n.T("Resets:")), w.resets),
+			)
+
+			if len(w.cycles) > 0 {
+				average, largest := w.cycleStats()
+				lines = append(lines, i18n.Sprintf(
+					"%s %s on average, up to %s (%d %s)",
+					key.String(i18n.T("Per reset cycle:")),
+					w.format(average),
+					w.format(largest),
+					len(w.cycles),
+					i18n.Plural(len(w.cycles), "cycle", "cycles"),
+				))
+			}
+		}
+
+		sections = append(sections, r.box(box, strings.Join(lines, "\n")))
+	}
+
+	return r.frame(sections)
+}
+
+// markdown renders the summary as a table per provider.
+func (s historySummary) markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", i18n.T("AI Quota Summary"))
+	fmt.Fprintf(&b, "_%s._\n", s.description())
+
+	for _, id := range s.providers {
+		if len(s.windows[id]) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n## %s\n\n", markdownEscape(s.providerName(id)))
+		fmt.Fprintf(
+			&b,
+			"| %s | %s | %s | %s | %s | %s | %s |\n",
+			i18n.T("Window"),
+			i18n.T("Peak"),
+			i18n.T("Per day"),
+			i18n.T("Limit reached"),
+			i18n.T("Resets"),
+			i18n.T("Per reset cycle"),
+			i18n.T("Largest cycle"),
+		)
+		b.WriteString("| --- | ---: | ---: | ---: | ---: | ---: | ---: |\n")
+		for _, w := range s.windows[id] {
+			average, largest := "-", "-"
+			if len(w.cycles) > 0 {
+				avg, max := w.cycleStats()
+				average, largest = w.format(avg), w.format(max)
+			}
+
+			fmt.Fprintf(
+				&b,
+				"| %s | %s | %s | %d | %d | %s | %s |\n",
+				markdownEscape(i18n.T(w.name)),
+				w.format(w.peak),
+				w.format(w.perDay()),
+				w.limitHits,
+				w.resets,
+				average,
+				largest,
+			)
+		}
+	}
+
+	return b.String()
+}
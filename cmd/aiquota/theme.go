@@ -0,0 +1,357 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/varavelio/tinta"
+)
+
+// themeColor is one of the 16 terminal colors by name, such as "blue" or
+// "bright-blue", or "none" for the default color of the terminal.
+type themeColor string
+
+const noColor themeColor = "none"
+
+// ansiColor draws a named color in text and box borders.
+type ansiColor struct {
+	name themeColor
+	text func(*tinta.TextStyle) *tinta.TextStyle
+	box  func(*tinta.BoxStyle) *tinta.BoxStyle
+}
+
+// ansiColors are the colors a theme may use, in the order of their ANSI
+// codes, which is how the TUI refers to them.
+var ansiColors = []ansiColor{
+	{"black", (*tinta.TextStyle).Black, (*tinta.BoxStyle).Black},
+	{"red", (*tinta.TextStyle).Red, (*tinta.BoxStyle).Red},
+	{"green", (*tinta.TextStyle).Green, (*tinta.BoxStyle).Green},
+	{"yellow", (*tinta.TextStyle).Yellow, (*tinta.BoxStyle).Yellow},
+	{"blue", (*tinta.TextStyle).Blue, (*tinta.BoxStyle).Blue},
+	{"magenta", (*tinta.TextStyle).Magenta, (*tinta.BoxStyle).Magenta},
+	{"cyan", (*tinta.TextStyle).Cyan, (*tinta.BoxStyle).Cyan},
+	{"white", (*tinta.TextStyle).White, (*tinta.BoxStyle).White},
+	{"bright-black", (*tinta.TextStyle).BrightBlack, (*tinta.BoxStyle).BrightBlack},
+	{"bright-red", (*tinta.TextStyle).BrightRed, (*tinta.BoxStyle).BrightRed},
+	{"bright-green", (*tinta.TextStyle).BrightGreen, (*tinta.BoxStyle).BrightGreen},
+	{"bright-yellow", (*tinta.TextStyle).BrightYellow, (*tinta.BoxStyle).BrightYellow},
+	{"bright-blue", (*tinta.TextStyle).BrightBlue, (*tinta.BoxStyle).BrightBlue},
+	{"bright-magenta", (*tinta.TextStyle).BrightMagenta, (*tinta.BoxStyle).BrightMagenta},
+	{"bright-cyan", (*tinta.TextStyle).BrightCyan, (*tinta.BoxStyle).BrightCyan},
+	{"bright-white", (*tinta.TextStyle).BrightWhite, (*tinta.BoxStyle).BrightWhite},
+}
+
+func parseThemeColor(value string) (themeColor, error) {
+	c := themeColor(strings.ReplaceAll(strings.ToLower(value), "_", "-"))
+	if c == noColor || c.index() >= 0 {
+		return c, nil
+	}
+
+	return "", fmt.Errorf("invalid color %q, expected none or one of black, red, green, yellow, blue, magenta, cyan or white, optionally prefixed by bright-", value)
+}
+
+// index returns the ANSI code of c, or -1 for none.
+func (c themeColor) index() int {
+	return slices.IndexFunc(ansiColors, func(a ansiColor) bool { return a.name == c })
+}
+
+// bright returns the bright variant of c, or c when it has none.
+func (c themeColor) bright() themeColor {
+	if i := c.index(); i >= 0 && i < 8 {
+		return ansiColors[i+8].name
+	}
+
+	return c
+}
+
+func (c themeColor) text(style *tinta.TextStyle) *tinta.TextStyle {
+	if i := c.index(); i >= 0 {
+		return ansiColors[i].text(style)
+	}
+
+	return style
+}
+
+func (c themeColor) box(style *tinta.BoxStyle) *tinta.BoxStyle {
+	if i := c.index(); i >= 0 {
+		return ansiColors[i].box(style)
+	}
+
+	return style
+}
+
+// ansi returns s in c whether or not stdout is colored, for the streams
+// styled apart from it.
+func (c themeColor) ansi(s string) string {
+	i := c.index()
+	if i < 0 {
+		return s
+	}
+
+	code := 30 + i
+	if i >= 8 {
+		code = 90 + i - 8
+	}
+
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", code, s)
+}
+
+// lipgloss returns c for the TUI.
+func (c themeColor) lipgloss() lipgloss.TerminalColor {
+	if i := c.index(); i >= 0 {
+		return lipgloss.Color(strconv.Itoa(i))
+	}
+
+	return lipgloss.NoColor{}
+}
+
+// theme maps the parts of the reports to colors. Provider colors are used for
+// the box borders, and headings use their bright variant unless the theme
+// keeps them as is.
+type theme struct {
+	providers map[string]themeColor
+	// other colors providers missing from providers.
+	other themeColor
+	// title colors report titles and the outer frame, group the group
+	// headings.
+	title themeColor
+	group themeColor
+	// low, medium and high color usage below 50%, from 50% and from 75%,
+	// and with them anything fine, worth a look or failing.
+	low    themeColor
+	medium themeColor
+	high   themeColor
+
+	plainHeadings bool
+}
+
+var defaultTheme = theme{
+	providers: map[string]themeColor{
+		"copilot":       "blue",
+		"copilot-org":   "blue",
+		"zai":           "yellow",
+		"codex":         "magenta",
+		"anthropic":     "red",
+		"anthropic-api": "red",
+		"replicate":     "green",
+		"gemini":        "cyan",
+		"openrouter":    "white",
+		"cursor":        "white",
+		"mistral":       "yellow",
+		"deepseek":      "blue",
+		"groq":          "red",
+		"xai":           "white",
+		"together":      "blue",
+		"fireworks":     "magenta",
+		"cohere":        "cyan",
+		"perplexity":    "cyan",
+		"moonshot":      "white",
+		"openai":        "green",
+		"azure":         "blue",
+		"bedrock":       "yellow",
+		"github-models": "blue",
+	},
+	other:  "white",
+	title:  "bright-cyan",
+	group:  "bright-magenta",
+	low:    "bright-green",
+	medium: "bright-yellow",
+	high:   "bright-red",
+}
+
+// themes are the values of --theme.
+var themes = map[string]theme{
+	"default": defaultTheme,
+	// solarized keeps to the accent colors, since Solarized terminals draw
+	// most bright colors as shades of gray.
+	"solarized": {
+		providers:     defaultTheme.providers,
+		other:         "white",
+		title:         "cyan",
+		group:         "magenta",
+		low:           "green",
+		medium:        "yellow",
+		high:          "red",
+		plainHeadings: true,
+	},
+	"mono": {
+		other:  noColor,
+		title:  noColor,
+		group:  noColor,
+		low:    noColor,
+		medium: noColor,
+		high:   noColor,
+	},
+	"high-contrast": {
+		providers: recolor(defaultTheme.providers, themeColor.bright),
+		other:     "bright-white",
+		title:     "bright-white",
+		group:     "bright-white",
+		low:       "bright-green",
+		medium:    "bright-yellow",
+		high:      "bright-red",
+	},
+	// colorblind avoids telling red from green, which most color vision
+	// deficiencies confuse, and goes from blue to yellow to magenta instead.
+	"colorblind": {
+		providers: recolor(defaultTheme.providers, func(c themeColor) themeColor {
+			switch c {
+			case "red":
+				return "magenta"
+			case "green":
+				return "cyan"
+			default:
+				return c
+			}
+		}),
+		other:  "white",
+		title:  "bright-cyan",
+		group:  "bright-white",
+		low:    "bright-blue",
+		medium: "bright-yellow",
+		high:   "bright-magenta",
+	},
+}
+
+func recolor(colors map[string]themeColor, fn func(themeColor) themeColor) map[string]themeColor {
+	recolored := make(map[string]themeColor, len(colors))
+	for id, c := range colors {
+		recolored[id] = fn(c)
+	}
+
+	return recolored
+}
+
+// themeRoles are the keys of --colors besides provider IDs.
+var themeRoles = []string{"title", "group", "other", "low", "medium", "high"}
+
+// activeTheme is the theme reports are drawn with. It is set while the flags
+// are parsed, like displayTime.
+var activeTheme = defaultTheme
+
+// addThemeFlags adds --theme and --colors. --colors overrides the colors of
+// the theme whichever order they are given in. Commands with several flag
+// groups add them once.
+func addThemeFlags(flags *flag.FlagSet) {
+	if flags.Lookup("theme") != nil {
+		return
+	}
+
+	base, overrides := defaultTheme, map[string]themeColor{}
+	update := func() {
+		activeTheme = base
+		activeTheme.providers = maps.Clone(base.providers)
+		if activeTheme.providers == nil {
+			activeTheme.providers = map[string]themeColor{}
+		}
+
+		for key, c := range overrides {
+			switch key {
+			case "title":
+				activeTheme.title = c
+			case "group":
+				activeTheme.group = c
+			case "other":
+				activeTheme.other = c
+			case "low":
+				activeTheme.low = c
+			case "medium":
+				activeTheme.medium = c
+			case "high":
+				activeTheme.high = c
+			default:
+				activeTheme.providers[key] = c
+			}
+		}
+	}
+
+	names := slices.Sorted(maps.Keys(themes))
+	flags.Func("theme", "color theme: "+strings.Join(names, ", "), func(value string) error {
+		t, ok := themes[strings.ToLower(value)]
+		if !ok {
+			return fmt.Errorf("unknown theme %q, expected one of %s", value, strings.Join(names, ", "))
+		}
+
+		base = t
+		update()
+		return nil
+	})
+	flags.Func("colors", "override theme colors, as key=color pairs, comma-separated; keys are provider IDs or "+strings.Join(themeRoles, ", "), func(value string) error {
+		for part := range strings.SplitSeq(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+
+			key, raw, ok := strings.Cut(part, "=")
+			if !ok {
+				return fmt.Errorf("invalid color %q, expected key=color", part)
+			}
+
+			c, err := parseThemeColor(strings.TrimSpace(raw))
+			if err != nil {
+				return err
+			}
+
+			overrides[strings.ToLower(strings.TrimSpace(key))] = c
+		}
+
+		update()
+		return nil
+	})
+}
+
+// providerColor returns the box color of a provider.
+func (t theme) providerColor(id string) themeColor {
+	if c, ok := t.providers[id]; ok {
+		return c
+	}
+
+	return t.other
+}
+
+// headingColor returns the heading color of a provider.
+func (t theme) headingColor(id string) themeColor {
+	if t.plainHeadings {
+		return t.providerColor(id)
+	}
+
+	return t.providerColor(id).bright()
+}
+
+// severity returns the color of a used percent.
+func (t theme) severity(percent float64) themeColor {
+	switch {
+	case percent >= 75:
+		return t.high
+	case percent >= 50:
+		return t.medium
+	default:
+		return t.low
+	}
+}
+
+// themeText returns a text style in c.
+func themeText(c themeColor) *tinta.TextStyle {
+	return c.text(tinta.Text())
+}
+
+// providerHeading draws the name of a provider over its box.
+func providerHeading(id string, name string) string {
+	return themeText(activeTheme.headingColor(id)).Bold().String(name)
+}
+
+// providerBox returns the open box provider reports are drawn in.
+func providerBox(id string) *tinta.BoxStyle {
+	return activeTheme.providerColor(id).box(tinta.Box().Border(boxBorder(tinta.BorderSimple))).
+		DisableTop().
+		DisableBottom().
+		DisableRight().
+		PaddingLeft(1).
+		PaddingRight(0)
+}
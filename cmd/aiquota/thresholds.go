@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// exitThresholdExceeded is the exit code used when a --fail-at threshold is
+// crossed, so scripts can tell it apart from fetch errors.
+const exitThresholdExceeded = 2
+
+// exitError carries a specific process exit code up to main.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
+// thresholdBreaches lists every window whose used percent is at or above the
+// threshold configured for its provider.
+func thresholdBreaches(results []provider.Result, thresholds *providerValues[float64]) []string {
+	var breaches []string
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+
+		threshold, ok := thresholds.get(result.Provider.ID())
+		if !ok {
+			continue
+		}
+
+		for _, window := range result.Quota.Windows() {
+			if window.UsedPercent == nil || *window.UsedPercent < threshold {
+				continue
+			}
+
+			breaches = append(breaches, fmt.Sprintf(
+				"%s %s is at %s%% (threshold %s%%)",
+				result.Provider.Name(),
+				window.Name,
+				formatPercent(*window.UsedPercent),
+				formatPercent(threshold),
+			))
+		}
+	}
+
+	return breaches
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+)
+
+// timeLayouts are the values of --time-format.
+var timeLayouts = map[string]string{
+	"24h":     "2006-01-02 15:04:05 MST",
+	"12h":     "2006-01-02 3:04:05 PM MST",
+	"rfc3339": time.RFC3339,
+}
+
+// displayTime is how reports show points in time such as resets. It is set
+// while the flags are parsed, like logLevel.
+var displayTime = struct {
+	location *time.Location
+	layout   string
+}{location: time.Local, layout: timeLayouts["24h"]}
+
+// addTimeFlags adds --utc, --tz and --time-format, and --reset-precision and
+// --reset-full for the time left until resets. Commands with several flag
+// groups add them once.
+func addTimeFlags(flags *flag.FlagSet) {
+	if flags.Lookup("tz") != nil {
+		return
+	}
+
+	precision, full := helpers.PrecisionMinutes, false
+	flags.Func("reset-precision", "how precisely to show the time until resets: seconds, minutes or coarse (default minutes)", func(value string) error {
+		switch p := helpers.DurationPrecision(strings.ToLower(value)); p {
+		case helpers.PrecisionSeconds, helpers.PrecisionMinutes, helpers.PrecisionCoarse:
+			precision = p
+		default:
+			return fmt.Errorf("invalid reset precision %q, expected seconds, minutes or coarse", value)
+		}
+
+		helpers.SetDurationFormat(precision, full)
+		return nil
+	})
+	flags.BoolFunc("reset-full", "show every unit of the time until resets, such as 1d 0h 5m, instead of the two largest", func(value string) error {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+
+		full = enabled
+		helpers.SetDurationFormat(precision, full)
+		return nil
+	})
+
+	flags.BoolFunc("utc", "show times in UTC, same as --tz UTC", func(string) error {
+		displayTime.location = time.UTC
+		return nil
+	})
+	flags.Func("tz", "show times in this IANA time zone, such as Europe/Madrid (default the local time zone)", func(value string) error {
+		location, err := time.LoadLocation(value)
+		if err != nil {
+			return fmt.Errorf("unknown time zone %q", value)
+		}
+
+		displayTime.location = location
+		return nil
+	})
+	flags.Func("time-format", "how to show times: 24h, 12h or rfc3339 (default 24h)", func(value string) error {
+		layout, ok := timeLayouts[strings.ToLower(value)]
+		if !ok {
+			return fmt.Errorf("invalid time format %q, expected 24h, 12h or rfc3339", value)
+		}
+
+		displayTime.layout = layout
+		return nil
+	})
+}
+
+// formatTime shows t in the zone and layout selected by the time flags.
+func formatTime(t time.Time) string {
+	return t.In(displayTime.location).Format(displayTime.layout)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/internal/i18n"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// providerAbbreviations are the short labels used in one-line segments.
+var providerAbbreviations = map[string]string{
+	"copilot":       "C",
+	"copilot-org":   "CO",
+	"zai":           "Z",
+	"codex":         "X",
+	"replicate":     "R",
+	"anthropic":     "A",
+	"anthropic-api": "AA",
+	"gemini":        "G",
+	"openrouter":    "O",
+	"cursor":        "Cu",
+	"mistral":       "M",
+	"deepseek":      "D",
+	"groq":          "Gq",
+	"xai":           "Xa",
+	"together":      "T",
+	"fireworks":     "F",
+	"cohere":        "Co",
+	"perplexity":    "P",
+	"moonshot":      "K",
+	"openai":        "OA",
+	"azure":         "Az",
+	"bedrock":       "B",
+	"github-models": "GM",
+}
+
+// tmuxColors maps severities to tmux style colors.
+var tmuxColors = map[string]string{
+	"normal":   "green",
+	"warning":  "yellow",
+	"critical": "red",
+}
+
+func runTmux(parent context.Context, args []string) error {
+	flags := flag.NewFlagSet("aiquota tmux", flag.ContinueOnError)
+	showReset := flags.Bool("reset", false, "append the time until the worst window resets")
+	noColor := flags.Bool("no-color", false, "print the segment without tmux style codes")
+	separator := flags.String("separator", " ", "text between provider entries")
+	fetch := addFetchFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	creds, err := fetch.credentials()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := fetch.context(parent)
+	defer cancel()
+
+	results, err := fetchQuotas(ctx, creds, fetch)
+	if err != nil {
+		return err
+	}
+
+	fetch.record(results)
+
+	fmt.Println(tmuxSegment(results, *showReset, !*noColor, *separator))
+	return nil
+}
+
+// tmuxSegment renders one entry per provider, such as "C:42% X:78%", showing
+// the provider's most used window. Providers that failed show "?".
+func tmuxSegment(results []provider.Result, showReset bool, color bool, separator string) string {
+	var entries []string
+	for _, result := range results {
+		label := abbreviation(result.Provider) + ":"
+		if result.Err != nil {
+			entries = append(entries, tmuxStyle(label+"?", "red", color))
+			continue
+		}
+
+		window, ok := mostUsedWindow(result.Quota)
+		if !ok {
+			continue
+		}
+
+		text := label + formatPercent(*window.UsedPercent) + "%"
+		if reset := helpers.FormatTimeUntil(window.ResetAt); showReset && reset != "unknown" {
+			text += "(" + i18n.T(reset) + ")"
+		}
+
+		entries = append(entries, tmuxStyle(text, tmuxColors[severity(*window.UsedPercent)], color))
+	}
+
+	return strings.Join(entries, separator)
+}
+
+func mostUsedWindow(quota provider.Quota) (provider.Window, bool) {
+	var (
+		worst provider.Window
+		found bool
+	)
+
+	for _, window := range quota.Windows() {
+		if window.UsedPercent != nil && (!found || *window.UsedPercent > *worst.UsedPercent) {
+			worst, found = window, true
+		}
+	}
+
+	return worst, found
+}
+
+func tmuxStyle(text string, fg string, color bool) string {
+	if !color {
+		return text
+	}
+
+	return "#[fg=" + fg + "]" + text + "#[default]"
+}
+
+// abbreviation returns the short label of a provider, falling back to the
+// upper-cased first letter of its name.
+func abbreviation(p provider.Provider) string {
+	if short, ok := providerAbbreviations[p.ID()]; ok {
+		return short
+	}
+
+	for _, r := range p.Name() {
+		return string(unicode.ToUpper(r))
+	}
+
+	return "?"
+}
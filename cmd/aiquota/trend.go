@@ -0,0 +1,325 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/eduardolat/aiquota/internal/history"
+	"github.com/eduardolat/aiquota/internal/i18n"
+	"github.com/eduardolat/aiquota/pkg/providers"
+	"github.com/varavelio/tinta"
+)
+
+// historyPeriods are the values of the --period flags of commands reading
+// the history database.
+var historyPeriods = map[string]time.Duration{
+	"day":   24 * time.Hour,
+	"week":  7 * 24 * time.Hour,
+	"month": 30 * 24 * time.Hour,
+}
+
+// sparkBlocks are the sparkline levels, lowest first.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// runTrend draws the used percent of every window over the past day, week or
+// month from the history database, as sparklines or as braille charts.
+func runTrend(args []string) error {
+	flags := flag.NewFlagSet("aiquota trend", flag.ContinueOnError)
+	period := flags.String("period", "day", "how far back to draw: day, week or month")
+	width := flags.Int("width", 60, "chart width in columns")
+	height := flags.Int("height", 0, "draw braille charts this many lines high instead of sparklines")
+	var only providerList
+	flags.Var(&only, "provider", "only show these providers, comma-separated or repeated")
+	output := addOutputFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	output.apply()
+
+	span, ok := historyPeriods[*period]
+	if !ok {
+		return fmt.Errorf("invalid period %q, expected day, week or month", *period)
+	}
+	if *width < 2 {
+		return fmt.Errorf("width must be at least 2")
+	}
+	if *height < 0 {
+		return fmt.Errorf("height must not be negative")
+	}
+
+	path, err := history.DefaultPath()
+	if err != nil {
+		return err
+	}
+
+	store, err := history.Open(path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	now := time.Now()
+	records, err := store.Since(now.Add(-span), "")
+	if err != nil {
+		return err
+	}
+
+	if len(only) > 0 {
+		records = slices.DeleteFunc(records, func(record history.Record) bool {
+			return !slices.Contains(only, record.Provider)
+		})
+	}
+
+	if len(records) == 0 {
+		fmt.Println(i18n.Sprintf("No usage history recorded in %s.", i18n.T("the last "+*period)))
+		return nil
+	}
+
+	chart := trendChart{start: now.Add(-span), end: now, width: *width, height: *height}
+	fmt.Println(asciiText(chart.render(records)))
+	return nil
+}
+
+// trendChart draws history records between start and end in width columns.
+// A zero height draws sparklines, otherwise braille charts of that many
+// lines, with two samples per column.
+type trendChart struct {
+	start  time.Time
+	end    time.Time
+	width  int
+	height int
+}
+
+// trendSeries is the history of one window, bucketed by time. Buckets
+// without samples are nil.
+type trendSeries struct {
+	name    string
+	percent bool
+	buckets []*float64
+	last    float64
+	peak    float64
+}
+
+func (c trendChart) render(records []history.Record) string {
+	points := c.width
+	if c.height > 0 {
+		points *= 2
+	}
+	bucketSpan := c.end.Sub(c.start) / time.Duration(points)
+
+	var providerOrder []string
+	windowOrder := map[string][]string{}
+	series := map[string]*trendSeries{}
+	for _, record := range records {
+		if _, seen := windowOrder[record.Provider]; !seen {
+			providerOrder = append(providerOrder, record.Provider)
+			windowOrder[record.Provider] = []string{}
+		}
+
+		bucket := min(int(record.Timestamp.Sub(c.start)/bucketSpan), points-1)
+		if bucket < 0 {
+			continue
+		}
+
+		for _, window := range record.Windows {
+			value, percent := windowValue(window)
+			if value == nil {
+				continue
+			}
+
+			key := record.Provider + "/" + window.ID
+			s, ok := series[key]
+			if !ok {
+				s = &trendSeries{buckets: make([]*float64, points)}
+				series[key] = s
+				windowOrder[record.Provider] = append(windowOrder[record.Provider], window.ID)
+			}
+
+			s.name, s.percent, s.last = window.Name, percent, *value
+			s.peak = max(s.peak, *value)
+			if s.buckets[bucket] == nil || *value > *s.buckets[bucket] {
+				s.buckets[bucket] = new(*value)
+			}
+		}
+	}
+
+	slices.SortStableFunc(providerOrder, func(a, b string) int {
+		return providerIndex(a) - providerIndex(b)
+	})
+
+	key := tinta.Text().Bold()
+	sections := []string{}
+	for _, id := range providerOrder {
+		if len(windowOrder[id]) == 0 {
+			continue
+		}
+
+		name := id
+		if p, ok := providers.Get(id); ok {
+			name = p.Name()
+		}
+
+		nameWidth := 0
+		for _, windowID := range windowOrder[id] {
+			nameWidth = max(nameWidth, utf8.RuneCountInString(i18n.T(series[id+"/"+windowID].name)))
+		}
+
+		lines := []string{providerHeading(id, name)}
+		for _, windowID := range windowOrder[id] {
+			s := series[id+"/"+windowID]
+			summary := fmt.Sprintf("%s %s  %s %s", key.String(i18n.T("last")), s.format(s.last), key.String(i18n.T("peak")), s.format(s.peak))
+			windowName := i18n.T(s.name)
+
+			if c.height == 0 {
+				padding := strings.Repeat(" ", nameWidth-utf8.RuneCountInString(windowName))
+				lines = append(lines, fmt.Sprintf("  %s%s  %s  %s", key.String(windowName), padding, trendStyle(s).String(c.sparkline(s)), summary))
+				continue
+			}
+
+			lines = append(lines, "", "  "+key.String(windowName)+"  "+summary)
+			lines = append(lines, c.braille(s)...)
+		}
+
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+
+	footer := tinta.Text().Dim().String(i18n.Sprintf(
+		"%s to %s, one column per %s",
+		c.start.In(displayTime.location).Format("2006-01-02 15:04"),
+		c.end.In(displayTime.location).Format("2006-01-02 15:04"),
+		formatBucket(c.end.Sub(c.start)/time.Duration(c.width)),
+	))
+
+	return strings.Join(sections, "\n\n") + "\n\n" + footer
+}
+
+func (s *trendSeries) format(value float64) string {
+	if s.percent {
+		return colorPercent(value)
+	}
+
+	return formatNumber(value)
+}
+
+// scale is the value drawn as a full column: 100% for percentages, the peak
+// for absolute usage.
+func (s *trendSeries) scale() float64 {
+	if s.percent {
+		return 100
+	}
+
+	return s.peak
+}
+
+// level maps a value to 0..levels, clamping values past the scale.
+func (s *trendSeries) level(value float64, levels int) int {
+	scale := s.scale()
+	if scale <= 0 {
+		return 0
+	}
+
+	return int(math.Round(min(max(value/scale, 0), 1) * float64(levels)))
+}
+
+// sparkline draws one block per bucket, leaving gaps where aiquota did not
+// run.
+func (c trendChart) sparkline(s *trendSeries) string {
+	var b strings.Builder
+	for _, value := range s.buckets {
+		if value == nil {
+			b.WriteRune(' ')
+			continue
+		}
+
+		b.WriteRune(sparkBlocks[s.level(*value, len(sparkBlocks)-1)])
+	}
+
+	return b.String()
+}
+
+// brailleDots are the dot bits of a braille cell, by column and by row from
+// the top.
+var brailleDots = [2][4]rune{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+// braille draws the series as filled braille columns, two buckets per
+// character, with the scale on the left. Buckets with samples get at least
+// the bottom dot so an idle window stands apart from a gap.
+func (c trendChart) braille(s *trendSeries) []string {
+	dots := c.height * 4
+	heights := make([]int, len(s.buckets))
+	for i, value := range s.buckets {
+		if value != nil {
+			heights[i] = max(s.level(*value, dots), 1)
+		}
+	}
+
+	top := "100%"
+	if !s.percent {
+		top = formatNumber(s.scale())
+	}
+	axisWidth := max(utf8.RuneCountInString(top), 2)
+
+	style := trendStyle(s)
+	lines := make([]string, 0, c.height)
+	for row := range c.height {
+		var b strings.Builder
+		for column := range c.width {
+			cell := rune(0x2800)
+			for side := range 2 {
+				filled := heights[column*2+side]
+				for y := range 4 {
+					// Dots count from 1 at the bottom of the chart.
+					if (c.height-1-row)*4+(4-y) <= filled {
+						cell |= brailleDots[side][y]
+					}
+				}
+			}
+			b.WriteRune(cell)
+		}
+
+		axis := ""
+		switch row {
+		case 0:
+			axis = top
+		case c.height - 1:
+			axis = "0"
+		}
+
+		lines = append(lines, fmt.Sprintf("  %*s ┤%s", axisWidth, axis, style.String(b.String())))
+	}
+
+	return lines
+}
+
+// trendStyle colors a chart by the peak of its window, as colorPercent
+// colors percentages.
+func trendStyle(s *trendSeries) *tinta.TextStyle {
+	if !s.percent {
+		return themeText(activeTheme.title)
+	}
+
+	return themeText(activeTheme.severity(s.peak))
+}
+
+// formatBucket describes the time one chart column covers, such as "24m" or
+// "2h48m".
+func formatBucket(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+
+	return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/internal/i18n"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+const (
+	// panelWidth is the outer width of a provider panel, borders included.
+	panelWidth = 46
+
+	// barWidth is the number of cells in a usage bar.
+	barWidth = 24
+)
+
+var (
+	tuiDim     = lipgloss.NewStyle().Faint(true)
+	tuiBold    = lipgloss.NewStyle().Bold(true)
+	tuiEmpty   = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	tuiJSONBox = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1)
+)
+
+// tuiStyle returns a bold style in a color of the active theme, which is only
+// known once the flags are parsed.
+func tuiStyle(c themeColor) lipgloss.Style {
+	return lipgloss.NewStyle().Bold(true).Foreground(c.lipgloss())
+}
+
+func runTUI(parent context.Context, args []string) error {
+	flags := flag.NewFlagSet("aiquota tui", flag.ContinueOnError)
+	interval := flags.Duration("interval", 60*time.Second, "time between refreshes")
+	fetch := addFetchFlags(flags)
+	alerts := addAlertFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *interval <= 0 {
+		return fmt.Errorf("interval must be greater than zero")
+	}
+
+	creds, err := fetch.credentials()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	model := &dashboard{
+		ctx:      ctx,
+		creds:    creds,
+		fetch:    fetch,
+		alerts:   alerts,
+		interval: *interval,
+		hidden:   map[string]bool{},
+	}
+
+	_, err = tea.NewProgram(model, tea.WithAltScreen()).Run()
+	return err
+}
+
+// dashboard is the bubbletea model behind `aiquota tui`.
+type dashboard struct {
+	ctx      context.Context
+	creds    credentials.Credentials
+	fetch    *fetchFlags
+	alerts   *alertFlags
+	interval time.Duration
+
+	results   []provider.Result
+	err       error
+	updated   time.Time
+	nextFetch time.Time
+	fetching  bool
+
+	// selected indexes results; hidden holds provider IDs toggled off.
+	selected int
+	hidden   map[string]bool
+
+	// showJSON switches the body to the raw quota of the selected provider,
+	// scrolled down by jsonOffset lines.
+	showJSON   bool
+	jsonOffset int
+
+	width  int
+	height int
+}
+
+type fetchedMsg struct {
+	results []provider.Result
+	err     error
+	at      time.Time
+}
+
+type tickMsg time.Time
+
+func tick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+func (m *dashboard) Init() tea.Cmd {
+	return tea.Batch(m.refresh(), tick())
+}
+
+// refresh starts a fetch unless one is already running.
+func (m *dashboard) refresh() tea.Cmd {
+	if m.fetching {
+		return nil
+	}
+
+	m.fetching = true
+	return func() tea.Msg {
+		fetchCtx, cancel := m.fetch.context(m.ctx)
+		defer cancel()
+
+		results, err := fetchQuotas(fetchCtx, m.creds, m.fetch)
+		if err == nil {
+			m.fetch.record(results)
+			m.alerts.dispatch(m.ctx, results)
+		}
+
+		return fetchedMsg{results: results, err: err, at: time.Now()}
+	}
+}
+
+func (m *dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case fetchedMsg:
+		m.fetching = false
+		m.updated = msg.at
+		m.nextFetch = msg.at.Add(m.interval)
+		m.err = msg.err
+		if msg.err == nil {
+			m.results = msg.results
+			m.selected = min(m.selected, len(m.results)-1)
+		}
+	case tickMsg:
+		if !m.fetching && !m.nextFetch.IsZero() && !time.Time(msg).Before(m.nextFetch) {
+			return m, tea.Batch(m.refresh(), tick())
+		}
+		return m, tick()
+	case tea.KeyMsg:
+		return m, m.handleKey(msg)
+	}
+
+	return m, nil
+}
+
+func (m *dashboard) handleKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return tea.Quit
+	case "r":
+		return m.refresh()
+	case "enter":
+		m.showJSON = !m.showJSON
+		m.jsonOffset = 0
+	case "esc":
+		m.showJSON = false
+	case "up":
+		if m.showJSON {
+			m.jsonOffset = max(m.jsonOffset-1, 0)
+		} else {
+			m.move(-1)
+		}
+	case "down":
+		if m.showJSON {
+			m.jsonOffset++
+		} else {
+			m.move(1)
+		}
+	case "left", "shift+tab":
+		m.move(-1)
+	case "right", "tab":
+		m.move(1)
+	case " ":
+		if m.selected >= 0 && m.selected < len(m.results) {
+			id := m.results[m.selected].Provider.ID()
+			m.hidden[id] = !m.hidden[id]
+		}
+	case "a":
+		clear(m.hidden)
+	}
+
+	return nil
+}
+
+// move changes the selected provider, wrapping around at either end.
+func (m *dashboard) move(delta int) {
+	if len(m.results) == 0 {
+		return
+	}
+
+	m.selected = (m.selected + delta + len(m.results)) % len(m.results)
+	m.jsonOffset = 0
+}
+
+func (m *dashboard) View() string {
+	if m.width == 0 {
+		return ""
+	}
+
+	var body string
+	switch {
+	case m.results == nil && m.err != nil:
+		body = tuiStyle(activeTheme.high).Render(i18n.Sprintf("Error: %v", m.err))
+	case m.results == nil:
+		body = tuiDim.Render(i18n.T("Fetching quotas…"))
+	case m.showJSON:
+		body = m.viewJSON()
+	default:
+		body = m.viewPanels()
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, m.viewHeader(), "", body, "", m.viewFooter())
+}
+
+func (m *dashboard) viewHeader() string {
+	status := i18n.T("Fetching…")
+	if !m.fetching && !m.updated.IsZero() {
+		status = i18n.Sprintf(
+			"Updated %s · next refresh in %s",
+			m.updated.Format("15:04:05"),
+			time.Until(m.nextFetch).Round(time.Second),
+		)
+	}
+
+	header := tuiStyle(activeTheme.title).Render(i18n.T("AI QUOTA DASHBOARD")) + "  " + tuiDim.Render(status)
+	if m.results != nil && m.err != nil {
+		header += "\n" + tuiStyle(activeTheme.high).Render(i18n.Sprintf("Last refresh failed: %v", m.err))
+	}
+
+	return header
+}
+
+func (m *dashboard) viewFooter() string {
+	if m.showJSON {
+		return tuiDim.Render(i18n.T("↑/↓ scroll · ←/→ provider · enter back · r refresh · q quit"))
+	}
+
+	return tuiDim.Render(i18n.T("←/→ select · space hide/show · a show all · enter raw JSON · r refresh · q quit"))
+}
+
+// viewPanels lays the provider panels out in as many columns as fit.
+func (m *dashboard) viewPanels() string {
+	var rows, row []string
+	rowWidth := 0
+
+	for index, result := range m.results {
+		panel := m.viewPanel(index, result)
+		if len(row) > 0 && rowWidth+lipgloss.Width(panel) > m.width {
+			rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, row...))
+			row, rowWidth = nil, 0
+		}
+
+		row = append(row, panel)
+		rowWidth += lipgloss.Width(panel)
+	}
+
+	if len(row) > 0 {
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, row...))
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+func (m *dashboard) viewPanel(index int, result provider.Result) string {
+	color := activeTheme.providerColor(result.Provider.ID()).lipgloss()
+
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(color).
+		Padding(0, 1).
+		Width(panelWidth - 2)
+	if index == m.selected {
+		style = style.Border(lipgloss.ThickBorder())
+	}
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(color).Render(result.Provider.Name())
+	if m.hidden[result.Provider.ID()] {
+		return style.Render(title + tuiDim.Render(i18n.T(" (hidden)")))
+	}
+
+	lines := []string{title}
+	if result.Err != nil {
+		lines = append(lines, "", tuiStyle(activeTheme.high).Render(i18n.T("Error: "))+result.Err.Error())
+		return style.Render(strings.Join(lines, "\n"))
+	}
+
+	for _, window := range result.Quota.Windows() {
+		lines = append(lines, "", tuiBold.Render(i18n.T(window.Name)))
+		lines = append(lines, viewWindowUsage(window))
+
+		if reset := helpers.FormatTimeUntil(window.ResetAt); reset != "unknown" {
+			lines = append(lines, tuiDim.Render(i18n.Sprintf("Resets in %s", i18n.T(reset))))
+		}
+	}
+
+	return style.Render(strings.Join(lines, "\n"))
+}
+
+func viewWindowUsage(window provider.Window) string {
+	if window.UsedPercent != nil {
+		return usageBar(*window.UsedPercent) + " " + percentStyle(*window.UsedPercent).Render(formatPercent(*window.UsedPercent)+"%")
+	}
+
+	if window.Used == nil && window.Remaining != nil {
+		return i18n.Sprintf("Remaining %s", formatNumber(*window.Remaining))
+	}
+
+	if window.Used == nil {
+		return tuiDim.Render(i18n.T("no data"))
+	}
+
+	if window.Limit != nil {
+		return i18n.Sprintf("Used %s of %s", formatNumber(*window.Used), formatNumber(*window.Limit))
+	}
+
+	return i18n.Sprintf("Used %s", formatNumber(*window.Used))
+}
+
+func usageBar(percent float64) string {
+	filled := int(math.Round(helpers.ClampPercent(percent) / 100 * barWidth))
+	return percentStyle(percent).Render(strings.Repeat("█", filled)) +
+		tuiEmpty.Render(strings.Repeat("░", barWidth-filled))
+}
+
+// percentStyle colors percents as colorPercent does in the report.
+func percentStyle(percent float64) lipgloss.Style {
+	return tuiStyle(activeTheme.severity(percent))
+}
+
+// viewJSON shows the raw quota of the selected provider, clipped to the
+// terminal height.
+func (m *dashboard) viewJSON() string {
+	result := m.results[m.selected]
+
+	var content string
+	if result.Err != nil {
+		content = tuiStyle(activeTheme.high).Render(i18n.T("Error: ")) + result.Err.Error()
+	} else {
+		raw, err := json.MarshalIndent(result.Quota, "", "  ")
+		if err != nil {
+			content = tuiStyle(activeTheme.high).Render(i18n.T("Error: ")) + err.Error()
+		} else {
+			content = string(raw)
+		}
+	}
+
+	lines := strings.Split(content, "\n")
+	// Header, footer, blank separators and the box border take 8 lines.
+	visible := max(m.height-8, 1)
+	m.jsonOffset = min(m.jsonOffset, max(len(lines)-visible, 0))
+	lines = lines[m.jsonOffset:min(m.jsonOffset+visible, len(lines))]
+
+	title := tuiBold.Render(i18n.Sprintf("%s raw quota", result.Provider.Name()))
+	return tuiJSONBox.Render(title + "\n" + strings.Join(lines, "\n"))
+}
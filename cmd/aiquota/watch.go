@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/i18n"
+	"github.com/varavelio/tinta"
+)
+
+// clearScreen moves the cursor home and clears the terminal so each refresh
+// redraws the report in place.
+const clearScreen = "\x1b[H\x1b[2J"
+
+func runWatch(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("aiquota watch", flag.ContinueOnError)
+	interval := flags.Duration("interval", 60*time.Second, "time between refreshes")
+	fetch := addFetchFlags(flags)
+	alerts := addAlertFlags(flags)
+	output := addOutputFlags(flags)
+	cfg, err := parseFlagsConfig(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if err := output.configure(cfg); err != nil {
+		return err
+	}
+	alerts.budgets = output.budgets
+	output.apply()
+
+	if *interval <= 0 {
+		return fmt.Errorf("interval must be greater than zero")
+	}
+
+	creds, err := fetch.credentials()
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	for {
+		fetchCtx, cancel := fetch.context(ctx)
+		results, err := fetchQuotas(fetchCtx, creds, fetch)
+		cancel()
+		output.fetchedAt, output.fromCache = fetch.fetchedAt, fetch.fromCache
+		if err == nil {
+			fetch.record(results)
+			alerts.dispatch(ctx, results)
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if !output.isPlain() {
+			fmt.Print(clearScreen)
+		}
+		if err != nil {
+			fmt.Println(themeText(activeTheme.high).Bold().String(i18n.Sprintf("Error: %v", err)))
+		} else {
+			fmt.Println(output.render(results))
+		}
+
+		fmt.Println()
+		fmt.Println(tinta.Text().Dim().String(asciiText(i18n.Sprintf(
+			"Updated %s · refreshing every %s · press Ctrl+C to exit",
+			time.Now().Format("15:04:05"),
+			interval.String(),
+		))))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
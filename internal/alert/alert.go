@@ -0,0 +1,315 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/internal/i18n"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// DefaultLevels are the used percents that trigger an alert by default.
+var DefaultLevels = []float64{75, 90, 100}
+
+// EventKind identifies what triggered an alert.
+type EventKind string
+
+const (
+	// ThresholdCrossed fires when a window reaches a configured level.
+	ThresholdCrossed EventKind = "threshold_crossed"
+	// WindowReset fires when a window that crossed a level starts a new
+	// period.
+	WindowReset EventKind = "reset_detected"
+	// FetchFailed fires when a provider starts failing. Only trackers with
+	// Failures set emit it.
+	FetchFailed EventKind = "fetch_failed"
+	// UsageSpike fires when a window is used much faster than its trailing
+	// average. Only a SpikeDetector emits it.
+	UsageSpike EventKind = "usage_spike"
+)
+
+// Event describes a single alert.
+type Event struct {
+	Kind         EventKind `json:"kind"`
+	ProviderID   string    `json:"providerId"`
+	ProviderName string    `json:"providerName"`
+	WindowID     string    `json:"windowId"`
+	WindowName   string    `json:"windowName"`
+	UsedPercent  float64   `json:"usedPercent"`
+	Level        float64   `json:"level"`
+	ResetAt      string    `json:"resetAt"`
+	Error        string    `json:"error,omitempty"`
+	// RatePerHour and TrailingRatePerHour are the current and trailing usage
+	// rates of a UsageSpike, in percent per hour.
+	RatePerHour         float64 `json:"ratePerHour,omitempty"`
+	TrailingRatePerHour float64 `json:"trailingRatePerHour,omitempty"`
+}
+
+// Title returns a short summary of the event.
+func (e Event) Title() string {
+	switch e.Kind {
+	case WindowReset:
+		return i18n.Sprintf("%s quota reset", e.ProviderName)
+	case FetchFailed:
+		return i18n.Sprintf("%s quota fetch failed", e.ProviderName)
+	case UsageSpike:
+		return i18n.Sprintf("%s usage spike", e.ProviderName)
+	}
+
+	return i18n.Sprintf("%s quota at %s%%", e.ProviderName, helpers.FormatFloat(e.UsedPercent))
+}
+
+// Message returns a one-line description of the event.
+func (e Event) Message() string {
+	switch e.Kind {
+	case WindowReset:
+		return i18n.Sprintf("%s %s reset (used %s%%)", e.ProviderName, i18n.T(e.WindowName), helpers.FormatFloat(e.UsedPercent))
+	case FetchFailed:
+		return i18n.Sprintf("%s could not be queried: %s", e.ProviderName, e.Error)
+	case UsageSpike:
+		trailing := i18n.Sprintf("its trailing %s%%/h", helpers.FormatFloat(e.TrailingRatePerHour))
+		if e.TrailingRatePerHour <= 0 {
+			trailing = i18n.T("an idle window")
+		}
+
+		return i18n.Sprintf(
+			"%s %s is burning %s%%/h, up from %s (used %s%%)",
+			e.ProviderName,
+			i18n.T(e.WindowName),
+			helpers.FormatFloat(e.RatePerHour),
+			trailing,
+			helpers.FormatFloat(e.UsedPercent),
+		)
+	}
+
+	message := i18n.Sprintf(
+		"%s %s crossed %s%% (used %s%%)",
+		e.ProviderName,
+		i18n.T(e.WindowName),
+		helpers.FormatFloat(e.Level),
+		helpers.FormatFloat(e.UsedPercent),
+	)
+
+	if resetIn := helpers.FormatTimeUntil(e.ResetAt); resetIn != "unknown" {
+		message += i18n.Sprintf(", resets in %s", i18n.T(resetIn))
+	}
+
+	return message
+}
+
+// Sink delivers alert events somewhere.
+type Sink interface {
+	// Name identifies the sink in error messages.
+	Name() string
+	// Send delivers the events.
+	Send(ctx context.Context, events []Event) error
+}
+
+// ReportSink is a sink that also receives the results the events were
+// evaluated from, including providers that failed.
+type ReportSink interface {
+	Sink
+	// SendWithResults delivers the events along with the results.
+	SendWithResults(ctx context.Context, events []Event, results []provider.Result) error
+}
+
+// SendAll delivers events to every sink concurrently and joins the errors.
+func SendAll(ctx context.Context, sinks []Sink, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	for _, sink := range sinks {
+		wg.Go(func() {
+			if err := sink.Send(ctx, events); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), httpclient.RedactError(err)))
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// windowState is what the tracker remembers about a window between runs.
+type windowState struct {
+	ResetAt     string  `json:"resetAt"`
+	Level       float64 `json:"level"`
+	UsedPercent float64 `json:"usedPercent"`
+	// Failed is set on the provider entry, keyed by provider ID alone,
+	// while the provider keeps failing.
+	Failed bool `json:"failed,omitempty"`
+}
+
+// Tracker turns results into events, remembering which levels already fired
+// for each window so an alert is only sent once per reset period.
+//
+// A window starts a new period when its reset time changes and its usage
+// drops. Windows that report their reset relative to the current time move
+// their reset time on every run, so usage has to confirm the reset.
+type Tracker struct {
+	// Failures adds a FetchFailed event when a provider that was answering
+	// starts failing, then stays quiet until it recovers.
+	Failures bool
+
+	path   string
+	levels []float64
+}
+
+// NewTracker returns a tracker whose state is stored under the given
+// namespace, so independent alert commands do not silence each other.
+func NewTracker(namespace string, levels []float64) (*Tracker, error) {
+	dir, err := helpers.StateDir()
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := slices.Clone(levels)
+	slices.Sort(sorted)
+
+	return &Tracker{
+		path:   filepath.Join(dir, "alerts-"+namespace+".json"),
+		levels: sorted,
+	}, nil
+}
+
+// Evaluate compares results with the stored state, returns the new events and
+// persists the updated state.
+func (t *Tracker) Evaluate(results []provider.Result) ([]Event, error) {
+	state, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	for _, result := range results {
+		id := result.Provider.ID()
+		if result.Err != nil {
+			if t.Failures && !state[id].Failed {
+				events = append(events, Event{
+					Kind:         FetchFailed,
+					ProviderID:   id,
+					ProviderName: result.Provider.Name(),
+					Error:        result.Err.Error(),
+				})
+				state[id] = windowState{Failed: true}
+			}
+			continue
+		}
+		delete(state, id)
+
+		for _, window := range result.Quota.Windows() {
+			if window.UsedPercent == nil {
+				continue
+			}
+
+			key := result.Provider.ID() + "/" + window.ID
+			previous, seen := state[key]
+			if seen && previous.ResetAt != window.ResetAt && *window.UsedPercent < previous.UsedPercent {
+				if previous.Level > 0 {
+					events = append(events, Event{
+						Kind:         WindowReset,
+						ProviderID:   result.Provider.ID(),
+						ProviderName: result.Provider.Name(),
+						WindowID:     window.ID,
+						WindowName:   window.Name,
+						UsedPercent:  *window.UsedPercent,
+						ResetAt:      window.ResetAt,
+					})
+				}
+
+				previous = windowState{}
+			}
+
+			crossed := t.highestLevel(*window.UsedPercent)
+			if crossed > previous.Level {
+				events = append(events, Event{
+					Kind:         ThresholdCrossed,
+					ProviderID:   result.Provider.ID(),
+					ProviderName: result.Provider.Name(),
+					WindowID:     window.ID,
+					WindowName:   window.Name,
+					UsedPercent:  *window.UsedPercent,
+					Level:        crossed,
+					ResetAt:      window.ResetAt,
+				})
+			}
+
+			state[key] = windowState{
+				ResetAt:     window.ResetAt,
+				Level:       max(previous.Level, crossed),
+				UsedPercent: *window.UsedPercent,
+			}
+		}
+	}
+
+	if err := t.save(state); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// highestLevel returns the highest configured level reached by usedPercent,
+// or zero when none is reached.
+func (t *Tracker) highestLevel(usedPercent float64) float64 {
+	reached := 0.0
+	for _, level := range t.levels {
+		if usedPercent >= level {
+			reached = level
+		}
+	}
+
+	return reached
+}
+
+func (t *Tracker) load() (map[string]windowState, error) {
+	state := map[string]windowState{}
+
+	content, err := os.ReadFile(t.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alert state: %w", err)
+	}
+
+	if err := json.Unmarshal(content, &state); err != nil {
+		return map[string]windowState{}, nil
+	}
+
+	return state, nil
+}
+
+func (t *Tracker) save(state map[string]windowState) error {
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode alert state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create alert state directory: %w", err)
+	}
+
+	if err := os.WriteFile(t.path, content, 0o600); err != nil {
+		return fmt.Errorf("failed to write alert state: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Desktop shows native desktop notifications using notify-send on Linux,
+// osascript on macOS and a PowerShell toast on Windows.
+type Desktop struct{}
+
+// Name implements Sink.
+func (Desktop) Name() string { return "desktop" }
+
+// Send implements Sink.
+func (Desktop) Send(ctx context.Context, events []Event) error {
+	for _, event := range events {
+		if err := notify(ctx, event.Title(), event.Message()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func notify(ctx context.Context, title string, message string) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.CommandContext(ctx, "notify-send", "--app-name=aiquota", title, message)
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+	case "windows":
+		cmd = exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", windowsToastScript(title, message))
+	default:
+		return fmt.Errorf("desktop notifications are not supported on %s", runtime.GOOS)
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if detail := strings.TrimSpace(string(output)); detail != "" {
+			return fmt.Errorf("failed to send desktop notification: %w: %s", err, detail)
+		}
+
+		return fmt.Errorf("failed to send desktop notification: %w", err)
+	}
+
+	return nil
+}
+
+func appleScriptString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+func windowsToastScript(title string, message string) string {
+	escape := strings.NewReplacer("'", "''", "<", "&lt;", ">", "&gt;", "&", "&amp;")
+
+	return strings.Join([]string{
+		"[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null",
+		"[Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime] > $null",
+		"$xml = New-Object Windows.Data.Xml.Dom.XmlDocument",
+		fmt.Sprintf(
+			"$xml.LoadXml('<toast><visual><binding template=\"ToastText02\"><text id=\"1\">%s</text><text id=\"2\">%s</text></binding></visual></toast>')",
+			escape.Replace(title),
+			escape.Replace(message),
+		),
+		"$toast = [Windows.UI.Notifications.ToastNotification]::new($xml)",
+		"[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('aiquota').Show($toast)",
+	}, "; ")
+}
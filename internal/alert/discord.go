@@ -0,0 +1,191 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/internal/i18n"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// discordMaxEmbeds is the number of embeds Discord accepts per message.
+const discordMaxEmbeds = 10
+
+// Embed colors by severity.
+const (
+	discordGreen  = 0x2ecc71
+	discordYellow = 0xf1c40f
+	discordRed    = 0xe74c3c
+	discordBlue   = 0x3498db
+)
+
+// Discord posts to a Discord webhook with one embed per provider.
+type Discord struct {
+	WebhookURL string
+}
+
+type discordEmbed struct {
+	Title     string         `json:"title"`
+	Color     int            `json:"color"`
+	Fields    []discordField `json:"fields"`
+	Timestamp string         `json:"timestamp"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// Name implements Sink.
+func (Discord) Name() string { return "discord" }
+
+// Send implements Sink, grouping the events by provider. Embeds with only
+// reset events are blue; the others take the color of their worst window.
+func (d Discord) Send(ctx context.Context, events []Event) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	index := map[string]int{}
+	var (
+		embeds []discordEmbed
+		worst  []float64
+	)
+
+	for _, event := range events {
+		i, ok := index[event.ProviderID]
+		if !ok {
+			i = len(embeds)
+			index[event.ProviderID] = i
+			embeds = append(embeds, discordEmbed{Title: event.ProviderName, Color: discordBlue, Timestamp: now})
+			worst = append(worst, -1)
+		}
+
+		value := i18n.T("Reset") + "\n" + usageSummary(&event.UsedPercent, event.ResetAt)
+		switch event.Kind {
+		case ThresholdCrossed:
+			value = i18n.Sprintf("Crossed %s%%\n%s", helpers.FormatFloat(event.Level), usageSummary(&event.UsedPercent, event.ResetAt))
+			worst[i] = max(worst[i], event.UsedPercent)
+			embeds[i].Color = severityColor(worst[i])
+		case UsageSpike:
+			value = i18n.Sprintf("Spiking at %s%%/h\n%s", helpers.FormatFloat(event.RatePerHour), usageSummary(&event.UsedPercent, event.ResetAt))
+		}
+
+		embeds[i].Fields = append(embeds[i].Fields, discordField{Name: i18n.T(event.WindowName), Value: value})
+	}
+
+	return d.post(ctx, i18n.T("AI quota alert"), embeds)
+}
+
+// SendReport posts the current quota of every successful result.
+func (d Discord) SendReport(ctx context.Context, results []provider.Result) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	var embeds []discordEmbed
+
+	for _, result := range results {
+		embed := discordEmbed{Title: result.Provider.Name(), Color: discordGreen, Timestamp: now}
+		if result.Err != nil {
+			embed.Color = discordRed
+			embed.Fields = append(embed.Fields, discordField{Name: i18n.T("Error"), Value: result.Err.Error()})
+			embeds = append(embeds, embed)
+			continue
+		}
+
+		worst := 0.0
+		for _, window := range result.Quota.Windows() {
+			if window.UsedPercent != nil {
+				worst = max(worst, *window.UsedPercent)
+			}
+
+			embed.Fields = append(embed.Fields, discordField{
+				Name:   i18n.T(window.Name),
+				Value:  windowSummary(window),
+				Inline: true,
+			})
+		}
+
+		embed.Color = severityColor(worst)
+		embeds = append(embeds, embed)
+	}
+
+	return d.post(ctx, i18n.T("AI quota report"), embeds)
+}
+
+// post sends the embeds, split into as many messages as Discord requires.
+func (d Discord) post(ctx context.Context, content string, embeds []discordEmbed) error {
+	for start := 0; start < len(embeds); start += discordMaxEmbeds {
+		payload, err := json.Marshal(map[string]any{
+			"content": content,
+			"embeds":  embeds[start:min(start+discordMaxEmbeds, len(embeds))],
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode Discord message: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.WebhookURL, bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("failed to create Discord request: %w", err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		response, err := httpclient.Default.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to post Discord message: %w", err)
+		}
+
+		body, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+
+		if response.StatusCode < 200 || response.StatusCode >= 300 {
+			return fmt.Errorf("failed to post Discord message. Status: %d, Response: %s", response.StatusCode, string(body))
+		}
+	}
+
+	return nil
+}
+
+// windowSummary describes a window for a report embed.
+func windowSummary(window provider.Window) string {
+	switch {
+	case window.UsedPercent != nil:
+		return usageSummary(window.UsedPercent, window.ResetAt)
+	case window.Remaining != nil:
+		return i18n.Sprintf("Remaining %s", helpers.FormatFloat(*window.Remaining))
+	case window.Used != nil:
+		return i18n.Sprintf("Used %s", helpers.FormatFloat(*window.Used))
+	default:
+		return i18n.T("no data")
+	}
+}
+
+func usageSummary(usedPercent *float64, resetAt string) string {
+	summary := i18n.Sprintf(
+		"Used %s%% · Remaining %s%%",
+		helpers.FormatFloat(*usedPercent),
+		helpers.FormatFloat(helpers.ClampPercent(100-*usedPercent)),
+	)
+
+	if resetIn := helpers.FormatTimeUntil(resetAt); resetIn != "unknown" {
+		summary += "\n" + i18n.Sprintf("Resets in %s", i18n.T(resetIn))
+	}
+
+	return summary
+}
+
+// severityColor uses the same thresholds as the terminal report.
+func severityColor(usedPercent float64) int {
+	switch {
+	case usedPercent >= 75:
+		return discordRed
+	case usedPercent >= 50:
+		return discordYellow
+	default:
+		return discordGreen
+	}
+}
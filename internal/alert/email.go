@@ -0,0 +1,221 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTP transport security modes.
+const (
+	// TLSStartTLS upgrades a plain connection with STARTTLS, as on port 587.
+	TLSStartTLS = "starttls"
+	// TLSImplicit connects over TLS from the start, as on port 465.
+	TLSImplicit = "tls"
+	// TLSNone sends without encryption, for local relays.
+	TLSNone = "none"
+)
+
+// Email sends alerts and reports over SMTP.
+type Email struct {
+	Host string
+	Port int
+	// TLS is TLSStartTLS, TLSImplicit or TLSNone. Empty means TLSStartTLS.
+	TLS      string
+	Username string
+	Password string
+	From     string
+	To       []string
+	// HTML adds an HTML alternative to the plain text body.
+	HTML bool
+}
+
+// Name implements Sink.
+func (Email) Name() string { return "email" }
+
+// Send implements Sink with a single message listing every event.
+func (e Email) Send(ctx context.Context, events []Event) error {
+	subject := events[0].Title()
+	if len(events) > 1 {
+		subject = fmt.Sprintf("AI quota alert: %d events", len(events))
+	}
+
+	lines := make([]string, 0, len(events))
+	items := make([]string, 0, len(events))
+	for _, event := range events {
+		lines = append(lines, "- "+event.Message())
+		items = append(items, "<li><strong>"+html.EscapeString(event.Title())+"</strong><br>"+html.EscapeString(event.Message())+"</li>")
+	}
+
+	text := "AI quota alert\n\n" + strings.Join(lines, "\n") + "\n"
+	htmlBody := "<!DOCTYPE html>\n<html><body><h2>AI quota alert</h2><ul>" + strings.Join(items, "") + "</ul></body></html>\n"
+
+	return e.SendMessage(ctx, subject, text, htmlBody)
+}
+
+// SendMessage sends a message with a plain text body. The HTML body is only
+// included when HTML is set.
+func (e Email) SendMessage(ctx context.Context, subject string, text string, htmlBody string) error {
+	if !e.HTML {
+		htmlBody = ""
+	}
+
+	message, err := e.compose(subject, text, htmlBody)
+	if err != nil {
+		return fmt.Errorf("failed to compose email: %w", err)
+	}
+
+	if err := e.deliver(ctx, message); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+func (e Email) compose(subject string, text string, htmlBody string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", e.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if htmlBody == "" {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, text); err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", parts.Boundary())
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", htmlBody},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n"))); err != nil {
+		return err
+	}
+
+	return qp.Close()
+}
+
+// deliver runs the SMTP conversation. net/smtp has no context support, so
+// the context deadline is applied to the connection instead.
+func (e Email) deliver(ctx context.Context, message []byte) error {
+	switch e.TLS {
+	case "", TLSStartTLS, TLSImplicit, TLSNone:
+	default:
+		return fmt.Errorf("unknown TLS mode %q, expected starttls, tls or none", e.TLS)
+	}
+
+	address := net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+	tlsConfig := &tls.Config{ServerName: e.Host}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if e.TLS == TLSImplicit {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if e.TLS == "" || e.TLS == TLSStartTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if e.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.Username, e.Password, e.Host)); err != nil {
+			return err
+		}
+	}
+
+	// The envelope takes bare addresses, the headers keep display names.
+	from, err := mail.ParseAddress(e.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender %q: %w", e.From, err)
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+
+	for _, value := range e.To {
+		to, err := mail.ParseAddress(value)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %w", value, err)
+		}
+		if err := client.Rcpt(to.Address); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
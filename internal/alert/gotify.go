@@ -0,0 +1,71 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+)
+
+// Gotify priorities used for events. Gotify clients alert loudly from 8.
+const (
+	gotifyNormal = 5
+	gotifyHigh   = 8
+)
+
+// Gotify pushes one message per event to a self-hosted Gotify server.
+type Gotify struct {
+	// URL is the Gotify server, Token an application token.
+	URL   string
+	Token string
+}
+
+// Name implements Sink.
+func (Gotify) Name() string { return "gotify" }
+
+// Send implements Sink.
+func (g Gotify) Send(ctx context.Context, events []Event) error {
+	endpoint := strings.TrimRight(g.URL, "/") + "/message"
+
+	for _, event := range events {
+		priority := gotifyNormal
+		if event.Kind == ThresholdCrossed && event.Level >= 100 {
+			priority = gotifyHigh
+		}
+
+		payload, err := json.Marshal(map[string]any{
+			"title":    event.Title(),
+			"message":  event.Message(),
+			"priority": priority,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode Gotify message: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("failed to create Gotify request: %w", err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Gotify-Key", g.Token)
+
+		response, err := httpclient.Default.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to send Gotify message: %w", err)
+		}
+
+		body, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+		if response.StatusCode < 200 || response.StatusCode >= 300 {
+			return fmt.Errorf("failed to send Gotify message. Status: %d, Response: %s", response.StatusCode, string(body))
+		}
+	}
+
+	return nil
+}
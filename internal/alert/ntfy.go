@@ -0,0 +1,83 @@
+package alert
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+)
+
+// DefaultNtfyServer is the public ntfy server.
+const DefaultNtfyServer = "https://ntfy.sh"
+
+// Ntfy publishes one push notification per event to an ntfy topic.
+type Ntfy struct {
+	// Server is the ntfy server URL. Empty means DefaultNtfyServer.
+	Server string
+	Topic  string
+	// Priority is an ntfy priority name or number. Empty uses "high" for
+	// windows at 100% and the server default otherwise.
+	Priority string
+	// Token is an access token for protected topics.
+	Token string
+}
+
+// Name implements Sink.
+func (Ntfy) Name() string { return "ntfy" }
+
+// Send implements Sink.
+func (n Ntfy) Send(ctx context.Context, events []Event) error {
+	endpoint := strings.TrimRight(cmp.Or(n.Server, DefaultNtfyServer), "/") + "/" + n.Topic
+
+	for _, event := range events {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(event.Message()))
+		if err != nil {
+			return fmt.Errorf("failed to create ntfy request: %w", err)
+		}
+
+		tags, priority := "warning", n.Priority
+		switch {
+		case event.Kind == WindowReset:
+			tags = "recycle"
+		case event.Kind == UsageSpike:
+			tags = "chart_with_upwards_trend"
+		case event.Level >= 100:
+			tags = "rotating_light"
+			priority = cmp.Or(priority, "high")
+		}
+
+		req.Header.Set("Title", event.Title())
+		req.Header.Set("Tags", tags)
+		if priority != "" {
+			req.Header.Set("Priority", priority)
+		}
+		if n.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+n.Token)
+		}
+
+		if err := n.publish(req); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (n Ntfy) publish(req *http.Request) error {
+	response, err := httpclient.Default.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to publish ntfy message: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(response.Body)
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("failed to publish ntfy message. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	return nil
+}
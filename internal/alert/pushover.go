@@ -0,0 +1,64 @@
+package alert
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+)
+
+// DefaultPushoverAPI is the Pushover message endpoint.
+const DefaultPushoverAPI = "https://api.pushover.net/1/messages.json"
+
+// Pushover sends one notification per event through the Pushover API.
+type Pushover struct {
+	// APIURL is the message endpoint. Empty means DefaultPushoverAPI.
+	APIURL string
+	// Token is the application token, User the user or group key.
+	Token string
+	User  string
+}
+
+// Name implements Sink.
+func (Pushover) Name() string { return "pushover" }
+
+// Send implements Sink. Windows at 100% are sent with high priority, which
+// bypasses the recipient's quiet hours.
+func (p Pushover) Send(ctx context.Context, events []Event) error {
+	for _, event := range events {
+		form := url.Values{
+			"token":   {p.Token},
+			"user":    {p.User},
+			"title":   {event.Title()},
+			"message": {event.Message()},
+		}
+		if event.Kind == ThresholdCrossed && event.Level >= 100 {
+			form.Set("priority", "1")
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, cmp.Or(p.APIURL, DefaultPushoverAPI), strings.NewReader(form.Encode()))
+		if err != nil {
+			return fmt.Errorf("failed to create Pushover request: %w", err)
+		}
+
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		response, err := httpclient.Default.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to send Pushover message: %w", err)
+		}
+
+		body, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+		if response.StatusCode < 200 || response.StatusCode >= 300 {
+			return fmt.Errorf("failed to send Pushover message. Status: %d, Response: %s", response.StatusCode, string(body))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+)
+
+// Slack posts events to a Slack incoming webhook as a single message.
+type Slack struct {
+	WebhookURL string
+}
+
+// Name implements Sink.
+func (Slack) Name() string { return "slack" }
+
+// Send implements Sink.
+func (s Slack) Send(ctx context.Context, events []Event) error {
+	payload, err := json.Marshal(slackMessage(events))
+	if err != nil {
+		return fmt.Errorf("failed to encode Slack message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.WebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create Slack request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	response, err := httpclient.Default.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post Slack message: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(response.Body)
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("failed to post Slack message. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// slackMessage builds a Block Kit message with one section per event. The
+// plain text is used for notifications and clients without blocks.
+func slackMessage(events []Event) map[string]any {
+	titles := make([]string, 0, len(events))
+	blocks := []map[string]any{
+		{
+			"type": "header",
+			"text": map[string]any{"type": "plain_text", "text": "AI quota alert"},
+		},
+	}
+
+	for _, event := range events {
+		titles = append(titles, event.Title())
+
+		emoji := ":warning:"
+		switch {
+		case event.Kind == WindowReset:
+			emoji = ":recycle:"
+		case event.Kind == UsageSpike:
+			emoji = ":chart_with_upwards_trend:"
+		case event.Level >= 100:
+			emoji = ":rotating_light:"
+		}
+
+		blocks = append(blocks, map[string]any{
+			"type": "section",
+			"text": map[string]any{
+				"type": "mrkdwn",
+				"text": fmt.Sprintf("%s *%s*\n%s", emoji, slackEscape(event.Title()), slackEscape(event.Message())),
+			},
+		})
+	}
+
+	return map[string]any{
+		"text":   strings.Join(titles, ", "),
+		"blocks": blocks,
+	}
+}
+
+// slackEscape escapes the characters Slack treats as control sequences in
+// mrkdwn text.
+func slackEscape(value string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(value)
+}
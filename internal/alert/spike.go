@@ -0,0 +1,126 @@
+package alert
+
+import (
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+const (
+	// SpikeRecent is the span the current usage rate is measured over, long
+	// enough that a single request does not read as a spike.
+	SpikeRecent = 15 * time.Minute
+	// SpikeTrailing is how far back the trailing rate reaches.
+	SpikeTrailing = 6 * time.Hour
+	// minTrailing is the shortest trailing span a spike is measured against.
+	minTrailing = time.Hour
+)
+
+// spikeSample is a used percent observed at a point in time.
+type spikeSample struct {
+	at          time.Time
+	usedPercent float64
+}
+
+// SpikeDetector fires a UsageSpike event when a window is used much faster
+// than its trailing average, such as an agent stuck in a loop. Samples are
+// kept in memory, so it suits long running processes like the daemon.
+type SpikeDetector struct {
+	// Factor is how many times the trailing rate the current rate must
+	// reach.
+	Factor float64
+	// MinRate is the lowest current rate, in percent per hour, that counts
+	// as a spike, so light use of an idle window does not fire.
+	MinRate float64
+
+	samples map[string][]spikeSample
+	spiking map[string]bool
+}
+
+// Evaluate adds the results observed at the given time and returns the
+// windows that started spiking. A spiking window fires again only after its
+// rate went back to normal.
+func (d *SpikeDetector) Evaluate(at time.Time, results []provider.Result) []Event {
+	if d.samples == nil {
+		d.samples, d.spiking = map[string][]spikeSample{}, map[string]bool{}
+	}
+
+	var events []Event
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+
+		for _, window := range result.Quota.Windows() {
+			if window.UsedPercent == nil {
+				continue
+			}
+
+			key := result.Provider.ID() + "/" + window.ID
+			current := spikeSample{at: at, usedPercent: *window.UsedPercent}
+
+			// Rates are only comparable within a reset period.
+			samples := d.samples[key]
+			if len(samples) > 0 && current.usedPercent < samples[len(samples)-1].usedPercent {
+				samples = nil
+			}
+
+			cutoff := at.Add(-SpikeTrailing - SpikeRecent)
+			for len(samples) > 0 && samples[0].at.Before(cutoff) {
+				samples = samples[1:]
+			}
+			samples = append(samples, current)
+			d.samples[key] = samples
+
+			rate, trailing, ok := spikeRates(samples)
+			if !ok {
+				continue
+			}
+
+			spiking := rate >= d.MinRate && rate >= d.Factor*trailing
+			if spiking && !d.spiking[key] {
+				events = append(events, Event{
+					Kind:                UsageSpike,
+					ProviderID:          result.Provider.ID(),
+					ProviderName:        result.Provider.Name(),
+					WindowID:            window.ID,
+					WindowName:          window.Name,
+					UsedPercent:         current.usedPercent,
+					ResetAt:             window.ResetAt,
+					RatePerHour:         rate,
+					TrailingRatePerHour: trailing,
+				})
+			}
+			d.spiking[key] = spiking
+		}
+	}
+
+	return events
+}
+
+// spikeRates returns the rate over the last SpikeRecent and the trailing rate
+// before it, in percent per hour, from samples ordered oldest first. It
+// returns false until the samples span enough time.
+func spikeRates(samples []spikeSample) (rate float64, trailing float64, ok bool) {
+	current := samples[len(samples)-1]
+
+	split := -1
+	for i := len(samples) - 2; i >= 0; i-- {
+		if !samples[i].at.After(current.at.Add(-SpikeRecent)) {
+			split = i
+			break
+		}
+	}
+	if split < 0 {
+		return 0, 0, false
+	}
+
+	oldest, recent := samples[0], samples[split]
+	if recent.at.Sub(oldest.at) < minTrailing {
+		return 0, 0, false
+	}
+
+	rate = (current.usedPercent - recent.usedPercent) / current.at.Sub(recent.at).Hours()
+	trailing = (recent.usedPercent - oldest.usedPercent) / recent.at.Sub(oldest.at).Hours()
+	return rate, trailing, true
+}
@@ -0,0 +1,96 @@
+package alert
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+)
+
+// DefaultTelegramAPI is the Telegram Bot API server.
+const DefaultTelegramAPI = "https://api.telegram.org"
+
+// Telegram sends events to a chat through a Telegram bot as a single
+// message.
+type Telegram struct {
+	// BaseURL is the Bot API server. Empty means DefaultTelegramAPI.
+	BaseURL string
+	Token   string
+	ChatID  string
+}
+
+// Name implements Sink.
+func (Telegram) Name() string { return "telegram" }
+
+// Send implements Sink.
+func (t Telegram) Send(ctx context.Context, events []Event) error {
+	payload, err := json.Marshal(map[string]any{
+		"chat_id":    t.ChatID,
+		"text":       telegramMessage(events),
+		"parse_mode": "HTML",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode Telegram message: %w", err)
+	}
+
+	endpoint := strings.TrimRight(cmp.Or(t.BaseURL, DefaultTelegramAPI), "/") + "/bot" + t.Token + "/sendMessage"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create Telegram request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	response, err := httpclient.Default.Do(req)
+	if err != nil {
+		// The bot token is part of the URL, keep it out of the error.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to send Telegram message: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(response.Body)
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("failed to send Telegram message. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// telegramMessage formats the events as Telegram HTML, one paragraph per
+// event with its title in bold.
+func telegramMessage(events []Event) string {
+	paragraphs := []string{"<b>AI quota alert</b>"}
+	for _, event := range events {
+		emoji := "⚠️"
+		switch {
+		case event.Kind == WindowReset:
+			emoji = "♻️"
+		case event.Kind == UsageSpike:
+			emoji = "📈"
+		case event.Level >= 100:
+			emoji = "🚨"
+		}
+
+		paragraphs = append(paragraphs, fmt.Sprintf(
+			"%s <b>%s</b>\n%s",
+			emoji,
+			html.EscapeString(event.Title()),
+			html.EscapeString(event.Message()),
+		))
+	}
+
+	return strings.Join(paragraphs, "\n\n")
+}
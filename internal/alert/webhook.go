@@ -0,0 +1,84 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/aiquota"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// Webhook posts every event as JSON to an arbitrary URL, together with the
+// report it was evaluated from when available.
+type Webhook struct {
+	URL string
+	// Secret, when set, signs each body with HMAC-SHA256 in the
+	// X-Aiquota-Signature header as "sha256=<hex>".
+	Secret string
+}
+
+// webhookPayload is the body of a webhook delivery. Report is omitted when
+// the sink is used by an [[alert]] rule, which only passes events.
+type webhookPayload struct {
+	Event  EventKind       `json:"event"`
+	Alert  Event           `json:"alert"`
+	Report *aiquota.Report `json:"report,omitempty"`
+}
+
+// Name implements Sink.
+func (Webhook) Name() string { return "webhook" }
+
+// Send implements Sink.
+func (w Webhook) Send(ctx context.Context, events []Event) error {
+	return w.post(ctx, events, nil)
+}
+
+// SendWithResults implements ReportSink.
+func (w Webhook) SendWithResults(ctx context.Context, events []Event, results []provider.Result) error {
+	report := aiquota.NewReport(time.Now(), results)
+	return w.post(ctx, events, &report)
+}
+
+func (w Webhook) post(ctx context.Context, events []Event, report *aiquota.Report) error {
+	for _, event := range events {
+		payload, err := json.Marshal(webhookPayload{Event: event.Kind, Alert: event, Report: report})
+		if err != nil {
+			return fmt.Errorf("failed to encode webhook payload: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("failed to create webhook request: %w", err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Aiquota-Event", string(event.Kind))
+		if w.Secret != "" {
+			mac := hmac.New(sha256.New, []byte(w.Secret))
+			mac.Write(payload)
+			req.Header.Set("X-Aiquota-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		}
+
+		response, err := httpclient.Default.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to post webhook: %w", err)
+		}
+
+		body, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+		if response.StatusCode < 200 || response.StatusCode >= 300 {
+			return fmt.Errorf("failed to post webhook. Status: %d, Response: %s", response.StatusCode, string(body))
+		}
+	}
+
+	return nil
+}
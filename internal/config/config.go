@@ -0,0 +1,390 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config holds defaults read from config.toml. Every setting mirrors a
+// command line flag, and flags given on the command line take precedence.
+type Config struct {
+	AuthFile        string   `toml:"auth_file"`
+	Providers       []string `toml:"providers"`
+	Timeout         string   `toml:"timeout"`
+	ProviderTimeout string   `toml:"provider_timeout"`
+	FailAt          *float64 `toml:"fail_at"`
+	Interval        string   `toml:"interval"`
+	NoHistory       *bool    `toml:"no_history"`
+	LogCSV          string   `toml:"log_csv"`
+	MaxAge          string   `toml:"max_age"`
+	Retries         *int     `toml:"retries"`
+	RetryBackoff    string   `toml:"retry_backoff"`
+	Statsd          string   `toml:"statsd"`
+	StatsdTags      *bool    `toml:"statsd_tags"`
+	Ping            string   `toml:"ping"`
+
+	// Format is "plain" or any --format value.
+	Format string `toml:"format"`
+	// Template is the --template text used by format "template".
+	Template string `toml:"template"`
+	// Color is "auto", "always" or "never".
+	Color string `toml:"color"`
+	// Theme is any --theme value, and Colors overrides its colors, keyed by
+	// provider ID or by title, group, other, low, medium or high, and
+	// written as a [colors] table.
+	Theme  string            `toml:"theme"`
+	Colors map[string]string `toml:"colors"`
+	// Sort is "used", "name" or "reset".
+	Sort string `toml:"sort"`
+	// Lang is any --lang value.
+	Lang string `toml:"lang"`
+	// TZ is an IANA time zone such as "Europe/Madrid", or "UTC".
+	TZ string `toml:"tz"`
+	// TimeFormat is "24h", "12h" or "rfc3339".
+	TimeFormat string `toml:"time_format"`
+	// ResetPrecision is "seconds", "minutes" or "coarse".
+	ResetPrecision string `toml:"reset_precision"`
+	ResetFull      *bool  `toml:"reset_full"`
+	NoDiff         *bool  `toml:"no_diff"`
+	Compact        *bool  `toml:"compact"`
+	BarWidth       *int   `toml:"bar_width"`
+	ASCII          *bool  `toml:"ascii"`
+
+	Notify         *bool     `toml:"notify"`
+	NotifyLevels   []float64 `toml:"notify_levels"`
+	SlackWebhook   string    `toml:"slack_webhook"`
+	DiscordWebhook string    `toml:"discord_webhook"`
+	NtfyServer     string    `toml:"ntfy_server"`
+	NtfyTopic      string    `toml:"ntfy_topic"`
+	NtfyPriority   string    `toml:"ntfy_priority"`
+	NtfyToken      string    `toml:"ntfy_token"`
+	TelegramToken  string    `toml:"telegram_token"`
+	TelegramChat   string    `toml:"telegram_chat_id"`
+	PushoverToken  string    `toml:"pushover_token"`
+	PushoverUser   string    `toml:"pushover_user"`
+	PushoverLevels []float64 `toml:"pushover_levels"`
+	GotifyURL      string    `toml:"gotify_url"`
+	GotifyToken    string    `toml:"gotify_token"`
+	GotifyLevels   []float64 `toml:"gotify_levels"`
+	WebhookURL     string    `toml:"webhook_url"`
+	WebhookSecret  string    `toml:"webhook_secret"`
+
+	// SMTPHost to EmailSummary configure the email sink. SMTPTLS is
+	// "starttls", "tls" or "none"; EmailSummary is the daemon schedule of
+	// the summary email.
+	SMTPHost     string   `toml:"smtp_host"`
+	SMTPPort     int      `toml:"smtp_port"`
+	SMTPTLS      string   `toml:"smtp_tls"`
+	SMTPUser     string   `toml:"smtp_user"`
+	SMTPPassword string   `toml:"smtp_password"`
+	EmailFrom    string   `toml:"email_from"`
+	EmailTo      []string `toml:"email_to"`
+	EmailHTML    *bool    `toml:"email_html"`
+	EmailSummary string   `toml:"email_summary"`
+
+	// InfluxURL and InfluxToken push daemon snapshots to InfluxDB.
+	InfluxURL   string `toml:"influx_url"`
+	InfluxToken string `toml:"influx_token"`
+
+	// OTLP exports daemon metrics to the OpenTelemetry collector set by the
+	// standard OTEL_EXPORTER_OTLP_* environment variables.
+	OTLP *bool `toml:"otlp"`
+
+	// SpikeFactor and SpikeMinRate tune the daemon usage spike alerts.
+	SpikeFactor  *float64 `toml:"spike_factor"`
+	SpikeMinRate *float64 `toml:"spike_min_rate"`
+
+	// Schedule, Listen, Token and CacheTTL configure serve and daemon.
+	Schedule string `toml:"schedule"`
+	Listen   string `toml:"listen"`
+	Token    string `toml:"token"`
+	CacheTTL string `toml:"cache_ttl"`
+
+	// Provider holds per-provider settings keyed by provider ID.
+	Provider map[string]ProviderConfig `toml:"provider"`
+
+	// Pricing adds or overrides the model prices of cost estimates, keyed by
+	// model name or prefix and written as [pricing."gpt-4.1"] tables.
+	Pricing map[string]PriceConfig `toml:"pricing"`
+
+	// Alerts are the alert rules evaluated by the daemon, written as
+	// [[alert]] tables.
+	Alerts []AlertRule `toml:"alert"`
+
+	// Budgets are monthly budgets shown in the report and alerted on like
+	// windows, written as [[budget]] tables.
+	Budgets []Budget `toml:"budget"`
+}
+
+// Budget caps what one provider may use in a calendar month. Exactly one of
+// Requests, Tokens or Dollars is set. Window is the ID of the window whose
+// usage counts; it defaults to "tokens" for Tokens and to "cost" for
+// Dollars, which falls back to the estimated cost, and Requests needs it.
+type Budget struct {
+	Name     string   `toml:"name"`
+	Provider string   `toml:"provider"`
+	Window   string   `toml:"window"`
+	Requests *float64 `toml:"requests"`
+	Tokens   *float64 `toml:"tokens"`
+	Dollars  *float64 `toml:"dollars"`
+}
+
+// AlertRule sends alerts for a subset of windows to a set of sinks. Empty
+// Providers or Windows match everything; empty Levels use the defaults.
+type AlertRule struct {
+	Name      string    `toml:"name"`
+	Providers []string  `toml:"providers"`
+	Windows   []string  `toml:"windows"`
+	Levels    []float64 `toml:"levels"`
+	// Sinks are "desktop", "slack", "discord", "ntfy", "telegram",
+	// "pushover", "gotify", "email" or "webhook". They use their own
+	// settings, such as slack_webhook or ntfy_topic.
+	Sinks []string `toml:"sinks"`
+}
+
+// ProviderConfig holds the settings of a single provider.
+type ProviderConfig struct {
+	Timeout string   `toml:"timeout"`
+	FailAt  *float64 `toml:"fail_at"`
+	BaseURL string   `toml:"base_url"`
+	// Label is shown next to the provider name and Group gathers providers
+	// under a heading in the report, such as "work" and "personal". Neither
+	// may contain commas.
+	Label string `toml:"label"`
+	Group string `toml:"group"`
+}
+
+// PriceConfig is the price of a model in USD, per million tokens or, for
+// Request, per tool call. Unset fields keep the default price.
+type PriceConfig struct {
+	Input       *float64 `toml:"input"`
+	CachedInput *float64 `toml:"cached_input"`
+	Output      *float64 `toml:"output"`
+	Request     *float64 `toml:"request"`
+}
+
+// DefaultPath returns $AIQUOTA_CONFIG, or config.toml in the user config
+// directory ($XDG_CONFIG_HOME/aiquota or ~/.config/aiquota on Linux).
+func DefaultPath() (string, error) {
+	if env := os.Getenv("AIQUOTA_CONFIG"); env != "" {
+		return env, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve config directory: %w", err)
+	}
+
+	return filepath.Join(dir, "aiquota", "config.toml"), nil
+}
+
+// Load reads the config file at path. A missing file yields an empty config
+// unless required is set, which is the case for an explicit --config.
+func Load(path string, required bool) (Config, error) {
+	var cfg Config
+	meta, err := toml.DecodeFile(path, &cfg)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return Config{}, nil
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return Config{}, fmt.Errorf("unknown setting %q in config file %s", undecoded[0].String(), path)
+	}
+
+	return cfg, nil
+}
+
+// FlagValues returns the config as flag name and value pairs, in the syntax
+// each flag accepts on the command line.
+func (c Config) FlagValues() map[string]string {
+	values := map[string]string{}
+	set := func(name string, value string) {
+		if value != "" {
+			values[name] = value
+		}
+	}
+
+	set("auth-file", c.AuthFile)
+	set("provider", strings.Join(c.Providers, ","))
+	set("timeout", c.Timeout)
+	set("interval", c.Interval)
+	set("log-csv", c.LogCSV)
+	set("max-age", c.MaxAge)
+	set("notify-levels", joinFloats(c.NotifyLevels))
+	set("slack-webhook", c.SlackWebhook)
+	set("discord-webhook", c.DiscordWebhook)
+	set("ntfy-server", c.NtfyServer)
+	set("ntfy-topic", c.NtfyTopic)
+	set("ntfy-priority", c.NtfyPriority)
+	set("ntfy-token", c.NtfyToken)
+	set("telegram-token", c.TelegramToken)
+	set("telegram-chat", c.TelegramChat)
+	set("pushover-token", c.PushoverToken)
+	set("pushover-user", c.PushoverUser)
+	set("pushover-levels", joinFloats(c.PushoverLevels))
+	set("gotify-url", c.GotifyURL)
+	set("gotify-token", c.GotifyToken)
+	set("gotify-levels", joinFloats(c.GotifyLevels))
+	set("webhook-url", c.WebhookURL)
+	set("webhook-secret", c.WebhookSecret)
+	set("smtp-host", c.SMTPHost)
+	set("smtp-tls", c.SMTPTLS)
+	set("smtp-user", c.SMTPUser)
+	set("smtp-password", c.SMTPPassword)
+	set("email-from", c.EmailFrom)
+	set("email-to", strings.Join(c.EmailTo, ","))
+	set("email-summary", c.EmailSummary)
+
+	if c.SMTPPort != 0 {
+		set("smtp-port", strconv.Itoa(c.SMTPPort))
+	}
+
+	if c.EmailHTML != nil {
+		set("email-html", strconv.FormatBool(*c.EmailHTML))
+	}
+
+	set("retry-backoff", c.RetryBackoff)
+	set("statsd", c.Statsd)
+	set("ping", c.Ping)
+
+	if c.StatsdTags != nil {
+		set("statsd-tags", strconv.FormatBool(*c.StatsdTags))
+	}
+
+	if c.Retries != nil {
+		set("retries", strconv.Itoa(*c.Retries))
+	}
+
+	if c.NoHistory != nil {
+		set("no-history", strconv.FormatBool(*c.NoHistory))
+	}
+
+	if c.NoDiff != nil {
+		set("no-diff", strconv.FormatBool(*c.NoDiff))
+	}
+
+	if c.Compact != nil {
+		set("compact", strconv.FormatBool(*c.Compact))
+	}
+
+	if c.BarWidth != nil {
+		set("bar-width", strconv.Itoa(*c.BarWidth))
+	}
+
+	if c.ASCII != nil {
+		set("ascii", strconv.FormatBool(*c.ASCII))
+	}
+
+	if c.Notify != nil {
+		set("notify", strconv.FormatBool(*c.Notify))
+	}
+
+	if c.Format == "plain" {
+		set("plain", "true")
+	} else {
+		set("format", c.Format)
+	}
+
+	set("template", c.Template)
+	set("color", c.Color)
+	set("theme", c.Theme)
+	set("sort", c.Sort)
+
+	colors := make([]string, 0, len(c.Colors))
+	for _, key := range slices.Sorted(maps.Keys(c.Colors)) {
+		colors = append(colors, key+"="+c.Colors[key])
+	}
+	set("colors", strings.Join(colors, ","))
+
+	set("lang", c.Lang)
+	set("tz", c.TZ)
+	set("time-format", c.TimeFormat)
+	set("reset-precision", c.ResetPrecision)
+
+	if c.ResetFull != nil {
+		set("reset-full", strconv.FormatBool(*c.ResetFull))
+	}
+	set("schedule", c.Schedule)
+	set("listen", c.Listen)
+	set("token", c.Token)
+	set("cache-ttl", c.CacheTTL)
+	set("influx-url", c.InfluxURL)
+	set("influx-token", c.InfluxToken)
+
+	if c.OTLP != nil {
+		set("otlp", strconv.FormatBool(*c.OTLP))
+	}
+
+	if c.SpikeFactor != nil {
+		set("spike-factor", strconv.FormatFloat(*c.SpikeFactor, 'f', -1, 64))
+	}
+
+	if c.SpikeMinRate != nil {
+		set("spike-min-rate", strconv.FormatFloat(*c.SpikeMinRate, 'f', -1, 64))
+	}
+
+	timeouts := []string{}
+	failAt := []string{}
+	baseURLs := []string{}
+	labels := []string{}
+	groups := []string{}
+	if c.ProviderTimeout != "" {
+		timeouts = append(timeouts, c.ProviderTimeout)
+	}
+	if c.FailAt != nil {
+		failAt = append(failAt, strconv.FormatFloat(*c.FailAt, 'f', -1, 64))
+	}
+
+	ids := make([]string, 0, len(c.Provider))
+	for id := range c.Provider {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	for _, id := range ids {
+		provider := c.Provider[id]
+		if provider.Timeout != "" {
+			timeouts = append(timeouts, id+"="+provider.Timeout)
+		}
+		if provider.FailAt != nil {
+			failAt = append(failAt, id+"="+strconv.FormatFloat(*provider.FailAt, 'f', -1, 64))
+		}
+		if provider.BaseURL != "" {
+			baseURLs = append(baseURLs, id+"="+provider.BaseURL)
+		}
+		if provider.Label != "" {
+			labels = append(labels, id+"="+provider.Label)
+		}
+		if provider.Group != "" {
+			groups = append(groups, id+"="+provider.Group)
+		}
+	}
+
+	set("provider-timeout", strings.Join(timeouts, ","))
+	set("fail-at", strings.Join(failAt, ","))
+	set("base-url", strings.Join(baseURLs, ","))
+	set("label", strings.Join(labels, ","))
+	set("group", strings.Join(groups, ","))
+
+	return values
+}
+
+func joinFloats(values []float64) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		parts = append(parts, strconv.FormatFloat(value, 'f', -1, 64))
+	}
+
+	return strings.Join(parts, ",")
+}
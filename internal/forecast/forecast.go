@@ -0,0 +1,79 @@
+package forecast
+
+import (
+	"time"
+)
+
+const (
+	// Lookback bounds how far back samples are used to estimate the burn
+	// rate, so the projection follows recent rather than historical pace.
+	Lookback = 24 * time.Hour
+
+	// MinElapsed is the shortest span between samples that yields a
+	// projection; shorter spans are too noisy to extrapolate.
+	MinElapsed = 10 * time.Minute
+)
+
+// Sample is a used percent observed at a point in time.
+type Sample struct {
+	At          time.Time
+	UsedPercent float64
+	ResetAt     string
+}
+
+// Projection estimates when a window will be exhausted at its current pace.
+type Projection struct {
+	// RatePerHour is the growth of the used percent per hour.
+	RatePerHour float64
+	// ExhaustIn is the time until the window reaches 100%.
+	ExhaustIn time.Duration
+	// BeforeReset reports whether exhaustion happens before the next reset.
+	// It is true when the reset time is unknown.
+	BeforeReset bool
+}
+
+// Project estimates the burn rate from samples ordered oldest first, where
+// the last sample is the current one. Only samples from the same reset period
+// as the current one and within Lookback are used. It returns false when
+// there is not enough data or usage is not growing.
+func Project(samples []Sample, now time.Time) (Projection, bool) {
+	if len(samples) < 2 {
+		return Projection{}, false
+	}
+
+	current := samples[len(samples)-1]
+	var first *Sample
+	for i := range samples[:len(samples)-1] {
+		sample := samples[i]
+		if sample.ResetAt != current.ResetAt || now.Sub(sample.At) > Lookback {
+			continue
+		}
+
+		first = &sample
+		break
+	}
+
+	if first == nil {
+		return Projection{}, false
+	}
+
+	elapsed := current.At.Sub(first.At)
+	delta := current.UsedPercent - first.UsedPercent
+	if elapsed < MinElapsed || delta <= 0 {
+		return Projection{}, false
+	}
+
+	rate := delta / elapsed.Hours()
+	exhaustIn := time.Duration((100 - current.UsedPercent) / rate * float64(time.Hour))
+	projection := Projection{
+		RatePerHour: rate,
+		ExhaustIn:   max(0, exhaustIn),
+		BeforeReset: true,
+	}
+
+	if resetAt, err := time.Parse(time.RFC3339, current.ResetAt); err == nil {
+		projection.BeforeReset = now.Add(projection.ExhaustIn).Before(resetAt)
+	}
+
+	return projection, true
+}
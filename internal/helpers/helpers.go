@@ -1,11 +1,56 @@
 package helpers
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// DurationPrecision selects how much of a duration FormatDuration shows.
+type DurationPrecision string
+
+const (
+	// PrecisionMinutes shows the two largest units down to minutes, such as
+	// "4d 12h" or "2h 5m". It is the default.
+	PrecisionMinutes DurationPrecision = "minutes"
+	// PrecisionSeconds shows the two largest units down to seconds, such as
+	// "4m 12s".
+	PrecisionSeconds DurationPrecision = "seconds"
+	// PrecisionCoarse shows only the largest unit, such as "4d" or "2h".
+	PrecisionCoarse DurationPrecision = "coarse"
+)
+
+// durationFormat is the format of FormatDuration, see SetDurationFormat.
+var durationFormat = struct {
+	precision DurationPrecision
+	full      bool
+}{precision: PrecisionMinutes}
+
+// durationUnits are the units of FormatDuration, largest first.
+var durationUnits = []struct {
+	size   time.Duration
+	suffix string
+}{
+	{24 * time.Hour, "d"},
+	{time.Hour, "h"},
+	{time.Minute, "m"},
+	{time.Second, "s"},
+}
+
+// SetDurationFormat sets the precision of FormatDuration and FormatTimeUntil.
+// With full, every unit down to the precision is shown, such as "1d 0h 5m",
+// instead of the two largest; coarse precision ignores it. It must be called
+// before durations are formatted.
+func SetDurationFormat(precision DurationPrecision, full bool) {
+	durationFormat.precision, durationFormat.full = precision, full
+}
+
 // FormatTimeUntil returns a compact human-readable duration until the given ISO datetime.
 func FormatTimeUntil(dateISO string) string {
 	target, err := time.Parse(time.RFC3339, dateISO)
@@ -14,24 +59,40 @@ func FormatTimeUntil(dateISO string) string {
 	}
 
 	diff := time.Until(target)
-	if diff <= 0 {
+	if diff <= 0 || durationFormat.precision == PrecisionSeconds && diff < time.Second {
 		return "now"
 	}
 
-	totalMinutes := int(math.Floor(diff.Minutes()))
-	days := totalMinutes / 1440
-	hours := (totalMinutes % 1440) / 60
-	minutes := totalMinutes % 60
+	return FormatDuration(diff)
+}
 
-	if days > 0 {
-		return fmt.Sprintf("%dd %dh", days, hours)
+// FormatDuration returns a compact human-readable form of a positive duration.
+func FormatDuration(diff time.Duration) string {
+	smallest := time.Minute
+	if durationFormat.precision == PrecisionSeconds {
+		smallest = time.Second
 	}
+	diff = diff.Truncate(smallest)
+
+	var parts []string
+	for _, unit := range durationUnits {
+		if unit.size < smallest {
+			break
+		}
 
-	if hours > 0 {
-		return fmt.Sprintf("%dh %dm", hours, minutes)
+		value := diff / unit.size
+		diff -= value * unit.size
+		if len(parts) == 0 && value == 0 && unit.size > smallest {
+			continue
+		}
+
+		parts = append(parts, fmt.Sprintf("%d%s", value, unit.suffix))
+		if durationFormat.precision == PrecisionCoarse || !durationFormat.full && len(parts) == 2 {
+			break
+		}
 	}
 
-	return fmt.Sprintf("%dm", minutes)
+	return strings.Join(parts, " ")
 }
 
 // ClampPercent rounds a percent value to two decimals within [0, 100].
@@ -63,3 +124,115 @@ func UnixMillisToISO(value float64) string {
 	date := time.UnixMilli(int64(value)).UTC()
 	return date.Format(time.RFC3339)
 }
+
+// NormalizeISO converts an ISO datetime with any offset or fractional seconds
+// to RFC3339 in UTC, or "unknown" when it cannot be parsed.
+func NormalizeISO(value string) string {
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return "unknown"
+	}
+
+	return date.UTC().Format(time.RFC3339)
+}
+
+// Plural returns singular when count is one and plural otherwise.
+func Plural(count int, singular string, plural string) string {
+	if count == 1 {
+		return singular
+	}
+
+	return plural
+}
+
+// FormatFloat formats a number with at most two decimals and no trailing zeros.
+func FormatFloat(value float64) string {
+	formatted := strconv.FormatFloat(value, 'f', 2, 64)
+	formatted = strings.TrimRight(formatted, "0")
+	formatted = strings.TrimRight(formatted, ".")
+	if formatted == "" || formatted == "-0" {
+		return "0"
+	}
+
+	return formatted
+}
+
+// DataDir returns the aiquota data directory, $XDG_DATA_HOME/aiquota or
+// ~/.local/share/aiquota.
+func DataDir() (string, error) {
+	return xdgDir("XDG_DATA_HOME", filepath.Join(".local", "share"))
+}
+
+// StateDir returns the aiquota state directory, $XDG_STATE_HOME/aiquota or
+// ~/.local/state/aiquota.
+func StateDir() (string, error) {
+	return xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// CacheDir returns the aiquota cache directory inside the user cache
+// directory, e.g. $XDG_CACHE_HOME/aiquota or ~/.cache/aiquota on Linux.
+func CacheDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve user cache directory: %w", err)
+	}
+
+	return filepath.Join(base, "aiquota"), nil
+}
+
+func xdgDir(env string, fallback string) (string, error) {
+	if base := os.Getenv(env); base != "" {
+		return filepath.Join(base, "aiquota"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve user home directory: %w", err)
+	}
+
+	return filepath.Join(home, fallback, "aiquota"), nil
+}
+
+// JWTExpiry returns the exp claim of a JWT without verifying its signature.
+func JWTExpiry(token string) (time.Time, bool) {
+	var claims struct {
+		Exp float64 `json:"exp"`
+	}
+	if !decodeJWTClaims(token, &claims) || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(int64(claims.Exp), 0), true
+}
+
+// JWTSubject returns the sub claim of a JWT without verifying its signature.
+func JWTSubject(token string) (string, bool) {
+	var claims struct {
+		Sub string `json:"sub"`
+	}
+	if !decodeJWTClaims(token, &claims) || claims.Sub == "" {
+		return "", false
+	}
+
+	return claims.Sub, true
+}
+
+// JWTClaims decodes the claims of a JWT into claims without verifying its
+// signature. It reports false for malformed tokens.
+func JWTClaims(token string, claims any) bool {
+	return decodeJWTClaims(token, claims)
+}
+
+func decodeJWTClaims(token string, claims any) bool {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return false
+	}
+
+	return json.Unmarshal(payload, claims) == nil
+}
@@ -0,0 +1,136 @@
+package history
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/provider"
+	bolt "go.etcd.io/bbolt"
+)
+
+var snapshotsBucket = []byte("snapshots")
+
+// Record is a stored snapshot of one provider at one point in time.
+type Record struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Provider  string            `json:"provider"`
+	Windows   []provider.Window `json:"windows"`
+}
+
+// Store persists provider snapshots in a local bbolt database.
+type Store struct {
+	db *bolt.DB
+}
+
+// DefaultPath returns the history database location, under
+// $XDG_DATA_HOME/aiquota or ~/.local/share/aiquota.
+func DefaultPath() (string, error) {
+	dir, err := helpers.DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "history.db"), nil
+}
+
+// Open opens or creates the history database at path.
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(snapshotsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize history database: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Save stores one record per successful result, all stamped with at.
+func (s *Store) Save(at time.Time, results []provider.Result) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(snapshotsBucket)
+		for _, result := range results {
+			if result.Err != nil {
+				continue
+			}
+
+			record := Record{
+				Timestamp: at.UTC(),
+				Provider:  result.Provider.ID(),
+				Windows:   result.Quota.Windows(),
+			}
+
+			value, err := json.Marshal(record)
+			if err != nil {
+				return fmt.Errorf("failed to encode history record: %w", err)
+			}
+
+			if err := bucket.Put(recordKey(record.Timestamp, record.Provider), value); err != nil {
+				return fmt.Errorf("failed to store history record: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Since returns the records stored at or after since, oldest first. An empty
+// providerID returns records for every provider.
+func (s *Store) Since(since time.Time, providerID string) ([]Record, error) {
+	var records []Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(snapshotsBucket).Cursor()
+		for key, value := cursor.Seek(timeKey(since)); key != nil; key, value = cursor.Next() {
+			var record Record
+			if err := json.Unmarshal(value, &record); err != nil {
+				return fmt.Errorf("failed to decode history record: %w", err)
+			}
+
+			if providerID != "" && record.Provider != providerID {
+				continue
+			}
+
+			records = append(records, record)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// recordKey orders records chronologically, using the provider ID to keep
+// records from the same run distinct.
+func recordKey(at time.Time, providerID string) []byte {
+	return append(timeKey(at), providerID...)
+}
+
+func timeKey(at time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(at.UnixNano()))
+	return key
+}
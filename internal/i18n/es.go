@@ -0,0 +1,287 @@
+package i18n
+
+// spanish is the Spanish catalog, written in neutral Latin American Spanish.
+var spanish = map[string]string{
+	// Report.
+	"AI QUOTA REPORT":                      "REPORTE DE CUOTAS DE IA",
+	"cached %s ago":                        "en caché desde hace %s",
+	"Warnings":                             "Advertencias",
+	"Some providers could not be queried:": "No se pudo consultar a algunos proveedores:",
+	"provider":                             "proveedor",
+	"providers":                            "proveedores",
+	"%d failed":                            "%d con error",
+	"highest %s%% (%s)":                    "máximo %s%% (%s)",
+	"other":                                "otros",
+
+	// Provider boxes.
+	"Account:":             "Cuenta:",
+	"Organization:":        "Organización:",
+	"Subscription:":        "Suscripción:",
+	"Token:":               "Token:",
+	"expires in %s":        "vence en %s",
+	"expired %s ago":       "venció hace %s",
+	"Reset in:":            "Reinicio en:",
+	"Requests:":            "Solicitudes:",
+	"Used:":                "Usado:",
+	"Usage:":               "Uso:",
+	"Count:":               "Cantidad:",
+	"Remaining:":           "Restante:",
+	"Limit:":               "Límite:",
+	"Balance:":             "Saldo:",
+	"Available:":           "Disponible:",
+	"Topped up:":           "Recargado:",
+	"Granted:":             "Otorgado:",
+	"Voucher:":             "Cupón:",
+	"Cash:":                "Efectivo:",
+	"Spent:":               "Gastado:",
+	"Status:":              "Estado:",
+	"Key:":                 "Clave:",
+	"API key:":             "Clave de API:",
+	"Overage:":             "Excedente:",
+	"Paid usage:":          "Uso pagado:",
+	"Seats:":               "Licencias:",
+	"Active:":              "Activas:",
+	"Pending:":             "Pendientes:",
+	"Allocated:":           "Asignado:",
+	"Split:":               "Desglose:",
+	"Tokens:":              "Tokens:",
+	"Tokens this month:":   "Tokens este mes:",
+	"Cost this month:":     "Costo este mes:",
+	"Premium requests:":    "Solicitudes premium:",
+	"Predictions:":         "Predicciones:",
+	"Period since:":        "Período desde:",
+	"Daily Requests:":      "Solicitudes diarias:",
+	"Rate limit:":          "Límite de frecuencia:",
+	"Peak (15m):":          "Pico (15m):",
+	"Requests/day:":        "Solicitudes/día:",
+	"Requests/min:":        "Solicitudes/min:",
+	"Tokens/min:":          "Tokens/min:",
+	"Credits":              "Créditos",
+	"Credit Balance":       "Saldo de créditos",
+	"Balance (%s)":         "Saldo (%s)",
+	"API Key Limit":        "Límite de la clave de API",
+	"Spend This Period":    "Gasto de este período",
+	"MCP Details":          "Detalle de MCP",
+	"Models":               "Modelos",
+	"Deployments":          "Implementaciones",
+	"Last activity":        "Última actividad",
+	"Production":           "Producción",
+	"Trial":                "Prueba",
+	"unlimited":            "ilimitado",
+	"unavailable":          "no disponible",
+	"unknown":              "desconocido",
+	"now":                  "ahora",
+	"never":                "nunca",
+	"minute":               "un minuto",
+	"available":            "disponible",
+	"insufficient balance": "saldo insuficiente",
+	"permitted":            "permitido",
+	"not permitted":        "no permitido",
+	"%s ago":               "hace %s",
+	" in %s":               " en %s",
+	"%s this cycle":        "%s en este ciclo",
+	" (%s remaining)":      " (%s restantes)",
+	", resets in %s":       ", se reinicia en %s",
+	"%s (%s tier)":         "%s (nivel %s)",
+
+	" (estimated at %s per request)":                            " (estimado a %s por solicitud)",
+	"%s %d / %d active this cycle":                              "%s %d / %d activas en este ciclo",
+	"%s %d invited, %d cancelling":                              "%s %d invitadas, %d en cancelación",
+	"… and %d more, see --format json":                          "… y %d más, consulta --format json",
+	"Seat list truncated, the organization has more seats":      "Lista de licencias recortada, la organización tiene más",
+	"%s %d requests / %s":                                       "%s %d solicitudes / %s",
+	"%s %s in / %s cache read / %s out":                         "%s %s de entrada / %s leídos de caché / %s de salida",
+	"%s %s in / %s out":                                         "%s %s de entrada / %s de salida",
+	"%s %s in (%s cached) / %s out":                             "%s %s de entrada (%s en caché) / %s de salida",
+	"%s %s requests per minute":                                 "%s %s solicitudes por minuto",
+	"API key is blocked or disabled":                            "La clave de API está bloqueada o desactivada",
+	"Production keys are billed per use and have no call limit": "Las claves de producción se cobran por uso y no tienen límite de llamadas",
+	"No on-demand quota found for this model":                   "No se encontró cuota bajo demanda para este modelo",
+
+	// Window names.
+	"Premium Requests":            "Solicitudes premium",
+	"Chat":                        "Chat",
+	"Completions":                 "Autocompletado",
+	"Token Quota":                 "Cuota de tokens",
+	"Prompt Quota":                "Cuota de prompts",
+	"MCP Quota":                   "Cuota de MCP",
+	"Rate Limit Primary Window":   "Ventana principal del límite de frecuencia",
+	"Rate Limit Secondary Window": "Ventana secundaria del límite de frecuencia",
+	"Code Review Primary Window":  "Ventana principal de revisión de código",
+	"5-Hour Window":               "Ventana de 5 horas",
+	"Weekly Window":               "Ventana semanal",
+	"Weekly Opus Window":          "Ventana semanal de Opus",
+	"Trial Calls This Month":      "Llamadas de prueba este mes",
+	"Trial Calls Per Minute":      "Llamadas de prueba por minuto",
+	"Trial Calls/Month":           "Llamadas de prueba/mes",
+	"Trial Calls/Minute":          "Llamadas de prueba/minuto",
+	"Monthly Tokens":              "Tokens mensuales",
+	"Monthly Cost (USD)":          "Costo mensual (USD)",
+	"Credit Balance (USD)":        "Saldo de créditos (USD)",
+	"Credits (USD)":               "Créditos (USD)",
+	"Paid Overage (USD)":          "Excedente pagado (USD)",
+	"Overage Requests":            "Solicitudes excedentes",
+	"Active Seats":                "Licencias activas",
+	"Predictions":                 "Predicciones",
+
+	// Changes, forecasts, costs and budgets.
+	"Change:":                      "Cambio:",
+	"%s %s in the last %s":         "%s %s desde hace %s",
+	"reset":                        "reiniciado",
+	" remaining":                   " restantes",
+	"Exhausts in:":                 "Se agota en:",
+	"not before reset":             "no antes del reinicio",
+	"Estimated cost:":              "Costo estimado:",
+	"Estimated cost":               "Costo estimado",
+	", %s projected for the month": ", %s proyectado para el mes",
+	"(no price for %s)":            "(sin precio para %s)",
+	"Budget:":                      "Presupuesto:",
+	"Budget %s:":                   "Presupuesto %s:",
+	"%s budget":                    "Presupuesto de %s",
+	"%s %s of %s (%s)":             "%s %s de %s (%s)",
+	"requests":                     "solicitudes",
+	"tokens":                       "tokens",
+	"dollars":                      "dólares",
+	"over budget":                  "presupuesto excedido",
+	"%s%% ahead of pace":           "%s%% por encima del ritmo",
+	"%s%% under pace":              "%s%% por debajo del ritmo",
+	"on pace":                      "al ritmo previsto",
+
+	// Compact report.
+	"no data":   "sin datos",
+	"resets %s": "reinicio: %s",
+	"%s left":   "quedan %s",
+	"%s used":   "%s usados",
+	"n/a":       "n/d",
+
+	// History.
+	"the last day":                       "el último día",
+	"the last week":                      "la última semana",
+	"the last month":                     "el último mes",
+	"the last %d days":                   "los últimos %d días",
+	"Last day":                           "Último día",
+	"Last week":                          "Última semana",
+	"Last month":                         "Último mes",
+	"No usage history recorded in %s.":   "No hay historial de uso registrado en %s.",
+	"%s to %s, one column per %s":        "%s a %s, una columna cada %s",
+	"last":                               "último",
+	"peak":                               "pico",
+	"AI QUOTA SUMMARY":                   "RESUMEN DE CUOTAS DE IA",
+	"AI Quota Summary":                   "Resumen de cuotas de IA",
+	"%s, %s to %s, %d %s":                "%s, %s a %s, %d %s",
+	"snapshot":                           "muestra",
+	"snapshots":                          "muestras",
+	"Peak:":                              "Pico:",
+	"Per day:":                           "Por día:",
+	"Limit reached:":                     "Límite alcanzado:",
+	"time":                               "vez",
+	"times":                              "veces",
+	"Resets:":                            "Reinicios:",
+	"Per reset cycle:":                   "Por ciclo de reinicio:",
+	"%s %s on average, up to %s (%d %s)": "%s %s en promedio, hasta %s (%d %s)",
+	"cycle":                              "ciclo",
+	"cycles":                             "ciclos",
+	"Window":                             "Ventana",
+	"Peak":                               "Pico",
+	"Per day":                            "Por día",
+	"Limit reached":                      "Límite alcanzado",
+	"Resets":                             "Reinicios",
+	"Per reset cycle":                    "Por ciclo de reinicio",
+	"Largest cycle":                      "Ciclo más grande",
+	"No upcoming resets reported.":       "No se informaron próximos reinicios.",
+	"in %-10s":                           "en %-10s",
+
+	// Alerts.
+	"%s quota reset":                                  "Cuota de %s reiniciada",
+	"%s quota fetch failed":                           "Falló la consulta de cuota de %s",
+	"%s usage spike":                                  "Pico de uso en %s",
+	"%s quota at %s%%":                                "Cuota de %s al %s%%",
+	"%s %s reset (used %s%%)":                         "%s %s se reinició (usado %s%%)",
+	"%s could not be queried: %s":                     "No se pudo consultar a %s: %s",
+	"its trailing %s%%/h":                             "su promedio reciente de %s%%/h",
+	"an idle window":                                  "una ventana inactiva",
+	"%s %s is burning %s%%/h, up from %s (used %s%%)": "%s %s consume %s%%/h, frente a %s (usado %s%%)",
+	"%s %s crossed %s%% (used %s%%)":                  "%s %s superó el %s%% (usado %s%%)",
+	"AI quota alert":                                  "Alerta de cuotas de IA",
+	"AI quota report":                                 "Reporte de cuotas de IA",
+	"Error":                                           "Error",
+	"Reset":                                           "Reinicio",
+	"Crossed %s%%\n%s":                                "Superó el %s%%\n%s",
+	"Spiking at %s%%/h\n%s":                           "Pico de %s%%/h\n%s",
+	"Remaining %s":                                    "Restante %s",
+	"Used %s":                                         "Usado %s",
+	"Used %s%% · Remaining %s%%":                      "Usado %s%% · Restante %s%%",
+	"Resets in %s":                                    "Se reinicia en %s",
+
+	// Status bars, watch and the dashboard.
+	"AI n/a":                          "IA n/d",
+	"Error: ":                         "Error: ",
+	"Error: %v":                       "Error: %v",
+	"Fetching…":                       "Consultando…",
+	" (hidden)":                       " (oculto)",
+	"%s raw quota":                    "Cuota sin procesar de %s",
+	"Used %s of %s":                   "Usado %s de %s",
+	"Fetching quotas…":                "Consultando cuotas…",
+	"AI QUOTA DASHBOARD":              "PANEL DE CUOTAS DE IA",
+	"Last refresh failed: %v":         "Falló la última actualización: %v",
+	"Updated %s · next refresh in %s": "Actualizado a las %s · próxima actualización en %s",
+	"Updated %s · refreshing every %s · press Ctrl+C to exit":                         "Actualizado a las %s · se actualiza cada %s · presiona Ctrl+C para salir",
+	"↑/↓ scroll · ←/→ provider · enter back · r refresh · q quit":                     "↑/↓ desplazar · ←/→ proveedor · enter volver · r actualizar · q salir",
+	"←/→ select · space hide/show · a show all · enter raw JSON · r refresh · q quit": "←/→ seleccionar · espacio ocultar/mostrar · a mostrar todos · enter JSON sin procesar · r actualizar · q salir",
+
+	// Markdown and HTML reports.
+	"AI Quota Report":   "Reporte de cuotas de IA",
+	"Generated %s":      "Generado el %s",
+	"No quota windows.": "Sin ventanas de cuota.",
+	"Used":              "Usado",
+	"Limit":             "Límite",
+	"Remaining":         "Restante",
+	"Used %":            "% usado",
+	"%s of %s used":     "%s de %s usados",
+	"%s remaining":      "%s restantes",
+	"resets in":         "se reinicia en",
+
+	// Doctor.
+	"Auth file":                                  "Archivo de autenticación",
+	"Fix: %s":                                    "Solución: %s",
+	"Not configured: %s":                         "Sin configurar: %s",
+	"entry":                                      "entrada",
+	"entries":                                    "entradas",
+	"%s is readable and holds %d %s":             "%s se puede leer y contiene %d %s",
+	"%s is not readable":                         "%s no se puede leer",
+	"%s is not valid JSON: %v":                   "%s no es JSON válido: %v",
+	"%s is reachable (%s)":                       "%s es accesible (%s)",
+	"%s is unreachable: %v":                      "No se puede acceder a %s: %v",
+	"invalid endpoint %s: %v":                    "endpoint inválido %s: %v",
+	"token looks valid":                          "el token parece válido",
+	"token expires in %s":                        "el token vence en %s",
+	"token expired %s ago":                       "el token venció hace %s",
+	"quota request succeeded":                    "la consulta de cuota funcionó",
+	"quota request failed: %s":                   "falló la consulta de cuota: %s",
+	"check that %s is a regular file":            "verifica que %s sea un archivo normal",
+	"run `chmod 600 %s` as its owner":            "ejecuta `chmod 600 %s` como su propietario",
+	"token expired %s ago and will be refreshed": "el token venció hace %s y se renovará",
+	"correct the --base-url or base_url setting": "corrige --base-url o la opción base_url",
+	"%s does not exist, so only CLI files, the keychain and AIQUOTA_* variables are used":                       "%s no existe, así que solo se usan los archivos de las CLI, el llavero y las variables AIQUOTA_*",
+	"set --auth-file or AIQUOTA_AUTH_FILE to the OpenCode auth.json":                                            "define --auth-file o AIQUOTA_AUTH_FILE con el auth.json de OpenCode",
+	"repair the file, or log in again from OpenCode to rewrite it":                                              "repara el archivo, o vuelve a iniciar sesión en OpenCode para reescribirlo",
+	"store a fresh token with `aiquota auth set %s` or update auth.json":                                        "guarda un token nuevo con `aiquota auth set %s` o actualiza auth.json",
+	"token contains whitespace or quotes, likely a copy and paste mistake":                                      "el token contiene espacios o comillas, probablemente un error al copiar y pegar",
+	"token does not start with %s, it may belong to another service":                                            "el token no empieza con %s, puede ser de otro servicio",
+	"run the gemini CLI once to refresh ~/.gemini/oauth_creds.json":                                             "ejecuta la CLI de gemini una vez para renovar ~/.gemini/oauth_creds.json",
+	"log in again from OpenCode or the provider CLI, or %s":                                                     "vuelve a iniciar sesión en OpenCode o en la CLI del proveedor, o %s",
+	"check the network, DNS and any HTTPS_PROXY setting, or the --base-url override":                            "revisa la red, el DNS y cualquier HTTPS_PROXY, o el --base-url indicado",
+	"run `aiquota --provider %s` to see the full response":                                                      "ejecuta `aiquota --provider %s` para ver la respuesta completa",
+	"the provider is slow, raise --provider-timeout %s=30s":                                                     "el proveedor es lento, aumenta --provider-timeout %s=30s",
+	"the check was interrupted, run `aiquota doctor` again":                                                     "la verificación se interrumpió, vuelve a ejecutar `aiquota doctor`",
+	"the endpoint does not exist, check the --base-url override or the account plan":                            "el endpoint no existe, revisa el --base-url indicado o el plan de la cuenta",
+	"the account is rate limited, wait a minute or lower how often aiquota runs":                                "la cuenta alcanzó su límite de frecuencia, espera un minuto o ejecuta aiquota con menos frecuencia",
+	"the provider is having problems, try again later":                                                          "el proveedor tiene problemas, inténtalo más tarde",
+	"the token was rejected, it may be revoked or lack permissions; store a new one with `aiquota auth set %s`": "el token fue rechazado, puede estar revocado o no tener permisos; guarda uno nuevo con `aiquota auth set %s`",
+
+	// Keychain.
+	"%s token: ":                                                   "Token de %s: ",
+	"Stored %s token in the keychain.":                             "Token de %s guardado en el llavero.",
+	"Deleted %s token from the keychain.":                          "Token de %s eliminado del llavero.",
+	"Usage: aiquota auth set|delete <provider>\n\nProviders: %s\n": "Uso: aiquota auth set|delete <proveedor>\n\nProveedores: %s\n",
+}
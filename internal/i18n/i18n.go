@@ -0,0 +1,115 @@
+// Package i18n translates the text aiquota prints for people. Messages are
+// keyed by their English text, so a message missing from a catalog is
+// printed in English.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// Languages are the supported language codes, English first.
+var Languages = []string{"en", "es"}
+
+// catalogs holds the translations of every language but English.
+var catalogs = map[string]map[string]string{
+	"es": spanish,
+}
+
+// current is the catalog in use, nil for English.
+var current atomic.Pointer[map[string]string]
+
+// language is the code of the language in use, nil for English.
+var language atomic.Pointer[string]
+
+// Set selects the language by code, such as "es" or "es_AR.UTF-8".
+func Set(lang string) error {
+	code, ok := normalize(lang)
+	if !ok {
+		return fmt.Errorf("unsupported language %q, expected one of %s", lang, strings.Join(Languages, ", "))
+	}
+
+	if catalog, ok := catalogs[code]; ok {
+		current.Store(&catalog)
+	} else {
+		current.Store(nil)
+	}
+	language.Store(&code)
+
+	return nil
+}
+
+// Language returns the code of the language in use, such as "es".
+func Language() string {
+	if code := language.Load(); code != nil {
+		return *code
+	}
+
+	return "en"
+}
+
+// Detect returns the language of the locale environment variables, checked
+// in the order of LC_ALL, LC_MESSAGES and LANG, or English when none names a
+// supported language.
+func Detect() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+
+		if code, ok := normalize(value); ok {
+			return code
+		}
+		return "en"
+	}
+
+	return "en"
+}
+
+// normalize turns a locale such as "es_ES.UTF-8" into a supported language
+// code. The C and POSIX locales are English.
+func normalize(lang string) (string, bool) {
+	code := strings.ToLower(lang)
+	if i := strings.IndexAny(code, "_-.@"); i >= 0 {
+		code = code[:i]
+	}
+	if code == "c" || code == "posix" {
+		code = "en"
+	}
+
+	return code, slices.Contains(Languages, code)
+}
+
+// T returns the translation of message, or message itself when the current
+// language has none.
+func T(message string) string {
+	catalog := current.Load()
+	if catalog == nil {
+		return message
+	}
+
+	if translated, ok := (*catalog)[message]; ok {
+		return translated
+	}
+
+	return message
+}
+
+// Sprintf formats the translation of format.
+func Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// Plural returns the translation of singular when count is one and of plural
+// otherwise.
+func Plural(count int, singular string, plural string) string {
+	if count == 1 {
+		return T(singular)
+	}
+
+	return T(plural)
+}
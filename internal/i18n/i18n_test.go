@@ -0,0 +1,85 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// messages returns the string literals passed to T, Sprintf and Plural
+// anywhere in the module, with the position of their first use.
+func messages(t *testing.T) map[string]string {
+	t.Helper()
+
+	root := filepath.Join("..", "..")
+	fset := token.NewFileSet()
+	found := map[string]string{}
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			selector, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || !slices.Contains([]string{"T", "Sprintf", "Plural"}, selector.Sel.Name) {
+				return true
+			}
+			if pkg, ok := selector.X.(*ast.Ident); !ok || pkg.Name != "i18n" {
+				return true
+			}
+
+			for _, arg := range call.Args {
+				literal, ok := arg.(*ast.BasicLit)
+				if !ok || literal.Kind != token.STRING {
+					continue
+				}
+				message, err := strconv.Unquote(literal.Value)
+				if err != nil {
+					continue
+				}
+				if _, seen := found[message]; !seen {
+					found[message] = fset.Position(literal.Pos()).String()
+				}
+			}
+			return true
+		})
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return found
+}
+
+func TestCatalogsHaveEveryMessage(t *testing.T) {
+	found := messages(t)
+	if len(found) == 0 {
+		t.Fatal("found no translated messages")
+	}
+
+	for code, catalog := range catalogs {
+		for _, message := range slices.Sorted(maps.Keys(found)) {
+			if _, ok := catalog[message]; !ok {
+				t.Errorf("%s: %q has no %s translation", found[message], message, code)
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package influx
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// tagEscaper escapes tag keys and values as required by the line protocol.
+var tagEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `, "\n", `\n`)
+
+// Write renders the results in InfluxDB line protocol, one "aiquota" point
+// per window and one "aiquota_fetch" point per provider, all stamped at
+// the given time:
+//
+//	aiquota,provider=copilot,window=premium used_percent=28,remaining=1080 1700000000000000000
+//	aiquota_fetch,provider=copilot success=true 1700000000000000000
+func Write(w io.Writer, at time.Time, results []provider.Result) error {
+	var b strings.Builder
+	timestamp := strconv.FormatInt(at.UnixNano(), 10)
+
+	for _, result := range results {
+		id := tagEscaper.Replace(result.Provider.ID())
+		fmt.Fprintf(&b, "aiquota_fetch,provider=%s success=%t %s\n", id, result.Err == nil, timestamp)
+		if result.Err != nil {
+			continue
+		}
+
+		for _, window := range result.Quota.Windows() {
+			fields := windowFields(at, window)
+			if len(fields) == 0 {
+				continue
+			}
+
+			fmt.Fprintf(&b, "aiquota,provider=%s,window=%s %s %s\n", id, tagEscaper.Replace(window.ID), strings.Join(fields, ","), timestamp)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// windowFields returns the fields known for a window. A point needs at
+// least one field, so windows without data are skipped by the caller.
+func windowFields(at time.Time, window provider.Window) []string {
+	var fields []string
+	add := func(name string, value *float64) {
+		if value != nil && !math.IsNaN(*value) && !math.IsInf(*value, 0) {
+			fields = append(fields, name+"="+strconv.FormatFloat(*value, 'f', -1, 64))
+		}
+	}
+
+	add("used_percent", window.UsedPercent)
+	add("used", window.Used)
+	add("limit", window.Limit)
+	add("remaining", window.Remaining)
+
+	if resetAt, err := time.Parse(time.RFC3339, window.ResetAt); err == nil {
+		add("reset_seconds", new(math.Max(0, math.Round(resetAt.Sub(at).Seconds()))))
+	}
+
+	return fields
+}
+
+// Push writes the results to an InfluxDB write endpoint, such as
+// http://localhost:8086/api/v2/write?org=home&bucket=aiquota or a v1
+// /write?db=aiquota URL. The token, when set, is sent as an InfluxDB v2
+// API token.
+func Push(ctx context.Context, url string, token string, at time.Time, results []provider.Result) error {
+	var body bytes.Buffer
+	if err := Write(&body, at, results); err != nil {
+		return fmt.Errorf("failed to encode InfluxDB points: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create InfluxDB request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if token != "" {
+		req.Header.Set("Authorization", "Token "+token)
+	}
+
+	response, err := httpclient.Default.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to write to InfluxDB: %w", err)
+	}
+	defer response.Body.Close()
+
+	responseBody, _ := io.ReadAll(response.Body)
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("failed to write to InfluxDB. Status: %d, Response: %s", response.StatusCode, string(responseBody))
+	}
+
+	return nil
+}
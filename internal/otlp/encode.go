@@ -0,0 +1,187 @@
+package otlp
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// The types below mirror the OTLP metrics messages aiquota sends. Their JSON
+// tags follow the OTLP/JSON mapping and appendProto encodes them with the
+// field numbers of opentelemetry/proto/metrics/v1/metrics.proto, which
+// avoids depending on the generated protobuf packages.
+
+type exportRequest struct {
+	ResourceMetrics []resourceMetrics `json:"resourceMetrics"`
+}
+
+type resourceMetrics struct {
+	Resource     resource       `json:"resource"`
+	ScopeMetrics []scopeMetrics `json:"scopeMetrics"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeMetrics struct {
+	Scope   scope    `json:"scope"`
+	Metrics []metric `json:"metrics"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type metric struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Unit        string `json:"unit,omitempty"`
+	Gauge       *gauge `json:"gauge,omitempty"`
+	Sum         *sum   `json:"sum,omitempty"`
+}
+
+type gauge struct {
+	DataPoints []dataPoint `json:"dataPoints"`
+}
+
+// temporalityCumulative is AGGREGATION_TEMPORALITY_CUMULATIVE.
+const temporalityCumulative = 2
+
+type sum struct {
+	DataPoints             []dataPoint `json:"dataPoints"`
+	AggregationTemporality int         `json:"aggregationTemporality"`
+	IsMonotonic            bool        `json:"isMonotonic"`
+}
+
+type dataPoint struct {
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano uint64     `json:"startTimeUnixNano,string,omitempty"`
+	TimeUnixNano      uint64     `json:"timeUnixNano,string"`
+	AsDouble          float64    `json:"asDouble"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+func (r exportRequest) appendProto(b []byte) []byte {
+	for _, rm := range r.ResourceMetrics {
+		b = appendMessage(b, 1, rm.appendProto(nil))
+	}
+
+	return b
+}
+
+func (r resourceMetrics) appendProto(b []byte) []byte {
+	b = appendMessage(b, 1, r.Resource.appendProto(nil))
+	for _, sm := range r.ScopeMetrics {
+		b = appendMessage(b, 2, sm.appendProto(nil))
+	}
+
+	return b
+}
+
+func (r resource) appendProto(b []byte) []byte {
+	for _, attribute := range r.Attributes {
+		b = appendMessage(b, 1, attribute.appendProto(nil))
+	}
+
+	return b
+}
+
+func (s scopeMetrics) appendProto(b []byte) []byte {
+	b = appendMessage(b, 1, appendString(nil, 1, s.Scope.Name))
+	for _, m := range s.Metrics {
+		b = appendMessage(b, 2, m.appendProto(nil))
+	}
+
+	return b
+}
+
+func (m metric) appendProto(b []byte) []byte {
+	b = appendString(b, 1, m.Name)
+	b = appendString(b, 2, m.Description)
+	b = appendString(b, 3, m.Unit)
+
+	if m.Gauge != nil {
+		var points []byte
+		for _, point := range m.Gauge.DataPoints {
+			points = appendMessage(points, 1, point.appendProto(nil))
+		}
+		b = appendMessage(b, 5, points)
+	}
+
+	if m.Sum != nil {
+		var fields []byte
+		for _, point := range m.Sum.DataPoints {
+			fields = appendMessage(fields, 1, point.appendProto(nil))
+		}
+		fields = appendVarint(fields, 2, uint64(m.Sum.AggregationTemporality))
+		if m.Sum.IsMonotonic {
+			fields = appendVarint(fields, 3, 1)
+		}
+		b = appendMessage(b, 7, fields)
+	}
+
+	return b
+}
+
+func (p dataPoint) appendProto(b []byte) []byte {
+	if p.StartTimeUnixNano != 0 {
+		b = appendFixed64(b, 2, p.StartTimeUnixNano)
+	}
+	b = appendFixed64(b, 3, p.TimeUnixNano)
+	// as_double is part of a oneof, so it is written even when zero.
+	b = appendFixed64(b, 4, math.Float64bits(p.AsDouble))
+	for _, attribute := range p.Attributes {
+		b = appendMessage(b, 7, attribute.appendProto(nil))
+	}
+
+	return b
+}
+
+func (kv keyValue) appendProto(b []byte) []byte {
+	b = appendString(b, 1, kv.Key)
+	return appendMessage(b, 2, appendString(nil, 1, kv.Value.StringValue))
+}
+
+// Protobuf wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+func appendTag(b []byte, field int, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(field)<<3|uint64(wire))
+}
+
+func appendVarint(b []byte, field int, value uint64) []byte {
+	if value == 0 {
+		return b
+	}
+
+	return binary.AppendUvarint(appendTag(b, field, wireVarint), value)
+}
+
+func appendFixed64(b []byte, field int, value uint64) []byte {
+	return binary.LittleEndian.AppendUint64(appendTag(b, field, wireFixed64), value)
+}
+
+func appendMessage(b []byte, field int, message []byte) []byte {
+	b = binary.AppendUvarint(appendTag(b, field, wireBytes), uint64(len(message)))
+	return append(b, message...)
+}
+
+func appendString(b []byte, field int, value string) []byte {
+	if value == "" {
+		return b
+	}
+
+	return appendMessage(b, field, []byte(value))
+}
@@ -0,0 +1,457 @@
+package otlp
+
+import (
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// Protocols accepted in OTEL_EXPORTER_OTLP_PROTOCOL.
+const (
+	ProtocolGRPC     = "grpc"
+	ProtocolProtobuf = "http/protobuf"
+	ProtocolJSON     = "http/json"
+)
+
+// grpcMethod is the path of the OTLP metrics export RPC.
+const grpcMethod = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+
+// scopeName is the instrumentation scope of every exported metric.
+const scopeName = "github.com/eduardolat/aiquota"
+
+// Exporter pushes quota metrics to an OpenTelemetry collector. It keeps the
+// fetch latency reported by Observe and a cumulative fetch error count per
+// provider between exports.
+type Exporter struct {
+	endpoint string
+	protocol string
+	gzip     bool
+	headers  http.Header
+	timeout  time.Duration
+	resource []keyValue
+	client   httpclient.Doer
+	start    time.Time
+
+	mu      sync.Mutex
+	latency map[string]time.Duration
+	errors  map[string]int
+}
+
+// FromEnv configures an exporter from the standard OTEL_EXPORTER_OTLP_*
+// variables, where the METRICS_ variants take precedence:
+//
+//   - ENDPOINT defaults to http://localhost:4318, or http://localhost:4317
+//     for grpc. The HTTP protocols append /v1/metrics to it, but not to
+//     OTEL_EXPORTER_OTLP_METRICS_ENDPOINT.
+//   - PROTOCOL is grpc, http/protobuf (the default) or http/json.
+//   - HEADERS are key=value pairs separated by commas.
+//   - TIMEOUT is in milliseconds and defaults to 10000.
+//   - COMPRESSION is gzip or none.
+//   - INSECURE makes a grpc endpoint without a scheme use plain HTTP/2.
+//
+// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES describe the resource,
+// whose service.name defaults to aiquota.
+func FromEnv() (*Exporter, error) {
+	e := &Exporter{
+		protocol: ProtocolProtobuf,
+		headers:  http.Header{},
+		timeout:  10 * time.Second,
+		start:    time.Now(),
+		latency:  map[string]time.Duration{},
+		errors:   map[string]int{},
+	}
+
+	if protocol := metricsEnv("PROTOCOL"); protocol != "" {
+		switch protocol {
+		case ProtocolGRPC, ProtocolProtobuf, ProtocolJSON:
+			e.protocol = protocol
+		default:
+			return nil, fmt.Errorf("unsupported OTLP protocol %q, expected %s, %s or %s", protocol, ProtocolGRPC, ProtocolProtobuf, ProtocolJSON)
+		}
+	}
+
+	switch compression := metricsEnv("COMPRESSION"); compression {
+	case "", "none":
+	case "gzip":
+		e.gzip = true
+	default:
+		return nil, fmt.Errorf("unsupported OTLP compression %q, expected gzip or none", compression)
+	}
+
+	if timeout := metricsEnv("TIMEOUT"); timeout != "" {
+		milliseconds, err := strconv.Atoi(timeout)
+		if err != nil || milliseconds <= 0 {
+			return nil, fmt.Errorf("invalid OTLP timeout %q, expected a number of milliseconds", timeout)
+		}
+		e.timeout = time.Duration(milliseconds) * time.Millisecond
+	}
+
+	for _, name := range []string{"OTEL_EXPORTER_OTLP_HEADERS", "OTEL_EXPORTER_OTLP_METRICS_HEADERS"} {
+		pairs, err := parsePairs(os.Getenv(name))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		for _, pair := range pairs {
+			e.headers.Set(pair.Key, pair.Value.StringValue)
+		}
+	}
+
+	endpoint, err := e.resolveEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	e.endpoint = endpoint
+
+	attributes, err := parsePairs(os.Getenv("OTEL_RESOURCE_ATTRIBUTES"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTEL_RESOURCE_ATTRIBUTES: %w", err)
+	}
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	for _, attribute := range attributes {
+		if attribute.Key == "service.name" {
+			serviceName = cmp.Or(serviceName, attribute.Value.StringValue)
+			continue
+		}
+		e.resource = append(e.resource, attribute)
+	}
+	e.resource = append([]keyValue{stringAttribute("service.name", cmp.Or(serviceName, "aiquota"))}, e.resource...)
+
+	e.client = httpclient.Default
+	if e.protocol == ProtocolGRPC {
+		e.client = grpcClient(strings.HasPrefix(e.endpoint, "http://"))
+	}
+
+	return e, nil
+}
+
+// Endpoint returns the URL metrics are sent to.
+func (e *Exporter) Endpoint() string {
+	return e.endpoint
+}
+
+// Observe records how long a provider took to answer. Its signature matches
+// provider.FetchOptions.OnResult, and it is safe for concurrent use.
+func (e *Exporter) Observe(result provider.Result, elapsed time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.latency[result.Provider.ID()] = elapsed
+}
+
+// Export sends the aiquota.used_percent, aiquota.used, aiquota.limit and
+// aiquota.remaining gauges of every window, the aiquota.fetch.duration gauge
+// of every provider observed since the last export and the cumulative
+// aiquota.fetch.errors sum of every provider.
+func (e *Exporter) Export(ctx context.Context, at time.Time, results []provider.Result) error {
+	request := exportRequest{ResourceMetrics: []resourceMetrics{{
+		Resource: resource{Attributes: e.resource},
+		ScopeMetrics: []scopeMetrics{{
+			Scope:   scope{Name: scopeName},
+			Metrics: e.metrics(at, results),
+		}},
+	}}}
+
+	var body []byte
+	if e.protocol == ProtocolJSON {
+		var err error
+		if body, err = json.Marshal(request); err != nil {
+			return fmt.Errorf("failed to encode OTLP metrics: %w", err)
+		}
+	} else {
+		body = request.appendProto(nil)
+	}
+
+	if e.gzip {
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		if _, err := writer.Write(body); err != nil {
+			return fmt.Errorf("failed to compress OTLP metrics: %w", err)
+		}
+		if err := writer.Close(); err != nil {
+			return fmt.Errorf("failed to compress OTLP metrics: %w", err)
+		}
+		body = compressed.Bytes()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	if e.protocol == ProtocolGRPC {
+		return e.sendGRPC(ctx, body)
+	}
+
+	return e.sendHTTP(ctx, body)
+}
+
+// metrics turns the results into OTLP metrics and updates the error counts.
+func (e *Exporter) metrics(at time.Time, results []provider.Result) []metric {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := uint64(at.UnixNano())
+	gauges := []metric{
+		{Name: "aiquota.used_percent", Description: "Used share of the quota window.", Unit: "%"},
+		{Name: "aiquota.used", Description: "Units used in the quota window.", Unit: "1"},
+		{Name: "aiquota.limit", Description: "Units available in the quota window.", Unit: "1"},
+		{Name: "aiquota.remaining", Description: "Units left in the quota window.", Unit: "1"},
+	}
+	for i := range gauges {
+		gauges[i].Gauge = &gauge{}
+	}
+	duration := metric{Name: "aiquota.fetch.duration", Description: "Time the last quota fetch took.", Unit: "s", Gauge: &gauge{}}
+	errors := metric{
+		Name:        "aiquota.fetch.errors",
+		Description: "Failed quota fetches since the daemon started.",
+		Unit:        "{error}",
+		Sum:         &sum{AggregationTemporality: temporalityCumulative, IsMonotonic: true},
+	}
+
+	for _, result := range results {
+		id := result.Provider.ID()
+		providerAttribute := stringAttribute("provider", id)
+
+		if elapsed, ok := e.latency[id]; ok {
+			duration.Gauge.DataPoints = append(duration.Gauge.DataPoints, dataPoint{
+				Attributes:   []keyValue{providerAttribute},
+				TimeUnixNano: now,
+				AsDouble:     elapsed.Seconds(),
+			})
+			delete(e.latency, id)
+		}
+
+		if result.Err != nil {
+			e.errors[id]++
+		}
+		errors.Sum.DataPoints = append(errors.Sum.DataPoints, dataPoint{
+			Attributes:        []keyValue{providerAttribute},
+			StartTimeUnixNano: uint64(e.start.UnixNano()),
+			TimeUnixNano:      now,
+			AsDouble:          float64(e.errors[id]),
+		})
+
+		if result.Err != nil {
+			continue
+		}
+
+		for _, window := range result.Quota.Windows() {
+			attributes := []keyValue{providerAttribute, stringAttribute("window", window.ID)}
+			for i, value := range []*float64{window.UsedPercent, window.Used, window.Limit, window.Remaining} {
+				if value == nil || math.IsNaN(*value) || math.IsInf(*value, 0) {
+					continue
+				}
+				gauges[i].Gauge.DataPoints = append(gauges[i].Gauge.DataPoints, dataPoint{
+					Attributes:   attributes,
+					TimeUnixNano: now,
+					AsDouble:     *value,
+				})
+			}
+		}
+	}
+
+	// A metric without data points is invalid, so empty ones are dropped.
+	var metrics []metric
+	for _, m := range append(gauges, duration) {
+		if len(m.Gauge.DataPoints) > 0 {
+			metrics = append(metrics, m)
+		}
+	}
+	if len(errors.Sum.DataPoints) > 0 {
+		metrics = append(metrics, errors)
+	}
+
+	return metrics
+}
+
+func (e *Exporter) sendHTTP(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create OTLP request: %w", err)
+	}
+
+	e.setHeaders(req)
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	if e.protocol == ProtocolJSON {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if e.gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	response, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to export OTLP metrics: %w", err)
+	}
+	defer response.Body.Close()
+
+	responseBody, _ := io.ReadAll(response.Body)
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		// Protobuf error bodies are binary, so only readable ones are shown.
+		if strings.HasPrefix(response.Header.Get("Content-Type"), "application/x-protobuf") {
+			return fmt.Errorf("failed to export OTLP metrics. Status: %d", response.StatusCode)
+		}
+		return fmt.Errorf("failed to export OTLP metrics. Status: %d, Response: %s", response.StatusCode, string(responseBody))
+	}
+
+	return nil
+}
+
+func (e *Exporter) sendGRPC(ctx context.Context, body []byte) error {
+	// Every gRPC message is prefixed with a compressed flag and its length.
+	frame := make([]byte, 5, 5+len(body))
+	if e.gzip {
+		frame[0] = 1
+	}
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(body)))
+	frame = append(frame, body...)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint+grpcMethod, bytes.NewReader(frame))
+	if err != nil {
+		return fmt.Errorf("failed to create OTLP request: %w", err)
+	}
+
+	e.setHeaders(req)
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	if e.gzip {
+		req.Header.Set("Grpc-Encoding", "gzip")
+	}
+
+	response, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to export OTLP metrics: %w", err)
+	}
+	defer response.Body.Close()
+
+	// The status is in the trailers, which are only complete after the body
+	// was read. Responses without a message carry it in the headers.
+	_, _ = io.Copy(io.Discard, response.Body)
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to export OTLP metrics. Status: %d", response.StatusCode)
+	}
+
+	status, message := response.Trailer.Get("Grpc-Status"), response.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status, message = response.Header.Get("Grpc-Status"), response.Header.Get("Grpc-Message")
+	}
+	if status != "0" {
+		if decoded, err := url.PathUnescape(message); err == nil {
+			message = decoded
+		}
+		return fmt.Errorf("failed to export OTLP metrics. gRPC status: %s, Message: %s", cmp.Or(status, "missing"), message)
+	}
+
+	return nil
+}
+
+func (e *Exporter) setHeaders(req *http.Request) {
+	for name, values := range e.headers {
+		req.Header[name] = values
+	}
+	req.Header.Set("User-Agent", "aiquota")
+}
+
+// resolveEndpoint returns the URL requests are posted to, following the
+// OTLP exporter rules for signal specific and generic endpoints.
+func (e *Exporter) resolveEndpoint() (string, error) {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT")
+	signal := endpoint != ""
+	if !signal {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+
+	if endpoint == "" {
+		if e.protocol == ProtocolGRPC {
+			return "http://localhost:4317", nil
+		}
+		return "http://localhost:4318/v1/metrics", nil
+	}
+
+	// gRPC endpoints are often given as host:port.
+	if e.protocol == ProtocolGRPC && !strings.Contains(endpoint, "://") {
+		scheme := "https://"
+		if insecure, _ := strconv.ParseBool(metricsEnv("INSECURE")); insecure {
+			scheme = "http://"
+		}
+		endpoint = scheme + endpoint
+	}
+
+	parsed, err := url.Parse(endpoint)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("invalid OTLP endpoint %q, expected an http or https URL", endpoint)
+	}
+
+	if e.protocol == ProtocolGRPC {
+		return parsed.Scheme + "://" + parsed.Host, nil
+	}
+
+	if !signal {
+		endpoint = strings.TrimSuffix(endpoint, "/") + "/v1/metrics"
+	}
+
+	return endpoint, nil
+}
+
+// grpcClient returns a client that speaks HTTP/2, over cleartext when
+// plaintext is set. It bypasses httpclient.Transport, whose retries and
+// dumps are meant for provider requests.
+func grpcClient(plaintext bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Protocols = new(http.Protocols)
+	if plaintext {
+		transport.Protocols.SetUnencryptedHTTP2(true)
+	} else {
+		transport.Protocols.SetHTTP2(true)
+	}
+
+	return &http.Client{Transport: transport}
+}
+
+// metricsEnv returns OTEL_EXPORTER_OTLP_METRICS_<name>, falling back to
+// OTEL_EXPORTER_OTLP_<name>.
+func metricsEnv(name string) string {
+	return cmp.Or(os.Getenv("OTEL_EXPORTER_OTLP_METRICS_"+name), os.Getenv("OTEL_EXPORTER_OTLP_"+name))
+}
+
+// parsePairs parses the key=value,key=value lists of OTEL_* variables, whose
+// values may be percent-encoded.
+func parsePairs(value string) ([]keyValue, error) {
+	var pairs []keyValue
+	for part := range strings.SplitSeq(value, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+
+		key, raw, ok := strings.Cut(part, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("expected key=value, got %q", part)
+		}
+
+		decoded, err := url.PathUnescape(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of %q: %w", key, err)
+		}
+		pairs = append(pairs, stringAttribute(key, decoded))
+	}
+
+	return pairs, nil
+}
+
+func stringAttribute(key string, value string) keyValue {
+	return keyValue{Key: key, Value: anyValue{StringValue: value}}
+}
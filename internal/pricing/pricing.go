@@ -0,0 +1,139 @@
+package pricing
+
+import (
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// Price is what a model costs in USD. Token prices are per million tokens;
+// Request is per call of tools billed per use.
+type Price struct {
+	Input       float64
+	CachedInput float64
+	Output      float64
+	Request     float64
+}
+
+// Table maps model names, or prefixes of them, to prices.
+type Table map[string]Price
+
+// Defaults are list prices at the time of writing. Providers change them,
+// so the config file can override any entry or add new models.
+var Defaults = Table{
+	// OpenAI
+	"gpt-5":        {Input: 1.25, CachedInput: 0.125, Output: 10},
+	"gpt-5-mini":   {Input: 0.25, CachedInput: 0.025, Output: 2},
+	"gpt-5-nano":   {Input: 0.05, CachedInput: 0.005, Output: 0.40},
+	"gpt-4.1":      {Input: 2, CachedInput: 0.50, Output: 8},
+	"gpt-4.1-mini": {Input: 0.40, CachedInput: 0.10, Output: 1.60},
+	"gpt-4.1-nano": {Input: 0.10, CachedInput: 0.025, Output: 0.40},
+	"gpt-4o":       {Input: 2.50, CachedInput: 1.25, Output: 10},
+	"gpt-4o-mini":  {Input: 0.15, CachedInput: 0.075, Output: 0.60},
+	"o3":           {Input: 2, CachedInput: 0.50, Output: 8},
+	"o3-mini":      {Input: 1.10, CachedInput: 0.55, Output: 4.40},
+	"o4-mini":      {Input: 1.10, CachedInput: 0.275, Output: 4.40},
+
+	// Anthropic
+	"claude-opus-4":     {Input: 15, CachedInput: 1.50, Output: 75},
+	"claude-opus-4-5":   {Input: 5, CachedInput: 0.50, Output: 25},
+	"claude-sonnet-4":   {Input: 3, CachedInput: 0.30, Output: 15},
+	"claude-haiku-4-5":  {Input: 1, CachedInput: 0.10, Output: 5},
+	"claude-3-7-sonnet": {Input: 3, CachedInput: 0.30, Output: 15},
+	"claude-3-5-sonnet": {Input: 3, CachedInput: 0.30, Output: 15},
+	"claude-3-5-haiku":  {Input: 0.80, CachedInput: 0.08, Output: 4},
+
+	// Mistral
+	"mistral-large":  {Input: 2, Output: 6},
+	"mistral-medium": {Input: 0.40, Output: 2},
+	"mistral-small":  {Input: 0.10, Output: 0.30},
+	"codestral":      {Input: 0.30, Output: 0.90},
+
+	// Z.ai
+	"glm-4.6":      {Input: 0.60, CachedInput: 0.11, Output: 2.20},
+	"glm-4.5":      {Input: 0.60, CachedInput: 0.11, Output: 2.20},
+	"search-prime": {Request: 0.01},
+}
+
+// With returns a copy of the table with overrides added or replacing
+// entries.
+func (t Table) With(overrides Table) Table {
+	merged := maps.Clone(t)
+	maps.Copy(merged, overrides)
+	return merged
+}
+
+// Lookup returns the price of a model: the entry with its exact name, or the
+// longest entry it starts with followed by "-", which covers dated
+// snapshots such as gpt-4.1-2025-04-14.
+func (t Table) Lookup(model string) (Price, bool) {
+	model = strings.ToLower(model)
+	if price, ok := t[model]; ok {
+		return price, true
+	}
+
+	var best string
+	for name := range t {
+		if len(name) > len(best) && strings.HasPrefix(model, name+"-") {
+			best = name
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+
+	return t[best], true
+}
+
+// Estimate is the priced consumption of a provider.
+type Estimate struct {
+	// CostUSD is the cost of the priced models.
+	CostUSD float64
+	// ProjectedUSD is CostUSD extrapolated to the whole billing period, nil
+	// when the period is unknown or has just started.
+	ProjectedUSD *float64
+	// Unpriced lists the models with usage but no price.
+	Unpriced []string
+}
+
+// minElapsed is how much of a period must have passed before it is
+// projected, as the first hours would extrapolate wildly.
+const minElapsed = 6 * time.Hour
+
+// EstimateUsage prices the usage of a period at the time now. It reports
+// false when no model with usage has a price.
+func (t Table) EstimateUsage(usage []provider.TokenUsage, periodStart time.Time, periodEnd time.Time, now time.Time) (Estimate, bool) {
+	var estimate Estimate
+	priced := false
+
+	for _, model := range usage {
+		if model.InputTokens == 0 && model.CachedInputTokens == 0 && model.OutputTokens == 0 && model.Requests == 0 {
+			continue
+		}
+
+		price, ok := t.Lookup(model.Model)
+		if !ok {
+			estimate.Unpriced = append(estimate.Unpriced, model.Model)
+			continue
+		}
+
+		priced = true
+		estimate.CostUSD += (float64(model.InputTokens)*price.Input +
+			float64(model.CachedInputTokens)*price.CachedInput +
+			float64(model.OutputTokens)*price.Output) / 1e6
+		estimate.CostUSD += float64(model.Requests) * price.Request
+	}
+
+	if !priced {
+		return Estimate{}, false
+	}
+
+	elapsed, total := now.Sub(periodStart), periodEnd.Sub(periodStart)
+	if !periodStart.IsZero() && total > 0 && elapsed >= minElapsed && elapsed < total {
+		estimate.ProjectedUSD = new(estimate.CostUSD * float64(total) / float64(elapsed))
+	}
+
+	return estimate, true
+}
@@ -0,0 +1,158 @@
+package prometheus
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// labelEscaper escapes label values as required by the exposition format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Collector keeps the latest provider results and renders them in the
+// Prometheus text exposition format.
+type Collector struct {
+	mu          sync.Mutex
+	results     []provider.Result
+	errors      map[string]int
+	lastSuccess map[string]time.Time
+}
+
+// NewCollector returns an empty collector.
+func NewCollector() *Collector {
+	return &Collector{
+		errors:      map[string]int{},
+		lastSuccess: map[string]time.Time{},
+	}
+}
+
+// Update replaces the current results and counts fetch errors.
+func (c *Collector) Update(results []provider.Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.results = results
+	for _, result := range results {
+		id := result.Provider.ID()
+		if result.Err != nil {
+			c.errors[id]++
+			continue
+		}
+
+		c.lastSuccess[id] = now
+	}
+}
+
+// Write renders every metric to w.
+func (c *Collector) Write(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var b strings.Builder
+	now := time.Now()
+
+	writeHeader(&b, "aiquota_used_percent", "gauge", "Used percent of a provider quota window.")
+	c.eachWindow(func(id string, window provider.Window) {
+		if window.UsedPercent != nil {
+			writeSample(&b, "aiquota_used_percent", *window.UsedPercent, "provider", id, "window", window.ID)
+		}
+	})
+
+	writeHeader(&b, "aiquota_used", "gauge", "Absolute usage of a provider quota window.")
+	c.eachWindow(func(id string, window provider.Window) {
+		if window.Used != nil {
+			writeSample(&b, "aiquota_used", *window.Used, "provider", id, "window", window.ID)
+		}
+	})
+
+	writeHeader(&b, "aiquota_limit", "gauge", "Absolute limit of a provider quota window.")
+	c.eachWindow(func(id string, window provider.Window) {
+		if window.Limit != nil {
+			writeSample(&b, "aiquota_limit", *window.Limit, "provider", id, "window", window.ID)
+		}
+	})
+
+	writeHeader(&b, "aiquota_remaining", "gauge", "Remaining balance of a prepaid provider window.")
+	c.eachWindow(func(id string, window provider.Window) {
+		if window.Remaining != nil {
+			writeSample(&b, "aiquota_remaining", *window.Remaining, "provider", id, "window", window.ID)
+		}
+	})
+
+	writeHeader(&b, "aiquota_reset_seconds", "gauge", "Seconds until a provider quota window resets.")
+	c.eachWindow(func(id string, window provider.Window) {
+		resetAt, err := time.Parse(time.RFC3339, window.ResetAt)
+		if err != nil {
+			return
+		}
+
+		writeSample(&b, "aiquota_reset_seconds", math.Max(0, resetAt.Sub(now).Seconds()), "provider", id, "window", window.ID)
+	})
+
+	writeHeader(&b, "aiquota_fetch_errors_total", "counter", "Failed provider fetches since start.")
+	for _, id := range sortedKeys(c.errors) {
+		writeSample(&b, "aiquota_fetch_errors_total", float64(c.errors[id]), "provider", id)
+	}
+
+	writeHeader(&b, "aiquota_last_success_timestamp_seconds", "gauge", "Unix time of the last successful provider fetch.")
+	for _, id := range sortedKeys(c.lastSuccess) {
+		writeSample(&b, "aiquota_last_success_timestamp_seconds", float64(c.lastSuccess[id].Unix()), "provider", id)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (c *Collector) eachWindow(fn func(id string, window provider.Window)) {
+	for _, result := range c.results {
+		if result.Err != nil {
+			continue
+		}
+
+		for _, window := range result.Quota.Windows() {
+			fn(result.Provider.ID(), window)
+		}
+	}
+}
+
+func writeHeader(b *strings.Builder, name string, kind string, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// writeSample writes one sample line. Labels are given as name/value pairs.
+func writeSample(b *strings.Builder, name string, value float64, labels ...string) {
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+
+			fmt.Fprintf(b, `%s="%s"`, labels[i], labelEscaper.Replace(labels[i+1]))
+		}
+		b.WriteByte('}')
+	}
+
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	b.WriteByte('\n')
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
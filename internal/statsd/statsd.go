@@ -0,0 +1,123 @@
+package statsd
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// maxPacket keeps datagrams under the usual 1500 byte MTU.
+const maxPacket = 1432
+
+// nameEscaper replaces the characters StatsD uses as separators in metric
+// names and DogStatsD tags.
+var nameEscaper = strings.NewReplacer(":", "_", "|", "_", "@", "_", ",", "_", "#", "_", " ", "_", "\n", "_")
+
+// Client emits quota gauges to a StatsD or DogStatsD server over UDP.
+type Client struct {
+	// Addr is the host:port of the server.
+	Addr string
+	// Tags sends provider and window as DogStatsD tags. Without it they are
+	// part of the metric name, as in aiquota.used_percent.copilot.premium,
+	// which plain StatsD servers understand.
+	Tags bool
+}
+
+// Send emits aiquota.used_percent, aiquota.used, aiquota.limit and
+// aiquota.remaining gauges for every window, and an aiquota.fetch_errors
+// count for every provider that failed.
+func (c Client) Send(results []provider.Result) error {
+	conn, err := net.DialTimeout("udp", c.Addr, 2*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to StatsD at %s: %w", c.Addr, err)
+	}
+	defer conn.Close()
+
+	var lines []string
+	for _, result := range results {
+		id := nameEscaper.Replace(result.Provider.ID())
+		if result.Err != nil {
+			lines = append(lines, c.line("aiquota.fetch_errors", "1|c", id, ""))
+			continue
+		}
+
+		for _, window := range result.Quota.Windows() {
+			windowID := nameEscaper.Replace(window.ID)
+			for _, gauge := range []struct {
+				name  string
+				value *float64
+			}{
+				{"aiquota.used_percent", window.UsedPercent},
+				{"aiquota.used", window.Used},
+				{"aiquota.limit", window.Limit},
+				{"aiquota.remaining", window.Remaining},
+			} {
+				if gauge.value == nil || math.IsNaN(*gauge.value) || math.IsInf(*gauge.value, 0) {
+					continue
+				}
+
+				value := strconv.FormatFloat(*gauge.value, 'f', -1, 64) + "|g"
+				lines = append(lines, c.line(gauge.name, value, id, windowID))
+			}
+		}
+	}
+
+	for _, packet := range packets(lines) {
+		if _, err := conn.Write([]byte(packet)); err != nil {
+			return fmt.Errorf("failed to send StatsD metrics: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// line formats one metric. window is empty for provider-level metrics.
+func (c Client) line(name string, value string, providerID string, windowID string) string {
+	if !c.Tags {
+		name += "." + providerID
+		if windowID != "" {
+			name += "." + windowID
+		}
+
+		return name + ":" + value
+	}
+
+	tags := "provider:" + providerID
+	if windowID != "" {
+		tags += ",window:" + windowID
+	}
+
+	return name + ":" + value + "|#" + tags
+}
+
+// packets joins lines into newline-separated datagrams of at most
+// maxPacket bytes.
+func packets(lines []string) []string {
+	var (
+		out     []string
+		current strings.Builder
+	)
+
+	for _, line := range lines {
+		if current.Len() > 0 && current.Len()+1+len(line) > maxPacket {
+			out = append(out, current.String())
+			current.Reset()
+		}
+
+		if current.Len() > 0 {
+			current.WriteByte('\n')
+		}
+		current.WriteString(line)
+	}
+
+	if current.Len() > 0 {
+		out = append(out, current.String())
+	}
+
+	return out
+}
@@ -0,0 +1,111 @@
+// Package aiquota is the library entry point of aiquota. It loads
+// credentials, fetches quotas from the supported AI providers and builds the
+// same report the aiquota command prints as JSON:
+//
+//	creds, err := aiquota.LoadCredentials("")
+//	if err != nil {
+//		return err
+//	}
+//
+//	results := aiquota.Fetch(ctx, creds, aiquota.Enabled(creds), aiquota.FetchOptions{})
+//	report := aiquota.NewReport(time.Now(), results)
+//
+// The provider packages under pkg/ can also be used on their own.
+package aiquota
+
+import (
+	"context"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/provider"
+	"github.com/eduardolat/aiquota/pkg/providers"
+)
+
+type (
+	// Provider is a source of quota information.
+	Provider = provider.Provider
+	// Quota is implemented by every provider quota type.
+	Quota = provider.Quota
+	// Window is a provider-agnostic view of a single usage window.
+	Window = provider.Window
+	// Result is the outcome of fetching a single provider.
+	Result = provider.Result
+	// FetchOptions controls how providers are fetched.
+	FetchOptions = provider.FetchOptions
+	// Credentials contains the API keys and tokens of every provider.
+	Credentials = credentials.Credentials
+)
+
+// TimestampLayout is RFC3339 in UTC with millisecond precision, so rapid
+// snapshots can still be told apart and ordered.
+const TimestampLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// LoadCredentials reads credentials from the OpenCode auth file at authFile,
+// or its default location when empty, the other supported CLI files, the OS
+// keychain and AIQUOTA_* environment variables.
+func LoadCredentials(authFile string) (Credentials, error) {
+	return credentials.GetCredentials(authFile)
+}
+
+// Providers returns every known provider in report order.
+func Providers() []Provider {
+	return providers.All()
+}
+
+// Lookup returns the provider with the given ID, such as "copilot".
+func Lookup(id string) (Provider, bool) {
+	return providers.Get(id)
+}
+
+// Enabled returns the known providers that can run with creds.
+func Enabled(creds Credentials) []Provider {
+	return provider.Enabled(providers.All(), creds)
+}
+
+// Fetch fetches the providers concurrently and returns their results in the
+// same order.
+func Fetch(ctx context.Context, creds Credentials, providers []Provider, opts FetchOptions) []Result {
+	return provider.FetchAll(ctx, creds, providers, opts)
+}
+
+// Report is the machine-readable form of a single run. Providers holds the
+// quota of every provider that returned data, keyed by provider ID.
+type Report struct {
+	Timestamp string         `json:"timestamp"`
+	Providers map[string]any `json:"providers"`
+	Warnings  []string       `json:"warnings"`
+}
+
+// NewReport builds the report of results fetched at the given time.
+func NewReport(at time.Time, results []Result) Report {
+	report := Report{
+		Timestamp: at.UTC().Format(TimestampLayout),
+		Providers: map[string]any{},
+		Warnings:  Warnings(results),
+	}
+
+	if report.Warnings == nil {
+		report.Warnings = []string{}
+	}
+
+	for _, result := range results {
+		if result.Err == nil {
+			report.Providers[result.Provider.ID()] = result.Quota
+		}
+	}
+
+	return report
+}
+
+// Warnings returns one message per provider that failed to fetch.
+func Warnings(results []Result) []string {
+	var out []string
+	for _, result := range results {
+		if result.Err != nil {
+			out = append(out, result.Provider.Name()+": "+result.Err.Error())
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,20 @@
+package aiquota
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewReportTimestamp(t *testing.T) {
+	at := time.Date(2026, time.October, 14, 11, 44, 22, 717_652_000, time.FixedZone("CEST", 2*60*60))
+
+	if got, want := NewReport(at, nil).Timestamp, "2026-10-14T09:44:22.717Z"; got != want {
+		t.Errorf("Timestamp = %q, want %q", got, want)
+	}
+
+	// Whole seconds keep their milliseconds, so timestamps sort as strings.
+	at = time.Date(2026, time.October, 14, 9, 44, 22, 0, time.UTC)
+	if got, want := NewReport(at, nil).Timestamp, "2026-10-14T09:44:22.000Z"; got != want {
+		t.Errorf("Timestamp = %q, want %q", got, want)
+	}
+}
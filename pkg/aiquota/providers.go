@@ -0,0 +1,153 @@
+package aiquota
+
+import (
+	"github.com/eduardolat/aiquota/pkg/anthropic"
+	"github.com/eduardolat/aiquota/pkg/azure"
+	"github.com/eduardolat/aiquota/pkg/bedrock"
+	"github.com/eduardolat/aiquota/pkg/codex"
+	"github.com/eduardolat/aiquota/pkg/cohere"
+	"github.com/eduardolat/aiquota/pkg/copilot"
+	"github.com/eduardolat/aiquota/pkg/cursor"
+	"github.com/eduardolat/aiquota/pkg/deepseek"
+	"github.com/eduardolat/aiquota/pkg/fireworks"
+	"github.com/eduardolat/aiquota/pkg/gemini"
+	"github.com/eduardolat/aiquota/pkg/githubmodels"
+	"github.com/eduardolat/aiquota/pkg/groq"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/mistral"
+	"github.com/eduardolat/aiquota/pkg/moonshot"
+	"github.com/eduardolat/aiquota/pkg/openai"
+	"github.com/eduardolat/aiquota/pkg/openrouter"
+	"github.com/eduardolat/aiquota/pkg/perplexity"
+	"github.com/eduardolat/aiquota/pkg/replicate"
+	"github.com/eduardolat/aiquota/pkg/together"
+	"github.com/eduardolat/aiquota/pkg/xai"
+	"github.com/eduardolat/aiquota/pkg/zai"
+)
+
+// Options configures a provider built by one of the constructors below. The
+// zero value talks to the public API through httpclient.Default.
+type Options struct {
+	// BaseURL overrides the default API base URL, for example to go through
+	// a proxy.
+	BaseURL string
+	// HTTPClient sends the requests. Nil means httpclient.Default.
+	HTTPClient httpclient.Doer
+}
+
+// NewCopilot returns the GitHub Copilot provider.
+func NewCopilot(opts Options) Provider {
+	return copilot.Provider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewCopilotOrg returns the GitHub Copilot organization seats provider.
+func NewCopilotOrg(opts Options) Provider {
+	return copilot.OrgProvider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewZAI returns the Z.ai provider.
+func NewZAI(opts Options) Provider {
+	return zai.Provider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewCodex returns the OpenAI Codex provider.
+func NewCodex(opts Options) Provider {
+	return codex.Provider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewReplicate returns the Replicate provider.
+func NewReplicate(opts Options) Provider {
+	return replicate.Provider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewAnthropic returns the Anthropic Claude provider.
+func NewAnthropic(opts Options) Provider {
+	return anthropic.Provider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewAnthropicAPI returns the Anthropic API usage and cost provider, which
+// needs an admin key.
+func NewAnthropicAPI(opts Options) Provider {
+	return anthropic.AdminProvider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewGemini returns the Google Gemini provider.
+func NewGemini(opts Options) Provider {
+	return gemini.Provider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewOpenRouter returns the OpenRouter provider.
+func NewOpenRouter(opts Options) Provider {
+	return openrouter.Provider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewCursor returns the Cursor provider.
+func NewCursor(opts Options) Provider {
+	return cursor.Provider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewMistral returns the Mistral provider.
+func NewMistral(opts Options) Provider {
+	return mistral.Provider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewDeepSeek returns the DeepSeek provider.
+func NewDeepSeek(opts Options) Provider {
+	return deepseek.Provider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewGroq returns the Groq provider.
+func NewGroq(opts Options) Provider {
+	return groq.Provider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewXAI returns the xAI provider.
+func NewXAI(opts Options) Provider {
+	return xai.Provider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewTogether returns the Together AI provider.
+func NewTogether(opts Options) Provider {
+	return together.Provider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewFireworks returns the Fireworks AI provider.
+func NewFireworks(opts Options) Provider {
+	return fireworks.Provider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewCohere returns the Cohere provider.
+func NewCohere(opts Options) Provider {
+	return cohere.Provider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewPerplexity returns the Perplexity provider.
+func NewPerplexity(opts Options) Provider {
+	return perplexity.Provider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewMoonshot returns the Moonshot (Kimi) provider.
+func NewMoonshot(opts Options) Provider {
+	return moonshot.Provider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewOpenAI returns the OpenAI platform billing provider, which needs an
+// admin key.
+func NewOpenAI(opts Options) Provider {
+	return openai.Provider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewAzure returns the Azure OpenAI provider.
+func NewAzure(opts Options) Provider {
+	return azure.Provider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewBedrock returns the AWS Bedrock provider.
+func NewBedrock(opts Options) Provider {
+	return bedrock.Provider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
+
+// NewGitHubModels returns the GitHub Models provider.
+func NewGitHubModels(opts Options) Provider {
+	return githubmodels.Provider{BaseURL: opts.BaseURL, HTTPClient: opts.HTTPClient}
+}
@@ -0,0 +1,163 @@
+package anthropic
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+// maxReportPages bounds how many pages of a usage or cost report are read.
+// One page of daily buckets already covers a whole month.
+const maxReportPages = 5
+
+// ModelUsage contains the tokens consumed by one model this month. Input
+// tokens include cache writes; cache reads are counted separately.
+type ModelUsage struct {
+	Model           string `json:"model"`
+	InputTokens     int64  `json:"inputTokens"`
+	CacheReadTokens int64  `json:"cacheReadTokens"`
+	OutputTokens    int64  `json:"outputTokens"`
+}
+
+// AdminQuota contains the pay-as-you-go API usage and cost of an Anthropic
+// organization for the current calendar month.
+type AdminQuota struct {
+	InputTokens     int64        `json:"inputTokens"`
+	CacheReadTokens int64        `json:"cacheReadTokens"`
+	OutputTokens    int64        `json:"outputTokens"`
+	TotalTokens     int64        `json:"totalTokens"`
+	CostUSD         float64      `json:"costUsd"`
+	Models          []ModelUsage `json:"models"`
+	PeriodStart     string       `json:"periodStart"`
+	ResetAt         string       `json:"resetAt"`
+	ResetIn         string       `json:"resetIn"`
+}
+
+// GetAdminQuota fetches this month's token usage and cost from the Admin API
+// usage and cost reports. It needs an admin key (sk-ant-admin...).
+func GetAdminQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (AdminQuota, error) {
+	if !credentials.HasValue(creds.AnthropicAdminKey) {
+		return AdminQuota{}, fmt.Errorf("missing Anthropic admin key in credentials")
+	}
+
+	now := time.Now().UTC()
+	periodStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	query := url.Values{
+		"starting_at":  {periodStart.Format(time.RFC3339)},
+		"bucket_width": {"1d"},
+		"limit":        {"31"},
+	}
+
+	resetAt := periodStart.AddDate(0, 1, 0).Format(time.RFC3339)
+	result := AdminQuota{
+		Models:      []ModelUsage{},
+		PeriodStart: periodStart.Format(time.RFC3339),
+		ResetAt:     resetAt,
+		ResetIn:     helpers.FormatTimeUntil(resetAt),
+	}
+
+	usageQuery := maps.Clone(query)
+	usageQuery.Set("group_by[]", "model")
+
+	models := map[string]*ModelUsage{}
+	err := getReport(ctx, client, *creds.AnthropicAdminKey, baseURL+"/v1/organizations/usage_report/messages", usageQuery, func(item gjson.Result) {
+		name := cmp.Or(item.Get("model").String(), "other")
+		model, ok := models[name]
+		if !ok {
+			model = &ModelUsage{Model: name}
+			models[name] = model
+		}
+
+		model.InputTokens += item.Get("uncached_input_tokens").Int() +
+			item.Get("cache_creation.ephemeral_5m_input_tokens").Int() +
+			item.Get("cache_creation.ephemeral_1h_input_tokens").Int()
+		model.CacheReadTokens += item.Get("cache_read_input_tokens").Int()
+		model.OutputTokens += item.Get("output_tokens").Int()
+	})
+	if err != nil {
+		return AdminQuota{}, err
+	}
+
+	for _, model := range models {
+		result.Models = append(result.Models, *model)
+		result.InputTokens += model.InputTokens
+		result.CacheReadTokens += model.CacheReadTokens
+		result.OutputTokens += model.OutputTokens
+	}
+	result.TotalTokens = result.InputTokens + result.CacheReadTokens + result.OutputTokens
+	slices.SortFunc(result.Models, func(a, b ModelUsage) int { return strings.Compare(a.Model, b.Model) })
+
+	// Cost amounts are decimal strings in cents.
+	var costCents float64
+	err = getReport(ctx, client, *creds.AnthropicAdminKey, baseURL+"/v1/organizations/cost_report", query, func(item gjson.Result) {
+		amount, err := strconv.ParseFloat(item.Get("amount").String(), 64)
+		if err == nil {
+			costCents += amount
+		}
+	})
+	if err != nil {
+		return AdminQuota{}, err
+	}
+	result.CostUSD = costCents / 100
+
+	return result, nil
+}
+
+// getReport walks the pages of an Admin API report and calls fn for every
+// result of every time bucket.
+func getReport(ctx context.Context, client httpclient.Doer, key string, endpoint string, query url.Values, fn func(gjson.Result)) error {
+	query = maps.Clone(query)
+	for range maxReportPages {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+query.Encode(), nil)
+		if err != nil {
+			return fmt.Errorf("failed to create Anthropic Admin API request: %w", err)
+		}
+
+		req.Header.Set("x-api-key", key)
+		req.Header.Set("anthropic-version", "2023-06-01")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+		response, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to fetch Anthropic API usage: %w", err)
+		}
+
+		body, err := io.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read Anthropic Admin API response: %w", err)
+		}
+
+		if response.StatusCode < 200 || response.StatusCode >= 300 {
+			return fmt.Errorf("failed to fetch Anthropic API usage. Status: %d, Response: %s", response.StatusCode, string(body))
+		}
+
+		for _, bucket := range gjson.GetBytes(body, "data").Array() {
+			for _, item := range bucket.Get("results").Array() {
+				fn(item)
+			}
+		}
+
+		next := gjson.GetBytes(body, "next_page").String()
+		if !gjson.GetBytes(body, "has_more").Bool() || next == "" {
+			return nil
+		}
+		query.Set("page", next)
+	}
+
+	return nil
+}
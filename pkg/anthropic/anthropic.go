@@ -0,0 +1,115 @@
+package anthropic
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultBaseURL is the Anthropic API base URL.
+const DefaultBaseURL = "https://api.anthropic.com"
+
+// RateLimitWindow describes a Claude usage window.
+type RateLimitWindow struct {
+	UsedPercent      *float64 `json:"usedPercent"`
+	RemainingPercent *float64 `json:"remainingPercent"`
+	ResetAt          *string  `json:"resetAt"`
+	ResetIn          *string  `json:"resetIn"`
+}
+
+// Quota contains Claude Pro/Max subscription usage information.
+type Quota struct {
+	AccountType        string          `json:"accountType"`
+	FiveHourWindow     RateLimitWindow `json:"fiveHourWindow"`
+	SevenDayWindow     RateLimitWindow `json:"sevenDayWindow"`
+	SevenDayOpusWindow RateLimitWindow `json:"sevenDayOpusWindow"`
+}
+
+// GetQuota fetches Claude subscription usage windows using the OAuth token.
+func GetQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (Quota, error) {
+	if creds.AnthropicAPIKey == nil || *creds.AnthropicAPIKey == "" {
+		return Quota{}, fmt.Errorf("missing Anthropic OAuth token in credentials")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/api/oauth/usage", nil)
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to create Anthropic request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+*creds.AnthropicAPIKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("anthropic-beta", "oauth-2025-04-20")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to fetch Anthropic quota: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to read Anthropic response: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return Quota{}, fmt.Errorf("failed to fetch Anthropic quota. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	accountType := "unknown"
+	if creds.AnthropicAccountType != nil && *creds.AnthropicAccountType != "" {
+		accountType = *creds.AnthropicAccountType
+	}
+
+	result := Quota{
+		AccountType:        accountType,
+		FiveHourWindow:     RateLimitWindow{},
+		SevenDayWindow:     RateLimitWindow{},
+		SevenDayOpusWindow: RateLimitWindow{},
+	}
+
+	fiveHour := gjson.GetBytes(body, "five_hour")
+	if fiveHour.Exists() && fiveHour.Type != gjson.Null {
+		result.FiveHourWindow = parseWindow(fiveHour)
+	}
+
+	sevenDay := gjson.GetBytes(body, "seven_day")
+	if sevenDay.Exists() && sevenDay.Type != gjson.Null {
+		result.SevenDayWindow = parseWindow(sevenDay)
+	}
+
+	sevenDayOpus := gjson.GetBytes(body, "seven_day_opus")
+	if sevenDayOpus.Exists() && sevenDayOpus.Type != gjson.Null {
+		result.SevenDayOpusWindow = parseWindow(sevenDayOpus)
+	}
+
+	return result, nil
+}
+
+func parseWindow(window gjson.Result) RateLimitWindow {
+	usedPercent := helpers.ClampPercent(window.Get("utilization").Float())
+	remainingPercent := helpers.ClampPercent(100 - usedPercent)
+	resetAt := isoResultToISO(window.Get("resets_at"))
+	resetIn := helpers.FormatTimeUntil(resetAt)
+
+	return RateLimitWindow{
+		UsedPercent:      &usedPercent,
+		RemainingPercent: &remainingPercent,
+		ResetAt:          &resetAt,
+		ResetIn:          &resetIn,
+	}
+}
+
+func isoResultToISO(value gjson.Result) string {
+	if !value.Exists() || value.Type == gjson.Null {
+		return "unknown"
+	}
+
+	return helpers.NormalizeISO(value.String())
+}
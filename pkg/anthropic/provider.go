@@ -0,0 +1,153 @@
+package anthropic
+
+import (
+	"cmp"
+	"context"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// Provider exposes Anthropic Claude subscriptions through the common provider interface.
+type Provider struct {
+	// BaseURL overrides DefaultBaseURL, for example to go through a proxy.
+	BaseURL string
+	// HTTPClient sends the requests. Nil means httpclient.Default.
+	HTTPClient httpclient.Doer
+}
+
+// ID implements provider.Provider.
+func (Provider) ID() string { return "anthropic" }
+
+// Name implements provider.Provider.
+func (Provider) Name() string { return "Anthropic Claude" }
+
+// Enabled implements provider.Provider.
+func (Provider) Enabled(creds credentials.Credentials) bool {
+	return credentials.HasValue(creds.AnthropicAPIKey)
+}
+
+// WithBaseURL implements provider.BaseURLSetter.
+func (p Provider) WithBaseURL(baseURL string) provider.Provider {
+	p.BaseURL = baseURL
+	return p
+}
+
+// Endpoint implements provider.Endpointer.
+func (p Provider) Endpoint() string {
+	return cmp.Or(p.BaseURL, DefaultBaseURL)
+}
+
+// Fetch implements provider.Provider.
+func (p Provider) Fetch(ctx context.Context, creds credentials.Credentials) (provider.Quota, error) {
+	quota, err := GetQuota(ctx, httpclient.OrDefault(p.HTTPClient), creds, cmp.Or(p.BaseURL, DefaultBaseURL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+// Windows implements provider.Quota.
+func (q Quota) Windows() []provider.Window {
+	return []provider.Window{
+		q.FiveHourWindow.window("five_hour", "5-Hour Window"),
+		q.SevenDayWindow.window("seven_day", "Weekly Window"),
+		q.SevenDayOpusWindow.window("seven_day_opus", "Weekly Opus Window"),
+	}
+}
+
+func (w RateLimitWindow) window(id string, name string) provider.Window {
+	resetAt := "unknown"
+	if w.ResetAt != nil {
+		resetAt = *w.ResetAt
+	}
+
+	return provider.Window{
+		ID:          id,
+		Name:        name,
+		UsedPercent: w.UsedPercent,
+		ResetAt:     resetAt,
+	}
+}
+
+// AdminProvider exposes the Anthropic API usage and cost of an organization
+// through the common provider interface. It is separate from Provider, which
+// reports the Claude subscription limits of a personal OAuth login.
+type AdminProvider struct {
+	// BaseURL overrides DefaultBaseURL, for example to go through a proxy.
+	BaseURL string
+	// HTTPClient sends the requests. Nil means httpclient.Default.
+	HTTPClient httpclient.Doer
+}
+
+// ID implements provider.Provider.
+func (AdminProvider) ID() string { return "anthropic-api" }
+
+// Name implements provider.Provider.
+func (AdminProvider) Name() string { return "Anthropic API" }
+
+// Enabled implements provider.Provider.
+func (AdminProvider) Enabled(creds credentials.Credentials) bool {
+	return credentials.HasValue(creds.AnthropicAdminKey)
+}
+
+// WithBaseURL implements provider.BaseURLSetter.
+func (p AdminProvider) WithBaseURL(baseURL string) provider.Provider {
+	p.BaseURL = baseURL
+	return p
+}
+
+// Endpoint implements provider.Endpointer.
+func (p AdminProvider) Endpoint() string {
+	return cmp.Or(p.BaseURL, DefaultBaseURL)
+}
+
+// Fetch implements provider.Provider.
+func (p AdminProvider) Fetch(ctx context.Context, creds credentials.Credentials) (provider.Quota, error) {
+	quota, err := GetAdminQuota(ctx, httpclient.OrDefault(p.HTTPClient), creds, cmp.Or(p.BaseURL, DefaultBaseURL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+// TokenUsage implements provider.TokenReporter. Cache reads are priced as
+// cached input.
+func (q AdminQuota) TokenUsage() ([]provider.TokenUsage, time.Time, time.Time) {
+	usage := make([]provider.TokenUsage, 0, len(q.Models))
+	for _, model := range q.Models {
+		usage = append(usage, provider.TokenUsage{
+			Model:             model.Model,
+			InputTokens:       model.InputTokens,
+			CachedInputTokens: model.CacheReadTokens,
+			OutputTokens:      model.OutputTokens,
+		})
+	}
+
+	start, _ := time.Parse(time.RFC3339, q.PeriodStart)
+	end, _ := time.Parse(time.RFC3339, q.ResetAt)
+	return usage, start, end
+}
+
+// Windows implements provider.Quota. Pay-as-you-go usage has no limit, so
+// both windows only carry the amount used this month.
+func (q AdminQuota) Windows() []provider.Window {
+	return []provider.Window{
+		{
+			ID:      "cost",
+			Name:    "Monthly Cost (USD)",
+			Used:    new(q.CostUSD),
+			ResetAt: q.ResetAt,
+		},
+		{
+			ID:      "tokens",
+			Name:    "Monthly Tokens",
+			Used:    new(float64(q.TotalTokens)),
+			ResetAt: q.ResetAt,
+		},
+	}
+}
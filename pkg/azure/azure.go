@@ -0,0 +1,183 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultBaseURL is the Azure Resource Manager base URL.
+const DefaultBaseURL = "https://management.azure.com"
+
+// apiVersion is the Microsoft.CognitiveServices API version used for every
+// request.
+const apiVersion = "2024-10-01"
+
+// Usage is the regional quota of one Azure OpenAI model and SKU, such as
+// "OpenAI.Standard.gpt-4o". Azure counts token quota in units of 1,000
+// tokens per minute.
+type Usage struct {
+	Name    string  `json:"name"`
+	Display string  `json:"display"`
+	Current float64 `json:"current"`
+	Limit   float64 `json:"limit"`
+	Unit    string  `json:"unit"`
+}
+
+// Deployment is one model deployment of the configured resource with its
+// provisioned rate limits.
+type Deployment struct {
+	Name              string `json:"name"`
+	Model             string `json:"model"`
+	SKU               string `json:"sku"`
+	Capacity          int64  `json:"capacity"`
+	RequestsPerMinute *int64 `json:"requestsPerMinute"`
+	TokensPerMinute   *int64 `json:"tokensPerMinute"`
+}
+
+// Quota contains the Azure OpenAI quota of a subscription in one region and,
+// when a resource is configured, its deployments.
+type Quota struct {
+	SubscriptionID string       `json:"subscriptionId"`
+	Location       string       `json:"location"`
+	Usages         []Usage      `json:"usages"`
+	Deployments    []Deployment `json:"deployments"`
+}
+
+// GetQuota fetches the Azure OpenAI quota usages of the subscription in the
+// configured location, or in the location of the configured resource.
+func GetQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (Quota, error) {
+	if !credentials.HasValue(creds.AzureSubscriptionID) {
+		return Quota{}, fmt.Errorf("missing Azure subscription ID in credentials")
+	}
+
+	token, err := accessToken(ctx, client, creds)
+	if err != nil {
+		return Quota{}, err
+	}
+
+	subscription := baseURL + "/subscriptions/" + url.PathEscape(*creds.AzureSubscriptionID)
+	result := Quota{
+		SubscriptionID: *creds.AzureSubscriptionID,
+		Usages:         []Usage{},
+		Deployments:    []Deployment{},
+	}
+	if creds.AzureLocation != nil {
+		result.Location = *creds.AzureLocation
+	}
+
+	hasResource := credentials.HasValue(creds.AzureResourceGroup) && credentials.HasValue(creds.AzureAccount)
+	if hasResource {
+		account := subscription +
+			"/resourceGroups/" + url.PathEscape(*creds.AzureResourceGroup) +
+			"/providers/Microsoft.CognitiveServices/accounts/" + url.PathEscape(*creds.AzureAccount)
+
+		body, err := get(ctx, client, token, account)
+		if err != nil {
+			return Quota{}, err
+		}
+		if result.Location == "" {
+			result.Location = gjson.GetBytes(body, "location").String()
+		}
+
+		if body, err = get(ctx, client, token, account+"/deployments"); err != nil {
+			return Quota{}, err
+		}
+		result.Deployments = parseDeployments(gjson.GetBytes(body, "value"))
+	}
+
+	if result.Location == "" {
+		return Quota{}, fmt.Errorf("missing Azure location, set AIQUOTA_AZURE_LOCATION or AIQUOTA_AZURE_RESOURCE")
+	}
+
+	body, err := get(ctx, client, token, subscription+"/providers/Microsoft.CognitiveServices/locations/"+url.PathEscape(result.Location)+"/usages")
+	if err != nil {
+		return Quota{}, err
+	}
+
+	for _, usage := range gjson.GetBytes(body, "value").Array() {
+		name := usage.Get("name.value").String()
+		limit := usage.Get("limit").Float()
+		if !strings.HasPrefix(name, "OpenAI.") || limit <= 0 {
+			continue
+		}
+
+		result.Usages = append(result.Usages, Usage{
+			Name:    name,
+			Display: usage.Get("name.localizedValue").String(),
+			Current: usage.Get("currentValue").Float(),
+			Limit:   limit,
+			Unit:    usage.Get("unit").String(),
+		})
+	}
+	slices.SortFunc(result.Usages, func(a, b Usage) int { return strings.Compare(a.Name, b.Name) })
+
+	return result, nil
+}
+
+func parseDeployments(values gjson.Result) []Deployment {
+	deployments := []Deployment{}
+	for _, value := range values.Array() {
+		deployment := Deployment{
+			Name:     value.Get("name").String(),
+			Model:    value.Get("properties.model.name").String(),
+			SKU:      value.Get("sku.name").String(),
+			Capacity: value.Get("sku.capacity").Int(),
+		}
+
+		for _, limit := range value.Get("properties.rateLimits").Array() {
+			// Limits are given per renewal period in seconds.
+			count := limit.Get("count").Float()
+			if period := limit.Get("renewalPeriod").Float(); period > 0 {
+				count = count * 60 / period
+			}
+
+			switch limit.Get("key").String() {
+			case "request":
+				deployment.RequestsPerMinute = new(int64(count))
+			case "token":
+				deployment.TokensPerMinute = new(int64(count))
+			}
+		}
+
+		deployments = append(deployments, deployment)
+	}
+
+	return deployments
+}
+
+func get(ctx context.Context, client httpclient.Doer, token string, endpoint string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?api-version="+apiVersion, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Azure OpenAI quota: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Azure response: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to fetch Azure OpenAI quota. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	return body, nil
+}
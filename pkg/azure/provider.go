@@ -0,0 +1,72 @@
+package azure
+
+import (
+	"cmp"
+	"context"
+	"strings"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// Provider exposes Azure OpenAI through the common provider interface.
+type Provider struct {
+	// BaseURL overrides DefaultBaseURL, for example for a sovereign cloud.
+	BaseURL string
+	// HTTPClient sends the requests. Nil means httpclient.Default.
+	HTTPClient httpclient.Doer
+}
+
+// ID implements provider.Provider.
+func (Provider) ID() string { return "azure" }
+
+// Name implements provider.Provider.
+func (Provider) Name() string { return "Azure OpenAI" }
+
+// Enabled implements provider.Provider.
+func (Provider) Enabled(creds credentials.Credentials) bool {
+	return credentials.HasValue(creds.AzureSubscriptionID) &&
+		(credentials.HasValue(creds.AzureToken) || credentials.HasValue(creds.AzureClientSecret))
+}
+
+// WithBaseURL implements provider.BaseURLSetter.
+func (p Provider) WithBaseURL(baseURL string) provider.Provider {
+	p.BaseURL = baseURL
+	return p
+}
+
+// Endpoint implements provider.Endpointer.
+func (p Provider) Endpoint() string {
+	return cmp.Or(p.BaseURL, DefaultBaseURL)
+}
+
+// Fetch implements provider.Provider.
+func (p Provider) Fetch(ctx context.Context, creds credentials.Credentials) (provider.Quota, error) {
+	quota, err := GetQuota(ctx, httpclient.OrDefault(p.HTTPClient), creds, cmp.Or(p.BaseURL, DefaultBaseURL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+// Windows implements provider.Quota with one window per model quota, keyed
+// by the usage name without its "OpenAI." prefix, such as
+// "standard.gpt-4o". Regional quota does not reset.
+func (q Quota) Windows() []provider.Window {
+	windows := make([]provider.Window, 0, len(q.Usages))
+	for _, usage := range q.Usages {
+		windows = append(windows, provider.Window{
+			ID:          strings.ToLower(strings.TrimPrefix(usage.Name, "OpenAI.")),
+			Name:        cmp.Or(usage.Display, usage.Name),
+			UsedPercent: new(usage.Current / usage.Limit * 100),
+			Used:        new(usage.Current),
+			Limit:       new(usage.Limit),
+			Remaining:   new(max(usage.Limit-usage.Current, 0)),
+			ResetAt:     "unknown",
+		})
+	}
+
+	return windows
+}
@@ -0,0 +1,72 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+// AuthorityURL is the Microsoft Entra ID login endpoint.
+const AuthorityURL = "https://login.microsoftonline.com"
+
+// managementScope is the OAuth scope of the Azure Resource Manager API.
+const managementScope = "https://management.azure.com/.default"
+
+// accessToken returns the management token to use, exchanging the service
+// principal secret for one when no token was given.
+func accessToken(ctx context.Context, client httpclient.Doer, creds credentials.Credentials) (string, error) {
+	if credentials.HasValue(creds.AzureToken) {
+		return *creds.AzureToken, nil
+	}
+
+	if !credentials.HasValue(creds.AzureTenantID) || !credentials.HasValue(creds.AzureClientID) || !credentials.HasValue(creds.AzureClientSecret) {
+		return "", fmt.Errorf("missing Azure token or service principal (tenant, client ID and secret) in credentials")
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {*creds.AzureClientID},
+		"client_secret": {*creds.AzureClientSecret},
+		"scope":         {managementScope},
+	}
+
+	tokenURL := AuthorityURL + "/" + url.PathEscape(*creds.AzureTenantID) + "/oauth2/v2.0/token"
+	// Repeating a client credentials grant only issues another token.
+	req, err := http.NewRequestWithContext(httpclient.WithIdempotent(ctx), http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create Azure token request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Azure token: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Azure token response: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return "", fmt.Errorf("failed to get Azure token. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	token := gjson.GetBytes(body, "access_token").String()
+	if token == "" {
+		return "", fmt.Errorf("failed to get Azure token: response has no access_token")
+	}
+
+	return token, nil
+}
@@ -0,0 +1,270 @@
+package bedrock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultBaseURL is the AWS endpoint template. {service} and {region} are
+// replaced per request; a base URL without them sends every service to the
+// same host, which is useful for proxies and local stacks.
+const DefaultBaseURL = "https://{service}.{region}.amazonaws.com"
+
+const (
+	// defaultRegion is used when neither AIQUOTA_BEDROCK_REGIONS nor
+	// AWS_REGION is set.
+	defaultRegion = "us-east-1"
+
+	// metricWindow is how far back the invocation peak is searched.
+	metricWindow = 15 * time.Minute
+
+	// quotaPrefix starts the name of the on-demand requests per minute quota
+	// of every model, followed by the provider and model name.
+	quotaPrefix = "on-demand model inference requests per minute for "
+
+	// maxQuotaPages bounds how many pages of Service Quotas are read per
+	// region.
+	maxQuotaPages = 10
+)
+
+// ModelQuota is the requests per minute quota of one model in one region
+// and the busiest minute of the last 15 minutes.
+type ModelQuota struct {
+	Region                 string   `json:"region"`
+	ModelID                string   `json:"modelId"`
+	ModelName              string   `json:"modelName"`
+	QuotaName              string   `json:"quotaName"`
+	RequestsPerMinuteLimit *float64 `json:"requestsPerMinuteLimit"`
+	PeakRequestsPerMinute  float64  `json:"peakRequestsPerMinute"`
+	UsedPercent            *float64 `json:"usedPercent"`
+}
+
+// Quota contains the Bedrock requests per minute utilization of the models
+// in AIQUOTA_BEDROCK_MODELS for every configured region.
+type Quota struct {
+	Models []ModelQuota `json:"models"`
+}
+
+// Regions returns the regions to query: AIQUOTA_BEDROCK_REGIONS, then
+// AWS_REGION and AWS_DEFAULT_REGION.
+func Regions(creds credentials.Credentials) []string {
+	if len(creds.BedrockRegions) > 0 {
+		return creds.BedrockRegions
+	}
+
+	for _, name := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if region := os.Getenv(name); region != "" {
+			return []string{region}
+		}
+	}
+
+	return []string{defaultRegion}
+}
+
+// GetQuota fetches the Service Quotas limits and the CloudWatch invocation
+// metrics of the configured models.
+func GetQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (Quota, error) {
+	if !credentials.HasValue(creds.AWSAccessKeyID) || !credentials.HasValue(creds.AWSSecretAccessKey) {
+		return Quota{}, fmt.Errorf("missing AWS access key in credentials")
+	}
+	if len(creds.BedrockModels) == 0 {
+		return Quota{}, fmt.Errorf("missing Bedrock models, set AIQUOTA_BEDROCK_MODELS")
+	}
+
+	result := Quota{Models: []ModelQuota{}}
+	for _, region := range Regions(creds) {
+		models, err := getRegion(ctx, client, creds, baseURL, region)
+		if err != nil {
+			return Quota{}, err
+		}
+		result.Models = append(result.Models, models...)
+	}
+
+	return result, nil
+}
+
+func getRegion(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string, region string) ([]ModelQuota, error) {
+	call := awsClient{client: client, creds: creds, baseURL: baseURL, region: region}
+
+	body, err := call.do(ctx, "bedrock", http.MethodGet, "/foundation-models", "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]string{}
+	for _, model := range gjson.GetBytes(body, "modelSummaries").Array() {
+		names[model.Get("modelId").String()] = model.Get("providerName").String() + " " + model.Get("modelName").String()
+	}
+
+	quotas, err := listQuotas(ctx, call)
+	if err != nil {
+		return nil, err
+	}
+
+	peaks, err := peakInvocations(ctx, call, creds.BedrockModels)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]ModelQuota, 0, len(creds.BedrockModels))
+	for i, id := range creds.BedrockModels {
+		model := ModelQuota{
+			Region:                region,
+			ModelID:               id,
+			ModelName:             names[id],
+			PeakRequestsPerMinute: peaks[i],
+		}
+
+		if model.ModelName != "" {
+			model.QuotaName = quotaPrefix + strings.ToLower(model.ModelName)
+			if limit, ok := quotas[model.QuotaName]; ok && limit > 0 {
+				model.RequestsPerMinuteLimit = new(limit)
+				model.UsedPercent = new(helpers.ClampPercent(model.PeakRequestsPerMinute / limit * 100))
+			}
+		}
+
+		models = append(models, model)
+	}
+
+	return models, nil
+}
+
+// listQuotas returns the Bedrock quota values keyed by lower-cased name.
+func listQuotas(ctx context.Context, call awsClient) (map[string]float64, error) {
+	quotas := map[string]float64{}
+	request := map[string]any{"ServiceCode": "bedrock", "MaxResults": 100}
+
+	for range maxQuotaPages {
+		payload, err := json.Marshal(request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode Service Quotas request: %w", err)
+		}
+
+		body, err := call.do(ctx, "servicequotas", http.MethodPost, "/", "ServiceQuotasV20190624.ListServiceQuotas", payload)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, quota := range gjson.GetBytes(body, "Quotas").Array() {
+			quotas[strings.ToLower(quota.Get("QuotaName").String())] = quota.Get("Value").Float()
+		}
+
+		next := gjson.GetBytes(body, "NextToken").String()
+		if next == "" {
+			break
+		}
+		request["NextToken"] = next
+	}
+
+	return quotas, nil
+}
+
+// peakInvocations returns, for each model, the largest number of
+// invocations in a single minute over the last metricWindow.
+func peakInvocations(ctx context.Context, call awsClient, models []string) ([]float64, error) {
+	now := time.Now()
+	queries := make([]map[string]any, 0, len(models))
+	for i, model := range models {
+		queries = append(queries, map[string]any{
+			"Id": "m" + strconv.Itoa(i),
+			"MetricStat": map[string]any{
+				"Metric": map[string]any{
+					"Namespace":  "AWS/Bedrock",
+					"MetricName": "Invocations",
+					"Dimensions": []map[string]string{{"Name": "ModelId", "Value": model}},
+				},
+				"Period": 60,
+				"Stat":   "Sum",
+			},
+		})
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"MetricDataQueries": queries,
+		"StartTime":         now.Add(-metricWindow).Unix(),
+		"EndTime":           now.Unix(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode CloudWatch request: %w", err)
+	}
+
+	body, err := call.do(ctx, "monitoring", http.MethodPost, "/", "GraniteServiceVersion20100801.GetMetricData", payload)
+	if err != nil {
+		return nil, err
+	}
+
+	peaks := make([]float64, len(models))
+	for _, metric := range gjson.GetBytes(body, "MetricDataResults").Array() {
+		index, err := strconv.Atoi(strings.TrimPrefix(metric.Get("Id").String(), "m"))
+		if err != nil || index < 0 || index >= len(peaks) {
+			continue
+		}
+
+		for _, value := range metric.Get("Values").Array() {
+			peaks[index] = max(peaks[index], value.Float())
+		}
+	}
+
+	return peaks, nil
+}
+
+// awsClient sends signed requests to the AWS services of one region.
+type awsClient struct {
+	client  httpclient.Doer
+	creds   credentials.Credentials
+	baseURL string
+	region  string
+}
+
+// do sends a request to service. A non-empty target makes it an AWS JSON
+// protocol call, as used by Service Quotas and CloudWatch.
+func (c awsClient) do(ctx context.Context, service string, method string, path string, target string, payload []byte) ([]byte, error) {
+	endpoint := strings.NewReplacer("{service}", service, "{region}", c.region).Replace(c.baseURL)
+	// ListServiceQuotas and GetMetricData are read-only.
+	req, err := http.NewRequestWithContext(httpclient.WithIdempotent(ctx), method, endpoint+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS %s request: %w", service, err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+	if target != "" {
+		contentType := "application/x-amz-json-1.1"
+		if service == "monitoring" {
+			contentType = "application/x-amz-json-1.0"
+		}
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("X-Amz-Target", target)
+	}
+	sign(req, payload, c.creds, c.region, service, time.Now())
+
+	response, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Bedrock quota from %s: %w", service, err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AWS %s response: %w", service, err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to fetch Bedrock quota from %s in %s. Status: %d, Response: %s", service, c.region, response.StatusCode, string(body))
+	}
+
+	return body, nil
+}
@@ -0,0 +1,72 @@
+package bedrock
+
+import (
+	"cmp"
+	"context"
+	"strings"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// Provider exposes AWS Bedrock through the common provider interface.
+type Provider struct {
+	// BaseURL overrides DefaultBaseURL, for example to go through a proxy.
+	BaseURL string
+	// HTTPClient sends the requests. Nil means httpclient.Default.
+	HTTPClient httpclient.Doer
+}
+
+// ID implements provider.Provider.
+func (Provider) ID() string { return "bedrock" }
+
+// Name implements provider.Provider.
+func (Provider) Name() string { return "AWS Bedrock" }
+
+// Enabled implements provider.Provider. AWS keys are common on developer
+// machines, so the provider also needs AIQUOTA_BEDROCK_MODELS.
+func (Provider) Enabled(creds credentials.Credentials) bool {
+	return credentials.HasValue(creds.AWSAccessKeyID) && len(creds.BedrockModels) > 0
+}
+
+// WithBaseURL implements provider.BaseURLSetter.
+func (p Provider) WithBaseURL(baseURL string) provider.Provider {
+	p.BaseURL = baseURL
+	return p
+}
+
+// Endpoint implements provider.Endpointer with the Bedrock endpoint of the
+// default region.
+func (p Provider) Endpoint() string {
+	return strings.NewReplacer("{service}", "bedrock", "{region}", defaultRegion).Replace(cmp.Or(p.BaseURL, DefaultBaseURL))
+}
+
+// Fetch implements provider.Provider.
+func (p Provider) Fetch(ctx context.Context, creds credentials.Credentials) (provider.Quota, error) {
+	quota, err := GetQuota(ctx, httpclient.OrDefault(p.HTTPClient), creds, cmp.Or(p.BaseURL, DefaultBaseURL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+// Windows implements provider.Quota with one window per model and region,
+// keyed as "<model ID>@<region>". Per-minute quotas renew continuously, so
+// no reset time is reported.
+func (q Quota) Windows() []provider.Window {
+	windows := make([]provider.Window, 0, len(q.Models))
+	for _, model := range q.Models {
+		windows = append(windows, provider.Window{
+			ID:          model.ModelID + "@" + model.Region,
+			Name:        cmp.Or(model.ModelName, model.ModelID) + " RPM (" + model.Region + ")",
+			UsedPercent: model.UsedPercent,
+			Used:        new(model.PeakRequestsPerMinute),
+			Limit:       model.RequestsPerMinuteLimit,
+			ResetAt:     "unknown",
+		})
+	}
+
+	return windows
+}
@@ -0,0 +1,289 @@
+package codex
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultBaseURL is the ChatGPT backend API base URL.
+const DefaultBaseURL = "https://chatgpt.com/backend-api"
+
+// RateLimitWindow describes a quota window.
+type RateLimitWindow struct {
+	UsedPercent      *float64 `json:"usedPercent"`
+	RemainingPercent *float64 `json:"remainingPercent"`
+	ResetAt          *string  `json:"resetAt"`
+	ResetIn          *string  `json:"resetIn"`
+}
+
+// NamedWindow is a rate-limit window beyond the fixed ones, such as a
+// tertiary window or the limits of a metered feature.
+type NamedWindow struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	RateLimitWindow
+}
+
+// Credits is the balance of purchased credits, used once the plan limits are
+// reached. Amounts the endpoint leaves out are nil.
+type Credits struct {
+	HasCredits bool     `json:"hasCredits"`
+	Unlimited  bool     `json:"unlimited"`
+	Granted    *float64 `json:"granted"`
+	Used       *float64 `json:"used"`
+	Remaining  *float64 `json:"remaining"`
+}
+
+// Quota contains Codex account and rate-limit usage information.
+type Quota struct {
+	AccountEmail string `json:"accountEmail"`
+	AccountType  string `json:"accountType"`
+	// TokenExpiresAt is when the access token used expires, empty when it
+	// has no readable expiry.
+	TokenExpiresAt           string          `json:"tokenExpiresAt,omitempty"`
+	RateLimitPrimaryWindow   RateLimitWindow `json:"rateLimitPrimaryWindow"`
+	RateLimitSecondaryWindow RateLimitWindow `json:"rateLimitSecondaryWindow"`
+	CodeReviewPrimaryWindow  RateLimitWindow `json:"codeReviewPrimaryWindow"`
+	// AdditionalWindows lists the other windows the endpoint reports, in
+	// the order it reports them.
+	AdditionalWindows []NamedWindow `json:"additionalWindows,omitempty"`
+	Credits           *Credits      `json:"credits,omitempty"`
+}
+
+// GetQuota fetches Codex usage and rate limit information.
+//
+// When a refresh token is available, an expired access token is refreshed
+// before the request and a rejected one is refreshed and retried once. The
+// new tokens are written back to the auth file they were read from, and
+// later calls with the same credentials use them.
+func GetQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (Quota, error) {
+	creds = currentTokens(creds)
+	refreshed := false
+	if canRefresh(creds) && accessTokenExpired(creds) {
+		var err error
+		if creds, err = refreshTokens(ctx, client, creds); err != nil {
+			return Quota{}, err
+		}
+		refreshed = true
+	}
+
+	if creds.CodexAPIKey == nil || *creds.CodexAPIKey == "" {
+		return Quota{}, fmt.Errorf("missing Codex API key in credentials")
+	}
+
+	// Without a refresh token an expired access token would only get a 401.
+	expiry, expires := helpers.JWTExpiry(*creds.CodexAPIKey)
+	if expires && !canRefresh(creds) && time.Now().After(expiry) {
+		return Quota{}, fmt.Errorf("Codex access token expired %s ago, log in to Codex again", helpers.FormatDuration(time.Since(expiry)))
+	}
+
+	body, status, err := fetchUsage(ctx, client, creds, baseURL)
+	if err != nil {
+		return Quota{}, err
+	}
+
+	if status == http.StatusUnauthorized && canRefresh(creds) && !refreshed {
+		if creds, err = refreshTokens(ctx, client, creds); err != nil {
+			return Quota{}, err
+		}
+
+		body, status, err = fetchUsage(ctx, client, creds, baseURL)
+		if err != nil {
+			return Quota{}, err
+		}
+	}
+
+	if status < 200 || status >= 300 {
+		return Quota{}, fmt.Errorf("failed to fetch OpenAI quota. Status: %d, Response: %s", status, string(body))
+	}
+
+	// A refresh after the 401 replaced the token.
+	expiry, expires = helpers.JWTExpiry(*creds.CodexAPIKey)
+
+	result := Quota{
+		AccountEmail:             gjson.GetBytes(body, "email").String(),
+		AccountType:              gjson.GetBytes(body, "plan_type").String(),
+		RateLimitPrimaryWindow:   RateLimitWindow{},
+		RateLimitSecondaryWindow: RateLimitWindow{},
+		CodeReviewPrimaryWindow:  RateLimitWindow{},
+	}
+
+	primary := gjson.GetBytes(body, "rate_limit.primary_window")
+	if primary.Exists() && primary.Type != gjson.Null {
+		result.RateLimitPrimaryWindow = parseWindow(primary)
+	}
+
+	secondary := gjson.GetBytes(body, "rate_limit.secondary_window")
+	if secondary.Exists() && secondary.Type != gjson.Null {
+		result.RateLimitSecondaryWindow = parseWindow(secondary)
+	}
+
+	codeReview := gjson.GetBytes(body, "code_review_rate_limit.primary_window")
+	if codeReview.Exists() && codeReview.Type != gjson.Null {
+		result.CodeReviewPrimaryWindow = parseWindow(codeReview)
+	}
+
+	if expires {
+		result.TokenExpiresAt = expiry.UTC().Format(time.RFC3339)
+	}
+
+	result.AdditionalWindows = parseAdditionalWindows(body)
+	result.Credits = parseCredits(gjson.GetBytes(body, "credits"))
+
+	return result, nil
+}
+
+// parseAdditionalWindows reads the rate_limit windows past the primary and
+// secondary ones, then the primary and secondary windows of every entry of
+// additional_rate_limits.
+func parseAdditionalWindows(body []byte) []NamedWindow {
+	var windows []NamedWindow
+	add := func(id string, name string, value gjson.Result) {
+		if value.IsObject() {
+			windows = append(windows, NamedWindow{ID: id, Name: name, RateLimitWindow: parseWindow(value)})
+		}
+	}
+
+	gjson.GetBytes(body, "rate_limit").ForEach(func(key, value gjson.Result) bool {
+		name, ok := strings.CutSuffix(key.String(), "_window")
+		if ok && name != "primary" && name != "secondary" {
+			add(name, "Rate Limit "+titleCase(name)+" Window", value)
+		}
+		return true
+	})
+
+	for _, limit := range gjson.GetBytes(body, "additional_rate_limits").Array() {
+		name := cmp.Or(limit.Get("limit_name").String(), limit.Get("metered_feature").String())
+		if name == "" {
+			continue
+		}
+
+		id := slug(name)
+		add(id+"_primary", name+" Primary Window", limit.Get("rate_limit.primary_window"))
+		add(id+"_secondary", name+" Secondary Window", limit.Get("rate_limit.secondary_window"))
+	}
+
+	return windows
+}
+
+// parseCredits reads the credit balance, which the endpoint may send as a
+// string. The used or remaining amount is derived from the others when
+// missing.
+func parseCredits(value gjson.Result) *Credits {
+	if !value.IsObject() {
+		return nil
+	}
+
+	amount := func(paths ...string) *float64 {
+		for _, path := range paths {
+			if field := value.Get(path); field.Exists() && field.Type != gjson.Null && field.String() != "" {
+				return new(field.Float())
+			}
+		}
+		return nil
+	}
+
+	credits := &Credits{
+		HasCredits: value.Get("has_credits").Bool(),
+		Unlimited:  value.Get("unlimited").Bool(),
+		Granted:    amount("granted", "total_granted"),
+		Used:       amount("used", "total_used"),
+		Remaining:  amount("balance", "remaining"),
+	}
+
+	if credits.Granted != nil {
+		switch {
+		case credits.Used == nil && credits.Remaining != nil:
+			credits.Used = new(max(0, *credits.Granted-*credits.Remaining))
+		case credits.Remaining == nil && credits.Used != nil:
+			credits.Remaining = new(max(0, *credits.Granted-*credits.Used))
+		}
+	}
+
+	return credits
+}
+
+// slug turns a limit name such as "GPT-5 Codex Mini" into a window ID such as
+// "gpt_5_codex_mini".
+func slug(name string) string {
+	var parts []string
+	for part := range strings.FieldsFuncSeq(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		parts = append(parts, part)
+	}
+
+	return strings.Join(parts, "_")
+}
+
+// titleCase turns a snake_case name such as "tertiary" into "Tertiary".
+func titleCase(name string) string {
+	words := strings.Split(name, "_")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// fetchUsage requests the usage endpoint and returns the body and status code.
+func fetchUsage(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/wham/usage", nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create Codex request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+*creds.CodexAPIKey)
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+	if creds.CodexAccountID != nil && *creds.CodexAccountID != "" {
+		req.Header.Set("ChatGPT-Account-Id", *creds.CodexAccountID)
+	}
+
+	response, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch Codex quota: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read Codex response: %w", err)
+	}
+
+	return body, response.StatusCode, nil
+}
+
+func parseWindow(window gjson.Result) RateLimitWindow {
+	usedPercent := helpers.ClampPercent(window.Get("used_percent").Float())
+	remainingPercent := helpers.ClampPercent(100 - usedPercent)
+	resetAt := unixSecondsResultToISO(window.Get("reset_at"))
+	resetIn := helpers.FormatTimeUntil(resetAt)
+
+	return RateLimitWindow{
+		UsedPercent:      &usedPercent,
+		RemainingPercent: &remainingPercent,
+		ResetAt:          &resetAt,
+		ResetIn:          &resetIn,
+	}
+}
+
+func unixSecondsResultToISO(value gjson.Result) string {
+	if !value.Exists() || value.Type == gjson.Null {
+		return "unknown"
+	}
+
+	return helpers.UnixSecondsToISO(value.Float())
+}
@@ -0,0 +1,95 @@
+package codex
+
+import (
+	"cmp"
+	"context"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// Provider exposes OpenAI Codex through the common provider interface.
+type Provider struct {
+	// BaseURL overrides DefaultBaseURL, for example to go through a proxy.
+	BaseURL string
+	// HTTPClient sends the requests. Nil means httpclient.Default.
+	HTTPClient httpclient.Doer
+}
+
+// ID implements provider.Provider.
+func (Provider) ID() string { return "codex" }
+
+// Name implements provider.Provider.
+func (Provider) Name() string { return "OpenAI Codex" }
+
+// Enabled implements provider.Provider.
+func (Provider) Enabled(creds credentials.Credentials) bool {
+	return credentials.HasValue(creds.CodexAPIKey)
+}
+
+// WithBaseURL implements provider.BaseURLSetter.
+func (p Provider) WithBaseURL(baseURL string) provider.Provider {
+	p.BaseURL = baseURL
+	return p
+}
+
+// Endpoint implements provider.Endpointer.
+func (p Provider) Endpoint() string {
+	return cmp.Or(p.BaseURL, DefaultBaseURL)
+}
+
+// Fetch implements provider.Provider.
+func (p Provider) Fetch(ctx context.Context, creds credentials.Credentials) (provider.Quota, error) {
+	quota, err := GetQuota(ctx, httpclient.OrDefault(p.HTTPClient), creds, cmp.Or(p.BaseURL, DefaultBaseURL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+// Windows implements provider.Quota. A limited balance of purchased credits
+// adds a "credits" window.
+func (q Quota) Windows() []provider.Window {
+	windows := []provider.Window{
+		q.RateLimitPrimaryWindow.window("primary", "Rate Limit Primary Window"),
+		q.RateLimitSecondaryWindow.window("secondary", "Rate Limit Secondary Window"),
+		q.CodeReviewPrimaryWindow.window("code_review", "Code Review Primary Window"),
+	}
+
+	for _, additional := range q.AdditionalWindows {
+		windows = append(windows, additional.window(additional.ID, additional.Name))
+	}
+
+	if q.Credits != nil && q.Credits.HasCredits && !q.Credits.Unlimited && (q.Credits.Remaining != nil || q.Credits.Used != nil) {
+		window := provider.Window{
+			ID:        "credits",
+			Name:      "Credits",
+			Used:      q.Credits.Used,
+			Limit:     q.Credits.Granted,
+			Remaining: q.Credits.Remaining,
+			ResetAt:   "unknown",
+		}
+		if q.Credits.Granted != nil && *q.Credits.Granted > 0 && q.Credits.Used != nil {
+			window.UsedPercent = new(*q.Credits.Used / *q.Credits.Granted * 100)
+		}
+		windows = append(windows, window)
+	}
+
+	return windows
+}
+
+func (w RateLimitWindow) window(id string, name string) provider.Window {
+	resetAt := "unknown"
+	if w.ResetAt != nil {
+		resetAt = *w.ResetAt
+	}
+
+	return provider.Window{
+		ID:          id,
+		Name:        name,
+		UsedPercent: w.UsedPercent,
+		ResetAt:     resetAt,
+	}
+}
@@ -0,0 +1,175 @@
+package codex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	tokenURL = "https://auth.openai.com/oauth/token"
+
+	// clientID is the public OAuth client used by the Codex CLI and OpenCode.
+	clientID = "app_EMoamEEZ73f0CkXaXp7hrann"
+
+	// expiryMargin refreshes tokens slightly before they actually expire.
+	expiryMargin = time.Minute
+)
+
+// rotations maps each refresh token this process exchanged to the tokens it
+// got back, which keep the same refresh token when the server does not
+// rotate it. Refresh tokens are single use, and watch, serve, daemon and the
+// TUI keep the credentials they read at startup, so every fetch starts from
+// the latest tokens instead of a revoked refresh token or an expired access
+// token. The mutex also keeps two fetches from refreshing at once.
+var rotations = struct {
+	sync.Mutex
+	tokens map[string]rotatedTokens
+}{tokens: map[string]rotatedTokens{}}
+
+type rotatedTokens struct {
+	accessToken  string
+	refreshToken string
+}
+
+// latestTokens returns creds with the tokens its refresh token was last
+// rotated to. rotations must be locked.
+func latestTokens(creds credentials.Credentials) credentials.Credentials {
+	if !canRefresh(creds) {
+		return creds
+	}
+
+	// A server that does not rotate returns the same refresh token, which
+	// must not loop.
+	for range len(rotations.tokens) {
+		rotated, ok := rotations.tokens[*creds.CodexRefreshToken]
+		if !ok {
+			break
+		}
+
+		creds.CodexAPIKey, creds.CodexRefreshToken = &rotated.accessToken, &rotated.refreshToken
+	}
+
+	return creds
+}
+
+// currentTokens returns creds with the tokens of the latest rotation of its
+// refresh token in this process.
+func currentTokens(creds credentials.Credentials) credentials.Credentials {
+	rotations.Lock()
+	defer rotations.Unlock()
+
+	return latestTokens(creds)
+}
+
+// canRefresh reports whether the credentials carry a refresh token.
+func canRefresh(creds credentials.Credentials) bool {
+	return credentials.HasValue(creds.CodexRefreshToken)
+}
+
+// accessTokenExpired reports whether the access token JWT has expired or is
+// about to. Tokens without a readable expiry are assumed valid.
+func accessTokenExpired(creds credentials.Credentials) bool {
+	if !credentials.HasValue(creds.CodexAPIKey) {
+		return true
+	}
+
+	expiry, ok := helpers.JWTExpiry(*creds.CodexAPIKey)
+	return ok && time.Now().Add(expiryMargin).After(expiry)
+}
+
+// refreshTokens exchanges the refresh token for a new access token, writes
+// the new tokens back to the file they came from, remembers them in
+// rotations and returns updated credentials. The refresh token is only
+// written when it rotated. When another fetch refreshed the tokens in the
+// meantime, its tokens are returned instead. Refresh tokens are single use,
+// so failing to persist the new one is an error: the file would otherwise
+// keep a revoked token.
+func refreshTokens(ctx context.Context, client httpclient.Doer, creds credentials.Credentials) (credentials.Credentials, error) {
+	rotations.Lock()
+	defer rotations.Unlock()
+
+	latest := latestTokens(creds)
+	rotated := *latest.CodexRefreshToken != *creds.CodexRefreshToken
+	renewed := !accessTokenExpired(latest) && (creds.CodexAPIKey == nil || *latest.CodexAPIKey != *creds.CodexAPIKey)
+	if rotated || renewed {
+		return latest, nil
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"client_id":     clientID,
+		"grant_type":    "refresh_token",
+		"refresh_token": *creds.CodexRefreshToken,
+		"scope":         "openid profile email",
+	})
+	if err != nil {
+		return creds, fmt.Errorf("failed to encode Codex token refresh request: %w", err)
+	}
+
+	// Not retried: the server may have consumed the single-use refresh token
+	// before failing, and a second attempt would only be rejected.
+	ctx = httpclient.WithPolicy(ctx, httpclient.Policy{})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, bytes.NewReader(payload))
+	if err != nil {
+		return creds, fmt.Errorf("failed to create Codex token refresh request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return creds, fmt.Errorf("failed to refresh Codex token: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return creds, fmt.Errorf("failed to read Codex token refresh response: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return creds, fmt.Errorf("failed to refresh Codex token. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	accessToken := gjson.GetBytes(body, "access_token").String()
+	if accessToken == "" {
+		return creds, fmt.Errorf("failed to refresh Codex token: response has no access_token")
+	}
+
+	// Refresh tokens rotate, but keep the old one if none was returned.
+	refreshToken := gjson.GetBytes(body, "refresh_token").String()
+	if refreshToken == "" {
+		refreshToken = *creds.CodexRefreshToken
+	}
+
+	rotations.tokens[*creds.CodexRefreshToken] = rotatedTokens{accessToken: accessToken, refreshToken: refreshToken}
+
+	saved := refreshToken
+	if refreshToken == *creds.CodexRefreshToken {
+		saved = ""
+	}
+
+	creds.CodexAPIKey = &accessToken
+	creds.CodexRefreshToken = &refreshToken
+
+	if creds.CodexTokenSource != nil {
+		idToken := gjson.GetBytes(body, "id_token").String()
+		if err := credentials.SaveCodexTokens(*creds.CodexTokenSource, accessToken, saved, idToken); err != nil {
+			return creds, fmt.Errorf("refreshed Codex token but could not save it: %w", err)
+		}
+	}
+
+	return creds, nil
+}
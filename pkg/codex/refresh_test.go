@@ -0,0 +1,150 @@
+package codex
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/tidwall/gjson"
+)
+
+// doerFunc is an httpclient.Doer answered by a function.
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func respond(status int, body string) *http.Response {
+	return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}
+}
+
+// expiredJWT returns an unsigned JWT that expired an hour ago, so every
+// fetch with it refreshes first.
+func expiredJWT(id string) string {
+	claims, _ := json.Marshal(map[string]any{"exp": time.Now().Add(-time.Hour).Unix(), "jti": id})
+	return "e30." + base64.RawURLEncoding.EncodeToString(claims) + ".sig"
+}
+
+// rotatingServer is a token endpoint that rotates refresh tokens and rejects
+// the ones it already exchanged, next to a usage endpoint. It records the
+// refresh tokens it was sent.
+type rotatingServer struct {
+	t    *testing.T
+	used []string
+}
+
+func (s *rotatingServer) Do(req *http.Request) (*http.Response, error) {
+	if req.URL.String() != tokenURL {
+		return respond(http.StatusOK, `{"email":"dev@example.com","plan_type":"plus"}`), nil
+	}
+
+	payload, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.t.Error(err)
+		return nil, err
+	}
+
+	token := gjson.GetBytes(payload, "refresh_token").String()
+	for _, used := range s.used {
+		if used == token {
+			return respond(http.StatusBadRequest, `{"error":"refresh_token_reused"}`), nil
+		}
+	}
+	s.used = append(s.used, token)
+
+	next := fmt.Sprintf("r%d", len(s.used)+1)
+	return respond(http.StatusOK, fmt.Sprintf(`{"access_token":%q,"refresh_token":%q}`, expiredJWT(next), next)), nil
+}
+
+func TestGetQuotaReusesRotatedRefreshToken(t *testing.T) {
+	t.Cleanup(func() { rotations.tokens = map[string]rotatedTokens{} })
+
+	path := filepath.Join(t.TempDir(), "auth.json")
+	if err := os.WriteFile(path, []byte(`{"tokens":{"refresh_token":"r1"}}`+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Long-running commands pass the credentials read at startup to every
+	// fetch, so both fetches get r1.
+	creds := credentials.Credentials{
+		CodexAPIKey:       new(expiredJWT("r1")),
+		CodexRefreshToken: new("r1"),
+		CodexTokenSource:  &credentials.TokenSource{Path: path, Kind: credentials.SourceCodexCLI},
+	}
+
+	server := &rotatingServer{t: t}
+	for range 2 {
+		if _, err := GetQuota(t.Context(), server, creds, "https://chatgpt.test/backend-api"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := strings.Join(server.used, ","); got != "r1,r2" {
+		t.Errorf("refresh tokens sent = %s, want r1,r2", got)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := gjson.GetBytes(content, "tokens.refresh_token").String(); got != "r3" {
+		t.Errorf("saved refresh token = %q, want r3", got)
+	}
+}
+
+func TestRefreshTokensKeepsUnrotatedToken(t *testing.T) {
+	t.Cleanup(func() { rotations.tokens = map[string]rotatedTokens{} })
+
+	// The Codex CLI rotated the refresh token in the file since it was read.
+	path := filepath.Join(t.TempDir(), "auth.json")
+	if err := os.WriteFile(path, []byte(`{"tokens":{"access_token":"a1","refresh_token":"r9"}}`+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A server that returns no refresh token keeps the old one valid.
+	requests := 0
+	client := doerFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return respond(http.StatusOK, `{"access_token":"a2"}`), nil
+	})
+
+	creds := credentials.Credentials{
+		CodexAPIKey:       new("a1"),
+		CodexRefreshToken: new("r1"),
+		CodexTokenSource:  &credentials.TokenSource{Path: path, Kind: credentials.SourceCodexCLI},
+	}
+	refreshed, err := refreshTokens(t.Context(), client, creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *refreshed.CodexAPIKey != "a2" || *refreshed.CodexRefreshToken != "r1" {
+		t.Errorf("tokens = %s, %s, want a2, r1", *refreshed.CodexAPIKey, *refreshed.CodexRefreshToken)
+	}
+
+	// Later fetches with the startup credentials use the new access token
+	// instead of refreshing again.
+	if latest := currentTokens(creds); *latest.CodexAPIKey != "a2" {
+		t.Errorf("current access token = %s, want a2", *latest.CodexAPIKey)
+	}
+	if _, err := refreshTokens(t.Context(), client, creds); err != nil || requests != 1 {
+		t.Errorf("second refresh sent %d requests (err %v), want the recorded tokens", requests, err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if access, refresh := gjson.GetBytes(content, "tokens.access_token").String(), gjson.GetBytes(content, "tokens.refresh_token").String(); access != "a2" || refresh != "r9" {
+		t.Errorf("saved tokens = %s, %s, want a2 and the untouched r9", access, refresh)
+	}
+}
@@ -0,0 +1,116 @@
+package cohere
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultBaseURL is the Cohere API base URL.
+const DefaultBaseURL = "https://api.cohere.com"
+
+// Key types reported in Quota.KeyType.
+const (
+	KeyTrial      = "trial"
+	KeyProduction = "production"
+)
+
+// CallLimit is a call limit of a trial key.
+type CallLimit struct {
+	Limit       int64    `json:"limit"`
+	Remaining   *int64   `json:"remaining"`
+	UsedPercent *float64 `json:"usedPercent"`
+	ResetAt     string   `json:"resetAt"`
+	ResetIn     string   `json:"resetIn"`
+}
+
+// Quota contains the Cohere key type and, for trial keys, the call limits.
+//
+// Cohere attaches the trial limits to the headers of every response made
+// with a trial key and leaves them out for production keys, which are
+// billed per use without a call cap. The monthly limit resets at the start
+// of each calendar month.
+type Quota struct {
+	KeyType        string     `json:"keyType"`
+	OrganizationID string     `json:"organizationId"`
+	Monthly        *CallLimit `json:"monthly"`
+	PerMinute      *CallLimit `json:"perMinute"`
+}
+
+// GetQuota validates the Cohere API key and reads its call limits.
+func GetQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (Quota, error) {
+	if creds.CohereAPIKey == nil || *creds.CohereAPIKey == "" {
+		return Quota{}, fmt.Errorf("missing Cohere API key in credentials")
+	}
+
+	// The key check has no side effects despite the POST.
+	req, err := http.NewRequestWithContext(httpclient.WithIdempotent(ctx), http.MethodPost, baseURL+"/v1/check-api-key", nil)
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to create Cohere request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+*creds.CohereAPIKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to fetch Cohere quota: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to read Cohere response: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return Quota{}, fmt.Errorf("failed to fetch Cohere quota. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	if !gjson.GetBytes(body, "valid").Bool() {
+		return Quota{}, fmt.Errorf("invalid Cohere API key")
+	}
+
+	now := time.Now().UTC()
+	monthEnd := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+
+	result := Quota{
+		KeyType:        KeyProduction,
+		OrganizationID: gjson.GetBytes(body, "organization_id").String(),
+		Monthly:        parseLimit(response.Header, "x-endpoint-monthly-call-", monthEnd),
+		PerMinute:      parseLimit(response.Header, "x-trial-endpoint-call-", now.Truncate(time.Minute).Add(time.Minute)),
+	}
+	if result.Monthly != nil || result.PerMinute != nil {
+		result.KeyType = KeyTrial
+	}
+
+	return result, nil
+}
+
+// parseLimit reads the <prefix>limit and <prefix>remaining headers. It
+// returns nil when the limit header is missing.
+func parseLimit(header http.Header, prefix string, resetAt time.Time) *CallLimit {
+	limit, err := strconv.ParseInt(header.Get(prefix+"limit"), 10, 64)
+	if err != nil || limit <= 0 {
+		return nil
+	}
+
+	result := &CallLimit{Limit: limit, ResetAt: resetAt.Format(time.RFC3339)}
+	result.ResetIn = helpers.FormatTimeUntil(result.ResetAt)
+
+	if remaining, err := strconv.ParseInt(header.Get(prefix+"remaining"), 10, 64); err == nil {
+		result.Remaining = &remaining
+		result.UsedPercent = new(helpers.ClampPercent(float64(limit-remaining) / float64(limit) * 100))
+	}
+
+	return result
+}
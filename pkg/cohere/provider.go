@@ -0,0 +1,79 @@
+package cohere
+
+import (
+	"cmp"
+	"context"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// Provider exposes Cohere through the common provider interface.
+type Provider struct {
+	// BaseURL overrides DefaultBaseURL, for example to go through a proxy.
+	BaseURL string
+	// HTTPClient sends the requests. Nil means httpclient.Default.
+	HTTPClient httpclient.Doer
+}
+
+// ID implements provider.Provider.
+func (Provider) ID() string { return "cohere" }
+
+// Name implements provider.Provider.
+func (Provider) Name() string { return "Cohere" }
+
+// Enabled implements provider.Provider.
+func (Provider) Enabled(creds credentials.Credentials) bool {
+	return credentials.HasValue(creds.CohereAPIKey)
+}
+
+// WithBaseURL implements provider.BaseURLSetter.
+func (p Provider) WithBaseURL(baseURL string) provider.Provider {
+	p.BaseURL = baseURL
+	return p
+}
+
+// Endpoint implements provider.Endpointer.
+func (p Provider) Endpoint() string {
+	return cmp.Or(p.BaseURL, DefaultBaseURL)
+}
+
+// Fetch implements provider.Provider.
+func (p Provider) Fetch(ctx context.Context, creds credentials.Credentials) (provider.Quota, error) {
+	quota, err := GetQuota(ctx, httpclient.OrDefault(p.HTTPClient), creds, cmp.Or(p.BaseURL, DefaultBaseURL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+// Windows implements provider.Quota with the trial call limits. Production
+// keys have none.
+func (q Quota) Windows() []provider.Window {
+	var windows []provider.Window
+	if q.Monthly != nil {
+		windows = append(windows, window("monthly_calls", "Trial Calls/Month", *q.Monthly))
+	}
+	if q.PerMinute != nil {
+		windows = append(windows, window("minute_calls", "Trial Calls/Minute", *q.PerMinute))
+	}
+
+	return windows
+}
+
+func window(id string, name string, limit CallLimit) provider.Window {
+	result := provider.Window{
+		ID:          id,
+		Name:        name,
+		UsedPercent: limit.UsedPercent,
+		Limit:       new(float64(limit.Limit)),
+		ResetAt:     limit.ResetAt,
+	}
+	if limit.Remaining != nil {
+		result.Used = new(float64(limit.Limit - *limit.Remaining))
+	}
+
+	return result
+}
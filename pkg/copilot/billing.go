@@ -0,0 +1,73 @@
+package copilot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+// OveragePrice is what GitHub charges in USD for each premium request past
+// the plan allowance. It estimates the paid usage when the billing API is not
+// available to the token.
+const OveragePrice = 0.04
+
+// getBilledOverage returns the net amount billed for Copilot premium requests
+// in the current calendar month, which is the Copilot billing cycle. It needs
+// a GitHub token allowed to read the user's billing, such as a classic token
+// with the user scope; Copilot session tokens are not accepted.
+func getBilledOverage(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string, login string, now time.Time) (float64, error) {
+	token := stringValue(creds.CopilotAPIKey)
+	if !isGitHubToken(token) {
+		token = stringValue(creds.CopilotRefreshToken)
+	}
+	if !isGitHubToken(token) || login == "" {
+		return 0, fmt.Errorf("no GitHub token to read Copilot billing")
+	}
+
+	now = now.UTC()
+	query := url.Values{
+		"year":    {fmt.Sprint(now.Year())},
+		"month":   {fmt.Sprint(int(now.Month()))},
+		"product": {"Copilot"},
+	}
+	endpoint := baseURL + "/users/" + url.PathEscape(login) + "/settings/billing/premium_request/usage?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create GitHub Copilot billing request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", userAgent)
+
+	response, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch GitHub Copilot billing: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read GitHub Copilot billing response: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return 0, fmt.Errorf("failed to fetch GitHub Copilot billing. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	var billed float64
+	for _, item := range gjson.GetBytes(body, "usageItems").Array() {
+		billed += item.Get("netAmount").Float()
+	}
+
+	return billed, nil
+}
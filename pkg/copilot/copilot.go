@@ -0,0 +1,185 @@
+package copilot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+const userAgent = "GitHubCopilotChat/0.35.0"
+
+// DefaultBaseURL is the GitHub API base URL.
+const DefaultBaseURL = "https://api.github.com"
+
+// Quota contains GitHub Copilot usage information. The Requests fields
+// describe the premium interactions snapshot; Snapshots lists every feature.
+type Quota struct {
+	AccountUser              string     `json:"accountUser"`
+	AccountType              string     `json:"accountType"`
+	RequestsTotal            int64      `json:"requestsTotal"`
+	RequestsUsed             int64      `json:"requestsUsed"`
+	RequestsUsedPercent      float64    `json:"requestsUsedPercent"`
+	RequestsRemaining        int64      `json:"requestsRemaining"`
+	RequestsRemainingPercent float64    `json:"requestsRemainingPercent"`
+	Snapshots                []Snapshot `json:"snapshots"`
+	ResetAt                  string     `json:"resetAt"`
+	ResetIn                  string     `json:"resetIn"`
+	// TokenExpiresAt is when the token used expires, empty for tokens
+	// without an expiry such as GitHub OAuth tokens.
+	TokenExpiresAt string `json:"tokenExpiresAt,omitempty"`
+
+	// OverageCount is the number of premium requests past the allowance in
+	// this cycle, and OveragePermitted whether the plan allows and bills
+	// them.
+	OverageCount     int64 `json:"overageCount"`
+	OveragePermitted bool  `json:"overagePermitted"`
+	// OverageCostUSD is the paid usage of the cycle: the amount billed when
+	// the billing API answers, as OverageBilled reports, otherwise
+	// OverageCount at OveragePrice.
+	OverageCostUSD float64 `json:"overageCostUsd"`
+	OverageBilled  bool    `json:"overageBilled"`
+}
+
+// Snapshot is the quota of a single Copilot feature, such as chat or code
+// completions. Unlimited features report no entitlement.
+type Snapshot struct {
+	ID               string  `json:"id"`
+	Name             string  `json:"name"`
+	Unlimited        bool    `json:"unlimited"`
+	Entitlement      int64   `json:"entitlement"`
+	Used             int64   `json:"used"`
+	Remaining        int64   `json:"remaining"`
+	UsedPercent      float64 `json:"usedPercent"`
+	RemainingPercent float64 `json:"remainingPercent"`
+	OverageCount     int64   `json:"overageCount"`
+	OveragePermitted bool    `json:"overagePermitted"`
+}
+
+// snapshotNames are the display names of the known quota snapshots.
+var snapshotNames = map[string]string{
+	"premium_interactions": "Premium Requests",
+	"chat":                 "Chat",
+	"completions":          "Completions",
+}
+
+// GetQuota fetches GitHub Copilot quota information.
+func GetQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (Quota, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/copilot_internal/user", nil)
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to create GitHub Copilot request: %w", err)
+	}
+
+	// An expired token that could not be renewed would only get a 401.
+	token, err := resolveToken(ctx, client, creds, baseURL)
+	if err != nil {
+		return Quota{}, err
+	}
+	expiry, expires := tokenExpiry(token)
+	if expires && time.Now().After(expiry) {
+		return Quota{}, fmt.Errorf("GitHub Copilot token expired %s ago, log in to Copilot again or store a GitHub OAuth token so it can be renewed", helpers.FormatDuration(time.Since(expiry)))
+	}
+
+	req.Header.Set("Authorization", "token "+token)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Editor-Version", "vscode/1.107.0")
+	req.Header.Set("Editor-Plugin-Version", "copilot-chat/0.35.0")
+	req.Header.Set("Copilot-Integration-Id", "vscode-chat")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to fetch GitHub Copilot quota: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to read GitHub Copilot response: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return Quota{}, fmt.Errorf("failed to fetch GitHub Copilot quota. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	result := Quota{
+		AccountUser: gjson.GetBytes(body, "login").String(),
+		AccountType: gjson.GetBytes(body, "access_type_sku").String(),
+		ResetAt:     gjson.GetBytes(body, "quota_reset_date_utc").String(),
+	}
+	result.ResetIn = helpers.FormatTimeUntil(result.ResetAt)
+	if expires {
+		result.TokenExpiresAt = expiry.UTC().Format(time.RFC3339)
+	}
+
+	gjson.GetBytes(body, "quota_snapshots").ForEach(func(key, value gjson.Result) bool {
+		snapshot := parseSnapshot(key.String(), value)
+		result.Snapshots = append(result.Snapshots, snapshot)
+
+		if snapshot.ID == "premium_interactions" {
+			result.RequestsTotal = snapshot.Entitlement
+			result.RequestsUsed = snapshot.Used
+			result.RequestsUsedPercent = snapshot.UsedPercent
+			result.RequestsRemaining = snapshot.Remaining
+			result.RequestsRemainingPercent = snapshot.RemainingPercent
+			result.OverageCount = snapshot.OverageCount
+			result.OveragePermitted = snapshot.OveragePermitted
+		}
+
+		return true
+	})
+
+	// Overage is only billed when permitted, and the billing API is only
+	// asked then. Tokens without billing access fall back to the estimate.
+	if result.OveragePermitted {
+		result.OverageCostUSD = float64(result.OverageCount) * OveragePrice
+		if billed, err := getBilledOverage(ctx, client, creds, baseURL, result.AccountUser, time.Now()); err == nil {
+			result.OverageCostUSD, result.OverageBilled = billed, true
+		}
+	}
+
+	return result, nil
+}
+
+func parseSnapshot(id string, value gjson.Result) Snapshot {
+	name, ok := snapshotNames[id]
+	if !ok {
+		name = id
+	}
+
+	snapshot := Snapshot{
+		ID:               id,
+		Name:             name,
+		Unlimited:        value.Get("unlimited").Bool(),
+		OverageCount:     value.Get("overage_count").Int(),
+		OveragePermitted: value.Get("overage_permitted").Bool(),
+	}
+
+	if snapshot.Unlimited {
+		snapshot.RemainingPercent = 100
+		return snapshot
+	}
+
+	snapshot.Entitlement = value.Get("entitlement").Int()
+	snapshot.Remaining = value.Get("remaining").Int()
+	snapshot.RemainingPercent = value.Get("percent_remaining").Float()
+	snapshot.Used = max(0, snapshot.Entitlement-snapshot.Remaining)
+	snapshot.UsedPercent = max(0.0, 100-snapshot.RemainingPercent)
+
+	return snapshot
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
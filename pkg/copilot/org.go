@@ -0,0 +1,138 @@
+package copilot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// seatsPerPage is the largest page size of the seats endpoint.
+	seatsPerPage = 100
+
+	// maxSeatPages bounds how many seat pages are walked per run.
+	maxSeatPages = 10
+)
+
+// OrgQuota contains the Copilot seats of a GitHub organization.
+//
+// Organizations pay per seat, so the seat breakdown stands in for a quota:
+// the active share shows how many paid seats are actually used this billing
+// cycle.
+type OrgQuota struct {
+	Organization        string `json:"organization"`
+	PlanType            string `json:"planType"`
+	SeatsTotal          int64  `json:"seatsTotal"`
+	SeatsActive         int64  `json:"seatsActive"`
+	SeatsInactive       int64  `json:"seatsInactive"`
+	PendingInvitation   int64  `json:"pendingInvitation"`
+	PendingCancellation int64  `json:"pendingCancellation"`
+	Seats               []Seat `json:"seats"`
+	SeatsPartial        bool   `json:"seatsPartial"`
+}
+
+// Seat is one assigned Copilot seat. LastActivityAt is "unknown" for seats
+// that were never used.
+type Seat struct {
+	Login              string `json:"login"`
+	PlanType           string `json:"planType"`
+	LastActivityAt     string `json:"lastActivityAt"`
+	LastActivityEditor string `json:"lastActivityEditor"`
+}
+
+// GetOrgQuota fetches the Copilot billing summary and seat assignments of
+// creds.CopilotOrg. The token needs the manage_billing:copilot scope or the
+// fine-grained "GitHub Copilot Business" read permission.
+func GetOrgQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (OrgQuota, error) {
+	if !credentials.HasValue(creds.CopilotOrg) || !credentials.HasValue(creds.CopilotOrgToken) {
+		return OrgQuota{}, fmt.Errorf("missing Copilot organization or token in credentials")
+	}
+
+	org := *creds.CopilotOrg
+	token := *creds.CopilotOrgToken
+	orgURL := baseURL + "/orgs/" + url.PathEscape(org) + "/copilot/billing"
+
+	billing, err := getOrg(ctx, client, token, orgURL)
+	if err != nil {
+		return OrgQuota{}, err
+	}
+
+	breakdown := gjson.GetBytes(billing, "seat_breakdown")
+	result := OrgQuota{
+		Organization:        org,
+		PlanType:            gjson.GetBytes(billing, "plan_type").String(),
+		SeatsTotal:          breakdown.Get("total").Int(),
+		SeatsActive:         breakdown.Get("active_this_cycle").Int(),
+		SeatsInactive:       breakdown.Get("inactive_this_cycle").Int(),
+		PendingInvitation:   breakdown.Get("pending_invitation").Int(),
+		PendingCancellation: breakdown.Get("pending_cancellation").Int(),
+		Seats:               []Seat{},
+	}
+
+	result.SeatsPartial = true
+	for page := 1; page <= maxSeatPages; page++ {
+		body, err := getOrg(ctx, client, token, orgURL+"/seats?per_page="+strconv.Itoa(seatsPerPage)+"&page="+strconv.Itoa(page))
+		if err != nil {
+			return OrgQuota{}, err
+		}
+
+		seats := gjson.GetBytes(body, "seats").Array()
+		for _, seat := range seats {
+			lastActivity := "unknown"
+			if at := seat.Get("last_activity_at").String(); at != "" {
+				lastActivity = helpers.NormalizeISO(at)
+			}
+
+			result.Seats = append(result.Seats, Seat{
+				Login:              seat.Get("assignee.login").String(),
+				PlanType:           seat.Get("plan_type").String(),
+				LastActivityAt:     lastActivity,
+				LastActivityEditor: seat.Get("last_activity_editor").String(),
+			})
+		}
+
+		if len(seats) < seatsPerPage || int64(len(result.Seats)) >= gjson.GetBytes(body, "total_seats").Int() {
+			result.SeatsPartial = false
+			break
+		}
+	}
+
+	return result, nil
+}
+
+func getOrg(ctx context.Context, client httpclient.Doer, token string, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Copilot organization request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Copilot organization seats: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Copilot organization response: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to fetch Copilot organization seats. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	return body, nil
+}
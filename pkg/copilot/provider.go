@@ -0,0 +1,161 @@
+package copilot
+
+import (
+	"cmp"
+	"context"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// Provider exposes GitHub Copilot through the common provider interface.
+type Provider struct {
+	// BaseURL overrides DefaultBaseURL, for example to go through a proxy.
+	BaseURL string
+	// HTTPClient sends the requests. Nil means httpclient.Default.
+	HTTPClient httpclient.Doer
+}
+
+// ID implements provider.Provider.
+func (Provider) ID() string { return "copilot" }
+
+// Name implements provider.Provider.
+func (Provider) Name() string { return "GitHub Copilot" }
+
+// Enabled implements provider.Provider.
+func (Provider) Enabled(creds credentials.Credentials) bool {
+	return credentials.HasValue(creds.CopilotAPIKey)
+}
+
+// WithBaseURL implements provider.BaseURLSetter.
+func (p Provider) WithBaseURL(baseURL string) provider.Provider {
+	p.BaseURL = baseURL
+	return p
+}
+
+// Endpoint implements provider.Endpointer.
+func (p Provider) Endpoint() string {
+	return cmp.Or(p.BaseURL, DefaultBaseURL)
+}
+
+// Fetch implements provider.Provider.
+func (p Provider) Fetch(ctx context.Context, creds credentials.Credentials) (provider.Quota, error) {
+	quota, err := GetQuota(ctx, httpclient.OrDefault(p.HTTPClient), creds, cmp.Or(p.BaseURL, DefaultBaseURL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+// Windows implements provider.Quota. Premium requests keep the "premium"
+// window ID; other limited features use their snapshot ID, and unlimited
+// ones have nothing to track. Plans that permit overage add the "overage"
+// requests and their "cost" in USD.
+func (q Quota) Windows() []provider.Window {
+	windows := []provider.Window{
+		{
+			ID:          "premium",
+			Name:        "Premium Requests",
+			UsedPercent: new(q.RequestsUsedPercent),
+			Used:        new(float64(q.RequestsUsed)),
+			Limit:       new(float64(q.RequestsTotal)),
+			ResetAt:     q.ResetAt,
+		},
+	}
+
+	for _, snapshot := range q.Snapshots {
+		if snapshot.ID == "premium_interactions" || snapshot.Unlimited {
+			continue
+		}
+
+		windows = append(windows, provider.Window{
+			ID:          snapshot.ID,
+			Name:        snapshot.Name,
+			UsedPercent: new(snapshot.UsedPercent),
+			Used:        new(float64(snapshot.Used)),
+			Limit:       new(float64(snapshot.Entitlement)),
+			ResetAt:     q.ResetAt,
+		})
+	}
+
+	if q.OveragePermitted {
+		windows = append(windows,
+			provider.Window{
+				ID:      "overage",
+				Name:    "Overage Requests",
+				Used:    new(float64(q.OverageCount)),
+				ResetAt: q.ResetAt,
+			},
+			provider.Window{
+				ID:      "cost",
+				Name:    "Paid Overage (USD)",
+				Used:    new(q.OverageCostUSD),
+				ResetAt: q.ResetAt,
+			},
+		)
+	}
+
+	return windows
+}
+
+// OrgProvider exposes the Copilot seats of an organization through the
+// common provider interface. It is separate from Provider because it needs
+// an organization admin token rather than a personal Copilot login.
+type OrgProvider struct {
+	// BaseURL overrides DefaultBaseURL, for example for GitHub Enterprise
+	// Server.
+	BaseURL string
+	// HTTPClient sends the requests. Nil means httpclient.Default.
+	HTTPClient httpclient.Doer
+}
+
+// ID implements provider.Provider.
+func (OrgProvider) ID() string { return "copilot-org" }
+
+// Name implements provider.Provider.
+func (OrgProvider) Name() string { return "GitHub Copilot Organization" }
+
+// Enabled implements provider.Provider.
+func (OrgProvider) Enabled(creds credentials.Credentials) bool {
+	return credentials.HasValue(creds.CopilotOrg) && credentials.HasValue(creds.CopilotOrgToken)
+}
+
+// WithBaseURL implements provider.BaseURLSetter.
+func (p OrgProvider) WithBaseURL(baseURL string) provider.Provider {
+	p.BaseURL = baseURL
+	return p
+}
+
+// Endpoint implements provider.Endpointer.
+func (p OrgProvider) Endpoint() string {
+	return cmp.Or(p.BaseURL, DefaultBaseURL)
+}
+
+// Fetch implements provider.Provider.
+func (p OrgProvider) Fetch(ctx context.Context, creds credentials.Credentials) (provider.Quota, error) {
+	quota, err := GetOrgQuota(ctx, httpclient.OrDefault(p.HTTPClient), creds, cmp.Or(p.BaseURL, DefaultBaseURL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+// Windows implements provider.Quota with the share of seats active in the
+// current billing cycle.
+func (q OrgQuota) Windows() []provider.Window {
+	window := provider.Window{
+		ID:      "active_seats",
+		Name:    "Active Seats",
+		Used:    new(float64(q.SeatsActive)),
+		Limit:   new(float64(q.SeatsTotal)),
+		ResetAt: "unknown",
+	}
+	if q.SeatsTotal > 0 {
+		window.UsedPercent = new(float64(q.SeatsActive) / float64(q.SeatsTotal) * 100)
+	}
+
+	return []provider.Window{window}
+}
@@ -0,0 +1,230 @@
+package copilot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+// sessionExpiryMargin avoids using a session token that is about to expire.
+const sessionExpiryMargin = time.Minute
+
+// ErrNoSubscription is reported when GitHub refuses a Copilot session token
+// with 404 Not Found, which it does for accounts without Copilot.
+var ErrNoSubscription = errors.New("no Copilot subscription for this GitHub account")
+
+// sessionToken is a short-lived Copilot token obtained from a GitHub OAuth
+// token, cached on disk until it expires.
+type sessionToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// resolveToken returns the token to authenticate the quota request with.
+//
+// The github-copilot entry may hold either a Copilot session token
+// ("tid=...;exp=...") or a GitHub OAuth token. A valid session token is used
+// as is. Otherwise a GitHub OAuth token, from the access value or the refresh
+// value, is exchanged for a session token. If no exchange is possible the
+// access value is used unchanged, unless GitHub reported that the account
+// has no Copilot subscription.
+func resolveToken(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (string, error) {
+	access := stringValue(creds.CopilotAPIKey)
+	if isSessionToken(access) && !sessionExpired(access) {
+		return access, nil
+	}
+
+	oauthToken := ""
+	switch {
+	case isGitHubToken(access):
+		oauthToken = access
+	case isGitHubToken(stringValue(creds.CopilotRefreshToken)):
+		oauthToken = stringValue(creds.CopilotRefreshToken)
+	}
+
+	if oauthToken == "" {
+		return access, nil
+	}
+
+	session, err := exchangeToken(ctx, client, oauthToken, baseURL)
+	if errors.Is(err, ErrNoSubscription) {
+		return "", err
+	}
+	if err != nil {
+		return access, nil
+	}
+
+	return session, nil
+}
+
+func isSessionToken(token string) bool {
+	return strings.HasPrefix(token, "tid=")
+}
+
+func isGitHubToken(token string) bool {
+	for _, prefix := range []string{"gho_", "ghu_", "ghp_", "github_pat_"} {
+		if strings.HasPrefix(token, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sessionExpired reads the exp field embedded in a session token. Tokens
+// with an unreadable exp field count as expired.
+func sessionExpired(token string) bool {
+	expiry, ok, err := sessionExpiry(token)
+	if err != nil {
+		return true
+	}
+
+	return ok && time.Now().Add(sessionExpiryMargin).After(expiry)
+}
+
+// sessionExpiry returns the exp field of a session token, and false when the
+// token has none.
+func sessionExpiry(token string) (time.Time, bool, error) {
+	for field := range strings.SplitSeq(token, ";") {
+		value, ok := strings.CutPrefix(field, "exp=")
+		if !ok {
+			continue
+		}
+
+		exp, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid session token expiry %q", value)
+		}
+
+		return time.Unix(exp, 0), true, nil
+	}
+
+	return time.Time{}, false, nil
+}
+
+// tokenExpiry returns when the token sent to the Copilot API expires: the
+// exp field of session tokens or the exp claim of JWTs. GitHub OAuth tokens
+// and personal access tokens report false.
+func tokenExpiry(token string) (time.Time, bool) {
+	if isSessionToken(token) {
+		expiry, ok, err := sessionExpiry(token)
+		return expiry, ok && err == nil
+	}
+
+	return helpers.JWTExpiry(token)
+}
+
+// exchangeToken trades a GitHub OAuth token for a Copilot session token,
+// reusing a cached one while it is still valid.
+func exchangeToken(ctx context.Context, client httpclient.Doer, oauthToken string, baseURL string) (string, error) {
+	cachePath, cacheErr := sessionCachePath(oauthToken)
+	if cacheErr == nil {
+		if cached, ok := readCachedSession(cachePath); ok {
+			return cached.Token, nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/copilot_internal/v2/token", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create GitHub Copilot token request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "token "+oauthToken)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Editor-Version", "vscode/1.107.0")
+	req.Header.Set("Editor-Plugin-Version", "copilot-chat/0.35.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to exchange GitHub Copilot token: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read GitHub Copilot token response: %w", err)
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return "", ErrNoSubscription
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return "", fmt.Errorf("failed to exchange GitHub Copilot token. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	session := sessionToken{
+		Token:     gjson.GetBytes(body, "token").String(),
+		ExpiresAt: time.Unix(gjson.GetBytes(body, "expires_at").Int(), 0),
+	}
+	if session.Token == "" {
+		return "", fmt.Errorf("failed to exchange GitHub Copilot token: empty token in response")
+	}
+
+	if cacheErr == nil {
+		writeCachedSession(cachePath, session)
+	}
+
+	return session.Token, nil
+}
+
+// sessionCachePath keys the cache by a hash of the OAuth token so switching
+// accounts never reuses another account's session.
+func sessionCachePath(oauthToken string) (string, error) {
+	dir, err := helpers.CacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(oauthToken))
+	return filepath.Join(dir, "copilot-session-"+hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+func readCachedSession(path string) (sessionToken, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return sessionToken{}, false
+	}
+
+	var session sessionToken
+	if err := json.Unmarshal(content, &session); err != nil || session.Token == "" {
+		return sessionToken{}, false
+	}
+
+	if time.Now().Add(sessionExpiryMargin).After(session.ExpiresAt) {
+		return sessionToken{}, false
+	}
+
+	return session, true
+}
+
+// writeCachedSession stores the session token. Caching is best effort, so
+// failures are ignored and the next run simply exchanges again.
+func writeCachedSession(path string, session sessionToken) {
+	content, err := json.Marshal(session)
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+
+	_ = os.WriteFile(path, content, 0o600)
+}
@@ -0,0 +1,29 @@
+package copilot
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+)
+
+func TestGetQuotaReportsNoSubscription(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	// GitHub answers the token exchange of an account without Copilot with
+	// 404; the quota endpoint must not be asked.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/copilot_internal/v2/token" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := GetQuota(t.Context(), server.Client(), credentials.Credentials{CopilotAPIKey: new("gho_test")}, server.URL)
+	if !errors.Is(err, ErrNoSubscription) {
+		t.Errorf("err = %v, want ErrNoSubscription", err)
+	}
+}
@@ -0,0 +1,55 @@
+package credentials
+
+import (
+	"bufio"
+	"cmp"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// readAWSCredentials fills in missing AWS keys from the shared credentials
+// file used by the AWS CLI and SDKs, honouring AWS_SHARED_CREDENTIALS_FILE
+// and AWS_PROFILE. It only runs when Bedrock models are configured, so AWS
+// keys alone never enable the Bedrock provider.
+func readAWSCredentials(home string, creds *Credentials) {
+	if len(creds.BedrockModels) == 0 || HasValue(creds.AWSAccessKeyID) {
+		return
+	}
+
+	path := cmp.Or(os.Getenv("AWS_SHARED_CREDENTIALS_FILE"), filepath.Join(home, ".aws", "credentials"))
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	profile := cmp.Or(os.Getenv("AWS_PROFILE"), "default")
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if section != profile {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "aws_access_key_id":
+			creds.AWSAccessKeyID = &value
+		case "aws_secret_access_key":
+			creds.AWSSecretAccessKey = &value
+		case "aws_session_token":
+			creds.AWSSessionToken = &value
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// azTimeout bounds the `az account get-access-token` call.
+const azTimeout = 10 * time.Second
+
+// readAzureCredentials fills in a missing Azure management token from the
+// Azure CLI. It only runs when a subscription is configured and neither a
+// token nor a service principal secret was given, so machines that do not
+// use Azure never start the CLI.
+func readAzureCredentials(creds *Credentials) {
+	if !HasValue(creds.AzureSubscriptionID) || HasValue(creds.AzureToken) || HasValue(creds.AzureClientSecret) {
+		return
+	}
+
+	path, err := exec.LookPath("az")
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), azTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(
+		ctx, path, "account", "get-access-token",
+		"--resource", "https://management.azure.com/",
+		"--query", "accessToken",
+		"--output", "tsv",
+	).Output()
+	if err != nil {
+		return
+	}
+
+	if token := strings.TrimSpace(string(output)); token != "" {
+		creds.AzureToken = &token
+	}
+}
+
+// readAzureResource reads the Azure OpenAI resource whose deployments are
+// listed from AIQUOTA_AZURE_RESOURCE, written as "<resource group>/<account>".
+func readAzureResource(creds *Credentials) error {
+	value := strings.TrimSpace(os.Getenv("AIQUOTA_AZURE_RESOURCE"))
+	if value == "" {
+		return nil
+	}
+
+	group, account, ok := strings.Cut(value, "/")
+	if !ok || group == "" || account == "" {
+		return fmt.Errorf("invalid AIQUOTA_AZURE_RESOURCE %q, expected <resource group>/<account>", value)
+	}
+
+	creds.AzureResourceGroup = &group
+	creds.AzureAccount = &account
+	return nil
+}
@@ -0,0 +1,202 @@
+package credentials
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// SourceKind identifies the format of a credentials file.
+type SourceKind string
+
+const (
+	// SourceOpenCode is the OpenCode auth.json format.
+	SourceOpenCode SourceKind = "opencode"
+	// SourceCodexCLI is the OpenAI Codex CLI ~/.codex/auth.json format.
+	SourceCodexCLI SourceKind = "codex-cli"
+)
+
+// TokenSource records which file a refreshable token came from, so that a
+// refreshed token can be written back to it.
+type TokenSource struct {
+	Path string     `json:"path"`
+	Kind SourceKind `json:"kind"`
+}
+
+// CodexCLIAuthPath returns the Codex CLI auth file location, honoring
+// CODEX_HOME like the Codex CLI does.
+func CodexCLIAuthPath(home string) string {
+	if codexHome := os.Getenv("CODEX_HOME"); codexHome != "" {
+		return filepath.Join(codexHome, "auth.json")
+	}
+
+	return filepath.Join(home, ".codex", "auth.json")
+}
+
+// codexCLIAuth holds the tokens of the Codex CLI auth file.
+type codexCLIAuth struct {
+	path         string
+	accessToken  *string
+	refreshToken *string
+	accountID    *string
+}
+
+// readCodexCLIAuth reads the Codex CLI auth file, if it exists. The account
+// ID falls back to the ChatGPT account claim of the id_token, which older
+// files carry without a separate account_id.
+func readCodexCLIAuth(home string) (codexCLIAuth, bool) {
+	path := CodexCLIAuthPath(home)
+	content, err := os.ReadFile(path)
+	if err != nil || !gjson.ValidBytes(content) {
+		return codexCLIAuth{}, false
+	}
+
+	auth := codexCLIAuth{
+		path:         path,
+		accessToken:  optionalString(gjson.GetBytes(content, "tokens.access_token")),
+		refreshToken: optionalString(gjson.GetBytes(content, "tokens.refresh_token")),
+		accountID:    optionalString(gjson.GetBytes(content, "tokens.account_id")),
+	}
+
+	if !HasValue(auth.accountID) {
+		var claims struct {
+			Auth struct {
+				AccountID string `json:"chatgpt_account_id"`
+			} `json:"https://api.openai.com/auth"`
+		}
+		idToken := gjson.GetBytes(content, "tokens.id_token").String()
+		if helpers.JWTClaims(idToken, &claims) && claims.Auth.AccountID != "" {
+			auth.accountID = &claims.Auth.AccountID
+		}
+	}
+
+	return auth, HasValue(auth.accessToken) || HasValue(auth.refreshToken)
+}
+
+// readCodexRefresh merges the Codex credentials of OpenCode's auth.json and
+// the Codex CLI auth file, whichever exist. OpenCode's refresh token is
+// preferred, and the CLI access token replaces a missing or expired OpenCode
+// one when it is still valid.
+func readCodexRefresh(home string, authFilePath string, content []byte, creds *Credentials) {
+	if refresh := optionalString(gjson.GetBytes(content, "openai.refresh")); HasValue(refresh) {
+		creds.CodexRefreshToken = refresh
+		creds.CodexTokenSource = &TokenSource{Path: authFilePath, Kind: SourceOpenCode}
+	}
+
+	cli, ok := readCodexCLIAuth(home)
+	if !ok {
+		return
+	}
+
+	if !HasValue(creds.CodexRefreshToken) && HasValue(cli.refreshToken) {
+		creds.CodexRefreshToken = cli.refreshToken
+		creds.CodexTokenSource = &TokenSource{Path: cli.path, Kind: SourceCodexCLI}
+	}
+
+	if !HasValue(cli.accessToken) {
+		return
+	}
+
+	if !HasValue(creds.CodexAPIKey) || (tokenExpired(*creds.CodexAPIKey) && !tokenExpired(*cli.accessToken)) {
+		creds.CodexAPIKey = cli.accessToken
+		creds.CodexAccountID = cli.accountID
+	}
+}
+
+// tokenExpired reports whether a JWT access token has expired. Tokens without
+// a readable expiry are assumed valid.
+func tokenExpired(token string) bool {
+	expiry, ok := helpers.JWTExpiry(token)
+	return ok && time.Now().After(expiry)
+}
+
+// SaveCodexTokens writes refreshed Codex tokens back to the file they came
+// from, keeping the rest of the file untouched. An empty refreshToken keeps
+// the stored one, so a refresh that did not rotate it never overwrites a
+// rotation the Codex CLI saved in the meantime. The file is replaced
+// atomically, so a crash or a concurrent write by the Codex CLI never leaves
+// it truncated.
+func SaveCodexTokens(source TokenSource, accessToken string, refreshToken string, idToken string) error {
+	content, err := os.ReadFile(source.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", source.Path, err)
+	}
+
+	info, err := os.Stat(source.Path)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", source.Path, err)
+	}
+
+	values := map[string]any{}
+	switch source.Kind {
+	case SourceOpenCode:
+		values["openai.access"] = accessToken
+		if refreshToken != "" {
+			values["openai.refresh"] = refreshToken
+		}
+		if expiry, ok := helpers.JWTExpiry(accessToken); ok {
+			values["openai.expires"] = expiry.UnixMilli()
+		}
+	case SourceCodexCLI:
+		values["tokens.access_token"] = accessToken
+		if refreshToken != "" {
+			values["tokens.refresh_token"] = refreshToken
+		}
+		if idToken != "" {
+			values["tokens.id_token"] = idToken
+		}
+		values["last_refresh"] = time.Now().UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Errorf("unknown credentials source %q", source.Kind)
+	}
+
+	updated := string(content)
+	for path, value := range values {
+		updated, err = sjson.Set(updated, path, value)
+		if err != nil {
+			return fmt.Errorf("failed to update %s: %w", source.Path, err)
+		}
+	}
+
+	if !strings.HasSuffix(updated, "\n") && strings.HasSuffix(string(content), "\n") {
+		updated += "\n"
+	}
+
+	return writeFileAtomic(source.Path, []byte(updated), info.Mode().Perm())
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it over path, with the given permissions.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := file.Chmod(perm); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package credentials
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// ghTimeout bounds the `gh auth token` call.
+const ghTimeout = 5 * time.Second
+
+// CopilotConfigDir returns the directory where the Copilot editor plugins
+// (VS Code, copilot.vim, JetBrains) keep apps.json and hosts.json.
+func CopilotConfigDir(home string) string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "github-copilot")
+	}
+
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+			return filepath.Join(dir, "github-copilot")
+		}
+	}
+
+	return filepath.Join(home, ".config", "github-copilot")
+}
+
+// readCopilotCredentials fills in a missing Copilot token from the editor
+// plugin files, newest format first.
+func readCopilotCredentials(home string, creds *Credentials) {
+	if HasValue(creds.CopilotAPIKey) || os.Getenv("AIQUOTA_COPILOT_TOKEN") != "" {
+		return
+	}
+
+	dir := CopilotConfigDir(home)
+	for _, name := range []string{"apps.json", "hosts.json"} {
+		if token := readCopilotPluginToken(filepath.Join(dir, name)); token != nil {
+			creds.CopilotAPIKey = token
+			return
+		}
+	}
+}
+
+// readCopilotPluginToken returns the first github.com OAuth token of a
+// plugin file. apps.json is keyed by "github.com:<app id>", hosts.json by
+// host name.
+func readCopilotPluginToken(path string) *string {
+	content, err := os.ReadFile(path)
+	if err != nil || !gjson.ValidBytes(content) {
+		return nil
+	}
+
+	var token *string
+	gjson.ParseBytes(content).ForEach(func(key, value gjson.Result) bool {
+		host, _, _ := strings.Cut(key.String(), ":")
+		if host != "github.com" {
+			return true
+		}
+
+		token = optionalString(value.Get("oauth_token"))
+		return !HasValue(token)
+	})
+
+	return token
+}
+
+// GitHubCLIToken asks the GitHub CLI for its token, or returns nil when gh
+// is not installed or not logged in. It runs gh, so GetCredentials leaves it
+// to callers, which only need it when no other source has a Copilot token.
+func GitHubCLIToken() *string {
+	path, err := exec.LookPath("gh")
+	if err != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ghTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, path, "auth", "token", "--hostname", "github.com").Output()
+	if err != nil {
+		return nil
+	}
+
+	token := strings.TrimSpace(string(output))
+	if token == "" {
+		return nil
+	}
+
+	return &token
+}
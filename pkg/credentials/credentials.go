@@ -0,0 +1,418 @@
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// Credentials contains API keys and account information read from auth.json.
+type Credentials struct {
+	CopilotAPIKey        *string      `json:"copilotApiKey,omitempty"`
+	CopilotRefreshToken  *string      `json:"copilotRefreshToken,omitempty"`
+	CopilotOrg           *string      `json:"copilotOrg,omitempty"`
+	CopilotOrgToken      *string      `json:"copilotOrgToken,omitempty"`
+	ZAIAPIKey            *string      `json:"zaiApiKey,omitempty"`
+	CodexAPIKey          *string      `json:"codexApiKey,omitempty"`
+	CodexAccountID       *string      `json:"codexAccountId,omitempty"`
+	CodexRefreshToken    *string      `json:"codexRefreshToken,omitempty"`
+	CodexTokenSource     *TokenSource `json:"codexTokenSource,omitempty"`
+	ReplicateAPIKey      *string      `json:"replicateApiKey,omitempty"`
+	AnthropicAPIKey      *string      `json:"anthropicApiKey,omitempty"`
+	AnthropicAccountType *string      `json:"anthropicAccountType,omitempty"`
+	AnthropicAdminKey    *string      `json:"anthropicAdminKey,omitempty"`
+	GeminiAccessToken    *string      `json:"geminiAccessToken,omitempty"`
+	GeminiTokenExpiry    *time.Time   `json:"geminiTokenExpiry,omitempty"`
+	GeminiProject        *string      `json:"geminiProject,omitempty"`
+	OpenRouterAPIKey     *string      `json:"openRouterApiKey,omitempty"`
+	CursorAccessToken    *string      `json:"cursorAccessToken,omitempty"`
+	CursorEmail          *string      `json:"cursorEmail,omitempty"`
+	CursorMembershipType *string      `json:"cursorMembershipType,omitempty"`
+	MistralAPIKey        *string      `json:"mistralApiKey,omitempty"`
+	MistralTokenLimit    *int64       `json:"mistralTokenLimit,omitempty"`
+	DeepSeekAPIKey       *string      `json:"deepSeekApiKey,omitempty"`
+	GroqAPIKey           *string      `json:"groqApiKey,omitempty"`
+	GroqModels           []string     `json:"groqModels,omitempty"`
+	XAIAPIKey            *string      `json:"xaiApiKey,omitempty"`
+	XAIManagementKey     *string      `json:"xaiManagementKey,omitempty"`
+	XAIModels            []string     `json:"xaiModels,omitempty"`
+	TogetherAPIKey       *string      `json:"togetherApiKey,omitempty"`
+	TogetherModels       []string     `json:"togetherModels,omitempty"`
+	FireworksAPIKey      *string      `json:"fireworksApiKey,omitempty"`
+	FireworksAccountID   *string      `json:"fireworksAccountId,omitempty"`
+	CohereAPIKey         *string      `json:"cohereApiKey,omitempty"`
+	PerplexityAPIKey     *string      `json:"perplexityApiKey,omitempty"`
+	PerplexityModels     []string     `json:"perplexityModels,omitempty"`
+	MoonshotAPIKey       *string      `json:"moonshotApiKey,omitempty"`
+	OpenAIAdminKey       *string      `json:"openAIAdminKey,omitempty"`
+	OpenAIOrganization   *string      `json:"openAIOrganization,omitempty"`
+	OpenAIBudget         *float64     `json:"openAIBudget,omitempty"`
+	AzureToken           *string      `json:"azureToken,omitempty"`
+	AzureTenantID        *string      `json:"azureTenantId,omitempty"`
+	AzureClientID        *string      `json:"azureClientId,omitempty"`
+	AzureClientSecret    *string      `json:"azureClientSecret,omitempty"`
+	AzureSubscriptionID  *string      `json:"azureSubscriptionId,omitempty"`
+	AzureLocation        *string      `json:"azureLocation,omitempty"`
+	AzureResourceGroup   *string      `json:"azureResourceGroup,omitempty"`
+	AzureAccount         *string      `json:"azureAccount,omitempty"`
+	AWSAccessKeyID       *string      `json:"awsAccessKeyId,omitempty"`
+	AWSSecretAccessKey   *string      `json:"awsSecretAccessKey,omitempty"`
+	AWSSessionToken      *string      `json:"awsSessionToken,omitempty"`
+	BedrockModels        []string     `json:"bedrockModels,omitempty"`
+	BedrockRegions       []string     `json:"bedrockRegions,omitempty"`
+	GitHubModelsToken    *string      `json:"gitHubModelsToken,omitempty"`
+	GitHubModels         []string     `json:"gitHubModels,omitempty"`
+}
+
+// envOverrides maps environment variables to the credential they override.
+// They take precedence over every file so aiquota can run on headless
+// machines without OpenCode. Later entries win, so AIQUOTA_XAI_KEY beats the
+// XAI_API_KEY used by the xAI SDKs.
+var envOverrides = []struct {
+	name  string
+	field func(*Credentials) **string
+}{
+	{"AIQUOTA_COPILOT_TOKEN", func(c *Credentials) **string { return &c.CopilotAPIKey }},
+	{"AIQUOTA_COPILOT_ORG", func(c *Credentials) **string { return &c.CopilotOrg }},
+	{"AIQUOTA_COPILOT_ORG_TOKEN", func(c *Credentials) **string { return &c.CopilotOrgToken }},
+	{"AIQUOTA_ZAI_KEY", func(c *Credentials) **string { return &c.ZAIAPIKey }},
+	{"AIQUOTA_CODEX_TOKEN", func(c *Credentials) **string { return &c.CodexAPIKey }},
+	{"AIQUOTA_CODEX_ACCOUNT_ID", func(c *Credentials) **string { return &c.CodexAccountID }},
+	{"AIQUOTA_REPLICATE_KEY", func(c *Credentials) **string { return &c.ReplicateAPIKey }},
+	{"AIQUOTA_ANTHROPIC_TOKEN", func(c *Credentials) **string { return &c.AnthropicAPIKey }},
+	{"ANTHROPIC_ADMIN_KEY", func(c *Credentials) **string { return &c.AnthropicAdminKey }},
+	{"AIQUOTA_ANTHROPIC_ADMIN_KEY", func(c *Credentials) **string { return &c.AnthropicAdminKey }},
+	{"AIQUOTA_OPENROUTER_KEY", func(c *Credentials) **string { return &c.OpenRouterAPIKey }},
+	{"AIQUOTA_CURSOR_TOKEN", func(c *Credentials) **string { return &c.CursorAccessToken }},
+	{"AIQUOTA_MISTRAL_KEY", func(c *Credentials) **string { return &c.MistralAPIKey }},
+	{"AIQUOTA_DEEPSEEK_KEY", func(c *Credentials) **string { return &c.DeepSeekAPIKey }},
+	{"AIQUOTA_GROQ_KEY", func(c *Credentials) **string { return &c.GroqAPIKey }},
+	{"XAI_API_KEY", func(c *Credentials) **string { return &c.XAIAPIKey }},
+	{"AIQUOTA_XAI_KEY", func(c *Credentials) **string { return &c.XAIAPIKey }},
+	{"AIQUOTA_XAI_MANAGEMENT_KEY", func(c *Credentials) **string { return &c.XAIManagementKey }},
+	{"AIQUOTA_TOGETHER_KEY", func(c *Credentials) **string { return &c.TogetherAPIKey }},
+	{"AIQUOTA_FIREWORKS_KEY", func(c *Credentials) **string { return &c.FireworksAPIKey }},
+	{"AIQUOTA_FIREWORKS_ACCOUNT_ID", func(c *Credentials) **string { return &c.FireworksAccountID }},
+	{"AIQUOTA_COHERE_KEY", func(c *Credentials) **string { return &c.CohereAPIKey }},
+	{"AIQUOTA_PERPLEXITY_KEY", func(c *Credentials) **string { return &c.PerplexityAPIKey }},
+	{"AIQUOTA_MOONSHOT_KEY", func(c *Credentials) **string { return &c.MoonshotAPIKey }},
+	{"OPENAI_ADMIN_KEY", func(c *Credentials) **string { return &c.OpenAIAdminKey }},
+	{"AIQUOTA_OPENAI_ADMIN_KEY", func(c *Credentials) **string { return &c.OpenAIAdminKey }},
+	{"AIQUOTA_OPENAI_ORG", func(c *Credentials) **string { return &c.OpenAIOrganization }},
+	{"AIQUOTA_AZURE_TOKEN", func(c *Credentials) **string { return &c.AzureToken }},
+	{"AZURE_TENANT_ID", func(c *Credentials) **string { return &c.AzureTenantID }},
+	{"AZURE_CLIENT_ID", func(c *Credentials) **string { return &c.AzureClientID }},
+	{"AZURE_CLIENT_SECRET", func(c *Credentials) **string { return &c.AzureClientSecret }},
+	{"AZURE_SUBSCRIPTION_ID", func(c *Credentials) **string { return &c.AzureSubscriptionID }},
+	{"AIQUOTA_AZURE_SUBSCRIPTION_ID", func(c *Credentials) **string { return &c.AzureSubscriptionID }},
+	{"AIQUOTA_AZURE_LOCATION", func(c *Credentials) **string { return &c.AzureLocation }},
+	{"AWS_ACCESS_KEY_ID", func(c *Credentials) **string { return &c.AWSAccessKeyID }},
+	{"AWS_SECRET_ACCESS_KEY", func(c *Credentials) **string { return &c.AWSSecretAccessKey }},
+	{"AWS_SESSION_TOKEN", func(c *Credentials) **string { return &c.AWSSessionToken }},
+	{"AIQUOTA_GITHUB_MODELS_TOKEN", func(c *Credentials) **string { return &c.GitHubModelsToken }},
+}
+
+// GetCredentials reads API keys and account information. Each credential
+// comes from the first source that has it: flags, environment variables, the
+// config file, the OS keychain, then the files of OpenCode (authFile, see
+// AuthFilePath) and of the other CLIs and editors. A missing auth.json is
+// only an error when no other source provides a credential.
+func GetCredentials(authFile string) (Credentials, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to resolve user home directory: %w", err)
+	}
+
+	authFilePath, err := AuthFilePath(authFile)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	var creds Credentials
+	content, readErr := os.ReadFile(authFilePath)
+	if readErr != nil && !errors.Is(readErr, os.ErrNotExist) {
+		return Credentials{}, fmt.Errorf("failed to read auth file. please ensure it exists and is properly formatted. error details: %w", readErr)
+	}
+
+	if readErr == nil {
+		if !gjson.ValidBytes(content) {
+			return Credentials{}, fmt.Errorf("failed to read auth file. please ensure it exists and is properly formatted. error details: invalid JSON")
+		}
+
+		creds = Credentials{
+			ZAIAPIKey:           optionalString(gjson.GetBytes(content, "zai-coding-plan.key")),
+			CopilotAPIKey:       optionalString(gjson.GetBytes(content, "github-copilot.access")),
+			CopilotRefreshToken: optionalString(gjson.GetBytes(content, "github-copilot.refresh")),
+			CodexAPIKey:         optionalString(gjson.GetBytes(content, "openai.access")),
+			CodexAccountID:      optionalString(gjson.GetBytes(content, "openai.accountId")),
+			ReplicateAPIKey:     optionalString(gjson.GetBytes(content, "replicate.key")),
+			AnthropicAPIKey:     optionalString(gjson.GetBytes(content, "anthropic.access")),
+			OpenRouterAPIKey:    optionalString(gjson.GetBytes(content, "openrouter.key")),
+			MistralAPIKey:       optionalString(gjson.GetBytes(content, "mistral.key")),
+			DeepSeekAPIKey:      optionalString(gjson.GetBytes(content, "deepseek.key")),
+			GroqAPIKey:          optionalString(gjson.GetBytes(content, "groq.key")),
+			XAIAPIKey:           optionalString(gjson.GetBytes(content, "xai.key")),
+			TogetherAPIKey:      optionalString(gjson.GetBytes(content, "togetherai.key")),
+			FireworksAPIKey:     optionalString(gjson.GetBytes(content, "fireworks-ai.key")),
+			CohereAPIKey:        optionalString(gjson.GetBytes(content, "cohere.key")),
+			PerplexityAPIKey:    optionalString(gjson.GetBytes(content, "perplexity.key")),
+			MoonshotAPIKey:      optionalString(gjson.GetBytes(content, "moonshotai.key")),
+		}
+	}
+
+	readCodexRefresh(home, authFilePath, content, &creds)
+	readCopilotCredentials(home, &creds)
+
+	if creds.AnthropicAPIKey == nil {
+		readClaudeCredentials(home, &creds)
+	}
+
+	readGeminiCredentials(home, &creds)
+	readCursorCredentials(&creds)
+	readKeychain(&creds)
+	applyEnvOverrides(&creds)
+
+	if err := readMistralTokenLimit(&creds); err != nil {
+		return Credentials{}, err
+	}
+
+	if err := readOpenAIBudget(&creds); err != nil {
+		return Credentials{}, err
+	}
+
+	if err := readAzureResource(&creds); err != nil {
+		return Credentials{}, err
+	}
+	readAzureCredentials(&creds)
+
+	creds.GroqModels = readModels("AIQUOTA_GROQ_MODELS")
+	creds.XAIModels = readModels("AIQUOTA_XAI_MODELS")
+	creds.TogetherModels = readModels("AIQUOTA_TOGETHER_MODELS")
+	creds.PerplexityModels = readModels("AIQUOTA_PERPLEXITY_MODELS")
+	creds.GitHubModels = readModels("AIQUOTA_GITHUB_MODELS")
+	creds.BedrockModels = readModels("AIQUOTA_BEDROCK_MODELS")
+	creds.BedrockRegions = readModels("AIQUOTA_BEDROCK_REGIONS")
+	readAWSCredentials(home, &creds)
+
+	if readErr != nil && creds.isEmpty() {
+		return Credentials{}, fmt.Errorf("failed to read auth file. please ensure it exists and is properly formatted. error details: %w", readErr)
+	}
+
+	return creds, nil
+}
+
+func applyEnvOverrides(creds *Credentials) {
+	for _, override := range envOverrides {
+		if value := os.Getenv(override.name); strings.TrimSpace(value) != "" {
+			*override.field(creds) = &value
+		}
+	}
+}
+
+// readMistralTokenLimit reads the monthly Mistral token budget, which the
+// Mistral API does not report, from AIQUOTA_MISTRAL_TOKEN_LIMIT.
+func readMistralTokenLimit(creds *Credentials) error {
+	value := strings.TrimSpace(os.Getenv("AIQUOTA_MISTRAL_TOKEN_LIMIT"))
+	if value == "" {
+		return nil
+	}
+
+	limit, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || limit <= 0 {
+		return fmt.Errorf("invalid AIQUOTA_MISTRAL_TOKEN_LIMIT %q, expected a positive number of tokens", value)
+	}
+
+	creds.MistralTokenLimit = &limit
+	return nil
+}
+
+// readOpenAIBudget reads the monthly OpenAI budget in USD, which the OpenAI
+// API does not report, from AIQUOTA_OPENAI_BUDGET.
+func readOpenAIBudget(creds *Credentials) error {
+	value := strings.TrimSpace(os.Getenv("AIQUOTA_OPENAI_BUDGET"))
+	if value == "" {
+		return nil
+	}
+
+	budget, err := strconv.ParseFloat(strings.TrimPrefix(value, "$"), 64)
+	if err != nil || budget <= 0 {
+		return fmt.Errorf("invalid AIQUOTA_OPENAI_BUDGET %q, expected a positive amount in USD", value)
+	}
+
+	creds.OpenAIBudget = &budget
+	return nil
+}
+
+// readModels reads a comma-separated list from the environment variable
+// name, such as the models to probe in AIQUOTA_GROQ_MODELS.
+func readModels(name string) []string {
+	var models []string
+	for model := range strings.SplitSeq(os.Getenv(name), ",") {
+		if model = strings.TrimSpace(model); model != "" {
+			models = append(models, model)
+		}
+	}
+
+	return models
+}
+
+// isEmpty reports whether no source provided any token or key.
+func (c Credentials) isEmpty() bool {
+	for _, value := range []*string{
+		c.CopilotAPIKey,
+		c.CopilotOrgToken,
+		c.ZAIAPIKey,
+		c.CodexAPIKey,
+		c.ReplicateAPIKey,
+		c.AnthropicAPIKey,
+		c.AnthropicAdminKey,
+		c.GeminiAccessToken,
+		c.OpenRouterAPIKey,
+		c.CursorAccessToken,
+		c.MistralAPIKey,
+		c.DeepSeekAPIKey,
+		c.GroqAPIKey,
+		c.XAIAPIKey,
+		c.TogetherAPIKey,
+		c.FireworksAPIKey,
+		c.CohereAPIKey,
+		c.PerplexityAPIKey,
+		c.MoonshotAPIKey,
+		c.OpenAIAdminKey,
+		c.AzureToken,
+		c.AzureClientSecret,
+		c.AWSSecretAccessKey,
+		c.GitHubModelsToken,
+	} {
+		if HasValue(value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// readClaudeCredentials fills the Anthropic fields from the Claude Code
+// credentials file. A missing or malformed file is not an error because the
+// provider is optional.
+func readClaudeCredentials(home string, creds *Credentials) {
+	content, err := os.ReadFile(filepath.Join(home, ".claude", ".credentials.json"))
+	if err != nil || !gjson.ValidBytes(content) {
+		return
+	}
+
+	creds.AnthropicAPIKey = optionalString(gjson.GetBytes(content, "claudeAiOauth.accessToken"))
+	creds.AnthropicAccountType = optionalString(gjson.GetBytes(content, "claudeAiOauth.subscriptionType"))
+}
+
+// readGeminiCredentials fills the Gemini fields from the Gemini CLI OAuth
+// file. GOOGLE_CLOUD_PROJECT overrides the Code Assist project, as it does
+// for the Gemini CLI itself.
+func readGeminiCredentials(home string, creds *Credentials) {
+	if project := os.Getenv("GOOGLE_CLOUD_PROJECT"); project != "" {
+		creds.GeminiProject = &project
+	}
+
+	content, err := os.ReadFile(filepath.Join(home, ".gemini", "oauth_creds.json"))
+	if err != nil || !gjson.ValidBytes(content) {
+		return
+	}
+
+	creds.GeminiAccessToken = optionalString(gjson.GetBytes(content, "access_token"))
+	if expiry := gjson.GetBytes(content, "expiry_date"); expiry.Exists() && expiry.Type == gjson.Number {
+		value := time.UnixMilli(expiry.Int())
+		creds.GeminiTokenExpiry = &value
+	}
+}
+
+// AuthFilePath resolves the OpenCode auth.json location. An explicit path
+// wins, then the AIQUOTA_AUTH_FILE environment variable, then
+// $XDG_DATA_HOME/opencode/auth.json, then ~/.local/share/opencode/auth.json.
+func AuthFilePath(override string) (string, error) {
+	if override != "" {
+		return override, nil
+	}
+
+	if env := os.Getenv("AIQUOTA_AUTH_FILE"); env != "" {
+		return env, nil
+	}
+
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return filepath.Join(dataHome, "opencode", "auth.json"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve user home directory: %w", err)
+	}
+
+	return filepath.Join(home, ".local", "share", "opencode", "auth.json"), nil
+}
+
+// HasValue reports whether an optional credential is set to a non-blank value.
+func HasValue(value *string) bool {
+	return value != nil && strings.TrimSpace(*value) != ""
+}
+
+func optionalString(result gjson.Result) *string {
+	if !result.Exists() || result.Type == gjson.Null {
+		return nil
+	}
+
+	value := result.String()
+	return &value
+}
+
+// Placeholder returns credentials with a placeholder in every string field,
+// so every provider can run against recorded responses without the tokens
+// they were recorded with.
+func Placeholder() Credentials {
+	var creds Credentials
+	value := reflect.ValueOf(&creds).Elem()
+	for i := range value.NumField() {
+		if field := value.Field(i); field.Type() == reflect.TypeFor[*string]() {
+			field.Set(reflect.ValueOf(new("placeholder")))
+		}
+	}
+
+	return creds
+}
+
+// accountFields are the string fields that name an account or hold a
+// setting rather than a secret, which Redacted keeps.
+var accountFields = []string{
+	"CopilotOrg", "CodexAccountID", "AnthropicAccountType", "GeminiProject",
+	"CursorEmail", "CursorMembershipType", "FireworksAccountID", "OpenAIOrganization",
+	"AzureTenantID", "AzureClientID", "AzureSubscriptionID", "AzureLocation",
+	"AzureResourceGroup", "AzureAccount",
+}
+
+// Redacted returns c with every token and key replaced as in Placeholder,
+// keeping account details and settings, so responses recorded with c can be
+// replayed and rendered as they were without storing its secrets. The Codex
+// token source and the Gemini token expiry are dropped, so a replay never
+// attempts a refresh.
+func (c Credentials) Redacted() Credentials {
+	redacted := Placeholder()
+	source, target := reflect.ValueOf(c), reflect.ValueOf(&redacted).Elem()
+	for i := range source.NumField() {
+		field := source.Field(i)
+		switch name := source.Type().Field(i).Name; {
+		case name == "CodexTokenSource", name == "GeminiTokenExpiry":
+		case field.Type() != reflect.TypeFor[*string]():
+			target.Field(i).Set(field)
+		case slices.Contains(accountFields, name) && !field.IsNil():
+			target.Field(i).Set(field)
+		}
+	}
+
+	return redacted
+}
@@ -0,0 +1,38 @@
+package credentials
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRedactedKeepsAccountDetails(t *testing.T) {
+	creds := Credentials{
+		AnthropicAPIKey:      new("sk-ant-secret"),
+		AnthropicAccountType: new("max"),
+		CursorEmail:          new("dev@example.com"),
+		OpenAIOrganization:   new("org-acme"),
+		OpenAIBudget:         new(50.0),
+		MistralTokenLimit:    new(int64(1_000_000)),
+		GroqModels:           []string{"llama-3.3-70b"},
+		CodexTokenSource:     &TokenSource{Path: "/home/dev/.codex/auth.json", Kind: SourceCodexCLI},
+		GeminiTokenExpiry:    new(time.Now()),
+	}
+
+	redacted := creds.Redacted()
+
+	if *redacted.AnthropicAPIKey != "placeholder" || *redacted.ZAIAPIKey != "placeholder" {
+		t.Errorf("keys = %q, %q, want placeholders", *redacted.AnthropicAPIKey, *redacted.ZAIAPIKey)
+	}
+	if *redacted.AnthropicAccountType != "max" || *redacted.CursorEmail != "dev@example.com" || *redacted.OpenAIOrganization != "org-acme" {
+		t.Errorf("account details = %q, %q, %q, want them kept", *redacted.AnthropicAccountType, *redacted.CursorEmail, *redacted.OpenAIOrganization)
+	}
+	if *redacted.CopilotOrg != "placeholder" {
+		t.Errorf("unset CopilotOrg = %q, want a placeholder", *redacted.CopilotOrg)
+	}
+	if *redacted.OpenAIBudget != 50 || *redacted.MistralTokenLimit != 1_000_000 || len(redacted.GroqModels) != 1 {
+		t.Errorf("settings = %v, %v, %v, want them kept", *redacted.OpenAIBudget, *redacted.MistralTokenLimit, redacted.GroqModels)
+	}
+	if redacted.CodexTokenSource != nil || redacted.GeminiTokenExpiry != nil {
+		t.Errorf("refresh state kept: %v, %v", redacted.CodexTokenSource, redacted.GeminiTokenExpiry)
+	}
+}
@@ -0,0 +1,56 @@
+package credentials
+
+import (
+	"database/sql"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	// Registers the pure Go "sqlite" driver used to read Cursor's state.
+	_ "modernc.org/sqlite"
+)
+
+// CursorStatePath returns the location of Cursor's global state database.
+func CursorStatePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "Cursor", "User", "globalStorage", "state.vscdb"), nil
+}
+
+// readCursorCredentials fills the Cursor fields from the editor's state
+// database, where Cursor keeps its session after sign-in. A missing or
+// unreadable database is not an error because the provider is optional.
+func readCursorCredentials(creds *Credentials) {
+	path, err := CursorStatePath()
+	if err != nil {
+		return
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return
+	}
+
+	// immutable=1 skips locking so a running Cursor does not block the read.
+	dsn := (&url.URL{Scheme: "file", Path: path, RawQuery: "mode=ro&immutable=1"}).String()
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return
+	}
+	defer db.Close()
+
+	creds.CursorAccessToken = cursorItem(db, "cursorAuth/accessToken")
+	creds.CursorEmail = cursorItem(db, "cursorAuth/cachedEmail")
+	creds.CursorMembershipType = cursorItem(db, "cursorAuth/stripeMembershipType")
+}
+
+func cursorItem(db *sql.DB, key string) *string {
+	var value sql.NullString
+	if err := db.QueryRow("SELECT value FROM ItemTable WHERE key = ?", key).Scan(&value); err != nil || !value.Valid {
+		return nil
+	}
+
+	return &value.String
+}
@@ -0,0 +1,121 @@
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+// keychainService is the service name of every aiquota keychain entry. The
+// entry user is the provider ID.
+const keychainService = "aiquota"
+
+// keychainFields maps provider IDs to the credential their keychain entry
+// holds.
+var keychainFields = map[string]func(*Credentials) **string{
+	"copilot":       func(c *Credentials) **string { return &c.CopilotAPIKey },
+	"copilot-org":   func(c *Credentials) **string { return &c.CopilotOrgToken },
+	"zai":           func(c *Credentials) **string { return &c.ZAIAPIKey },
+	"codex":         func(c *Credentials) **string { return &c.CodexAPIKey },
+	"replicate":     func(c *Credentials) **string { return &c.ReplicateAPIKey },
+	"anthropic":     func(c *Credentials) **string { return &c.AnthropicAPIKey },
+	"anthropic-api": func(c *Credentials) **string { return &c.AnthropicAdminKey },
+	"openrouter":    func(c *Credentials) **string { return &c.OpenRouterAPIKey },
+	"cursor":        func(c *Credentials) **string { return &c.CursorAccessToken },
+	"mistral":       func(c *Credentials) **string { return &c.MistralAPIKey },
+	"deepseek":      func(c *Credentials) **string { return &c.DeepSeekAPIKey },
+	"groq":          func(c *Credentials) **string { return &c.GroqAPIKey },
+	"xai":           func(c *Credentials) **string { return &c.XAIAPIKey },
+	"together":      func(c *Credentials) **string { return &c.TogetherAPIKey },
+	"fireworks":     func(c *Credentials) **string { return &c.FireworksAPIKey },
+	"cohere":        func(c *Credentials) **string { return &c.CohereAPIKey },
+	"perplexity":    func(c *Credentials) **string { return &c.PerplexityAPIKey },
+	"moonshot":      func(c *Credentials) **string { return &c.MoonshotAPIKey },
+	"openai":        func(c *Credentials) **string { return &c.OpenAIAdminKey },
+	"azure":         func(c *Credentials) **string { return &c.AzureClientSecret },
+	"bedrock":       func(c *Credentials) **string { return &c.AWSSecretAccessKey },
+	"github-models": func(c *Credentials) **string { return &c.GitHubModelsToken },
+}
+
+// Token returns the main token or API key of a provider, or nil when it is
+// not set or the provider ID is unknown.
+func (c Credentials) Token(providerID string) *string {
+	if providerID == "gemini" {
+		return c.GeminiAccessToken
+	}
+
+	field, ok := keychainFields[providerID]
+	if !ok {
+		return nil
+	}
+
+	return *field(&c)
+}
+
+// KeychainProviders returns the provider IDs whose token can be stored in
+// the OS keychain.
+func KeychainProviders() []string {
+	ids := make([]string, 0, len(keychainFields))
+	for id := range keychainFields {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	return ids
+}
+
+// SetKeychainToken stores the token of a provider in the OS keychain: the
+// macOS Keychain, the Secret Service on Linux or the Windows Credential
+// Manager.
+func SetKeychainToken(providerID string, token string) error {
+	if _, ok := keychainFields[providerID]; !ok {
+		return fmt.Errorf("%s tokens cannot be stored in the keychain, expected one of %s", providerID, strings.Join(KeychainProviders(), ", "))
+	}
+
+	if err := keyring.Set(keychainService, providerID, token); err != nil {
+		return fmt.Errorf("failed to store %s token in the keychain: %w", providerID, err)
+	}
+
+	return nil
+}
+
+// DeleteKeychainToken removes the token of a provider from the OS keychain.
+func DeleteKeychainToken(providerID string) error {
+	err := keyring.Delete(keychainService, providerID)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("no %s token stored in the keychain", providerID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete %s token from the keychain: %w", providerID, err)
+	}
+
+	return nil
+}
+
+// readKeychain overrides file credentials with tokens stored in the OS
+// keychain. AIQUOTA_NO_KEYCHAIN skips the lookup, for example on headless
+// machines where an unlock prompt would block. A keychain that cannot be
+// reached at all is skipped after the first failure.
+func readKeychain(creds *Credentials) {
+	if os.Getenv("AIQUOTA_NO_KEYCHAIN") != "" {
+		return
+	}
+
+	for _, id := range KeychainProviders() {
+		token, err := keyring.Get(keychainService, id)
+		if errors.Is(err, keyring.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return
+		}
+
+		if strings.TrimSpace(token) != "" {
+			*keychainFields[id](creds) = &token
+		}
+	}
+}
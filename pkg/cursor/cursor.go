@@ -0,0 +1,112 @@
+package cursor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultBaseURL is the Cursor web API base URL.
+const DefaultBaseURL = "https://cursor.com"
+
+// Quota contains Cursor premium (fast) request usage for the billing cycle.
+type Quota struct {
+	AccountEmail        string   `json:"accountEmail"`
+	AccountType         string   `json:"accountType"`
+	RequestsUsed        int64    `json:"requestsUsed"`
+	RequestsLimit       *int64   `json:"requestsLimit"`
+	RequestsRemaining   *int64   `json:"requestsRemaining"`
+	RequestsUsedPercent *float64 `json:"requestsUsedPercent"`
+	BillingCycleStart   string   `json:"billingCycleStart"`
+	ResetAt             string   `json:"resetAt"`
+	ResetIn             string   `json:"resetIn"`
+}
+
+// GetQuota fetches Cursor request usage using the editor session token.
+//
+// Premium requests are reported under the "gpt-4" model key, with a null
+// maxRequestUsage meaning the plan has no request limit.
+func GetQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (Quota, error) {
+	if creds.CursorAccessToken == nil || *creds.CursorAccessToken == "" {
+		return Quota{}, fmt.Errorf("missing Cursor session token in credentials")
+	}
+
+	token := *creds.CursorAccessToken
+	subject, ok := helpers.JWTSubject(token)
+	if !ok {
+		return Quota{}, fmt.Errorf("invalid Cursor session token: missing user id")
+	}
+
+	// The subject looks like "auth0|user_01H...", the API wants the last part.
+	userID := subject[strings.LastIndex(subject, "|")+1:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/api/usage?user="+url.QueryEscape(userID), nil)
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to create Cursor request: %w", err)
+	}
+
+	req.Header.Set("Cookie", "WorkosCursorSessionToken="+userID+"%3A%3A"+token)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to fetch Cursor quota: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to read Cursor response: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return Quota{}, fmt.Errorf("failed to fetch Cursor quota. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	result := Quota{
+		AccountEmail:      stringValue(creds.CursorEmail),
+		AccountType:       stringValue(creds.CursorMembershipType),
+		RequestsUsed:      gjson.GetBytes(body, "gpt-4.numRequests").Int(),
+		BillingCycleStart: "unknown",
+		ResetAt:           "unknown",
+		ResetIn:           "unknown",
+	}
+
+	if limit := gjson.GetBytes(body, "gpt-4.maxRequestUsage"); limit.Exists() && limit.Type != gjson.Null {
+		value := limit.Int()
+		remaining := max(0, value-result.RequestsUsed)
+		result.RequestsLimit = &value
+		result.RequestsRemaining = &remaining
+		if value > 0 {
+			usedPercent := helpers.ClampPercent(float64(result.RequestsUsed) / float64(value) * 100)
+			result.RequestsUsedPercent = &usedPercent
+		}
+	}
+
+	// The cycle starts on the subscription anniversary and lasts one month.
+	if start, err := time.Parse(time.RFC3339, gjson.GetBytes(body, "startOfMonth").String()); err == nil {
+		result.BillingCycleStart = start.UTC().Format(time.RFC3339)
+		result.ResetAt = start.UTC().AddDate(0, 1, 0).Format(time.RFC3339)
+		result.ResetIn = helpers.FormatTimeUntil(result.ResetAt)
+	}
+
+	return result, nil
+}
+
+func stringValue(value *string) string {
+	if value == nil || *value == "" {
+		return "unknown"
+	}
+
+	return *value
+}
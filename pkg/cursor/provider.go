@@ -0,0 +1,67 @@
+package cursor
+
+import (
+	"cmp"
+	"context"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// Provider exposes Cursor through the common provider interface.
+type Provider struct {
+	// BaseURL overrides DefaultBaseURL, for example to go through a proxy.
+	BaseURL string
+	// HTTPClient sends the requests. Nil means httpclient.Default.
+	HTTPClient httpclient.Doer
+}
+
+// ID implements provider.Provider.
+func (Provider) ID() string { return "cursor" }
+
+// Name implements provider.Provider.
+func (Provider) Name() string { return "Cursor" }
+
+// Enabled implements provider.Provider.
+func (Provider) Enabled(creds credentials.Credentials) bool {
+	return credentials.HasValue(creds.CursorAccessToken)
+}
+
+// WithBaseURL implements provider.BaseURLSetter.
+func (p Provider) WithBaseURL(baseURL string) provider.Provider {
+	p.BaseURL = baseURL
+	return p
+}
+
+// Endpoint implements provider.Endpointer.
+func (p Provider) Endpoint() string {
+	return cmp.Or(p.BaseURL, DefaultBaseURL)
+}
+
+// Fetch implements provider.Provider.
+func (p Provider) Fetch(ctx context.Context, creds credentials.Credentials) (provider.Quota, error) {
+	quota, err := GetQuota(ctx, httpclient.OrDefault(p.HTTPClient), creds, cmp.Or(p.BaseURL, DefaultBaseURL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+// Windows implements provider.Quota.
+func (q Quota) Windows() []provider.Window {
+	window := provider.Window{
+		ID:          "premium",
+		Name:        "Premium Requests",
+		UsedPercent: q.RequestsUsedPercent,
+		Used:        new(float64(q.RequestsUsed)),
+		ResetAt:     q.ResetAt,
+	}
+
+	if q.RequestsLimit != nil {
+		window.Limit = new(float64(*q.RequestsLimit))
+	}
+
+	return []provider.Window{window}
+}
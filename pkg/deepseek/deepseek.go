@@ -0,0 +1,77 @@
+package deepseek
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultBaseURL is the DeepSeek API base URL.
+const DefaultBaseURL = "https://api.deepseek.com"
+
+// Balance is the account balance held in one currency.
+type Balance struct {
+	Currency        string  `json:"currency"`
+	TotalBalance    float64 `json:"totalBalance"`
+	GrantedBalance  float64 `json:"grantedBalance"`
+	ToppedUpBalance float64 `json:"toppedUpBalance"`
+}
+
+// Quota contains the DeepSeek account balance.
+//
+// DeepSeek is pay as you go, so the remaining balance stands in for a quota
+// and never resets.
+type Quota struct {
+	IsAvailable bool      `json:"isAvailable"`
+	Balances    []Balance `json:"balances"`
+}
+
+// GetQuota fetches the DeepSeek account balance.
+func GetQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (Quota, error) {
+	if creds.DeepSeekAPIKey == nil || *creds.DeepSeekAPIKey == "" {
+		return Quota{}, fmt.Errorf("missing DeepSeek API key in credentials")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/user/balance", nil)
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to create DeepSeek request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+*creds.DeepSeekAPIKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to fetch DeepSeek quota: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to read DeepSeek response: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return Quota{}, fmt.Errorf("failed to fetch DeepSeek quota. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	result := Quota{IsAvailable: gjson.GetBytes(body, "is_available").Bool()}
+
+	// Amounts are returned as decimal strings, which gjson converts.
+	for _, info := range gjson.GetBytes(body, "balance_infos").Array() {
+		result.Balances = append(result.Balances, Balance{
+			Currency:        info.Get("currency").String(),
+			TotalBalance:    info.Get("total_balance").Float(),
+			GrantedBalance:  info.Get("granted_balance").Float(),
+			ToppedUpBalance: info.Get("topped_up_balance").Float(),
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,66 @@
+package deepseek
+
+import (
+	"cmp"
+	"context"
+	"strings"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// Provider exposes DeepSeek through the common provider interface.
+type Provider struct {
+	// BaseURL overrides DefaultBaseURL, for example to go through a proxy.
+	BaseURL string
+	// HTTPClient sends the requests. Nil means httpclient.Default.
+	HTTPClient httpclient.Doer
+}
+
+// ID implements provider.Provider.
+func (Provider) ID() string { return "deepseek" }
+
+// Name implements provider.Provider.
+func (Provider) Name() string { return "DeepSeek" }
+
+// Enabled implements provider.Provider.
+func (Provider) Enabled(creds credentials.Credentials) bool {
+	return credentials.HasValue(creds.DeepSeekAPIKey)
+}
+
+// WithBaseURL implements provider.BaseURLSetter.
+func (p Provider) WithBaseURL(baseURL string) provider.Provider {
+	p.BaseURL = baseURL
+	return p
+}
+
+// Endpoint implements provider.Endpointer.
+func (p Provider) Endpoint() string {
+	return cmp.Or(p.BaseURL, DefaultBaseURL)
+}
+
+// Fetch implements provider.Provider.
+func (p Provider) Fetch(ctx context.Context, creds credentials.Credentials) (provider.Quota, error) {
+	quota, err := GetQuota(ctx, httpclient.OrDefault(p.HTTPClient), creds, cmp.Or(p.BaseURL, DefaultBaseURL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+// Windows implements provider.Quota with one balance window per currency.
+func (q Quota) Windows() []provider.Window {
+	windows := make([]provider.Window, 0, len(q.Balances))
+	for _, balance := range q.Balances {
+		windows = append(windows, provider.Window{
+			ID:        "balance_" + strings.ToLower(balance.Currency),
+			Name:      "Balance (" + balance.Currency + ")",
+			Remaining: new(balance.TotalBalance),
+			ResetAt:   "unknown",
+		})
+	}
+
+	return windows
+}
@@ -0,0 +1,124 @@
+package fireworks
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultBaseURL is the Fireworks AI API base URL.
+const DefaultBaseURL = "https://api.fireworks.ai"
+
+// Quota contains the Fireworks AI prepaid credit balance and the spend of
+// the current billing period.
+//
+// Fireworks bills monthly, so the spend resets at PeriodEnd while the
+// credit balance only changes with top-ups and usage.
+type Quota struct {
+	AccountID     string  `json:"accountId"`
+	Currency      string  `json:"currency"`
+	CreditBalance float64 `json:"creditBalance"`
+	PeriodSpend   float64 `json:"periodSpend"`
+	PeriodStart   string  `json:"periodStart"`
+	ResetAt       string  `json:"resetAt"`
+	ResetIn       string  `json:"resetIn"`
+}
+
+// GetQuota fetches the billing summary of creds.FireworksAccountID, or of
+// the first account the key belongs to.
+func GetQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (Quota, error) {
+	if creds.FireworksAPIKey == nil || *creds.FireworksAPIKey == "" {
+		return Quota{}, fmt.Errorf("missing Fireworks AI API key in credentials")
+	}
+
+	apiKey := *creds.FireworksAPIKey
+	accountID := ""
+	if creds.FireworksAccountID != nil {
+		accountID = *creds.FireworksAccountID
+	}
+
+	if accountID == "" {
+		accounts, err := get(ctx, client, apiKey, baseURL+"/v1/accounts")
+		if err != nil {
+			return Quota{}, err
+		}
+
+		// Account names have the form "accounts/<id>".
+		name := gjson.GetBytes(accounts, "accounts.0.name").String()
+		accountID = strings.TrimPrefix(name, "accounts/")
+		if accountID == "" {
+			return Quota{}, fmt.Errorf("no Fireworks AI account found for the API key")
+		}
+	}
+
+	summary, err := get(ctx, client, apiKey, baseURL+"/v1/accounts/"+url.PathEscape(accountID)+"/billingSummary")
+	if err != nil {
+		return Quota{}, err
+	}
+
+	// Without period bounds in the summary, assume a calendar month.
+	now := time.Now().UTC()
+	periodStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	periodEnd := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	if start := gjson.GetBytes(summary, "periodStartTime").String(); start != "" {
+		periodStart = helpers.NormalizeISO(start)
+	}
+	if end := gjson.GetBytes(summary, "periodEndTime").String(); end != "" {
+		periodEnd = helpers.NormalizeISO(end)
+	}
+
+	balance := gjson.GetBytes(summary, "creditBalance")
+	return Quota{
+		AccountID:     accountID,
+		Currency:      strings.ToUpper(cmp.Or(balance.Get("currencyCode").String(), "USD")),
+		CreditBalance: money(balance),
+		PeriodSpend:   money(gjson.GetBytes(summary, "currentPeriodSpend")),
+		PeriodStart:   periodStart,
+		ResetAt:       periodEnd,
+		ResetIn:       helpers.FormatTimeUntil(periodEnd),
+	}, nil
+}
+
+// money converts a google.type.Money value, split into whole units and
+// nanos, to a float.
+func money(value gjson.Result) float64 {
+	return value.Get("units").Float() + value.Get("nanos").Float()/1e9
+}
+
+func get(ctx context.Context, client httpclient.Doer, apiKey string, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Fireworks AI request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Fireworks AI quota: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Fireworks AI response: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to fetch Fireworks AI quota. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	return body, nil
+}
@@ -0,0 +1,69 @@
+package fireworks
+
+import (
+	"cmp"
+	"context"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// Provider exposes Fireworks AI through the common provider interface.
+type Provider struct {
+	// BaseURL overrides DefaultBaseURL, for example to go through a proxy.
+	BaseURL string
+	// HTTPClient sends the requests. Nil means httpclient.Default.
+	HTTPClient httpclient.Doer
+}
+
+// ID implements provider.Provider.
+func (Provider) ID() string { return "fireworks" }
+
+// Name implements provider.Provider.
+func (Provider) Name() string { return "Fireworks AI" }
+
+// Enabled implements provider.Provider.
+func (Provider) Enabled(creds credentials.Credentials) bool {
+	return credentials.HasValue(creds.FireworksAPIKey)
+}
+
+// WithBaseURL implements provider.BaseURLSetter.
+func (p Provider) WithBaseURL(baseURL string) provider.Provider {
+	p.BaseURL = baseURL
+	return p
+}
+
+// Endpoint implements provider.Endpointer.
+func (p Provider) Endpoint() string {
+	return cmp.Or(p.BaseURL, DefaultBaseURL)
+}
+
+// Fetch implements provider.Provider.
+func (p Provider) Fetch(ctx context.Context, creds credentials.Credentials) (provider.Quota, error) {
+	quota, err := GetQuota(ctx, httpclient.OrDefault(p.HTTPClient), creds, cmp.Or(p.BaseURL, DefaultBaseURL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+// Windows implements provider.Quota with the credit balance, which never
+// resets, and the spend of the current billing period.
+func (q Quota) Windows() []provider.Window {
+	return []provider.Window{
+		{
+			ID:        "balance",
+			Name:      "Credit Balance (" + q.Currency + ")",
+			Remaining: new(q.CreditBalance),
+			ResetAt:   "unknown",
+		},
+		{
+			ID:      "spend",
+			Name:    "Spend This Period (" + q.Currency + ")",
+			Used:    new(q.PeriodSpend),
+			ResetAt: q.ResetAt,
+		},
+	}
+}
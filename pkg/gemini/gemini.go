@@ -0,0 +1,157 @@
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultBaseURL is the Code Assist API base URL.
+const DefaultBaseURL = "https://cloudcode-pa.googleapis.com/v1internal"
+
+// ModelQuota describes the daily quota bucket of a single model.
+type ModelQuota struct {
+	ModelID          string  `json:"modelId"`
+	TokenType        string  `json:"tokenType"`
+	UsedPercent      float64 `json:"usedPercent"`
+	RemainingPercent float64 `json:"remainingPercent"`
+	Remaining        *int64  `json:"remaining"`
+	ResetAt          string  `json:"resetAt"`
+	ResetIn          string  `json:"resetIn"`
+}
+
+// Quota contains Gemini Code Assist tier and per-model quota information.
+type Quota struct {
+	AccountType string       `json:"accountType"`
+	Project     string       `json:"project"`
+	Models      []ModelQuota `json:"models"`
+}
+
+// GetQuota fetches Gemini Code Assist quota using the Gemini CLI OAuth token.
+func GetQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (Quota, error) {
+	if creds.GeminiAccessToken == nil || *creds.GeminiAccessToken == "" {
+		return Quota{}, fmt.Errorf("missing Gemini OAuth token in credentials")
+	}
+
+	if creds.GeminiTokenExpiry != nil && time.Now().After(*creds.GeminiTokenExpiry) {
+		return Quota{}, fmt.Errorf("gemini OAuth token expired at %s, run the gemini CLI once to refresh it", creds.GeminiTokenExpiry.UTC().Format(time.RFC3339))
+	}
+
+	assist, err := post(ctx, client, baseURL, *creds.GeminiAccessToken, "loadCodeAssist", map[string]any{
+		"metadata": map[string]string{
+			"ideType":    "IDE_UNSPECIFIED",
+			"platform":   "PLATFORM_UNSPECIFIED",
+			"pluginType": "GEMINI",
+		},
+	})
+	if err != nil {
+		return Quota{}, err
+	}
+
+	project := gjson.GetBytes(assist, "cloudaicompanionProject").String()
+	if creds.GeminiProject != nil && *creds.GeminiProject != "" {
+		project = *creds.GeminiProject
+	}
+
+	quotaBody, err := post(ctx, client, baseURL, *creds.GeminiAccessToken, "retrieveUserQuota", map[string]any{"project": project})
+	if err != nil {
+		return Quota{}, err
+	}
+
+	accountType := gjson.GetBytes(assist, "currentTier.name").String()
+	if accountType == "" {
+		accountType = gjson.GetBytes(assist, "currentTier.id").String()
+	}
+
+	return Quota{
+		AccountType: accountType,
+		Project:     project,
+		Models:      parseBuckets(gjson.GetBytes(quotaBody, "buckets")),
+	}, nil
+}
+
+func parseBuckets(buckets gjson.Result) []ModelQuota {
+	items := buckets.Array()
+	result := make([]ModelQuota, 0, len(items))
+	for _, item := range items {
+		remainingPercent := helpers.ClampPercent(item.Get("remainingFraction").Float() * 100)
+		usedPercent := helpers.ClampPercent(100 - remainingPercent)
+		resetAt := isoResultToISO(item.Get("resetTime"))
+
+		var remaining *int64
+		if amount := item.Get("remainingAmount"); amount.Exists() && amount.Type != gjson.Null {
+			value := int64(math.Max(0, amount.Float()))
+			remaining = &value
+		}
+
+		result = append(result, ModelQuota{
+			ModelID:          item.Get("modelId").String(),
+			TokenType:        item.Get("tokenType").String(),
+			UsedPercent:      usedPercent,
+			RemainingPercent: remainingPercent,
+			Remaining:        remaining,
+			ResetAt:          resetAt,
+			ResetIn:          helpers.FormatTimeUntil(resetAt),
+		})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].ModelID < result[j].ModelID
+	})
+
+	return result
+}
+
+func post(ctx context.Context, client httpclient.Doer, baseURL string, token string, method string, payload any) ([]byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Gemini request: %w", err)
+	}
+
+	// loadCodeAssist and retrieveUserQuota only read, so they are safe to
+	// retry.
+	req, err := http.NewRequestWithContext(httpclient.WithIdempotent(ctx), http.MethodPost, baseURL+":"+method, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Gemini quota: %w", err)
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Gemini response: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to fetch Gemini quota. Status: %d, Response: %s", response.StatusCode, string(responseBody))
+	}
+
+	return responseBody, nil
+}
+
+func isoResultToISO(value gjson.Result) string {
+	if !value.Exists() || value.Type == gjson.Null {
+		return "unknown"
+	}
+
+	return helpers.NormalizeISO(value.String())
+}
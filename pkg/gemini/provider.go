@@ -0,0 +1,73 @@
+package gemini
+
+import (
+	"cmp"
+	"context"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// Provider exposes Gemini Code Assist through the common provider interface.
+type Provider struct {
+	// BaseURL overrides DefaultBaseURL, for example to go through a proxy.
+	BaseURL string
+	// HTTPClient sends the requests. Nil means httpclient.Default.
+	HTTPClient httpclient.Doer
+}
+
+// ID implements provider.Provider.
+func (Provider) ID() string { return "gemini" }
+
+// Name implements provider.Provider.
+func (Provider) Name() string { return "Google Gemini" }
+
+// Enabled implements provider.Provider.
+func (Provider) Enabled(creds credentials.Credentials) bool {
+	return credentials.HasValue(creds.GeminiAccessToken)
+}
+
+// WithBaseURL implements provider.BaseURLSetter.
+func (p Provider) WithBaseURL(baseURL string) provider.Provider {
+	p.BaseURL = baseURL
+	return p
+}
+
+// Endpoint implements provider.Endpointer.
+func (p Provider) Endpoint() string {
+	return cmp.Or(p.BaseURL, DefaultBaseURL)
+}
+
+// Fetch implements provider.Provider.
+func (p Provider) Fetch(ctx context.Context, creds credentials.Credentials) (provider.Quota, error) {
+	quota, err := GetQuota(ctx, httpclient.OrDefault(p.HTTPClient), creds, cmp.Or(p.BaseURL, DefaultBaseURL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+// Windows implements provider.Quota with one daily window per model.
+func (q Quota) Windows() []provider.Window {
+	windows := make([]provider.Window, 0, len(q.Models))
+	for _, model := range q.Models {
+		window := provider.Window{
+			ID:          model.ModelID,
+			Name:        model.ModelID,
+			UsedPercent: new(model.UsedPercent),
+			ResetAt:     model.ResetAt,
+		}
+
+		if model.Remaining != nil && model.RemainingPercent > 0 {
+			limit := float64(*model.Remaining) * 100 / model.RemainingPercent
+			window.Limit = &limit
+			window.Used = new(limit - float64(*model.Remaining))
+		}
+
+		windows = append(windows, window)
+	}
+
+	return windows
+}
@@ -0,0 +1,194 @@
+package githubmodels
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultBaseURL is the GitHub Models API base URL.
+const DefaultBaseURL = "https://models.github.ai"
+
+// DefaultModels are probed when AIQUOTA_GITHUB_MODELS is not set, one from
+// the low and one from the high rate limit tier.
+var DefaultModels = []string{"openai/gpt-4.1-mini", "openai/gpt-4.1"}
+
+// Limit is one rate limit of a model tier as reported by GitHub Models.
+type Limit struct {
+	Limit       int64   `json:"limit"`
+	Remaining   int64   `json:"remaining"`
+	UsedPercent float64 `json:"usedPercent"`
+	ResetAt     string  `json:"resetAt"`
+	ResetIn     string  `json:"resetIn"`
+}
+
+// ModelLimits holds the free-tier allowance of the tier a model belongs to.
+type ModelLimits struct {
+	Model    string `json:"model"`
+	Tier     string `json:"tier"`
+	Requests Limit  `json:"requests"`
+	Tokens   Limit  `json:"tokens"`
+}
+
+// Quota contains the GitHub Models request allowance of the probed models.
+//
+// Limits are shared by every model of a rate limit tier and are only
+// reported in the x-ratelimit-* headers of inference responses, so each
+// model is probed with a one-token completion that itself counts as one
+// request.
+type Quota struct {
+	Models []ModelLimits `json:"models"`
+}
+
+// Token returns the token used for GitHub Models: AIQUOTA_GITHUB_MODELS_TOKEN,
+// or the Copilot token.
+func Token(creds credentials.Credentials) *string {
+	if credentials.HasValue(creds.GitHubModelsToken) {
+		return creds.GitHubModelsToken
+	}
+
+	return creds.CopilotAPIKey
+}
+
+// GetQuota probes every model in creds.GitHubModels, or DefaultModels, and
+// reads the allowance of its tier.
+func GetQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (Quota, error) {
+	token := Token(creds)
+	if !credentials.HasValue(token) {
+		return Quota{}, fmt.Errorf("missing GitHub token in credentials")
+	}
+
+	models := creds.GitHubModels
+	if len(models) == 0 {
+		models = DefaultModels
+	}
+
+	tiers := catalogTiers(ctx, client, *token, baseURL)
+
+	result := Quota{Models: make([]ModelLimits, 0, len(models))}
+	for _, model := range models {
+		limits, err := probe(ctx, client, *token, baseURL, model)
+		if err != nil {
+			return Quota{}, err
+		}
+
+		limits.Tier = cmp.Or(tiers[model], "unknown")
+		result.Models = append(result.Models, limits)
+	}
+
+	return result, nil
+}
+
+// catalogTiers maps model IDs to their rate limit tier. The catalog is only
+// used for labels, so failures yield an empty map.
+func catalogTiers(ctx context.Context, client httpclient.Doer, token string, baseURL string) map[string]string {
+	tiers := map[string]string{}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/catalog/models", nil)
+	if err != nil {
+		return tiers
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return tiers
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil || response.StatusCode != http.StatusOK {
+		return tiers
+	}
+
+	for _, model := range gjson.ParseBytes(body).Array() {
+		tiers[model.Get("id").String()] = model.Get("rate_limit_tier").String()
+	}
+
+	return tiers
+}
+
+// probe sends a minimal completion for model and parses the rate limit
+// headers of the response. A 429 still carries the headers and means the
+// allowance is spent, so it is not an error.
+func probe(ctx context.Context, client httpclient.Doer, token string, baseURL string, model string) (ModelLimits, error) {
+	payload, err := json.Marshal(map[string]any{
+		"model":      model,
+		"messages":   []map[string]string{{"role": "user", "content": "."}},
+		"max_tokens": 1,
+	})
+	if err != nil {
+		return ModelLimits{}, fmt.Errorf("failed to encode GitHub Models request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/inference/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return ModelLimits{}, fmt.Errorf("failed to create GitHub Models request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return ModelLimits{}, fmt.Errorf("failed to fetch GitHub Models quota: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return ModelLimits{}, fmt.Errorf("failed to read GitHub Models response: %w", err)
+	}
+
+	ok := response.StatusCode >= 200 && response.StatusCode < 300
+	if !ok && response.StatusCode != http.StatusTooManyRequests {
+		return ModelLimits{}, fmt.Errorf("failed to fetch GitHub Models quota for %s. Status: %d, Response: %s", model, response.StatusCode, string(body))
+	}
+
+	now := time.Now()
+	return ModelLimits{
+		Model:    model,
+		Requests: parseLimit(response.Header, "requests", now),
+		Tokens:   parseLimit(response.Header, "tokens", now),
+	}, nil
+}
+
+func parseLimit(header http.Header, kind string, now time.Time) Limit {
+	limit, _ := strconv.ParseInt(header.Get("x-ratelimit-limit-"+kind), 10, 64)
+	remaining, _ := strconv.ParseInt(header.Get("x-ratelimit-remaining-"+kind), 10, 64)
+
+	result := Limit{Limit: limit, Remaining: remaining, ResetAt: "unknown", ResetIn: "unknown"}
+	if limit > 0 {
+		result.UsedPercent = helpers.ClampPercent(float64(limit-remaining) / float64(limit) * 100)
+	}
+
+	// Resets are given in seconds, either as a plain number or with a unit.
+	reset := header.Get("x-ratelimit-reset-" + kind)
+	if _, err := strconv.ParseFloat(reset, 64); err == nil {
+		reset += "s"
+	}
+	if duration, err := time.ParseDuration(reset); err == nil {
+		result.ResetAt = now.Add(duration).UTC().Format(time.RFC3339)
+		result.ResetIn = helpers.FormatTimeUntil(result.ResetAt)
+	}
+
+	return result
+}
@@ -0,0 +1,76 @@
+package githubmodels
+
+import (
+	"cmp"
+	"context"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// Provider exposes GitHub Models through the common provider interface.
+type Provider struct {
+	// BaseURL overrides DefaultBaseURL, for example to go through a proxy.
+	BaseURL string
+	// HTTPClient sends the requests. Nil means httpclient.Default.
+	HTTPClient httpclient.Doer
+}
+
+// ID implements provider.Provider.
+func (Provider) ID() string { return "github-models" }
+
+// Name implements provider.Provider.
+func (Provider) Name() string { return "GitHub Models" }
+
+// Enabled implements provider.Provider. Every probe spends one request of
+// the daily allowance, so the Copilot token alone does not enable it: it
+// also needs AIQUOTA_GITHUB_MODELS or its own AIQUOTA_GITHUB_MODELS_TOKEN.
+func (Provider) Enabled(creds credentials.Credentials) bool {
+	return credentials.HasValue(creds.GitHubModelsToken) ||
+		(len(creds.GitHubModels) > 0 && credentials.HasValue(creds.CopilotAPIKey))
+}
+
+// WithBaseURL implements provider.BaseURLSetter.
+func (p Provider) WithBaseURL(baseURL string) provider.Provider {
+	p.BaseURL = baseURL
+	return p
+}
+
+// Endpoint implements provider.Endpointer.
+func (p Provider) Endpoint() string {
+	return cmp.Or(p.BaseURL, DefaultBaseURL)
+}
+
+// Fetch implements provider.Provider.
+func (p Provider) Fetch(ctx context.Context, creds credentials.Credentials) (provider.Quota, error) {
+	quota, err := GetQuota(ctx, httpclient.OrDefault(p.HTTPClient), creds, cmp.Or(p.BaseURL, DefaultBaseURL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+// Windows implements provider.Quota with a request window and a token
+// window for every probed model.
+func (q Quota) Windows() []provider.Window {
+	windows := make([]provider.Window, 0, 2*len(q.Models))
+	for _, model := range q.Models {
+		windows = append(windows, window(model.Model+"_requests", model.Model+" Requests", model.Requests))
+		windows = append(windows, window(model.Model+"_tokens", model.Model+" Tokens", model.Tokens))
+	}
+
+	return windows
+}
+
+func window(id string, name string, limit Limit) provider.Window {
+	return provider.Window{
+		ID:          id,
+		Name:        name,
+		UsedPercent: new(limit.UsedPercent),
+		Used:        new(float64(limit.Limit - limit.Remaining)),
+		Limit:       new(float64(limit.Limit)),
+		ResetAt:     limit.ResetAt,
+	}
+}
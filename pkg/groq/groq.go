@@ -0,0 +1,136 @@
+package groq
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+)
+
+// DefaultBaseURL is the Groq OpenAI-compatible API base URL.
+const DefaultBaseURL = "https://api.groq.com/openai/v1"
+
+// DefaultModels are probed when AIQUOTA_GROQ_MODELS is not set.
+var DefaultModels = []string{"llama-3.1-8b-instant"}
+
+// Limit is one rate limit of a model as reported by Groq.
+type Limit struct {
+	Limit       int64   `json:"limit"`
+	Remaining   int64   `json:"remaining"`
+	UsedPercent float64 `json:"usedPercent"`
+	ResetAt     string  `json:"resetAt"`
+	ResetIn     string  `json:"resetIn"`
+}
+
+// ModelLimits holds the rate limits Groq applies to one model.
+type ModelLimits struct {
+	Model           string `json:"model"`
+	RequestsPerDay  Limit  `json:"requestsPerDay"`
+	TokensPerMinute Limit  `json:"tokensPerMinute"`
+}
+
+// Quota contains the Groq rate limits of the probed models.
+//
+// Groq only reports limits in the x-ratelimit-* headers of inference
+// responses, so each model is probed with a one-token completion. Daily
+// token limits are not exposed in the headers.
+type Quota struct {
+	Models []ModelLimits `json:"models"`
+}
+
+// GetQuota probes every model in creds.GroqModels, or DefaultModels, and
+// reads its rate limits.
+func GetQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (Quota, error) {
+	if creds.GroqAPIKey == nil || *creds.GroqAPIKey == "" {
+		return Quota{}, fmt.Errorf("missing Groq API key in credentials")
+	}
+
+	models := creds.GroqModels
+	if len(models) == 0 {
+		models = DefaultModels
+	}
+
+	result := Quota{Models: make([]ModelLimits, 0, len(models))}
+	for _, model := range models {
+		limits, err := probe(ctx, client, *creds.GroqAPIKey, baseURL, model)
+		if err != nil {
+			return Quota{}, err
+		}
+
+		result.Models = append(result.Models, limits)
+	}
+
+	return result, nil
+}
+
+// probe sends a minimal completion for model and parses the rate limit
+// headers of the response. A 429 still carries the headers and means the
+// limit is spent, so it is not an error.
+func probe(ctx context.Context, client httpclient.Doer, apiKey string, baseURL string, model string) (ModelLimits, error) {
+	payload, err := json.Marshal(map[string]any{
+		"model":                 model,
+		"messages":              []map[string]string{{"role": "user", "content": "."}},
+		"max_completion_tokens": 1,
+	})
+	if err != nil {
+		return ModelLimits{}, fmt.Errorf("failed to encode Groq request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return ModelLimits{}, fmt.Errorf("failed to create Groq request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return ModelLimits{}, fmt.Errorf("failed to fetch Groq quota: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return ModelLimits{}, fmt.Errorf("failed to read Groq response: %w", err)
+	}
+
+	ok := response.StatusCode >= 200 && response.StatusCode < 300
+	if !ok && response.StatusCode != http.StatusTooManyRequests {
+		return ModelLimits{}, fmt.Errorf("failed to fetch Groq quota for %s. Status: %d, Response: %s", model, response.StatusCode, string(body))
+	}
+
+	now := time.Now()
+	return ModelLimits{
+		Model:           model,
+		RequestsPerDay:  parseLimit(response.Header, "requests", now),
+		TokensPerMinute: parseLimit(response.Header, "tokens", now),
+	}, nil
+}
+
+func parseLimit(header http.Header, kind string, now time.Time) Limit {
+	limit, _ := strconv.ParseInt(header.Get("x-ratelimit-limit-"+kind), 10, 64)
+	remaining, _ := strconv.ParseInt(header.Get("x-ratelimit-remaining-"+kind), 10, 64)
+
+	result := Limit{Limit: limit, Remaining: remaining, ResetAt: "unknown", ResetIn: "unknown"}
+	if limit > 0 {
+		result.UsedPercent = helpers.ClampPercent(float64(limit-remaining) / float64(limit) * 100)
+	}
+
+	// Resets are relative durations such as "2m59.56s".
+	if reset, err := time.ParseDuration(header.Get("x-ratelimit-reset-" + kind)); err == nil {
+		result.ResetAt = now.Add(reset).UTC().Format(time.RFC3339)
+		result.ResetIn = helpers.FormatTimeUntil(result.ResetAt)
+	}
+
+	return result
+}
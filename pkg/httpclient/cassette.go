@@ -0,0 +1,73 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// RecordEnv is the environment variable that switches cassettes from replay
+// to recording.
+const RecordEnv = "AIQUOTA_RECORD"
+
+// Cassette is a Doer that plays back provider responses stored in a
+// directory, in the --dump-raw format, so a provider can be exercised
+// offline against fixtures. With AIQUOTA_RECORD set it sends the requests to
+// the provider instead and records the redacted responses, replacing the
+// previous recording. Either way requests go through the base client with
+// the Replay or Dump in their context, so a cassette over Default exercises
+// the same Transport as a --from-dump or --dump-raw run.
+//
+// A Cassette attributes its requests to one provider, so it can be passed
+// directly as the HTTPClient of a provider:
+//
+//	client, err := httpclient.NewCassette("testdata/zai", "zai", nil)
+//	quota, err := zai.Provider{HTTPClient: client}.Fetch(ctx, creds)
+type Cassette struct {
+	base       Doer
+	providerID string
+	dump       *Dump
+	replay     *Replay
+}
+
+// NewCassette returns a cassette for providerID backed by dir. Base sends
+// the requests, and must use a Transport for the cassette to take effect;
+// nil means Default.
+func NewCassette(dir string, providerID string, base Doer) (*Cassette, error) {
+	cassette := &Cassette{base: OrDefault(base), providerID: providerID}
+
+	// Dumps and replays keep one subdirectory per provider, so the cassette
+	// directory is used as the parent of the provider directory.
+	var err error
+	if os.Getenv(RecordEnv) != "" {
+		cassette.dump, err = NewDump(dir)
+	} else {
+		cassette.replay, err = NewReplay(dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette %s: %w", dir, err)
+	}
+
+	return cassette, nil
+}
+
+// Recording reports whether the cassette records instead of replaying.
+func (c *Cassette) Recording() bool {
+	return c.dump != nil
+}
+
+// Do implements Doer.
+func (c *Cassette) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if providerFrom(ctx) == "" {
+		ctx = WithProvider(ctx, c.providerID)
+	}
+
+	if c.replay != nil {
+		ctx = WithReplay(ctx, c.replay)
+	} else {
+		ctx = WithDump(ctx, c.dump)
+	}
+
+	return c.base.Do(req.WithContext(ctx))
+}
@@ -0,0 +1,86 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func cassetteGet(t *testing.T, cassette *Cassette, url string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer sk-test")
+
+	response, err := cassette.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return response.StatusCode, string(body)
+}
+
+func TestCassetteRecordsAndReplays(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"balance":12.5,"email":"dev@example.com"}`))
+	}))
+	dir := t.TempDir()
+	base := &http.Client{Transport: &Transport{Base: http.DefaultTransport}}
+
+	t.Setenv(RecordEnv, "1")
+	recorder, err := NewCassette(dir, "deepseek", base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !recorder.Recording() {
+		t.Fatalf("cassette replays with %s set", RecordEnv)
+	}
+	if status, body := cassetteGet(t, recorder, server.URL+"/user/balance"); status != http.StatusOK || !strings.Contains(body, "dev@example.com") {
+		t.Errorf("recording returned %d %s, want the live response", status, body)
+	}
+
+	// The replay never reaches the server, and serves the recording with
+	// its secrets redacted.
+	server.Close()
+	t.Setenv(RecordEnv, "")
+	player, err := NewCassette(dir, "deepseek", base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, body := cassetteGet(t, player, server.URL+"/user/balance")
+	if status != http.StatusOK || !strings.Contains(body, "12.5") {
+		t.Errorf("replay returned %d %s, want the recorded response", status, body)
+	}
+	if strings.Contains(body, "dev@example.com") {
+		t.Errorf("replay returned the unredacted email: %s", body)
+	}
+}
+
+func TestCassetteReportsMissingResponse(t *testing.T) {
+	player, err := NewCassette(t.TempDir(), "deepseek", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://api.deepseek.com/user/balance", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := player.Do(req); err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Errorf("err = %v, want a missing recording", err)
+	}
+}
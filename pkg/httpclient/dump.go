@@ -0,0 +1,307 @@
+package httpclient
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// secretFields are JSON response fields, matched as lower-case substrings,
+// whose values are redacted in dumps. Other string values still go through
+// RedactText.
+var secretFields = []string{"token", "secret", "password", "api_key", "apikey", "email", "account_id", "accountid"}
+
+// Exchange is one recorded request and response, as stored in a dump file.
+type Exchange struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	// Body is the response body: JSON when the body is JSON, a string
+	// otherwise. It is redacted unless a private dump wrote it.
+	Body json.RawMessage `json:"body"`
+}
+
+// Dump writes every provider response to DIR/<provider>/<n>.json with
+// credentials redacted, so the files can be attached to bug reports.
+type Dump struct {
+	dir string
+	// private keeps the response bodies as received, except those of token
+	// and login endpoints.
+	private bool
+
+	mu    sync.Mutex
+	count map[string]int
+}
+
+type dumpKey struct{}
+
+// WithDump returns a copy of ctx whose responses are written to dump.
+func WithDump(ctx context.Context, dump *Dump) context.Context {
+	return context.WithValue(ctx, dumpKey{}, dump)
+}
+
+// NewDump returns a Dump writing to dir, creating it if needed.
+func NewDump(dir string) (*Dump, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create dump directory: %w", err)
+	}
+
+	return &Dump{dir: dir, count: map[string]int{}}, nil
+}
+
+// NewPrivateDump returns a Dump writing to dir like NewDump, but which keeps
+// the response bodies unredacted, so replaying it renders account details
+// such as emails as the live responses did. Its files are only readable by
+// the user, and must not be shared.
+func NewPrivateDump(dir string) (*Dump, error) {
+	dump, err := NewDump(dir)
+	if err != nil {
+		return nil, err
+	}
+	dump.private = true
+
+	return dump, nil
+}
+
+// record stores response and returns it with an unread body.
+func (d *Dump) record(req *http.Request, response *http.Response) (*http.Response, error) {
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(body))
+
+	exchange := Exchange{
+		Method:  req.Method,
+		URL:     RedactURL(req.URL),
+		Status:  response.StatusCode,
+		Headers: map[string]string{},
+		Body:    redactBody(body),
+	}
+	if d.private && !credentialEndpoint(req.URL) {
+		exchange.Body = rawBody(body)
+	}
+	for key, values := range response.Header {
+		value := RedactText(strings.Join(values, ", "))
+		if secretHeaders[key] {
+			value = redacted
+		}
+		exchange.Headers[key] = value
+	}
+
+	content, err := json.MarshalIndent(exchange, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode raw dump: %w", err)
+	}
+
+	providerID := cmp.Or(providerFrom(req.Context()), "unknown")
+	dir := filepath.Join(d.dir, providerID)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// A fresh run replaces the previous dump of the provider, so replays
+	// never mix responses from two runs.
+	if d.count[providerID] == 0 {
+		if err := os.RemoveAll(dir); err != nil {
+			return nil, fmt.Errorf("failed to clear raw dump directory: %w", err)
+		}
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create raw dump directory: %w", err)
+	}
+
+	d.count[providerID]++
+	path := filepath.Join(dir, fmt.Sprintf("%03d.json", d.count[providerID]))
+	if err := os.WriteFile(path, append(content, '\n'), 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write raw dump: %w", err)
+	}
+
+	return response, nil
+}
+
+// Replay answers requests from the files written by a Dump instead of the
+// network.
+type Replay struct {
+	mu        sync.Mutex
+	exchanges map[string][]*Exchange
+	used      map[*Exchange]bool
+}
+
+type replayKey struct{}
+
+// WithReplay returns a copy of ctx whose requests are answered by replay.
+func WithReplay(ctx context.Context, replay *Replay) context.Context {
+	return context.WithValue(ctx, replayKey{}, replay)
+}
+
+// NewReplay loads the dump in dir.
+func NewReplay(dir string) (*Replay, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dump directory: %w", err)
+	}
+
+	replay := &Replay{exchanges: map[string][]*Exchange{}, used: map[*Exchange]bool{}}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		files, err := filepath.Glob(filepath.Join(dir, entry.Name(), "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		slices.Sort(files)
+
+		for _, file := range files {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read dump file: %w", err)
+			}
+
+			var exchange Exchange
+			if err := json.Unmarshal(content, &exchange); err != nil {
+				return nil, fmt.Errorf("invalid dump file %s: %w", file, err)
+			}
+			replay.exchanges[entry.Name()] = append(replay.exchanges[entry.Name()], &exchange)
+		}
+	}
+
+	return replay, nil
+}
+
+// Providers returns the IDs of the providers with recorded responses.
+func (r *Replay) Providers() []string {
+	ids := make([]string, 0, len(r.exchanges))
+	for id, exchanges := range r.exchanges {
+		if len(exchanges) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	return ids
+}
+
+// serve returns the next unused response of the provider that sent req,
+// preferring one recorded for the same method and path. Paths can differ
+// when they embed account names that a replay without credentials lacks,
+// so responses are otherwise served in recording order.
+func (r *Replay) serve(req *http.Request) (*http.Response, error) {
+	providerID := providerFrom(req.Context())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var match *Exchange
+	for _, exchange := range r.exchanges[providerID] {
+		if r.used[exchange] {
+			continue
+		}
+
+		if match == nil {
+			match = exchange
+		}
+
+		recorded, err := url.Parse(exchange.URL)
+		if err == nil && exchange.Method == req.Method && recorded.Path == req.URL.Path {
+			match = exchange
+			break
+		}
+	}
+
+	if match == nil {
+		return nil, fmt.Errorf("no recorded response for %s %s", req.Method, RedactURL(req.URL))
+	}
+	r.used[match] = true
+
+	body := []byte(match.Body)
+	var text string
+	if json.Unmarshal(match.Body, &text) == nil {
+		body = []byte(text)
+	}
+
+	header := http.Header{}
+	for key, value := range match.Headers {
+		header.Set(key, value)
+	}
+
+	return &http.Response{
+		Status:        http.StatusText(match.Status),
+		StatusCode:    match.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// rawBody returns body unchanged when it is JSON, or as a JSON string
+// otherwise.
+func rawBody(body []byte) json.RawMessage {
+	if json.Valid(body) {
+		return body
+	}
+
+	text, _ := json.Marshal(string(body))
+	return text
+}
+
+// redactBody returns body as JSON with secret fields redacted, or as a
+// redacted JSON string when it is not JSON.
+func redactBody(body []byte) json.RawMessage {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		text, _ := json.Marshal(RedactText(string(body)))
+		return text
+	}
+
+	content, err := json.Marshal(redactValue(value))
+	if err != nil {
+		text, _ := json.Marshal(RedactText(string(body)))
+		return text
+	}
+
+	return content
+}
+
+func redactValue(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, field := range value {
+			lower := strings.ToLower(key)
+			if _, isString := field.(string); isString && slices.ContainsFunc(secretFields, func(secret string) bool { return strings.Contains(lower, secret) }) {
+				value[key] = redacted
+				continue
+			}
+			value[key] = redactValue(field)
+		}
+	case []any:
+		for i, item := range value {
+			value[i] = redactValue(item)
+		}
+	case string:
+		return RedactText(value)
+	}
+
+	return value
+}
@@ -0,0 +1,168 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// credentialPaths are URL path segments, matched case-insensitively, of
+// endpoints that hand out tokens or sessions, whose responses Validators
+// never stores and private dumps redact.
+var credentialPaths = []string{"token", "tokens", "oauth", "oauth2", "auth", "login", "session", "sessions"}
+
+// Validators remembers the ETag and Last-Modified validators of successful
+// GET responses, with their body, in DIR/<hash>.json, and makes later
+// requests for the same URL conditional. Servers that support it, such as
+// the GitHub API, then answer 304 Not Modified instead of sending the body
+// again, and on the GitHub API such answers do not count against the rate
+// limit. Callers never see the 304: it is answered with the stored response.
+// Stored responses keep their body as received, since a 304 serves it back
+// to the provider, but lose the headers that carry credentials. The files
+// are only readable by the user and named after a hash of the URL, and
+// responses of token and login endpoints are never stored.
+type Validators struct {
+	dir string
+}
+
+type validatorsKey struct{}
+
+// WithValidators returns a copy of ctx whose requests are made conditional
+// by validators.
+func WithValidators(ctx context.Context, validators *Validators) context.Context {
+	return context.WithValue(ctx, validatorsKey{}, validators)
+}
+
+// NewValidators returns Validators stored in dir, which is created on the
+// first response worth storing.
+func NewValidators(dir string) *Validators {
+	return &Validators{dir: dir}
+}
+
+// validatedResponse is a response stored by Validators. Body is the JSON
+// body, or a JSON string when the body is not JSON.
+type validatedResponse struct {
+	ETag         string          `json:"etag,omitempty"`
+	LastModified string          `json:"last_modified,omitempty"`
+	Header       http.Header     `json:"header"`
+	Body         json.RawMessage `json:"body"`
+}
+
+// revalidate returns req with the validators of the response stored for it,
+// and that response, or req unchanged and nil when there is none. Only GET
+// requests are made conditional, and only when the caller did not already.
+func (v *Validators) revalidate(req *http.Request) (*http.Request, *validatedResponse) {
+	if !v.applies(req) {
+		return req, nil
+	}
+
+	content, err := os.ReadFile(v.path(req))
+	if err != nil {
+		return req, nil
+	}
+
+	var stored validatedResponse
+	if json.Unmarshal(content, &stored) != nil {
+		return req, nil
+	}
+
+	conditional := req.Clone(req.Context())
+	if stored.ETag != "" {
+		conditional.Header.Set("If-None-Match", stored.ETag)
+	}
+	if stored.LastModified != "" {
+		conditional.Header.Set("If-Modified-Since", stored.LastModified)
+	}
+
+	return conditional, &stored
+}
+
+// update answers a 304 response with stored, and stores successful responses
+// that carry validators. Storing is best effort, so write failures only mean
+// the next request is not conditional.
+func (v *Validators) update(req *http.Request, stored *validatedResponse, response *http.Response) (*http.Response, error) {
+	if !v.applies(req) {
+		return response, nil
+	}
+
+	if response.StatusCode == http.StatusNotModified && stored != nil {
+		_, _ = io.Copy(io.Discard, response.Body)
+		response.Body.Close()
+
+		body := []byte(stored.Body)
+		var text string
+		if json.Unmarshal(stored.Body, &text) == nil {
+			body = []byte(text)
+		}
+
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         response.Proto,
+			ProtoMajor:    response.ProtoMajor,
+			ProtoMinor:    response.ProtoMinor,
+			Header:        stored.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       response.Request,
+		}, nil
+	}
+
+	etag, lastModified := response.Header.Get("ETag"), response.Header.Get("Last-Modified")
+	if response.StatusCode != http.StatusOK || etag == "" && lastModified == "" {
+		return response, nil
+	}
+
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := response.Header.Clone()
+	for key := range header {
+		if secretHeaders[key] {
+			header.Del(key)
+		}
+	}
+
+	content, err := json.Marshal(validatedResponse{ETag: etag, LastModified: lastModified, Header: header, Body: rawBody(body)})
+	if err == nil && os.MkdirAll(v.dir, 0o700) == nil {
+		_ = os.WriteFile(v.path(req), content, 0o600)
+	}
+
+	return response, nil
+}
+
+func (v *Validators) applies(req *http.Request) bool {
+	return req.Method == http.MethodGet &&
+		req.Header.Get("If-None-Match") == "" &&
+		req.Header.Get("If-Modified-Since") == "" &&
+		!credentialEndpoint(req.URL)
+}
+
+// credentialEndpoint reports whether u has one of the credentialPaths.
+func credentialEndpoint(u *url.URL) bool {
+	return slices.ContainsFunc(strings.Split(strings.ToLower(u.Path), "/"), func(segment string) bool {
+		return slices.Contains(credentialPaths, segment)
+	})
+}
+
+// path returns the file of the response stored for req, keyed by its URL
+// with credentials redacted, so rotating a token reuses the file instead of
+// leaving the old one behind. Another account requesting the same URL gets
+// its own response back, because a 304 means the content is unchanged.
+func (v *Validators) path(req *http.Request) string {
+	hash := sha256.Sum256([]byte(RedactURL(req.URL)))
+	return filepath.Join(v.dir, hex.EncodeToString(hash[:16])+".json")
+}
@@ -0,0 +1,101 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// etagServer answers every path with an ETag, and 304 when the request
+// already carries it.
+func etagServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Set-Cookie", "session=secret")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte(`{"login":"octocat","email":"dev@example.com","token":"tid=secret"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func getWithValidators(t *testing.T, validators *Validators, url string, token string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(WithValidators(context.Background(), validators), http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "token "+token)
+
+	client := &http.Client{Transport: &Transport{Base: http.DefaultTransport}}
+	response, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return response.StatusCode, string(body)
+}
+
+func TestValidatorsStoreResponsePerURL(t *testing.T) {
+	server := etagServer(t)
+	dir := t.TempDir()
+	validators := NewValidators(dir)
+
+	if status, _ := getWithValidators(t, validators, server.URL+"/user", "gho_first"); status != http.StatusOK {
+		t.Fatalf("first status = %d", status)
+	}
+
+	// A rotated token reuses the stored response instead of a new file.
+	// The stored body is served as received, or providers would parse
+	// redacted account details.
+	status, body := getWithValidators(t, validators, server.URL+"/user", "gho_second")
+	if status != http.StatusOK || body != `{"login":"octocat","email":"dev@example.com","token":"tid=secret"}` {
+		t.Errorf("revalidated response = %d %s, want the stored body", status, body)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("stored %d files, want 1", len(entries))
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "session=secret") {
+		t.Error("stored response contains the Set-Cookie header")
+	}
+}
+
+func TestValidatorsSkipTokenEndpoints(t *testing.T) {
+	server := etagServer(t)
+	dir := t.TempDir()
+	validators := NewValidators(dir)
+
+	getWithValidators(t, validators, server.URL+"/copilot_internal/v2/token", "gho_first")
+
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		t.Errorf("stored %d files for a token endpoint, want none", len(entries))
+	}
+}
@@ -0,0 +1,39 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// Default is the client providers use for quota requests. Its transport
+// retries transient failures according to the Policy carried by the
+// request context.
+var Default = &http.Client{Transport: &Transport{Base: http.DefaultTransport}}
+
+// Doer sends HTTP requests. *http.Client implements it, and tests can
+// substitute a client pointed at an httptest server.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type providerKey struct{}
+
+// WithProvider returns a copy of ctx whose requests are attributed to the
+// provider id in logs and dumps.
+func WithProvider(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, providerKey{}, id)
+}
+
+func providerFrom(ctx context.Context) string {
+	id, _ := ctx.Value(providerKey{}).(string)
+	return id
+}
+
+// OrDefault returns client, or Default when client is nil.
+func OrDefault(client Doer) Doer {
+	if client == nil {
+		return Default
+	}
+
+	return client
+}
@@ -0,0 +1,106 @@
+package httpclient
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// redacted replaces secrets in logged URLs and headers.
+const redacted = "REDACTED"
+
+// secretHeaders are request headers that carry credentials.
+var secretHeaders = map[string]bool{
+	"Authorization":        true,
+	"Cookie":               true,
+	"Set-Cookie":           true,
+	"X-Api-Key":            true,
+	"X-Goog-Api-Key":       true,
+	"X-Amz-Security-Token": true,
+	"X-Gotify-Key":         true,
+}
+
+// secretParams are query parameters that carry credentials.
+var secretParams = []string{"key", "token", "secret", "signature", "password", "auth"}
+
+// logAttrs returns the attributes every request log of ctx carries.
+func logAttrs(ctx context.Context) []slog.Attr {
+	if providerID := providerFrom(ctx); providerID != "" {
+		return []slog.Attr{slog.String("provider", providerID)}
+	}
+
+	return nil
+}
+
+// RedactURL returns u as a string with user info and credential-like query
+// parameters replaced.
+func RedactURL(u *url.URL) string {
+	clean := *u
+	if clean.User != nil {
+		clean.User = url.User(redacted)
+	}
+
+	query := clean.Query()
+	changed := false
+	for name := range query {
+		lower := strings.ToLower(name)
+		for _, secret := range secretParams {
+			if strings.Contains(lower, secret) {
+				query.Set(name, redacted)
+				changed = true
+				break
+			}
+		}
+	}
+	if changed {
+		clean.RawQuery = query.Encode()
+	}
+
+	return clean.String()
+}
+
+// RedactHeaders returns header as a log group with credential headers
+// replaced.
+func RedactHeaders(name string, header http.Header) slog.Attr {
+	attrs := make([]any, 0, len(header))
+	for key, values := range header {
+		value := strings.Join(values, ", ")
+		if secretHeaders[http.CanonicalHeaderKey(key)] {
+			value = redacted
+		}
+		attrs = append(attrs, slog.String(key, value))
+	}
+
+	return slog.Group(name, attrs...)
+}
+
+// logAttempt logs one attempt of req. Responses are logged at info level,
+// headers only at debug level.
+func logAttempt(ctx context.Context, req *http.Request, attempt int, response *http.Response, err error, latency time.Duration) {
+	logger := slog.Default()
+	if !logger.Enabled(ctx, slog.LevelInfo) {
+		return
+	}
+
+	attrs := append(logAttrs(ctx),
+		slog.String("method", req.Method),
+		slog.String("url", RedactURL(req.URL)),
+		slog.Int("attempt", attempt+1),
+		slog.Any("latency", latency),
+	)
+
+	if err != nil {
+		logger.LogAttrs(ctx, slog.LevelInfo, "request failed", append(attrs, slog.String("error", err.Error()))...)
+		return
+	}
+
+	attrs = append(attrs, slog.Int("status", response.StatusCode))
+	if logger.Enabled(ctx, slog.LevelDebug) {
+		attrs = append(attrs, RedactHeaders("request_headers", req.Header), RedactHeaders("response_headers", response.Header))
+	}
+
+	logger.LogAttrs(ctx, slog.LevelInfo, "request", attrs...)
+}
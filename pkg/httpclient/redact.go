@@ -0,0 +1,81 @@
+package httpclient
+
+import (
+	"regexp"
+	"strings"
+)
+
+// secretPatterns match credentials in free text, such as response bodies
+// echoed in error messages. Each pattern keeps its first group, if any, and
+// replaces the rest of the match.
+var secretPatterns = []*regexp.Regexp{
+	// Authorization header values.
+	regexp.MustCompile(`(?i)(\bbearer\s+)[^\s"',;]+`),
+	regexp.MustCompile(`(\bBasic\s+)[A-Za-z0-9+/]{8,}={0,2}`),
+	// JSON fields and key=value pairs named like credentials or account IDs.
+	regexp.MustCompile(`(?i)("[\w-]*(?:token|secret|password|api[_-]?key|authorization|account[_-]?id|signature)[\w-]*"\s*:\s*")[^"]+`),
+	regexp.MustCompile(`(?i)(\b[\w-]*(?:token|secret|password|api[_-]?key|account[_-]?id|signature)[\w-]*=)[^\s&"',;]{8,}`),
+	// Telegram bot tokens in URL paths.
+	regexp.MustCompile(`(/bot)\d+:[\w-]+`),
+	// GitHub Copilot session tokens.
+	regexp.MustCompile(`()\btid=[^\s"']+`),
+	// JWTs.
+	regexp.MustCompile(`()\beyJ[\w-]+\.[\w-]+\.[\w-]*`),
+	// Provider key prefixes.
+	regexp.MustCompile(`()\b(?:sk|xai|pplx|fw)-[\w-]{16,}`),
+	regexp.MustCompile(`()\b(?:gh[pousr]|github_pat|gsk|r8|nvapi)_[\w-]{16,}`),
+	regexp.MustCompile(`()\bAIza[\w-]{30,}`),
+	regexp.MustCompile(`()\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+}
+
+// opaquePattern matches long runs of key-like characters. Runs that mix
+// letters and digits are redacted as unknown keys or account IDs.
+var opaquePattern = regexp.MustCompile(`[A-Za-z0-9_-]{32,}`)
+
+// RedactText returns s with anything that looks like a token, key or
+// account ID replaced, for error messages and logs that may echo response
+// bodies or request URLs.
+func RedactText(s string) string {
+	for _, pattern := range secretPatterns {
+		s = pattern.ReplaceAllString(s, "${1}"+redacted)
+	}
+
+	return opaquePattern.ReplaceAllStringFunc(s, func(match string) string {
+		if strings.ContainsAny(match, "0123456789") && strings.ContainsFunc(match, isLetter) {
+			return redacted
+		}
+
+		return match
+	})
+}
+
+func isLetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+// redactedError is an error whose message is redacted, keeping the wrapped
+// error for errors.Is and errors.As.
+type redactedError struct {
+	err error
+}
+
+func (e redactedError) Error() string {
+	return RedactText(e.err.Error())
+}
+
+func (e redactedError) Unwrap() error {
+	return e.err
+}
+
+// RedactError returns err with its message passed through RedactText, or nil
+// when err is nil.
+func RedactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(redactedError); ok {
+		return err
+	}
+
+	return redactedError{err: err}
+}
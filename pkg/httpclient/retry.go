@@ -0,0 +1,209 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MaxBackoff caps the wait between two attempts.
+const MaxBackoff = 30 * time.Second
+
+// Policy controls how transient failures are retried.
+type Policy struct {
+	// Retries is the number of attempts after the first one.
+	Retries int
+	// Backoff is the wait before the first retry. It doubles, with jitter,
+	// for every further retry, unless the server sends Retry-After.
+	Backoff time.Duration
+	// Idempotent retries requests of every method. Without it only GET and
+	// HEAD requests are retried, so a POST to an alert sink or a write API
+	// that failed after the server acted on it is never sent twice.
+	Idempotent bool
+}
+
+// DefaultPolicy applies to requests whose context carries no policy.
+var DefaultPolicy = Policy{Retries: 2, Backoff: 500 * time.Millisecond}
+
+type policyKey struct{}
+
+// WithPolicy returns a copy of ctx whose requests are retried according to
+// policy.
+func WithPolicy(ctx context.Context, policy Policy) context.Context {
+	return context.WithValue(ctx, policyKey{}, policy)
+}
+
+// WithIdempotent returns a copy of ctx whose requests are retried whatever
+// their method, for callers whose POST requests only read, keeping the rest
+// of the policy of ctx.
+func WithIdempotent(ctx context.Context) context.Context {
+	policy := PolicyFrom(ctx)
+	policy.Idempotent = true
+
+	return WithPolicy(ctx, policy)
+}
+
+// PolicyFrom returns the policy carried by ctx, or DefaultPolicy.
+func PolicyFrom(ctx context.Context) Policy {
+	if policy, ok := ctx.Value(policyKey{}).(Policy); ok {
+		return policy
+	}
+
+	return DefaultPolicy
+}
+
+// Transport retries requests that fail with a network error, 429 Too Many
+// Requests or a 5xx status, when their method is GET or HEAD or the policy
+// is Idempotent. A retry is skipped when its wait would outlive
+// the request deadline, in which case the last response is returned as is.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper. Requests whose context carries a
+// Replay are answered from it without touching the network, requests are
+// made conditional by the Validators carried by the context, and final
+// responses are written to the Dump carried by the context, if any.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if replay, ok := ctx.Value(replayKey{}).(*Replay); ok {
+		return replay.serve(req)
+	}
+
+	validators, _ := ctx.Value(validatorsKey{}).(*Validators)
+	sent := req
+	var stored *validatedResponse
+	if validators != nil {
+		sent, stored = validators.revalidate(req)
+	}
+
+	response, err := t.retry(sent)
+	if validators != nil && err == nil {
+		response, err = validators.update(req, stored, response)
+	}
+	if dump, ok := ctx.Value(dumpKey{}).(*Dump); ok && err == nil {
+		return dump.record(req, response)
+	}
+
+	return response, err
+}
+
+func (t *Transport) retry(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	policy := PolicyFrom(ctx)
+
+	for attempt := 0; ; attempt++ {
+		attemptReq, err := rewind(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		response, err := t.Base.RoundTrip(attemptReq)
+		logAttempt(ctx, attemptReq, attempt, response, err, time.Since(start))
+		if attempt >= policy.Retries || !policy.retries(req) || !retryable(ctx, response, err) {
+			return response, err
+		}
+
+		wait := backoff(policy.Backoff, attempt)
+		if response != nil {
+			if after, ok := retryAfter(response.Header.Get("Retry-After")); ok {
+				wait = after
+			}
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			return response, err
+		}
+
+		slog.LogAttrs(ctx, slog.LevelInfo, "retrying request", append(logAttrs(ctx),
+			slog.String("url", RedactURL(req.URL)),
+			slog.Int("attempt", attempt+2),
+			slog.Duration("wait", wait),
+		)...)
+
+		if response != nil {
+			_, _ = io.Copy(io.Discard, response.Body)
+			response.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// rewind returns the request to send for an attempt, with a fresh body for
+// every retry.
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+
+	if req.GetBody == nil {
+		return nil, errors.New("cannot retry request with a body that cannot be rewound")
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	clone := req.Clone(req.Context())
+	clone.Body = body
+	return clone, nil
+}
+
+// retries reports whether the policy retries requests with the method of req.
+func (p Policy) retries(req *http.Request) bool {
+	return p.Idempotent || req.Method == http.MethodGet || req.Method == http.MethodHead
+}
+
+func retryable(ctx context.Context, response *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+
+	return response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500
+}
+
+// backoff returns the jittered exponential wait before retry attempt+1.
+func backoff(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+
+	wait := MaxBackoff
+	if attempt < 16 {
+		wait = min(base<<attempt, MaxBackoff)
+	}
+
+	return wait/2 + rand.N(wait/2+1)
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP
+// date, capped at MaxBackoff.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return min(time.Duration(seconds)*time.Second, MaxBackoff), true
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		return min(max(time.Until(at), 0), MaxBackoff), true
+	}
+
+	return 0, false
+}
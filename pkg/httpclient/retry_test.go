@@ -0,0 +1,48 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTransportRetriesOnlySafeMethods(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		method string
+		ctx    func(context.Context) context.Context
+		want   int
+	}{
+		{"GET", http.MethodGet, func(ctx context.Context) context.Context { return ctx }, 3},
+		{"POST", http.MethodPost, func(ctx context.Context) context.Context { return ctx }, 1},
+		{"idempotent POST", http.MethodPost, WithIdempotent, 3},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			t.Cleanup(server.Close)
+
+			ctx := test.ctx(WithPolicy(context.Background(), Policy{Retries: 2}))
+			req, err := http.NewRequestWithContext(ctx, test.method, server.URL, strings.NewReader("{}"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := &http.Client{Transport: &Transport{Base: http.DefaultTransport}}
+			response, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			response.Body.Close()
+
+			if attempts != test.want {
+				t.Errorf("sent %d attempts, want %d", attempts, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package mistral
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultBaseURL is the Mistral console API base URL, which serves billing.
+const DefaultBaseURL = "https://console.mistral.ai/api"
+
+// ModelUsage contains the tokens consumed by one model this month.
+type ModelUsage struct {
+	Model        string `json:"model"`
+	InputTokens  int64  `json:"inputTokens"`
+	OutputTokens int64  `json:"outputTokens"`
+}
+
+// Quota contains Mistral La Plateforme token consumption for the current
+// calendar month.
+//
+// Mistral does not expose a token limit through its API, so the limit comes
+// from AIQUOTA_MISTRAL_TOKEN_LIMIT and the used percent is only known when
+// it is set.
+type Quota struct {
+	InputTokens  int64        `json:"inputTokens"`
+	OutputTokens int64        `json:"outputTokens"`
+	TotalTokens  int64        `json:"totalTokens"`
+	TokenLimit   *int64       `json:"tokenLimit"`
+	UsedPercent  *float64     `json:"usedPercent"`
+	Models       []ModelUsage `json:"models"`
+	PeriodStart  string       `json:"periodStart"`
+	ResetAt      string       `json:"resetAt"`
+	ResetIn      string       `json:"resetIn"`
+}
+
+// GetQuota fetches Mistral monthly token usage from the billing endpoint.
+func GetQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (Quota, error) {
+	if creds.MistralAPIKey == nil || *creds.MistralAPIKey == "" {
+		return Quota{}, fmt.Errorf("missing Mistral API key in credentials")
+	}
+
+	now := time.Now().UTC()
+	periodStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	url := fmt.Sprintf("%s/billing/v2/usage?month=%d&year=%d", baseURL, int(now.Month()), now.Year())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to create Mistral request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+*creds.MistralAPIKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to fetch Mistral quota: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to read Mistral response: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return Quota{}, fmt.Errorf("failed to fetch Mistral quota. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	resetAt := periodStart.AddDate(0, 1, 0).Format(time.RFC3339)
+	result := Quota{
+		Models:      parseModels(gjson.GetBytes(body, "completion.models")),
+		PeriodStart: periodStart.Format(time.RFC3339),
+		ResetAt:     resetAt,
+		ResetIn:     helpers.FormatTimeUntil(resetAt),
+	}
+
+	for _, model := range result.Models {
+		result.InputTokens += model.InputTokens
+		result.OutputTokens += model.OutputTokens
+	}
+	result.TotalTokens = result.InputTokens + result.OutputTokens
+
+	if creds.MistralTokenLimit != nil && *creds.MistralTokenLimit > 0 {
+		limit := *creds.MistralTokenLimit
+		usedPercent := helpers.ClampPercent(float64(result.TotalTokens) / float64(limit) * 100)
+		result.TokenLimit = &limit
+		result.UsedPercent = &usedPercent
+	}
+
+	return result, nil
+}
+
+// parseModels reads the per-model usage, keyed like
+// "mistral-large-latest::mistral-large-2411", and sorts it by model name.
+func parseModels(models gjson.Result) []ModelUsage {
+	var out []ModelUsage
+	models.ForEach(func(key gjson.Result, value gjson.Result) bool {
+		name, _, _ := strings.Cut(key.String(), "::")
+		out = append(out, ModelUsage{
+			Model:        name,
+			InputTokens:  sumTokens(value.Get("input")),
+			OutputTokens: sumTokens(value.Get("output")),
+		})
+		return true
+	})
+
+	slices.SortFunc(out, func(a, b ModelUsage) int { return strings.Compare(a.Model, b.Model) })
+	return out
+}
+
+func sumTokens(entries gjson.Result) int64 {
+	var total int64
+	for _, entry := range entries.Array() {
+		total += entry.Get("value").Int()
+	}
+
+	return total
+}
@@ -0,0 +1,84 @@
+package mistral
+
+import (
+	"cmp"
+	"context"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// Provider exposes Mistral through the common provider interface.
+type Provider struct {
+	// BaseURL overrides DefaultBaseURL, for example to go through a proxy.
+	BaseURL string
+	// HTTPClient sends the requests. Nil means httpclient.Default.
+	HTTPClient httpclient.Doer
+}
+
+// ID implements provider.Provider.
+func (Provider) ID() string { return "mistral" }
+
+// Name implements provider.Provider.
+func (Provider) Name() string { return "Mistral" }
+
+// Enabled implements provider.Provider.
+func (Provider) Enabled(creds credentials.Credentials) bool {
+	return credentials.HasValue(creds.MistralAPIKey)
+}
+
+// WithBaseURL implements provider.BaseURLSetter.
+func (p Provider) WithBaseURL(baseURL string) provider.Provider {
+	p.BaseURL = baseURL
+	return p
+}
+
+// Endpoint implements provider.Endpointer.
+func (p Provider) Endpoint() string {
+	return cmp.Or(p.BaseURL, DefaultBaseURL)
+}
+
+// Fetch implements provider.Provider.
+func (p Provider) Fetch(ctx context.Context, creds credentials.Credentials) (provider.Quota, error) {
+	quota, err := GetQuota(ctx, httpclient.OrDefault(p.HTTPClient), creds, cmp.Or(p.BaseURL, DefaultBaseURL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+// TokenUsage implements provider.TokenReporter.
+func (q Quota) TokenUsage() ([]provider.TokenUsage, time.Time, time.Time) {
+	usage := make([]provider.TokenUsage, 0, len(q.Models))
+	for _, model := range q.Models {
+		usage = append(usage, provider.TokenUsage{
+			Model:        model.Model,
+			InputTokens:  model.InputTokens,
+			OutputTokens: model.OutputTokens,
+		})
+	}
+
+	start, _ := time.Parse(time.RFC3339, q.PeriodStart)
+	end, _ := time.Parse(time.RFC3339, q.ResetAt)
+	return usage, start, end
+}
+
+// Windows implements provider.Quota.
+func (q Quota) Windows() []provider.Window {
+	window := provider.Window{
+		ID:          "tokens",
+		Name:        "Monthly Tokens",
+		UsedPercent: q.UsedPercent,
+		Used:        new(float64(q.TotalTokens)),
+		ResetAt:     q.ResetAt,
+	}
+
+	if q.TokenLimit != nil {
+		window.Limit = new(float64(*q.TokenLimit))
+	}
+
+	return []provider.Window{window}
+}
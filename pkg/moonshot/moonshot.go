@@ -0,0 +1,80 @@
+package moonshot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// DefaultBaseURL is the international Moonshot API base URL, billed in
+	// US dollars.
+	DefaultBaseURL = "https://api.moonshot.ai/v1"
+
+	// ChinaBaseURL is the mainland China Moonshot API base URL, billed in
+	// yuan.
+	ChinaBaseURL = "https://api.moonshot.cn/v1"
+)
+
+// Quota contains the Moonshot (Kimi) account balance.
+//
+// Moonshot is pay as you go, so the available balance stands in for a
+// quota and never resets. It is the sum of the voucher balance, spent
+// first, and the cash balance, which goes negative when the account is
+// overdrawn.
+type Quota struct {
+	Currency         string  `json:"currency"`
+	AvailableBalance float64 `json:"availableBalance"`
+	VoucherBalance   float64 `json:"voucherBalance"`
+	CashBalance      float64 `json:"cashBalance"`
+}
+
+// GetQuota fetches the Moonshot account balance.
+func GetQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (Quota, error) {
+	if creds.MoonshotAPIKey == nil || *creds.MoonshotAPIKey == "" {
+		return Quota{}, fmt.Errorf("missing Moonshot API key in credentials")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/users/me/balance", nil)
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to create Moonshot request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+*creds.MoonshotAPIKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to fetch Moonshot quota: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to read Moonshot response: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 || !gjson.GetBytes(body, "status").Bool() {
+		return Quota{}, fmt.Errorf("failed to fetch Moonshot quota. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	currency := "USD"
+	if strings.Contains(baseURL, "moonshot.cn") {
+		currency = "CNY"
+	}
+
+	data := gjson.GetBytes(body, "data")
+	return Quota{
+		Currency:         currency,
+		AvailableBalance: data.Get("available_balance").Float(),
+		VoucherBalance:   data.Get("voucher_balance").Float(),
+		CashBalance:      data.Get("cash_balance").Float(),
+	}, nil
+}
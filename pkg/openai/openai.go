@@ -0,0 +1,174 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultBaseURL is the OpenAI API base URL.
+const DefaultBaseURL = "https://api.openai.com/v1"
+
+// maxReportPages bounds how many pages of a usage or cost report are read.
+// One page of daily buckets already covers a whole month.
+const maxReportPages = 5
+
+// ModelUsage contains the tokens consumed by one model this month.
+type ModelUsage struct {
+	Model             string `json:"model"`
+	InputTokens       int64  `json:"inputTokens"`
+	CachedInputTokens int64  `json:"cachedInputTokens"`
+	OutputTokens      int64  `json:"outputTokens"`
+}
+
+// Quota contains the OpenAI platform spend of an organization for the
+// current calendar month.
+//
+// The API does not expose the organization budget, so it comes from
+// AIQUOTA_OPENAI_BUDGET and the used percent is only known when it is set.
+type Quota struct {
+	Organization      string       `json:"organization,omitempty"`
+	CostUSD           float64      `json:"costUsd"`
+	BudgetUSD         *float64     `json:"budgetUsd"`
+	UsedPercent       *float64     `json:"usedPercent"`
+	InputTokens       int64        `json:"inputTokens"`
+	CachedInputTokens int64        `json:"cachedInputTokens"`
+	OutputTokens      int64        `json:"outputTokens"`
+	Models            []ModelUsage `json:"models"`
+	PeriodStart       string       `json:"periodStart"`
+	ResetAt           string       `json:"resetAt"`
+	ResetIn           string       `json:"resetIn"`
+}
+
+// GetQuota fetches this month's cost and completion usage from the
+// organization costs and usage endpoints. They need an admin key.
+func GetQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (Quota, error) {
+	if !credentials.HasValue(creds.OpenAIAdminKey) {
+		return Quota{}, fmt.Errorf("missing OpenAI admin key in credentials")
+	}
+
+	now := time.Now().UTC()
+	periodStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	query := url.Values{
+		"start_time":   {strconv.FormatInt(periodStart.Unix(), 10)},
+		"bucket_width": {"1d"},
+		"limit":        {"31"},
+	}
+
+	resetAt := periodStart.AddDate(0, 1, 0).Format(time.RFC3339)
+	result := Quota{
+		Models:      []ModelUsage{},
+		BudgetUSD:   creds.OpenAIBudget,
+		PeriodStart: periodStart.Format(time.RFC3339),
+		ResetAt:     resetAt,
+		ResetIn:     helpers.FormatTimeUntil(resetAt),
+	}
+	if creds.OpenAIOrganization != nil {
+		result.Organization = *creds.OpenAIOrganization
+	}
+
+	err := getReport(ctx, client, creds, baseURL+"/organization/costs", query, func(item gjson.Result) {
+		result.CostUSD += item.Get("amount.value").Float()
+	})
+	if err != nil {
+		return Quota{}, err
+	}
+
+	usageQuery := maps.Clone(query)
+	usageQuery.Set("group_by", "model")
+
+	models := map[string]*ModelUsage{}
+	err = getReport(ctx, client, creds, baseURL+"/organization/usage/completions", usageQuery, func(item gjson.Result) {
+		name := item.Get("model").String()
+		if name == "" {
+			name = "other"
+		}
+
+		model, ok := models[name]
+		if !ok {
+			model = &ModelUsage{Model: name}
+			models[name] = model
+		}
+
+		model.InputTokens += item.Get("input_tokens").Int()
+		model.CachedInputTokens += item.Get("input_cached_tokens").Int()
+		model.OutputTokens += item.Get("output_tokens").Int()
+	})
+	if err != nil {
+		return Quota{}, err
+	}
+
+	for _, model := range models {
+		result.Models = append(result.Models, *model)
+		result.InputTokens += model.InputTokens
+		result.CachedInputTokens += model.CachedInputTokens
+		result.OutputTokens += model.OutputTokens
+	}
+	slices.SortFunc(result.Models, func(a, b ModelUsage) int { return strings.Compare(a.Model, b.Model) })
+
+	if result.BudgetUSD != nil && *result.BudgetUSD > 0 {
+		result.UsedPercent = new(helpers.ClampPercent(result.CostUSD / *result.BudgetUSD * 100))
+	}
+
+	return result, nil
+}
+
+// getReport walks the pages of an organization report and calls fn for
+// every result of every time bucket.
+func getReport(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, endpoint string, query url.Values, fn func(gjson.Result)) error {
+	query = maps.Clone(query)
+	for range maxReportPages {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+query.Encode(), nil)
+		if err != nil {
+			return fmt.Errorf("failed to create OpenAI request: %w", err)
+		}
+
+		req.Header.Set("Authorization", "Bearer "+*creds.OpenAIAdminKey)
+		if credentials.HasValue(creds.OpenAIOrganization) {
+			req.Header.Set("OpenAI-Organization", *creds.OpenAIOrganization)
+		}
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+		response, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to fetch OpenAI usage: %w", err)
+		}
+
+		body, err := io.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read OpenAI response: %w", err)
+		}
+
+		if response.StatusCode < 200 || response.StatusCode >= 300 {
+			return fmt.Errorf("failed to fetch OpenAI usage. Status: %d, Response: %s", response.StatusCode, string(body))
+		}
+
+		for _, bucket := range gjson.GetBytes(body, "data").Array() {
+			for _, item := range bucket.Get("results").Array() {
+				fn(item)
+			}
+		}
+
+		next := gjson.GetBytes(body, "next_page").String()
+		if !gjson.GetBytes(body, "has_more").Bool() || next == "" {
+			return nil
+		}
+		query.Set("page", next)
+	}
+
+	return nil
+}
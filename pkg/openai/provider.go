@@ -0,0 +1,84 @@
+package openai
+
+import (
+	"cmp"
+	"context"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// Provider exposes the OpenAI platform billing through the common provider
+// interface. It is separate from the Codex provider, which reports the
+// ChatGPT subscription limits.
+type Provider struct {
+	// BaseURL overrides DefaultBaseURL, for example to go through a proxy.
+	BaseURL string
+	// HTTPClient sends the requests. Nil means httpclient.Default.
+	HTTPClient httpclient.Doer
+}
+
+// ID implements provider.Provider.
+func (Provider) ID() string { return "openai" }
+
+// Name implements provider.Provider.
+func (Provider) Name() string { return "OpenAI API" }
+
+// Enabled implements provider.Provider.
+func (Provider) Enabled(creds credentials.Credentials) bool {
+	return credentials.HasValue(creds.OpenAIAdminKey)
+}
+
+// WithBaseURL implements provider.BaseURLSetter.
+func (p Provider) WithBaseURL(baseURL string) provider.Provider {
+	p.BaseURL = baseURL
+	return p
+}
+
+// Endpoint implements provider.Endpointer.
+func (p Provider) Endpoint() string {
+	return cmp.Or(p.BaseURL, DefaultBaseURL)
+}
+
+// Fetch implements provider.Provider.
+func (p Provider) Fetch(ctx context.Context, creds credentials.Credentials) (provider.Quota, error) {
+	quota, err := GetQuota(ctx, httpclient.OrDefault(p.HTTPClient), creds, cmp.Or(p.BaseURL, DefaultBaseURL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+// TokenUsage implements provider.TokenReporter.
+func (q Quota) TokenUsage() ([]provider.TokenUsage, time.Time, time.Time) {
+	usage := make([]provider.TokenUsage, 0, len(q.Models))
+	for _, model := range q.Models {
+		usage = append(usage, provider.TokenUsage{
+			Model:             model.Model,
+			InputTokens:       model.InputTokens,
+			CachedInputTokens: model.CachedInputTokens,
+			OutputTokens:      model.OutputTokens,
+		})
+	}
+
+	start, _ := time.Parse(time.RFC3339, q.PeriodStart)
+	end, _ := time.Parse(time.RFC3339, q.ResetAt)
+	return usage, start, end
+}
+
+// Windows implements provider.Quota.
+func (q Quota) Windows() []provider.Window {
+	return []provider.Window{
+		{
+			ID:          "cost",
+			Name:        "Monthly Cost (USD)",
+			UsedPercent: q.UsedPercent,
+			Used:        new(q.CostUSD),
+			Limit:       q.BudgetUSD,
+			ResetAt:     q.ResetAt,
+		},
+	}
+}
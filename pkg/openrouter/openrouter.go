@@ -0,0 +1,112 @@
+package openrouter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultBaseURL is the OpenRouter API base URL.
+const DefaultBaseURL = "https://openrouter.ai/api/v1"
+
+// Quota contains OpenRouter credit balance and API key limits.
+type Quota struct {
+	AccountLabel      string   `json:"accountLabel"`
+	AccountType       string   `json:"accountType"`
+	TotalCredits      float64  `json:"totalCredits"`
+	TotalUsage        float64  `json:"totalUsage"`
+	RemainingCredits  float64  `json:"remainingCredits"`
+	UsedPercent       float64  `json:"usedPercent"`
+	KeyLimit          *float64 `json:"keyLimit"`
+	KeyUsage          float64  `json:"keyUsage"`
+	KeyUsedPercent    *float64 `json:"keyUsedPercent"`
+	RateLimitRequests int64    `json:"rateLimitRequests"`
+	RateLimitInterval string   `json:"rateLimitInterval"`
+}
+
+// GetQuota fetches OpenRouter credits and API key information.
+func GetQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (Quota, error) {
+	if creds.OpenRouterAPIKey == nil || *creds.OpenRouterAPIKey == "" {
+		return Quota{}, fmt.Errorf("missing OpenRouter API key in credentials")
+	}
+
+	credits, err := get(ctx, client, *creds.OpenRouterAPIKey, baseURL+"/credits")
+	if err != nil {
+		return Quota{}, err
+	}
+
+	key, err := get(ctx, client, *creds.OpenRouterAPIKey, baseURL+"/key")
+	if err != nil {
+		return Quota{}, err
+	}
+
+	totalCredits := gjson.GetBytes(credits, "data.total_credits").Float()
+	totalUsage := gjson.GetBytes(credits, "data.total_usage").Float()
+
+	usedPercent := 0.0
+	if totalCredits > 0 {
+		usedPercent = helpers.ClampPercent(totalUsage / totalCredits * 100)
+	}
+
+	accountType := "paid"
+	if gjson.GetBytes(key, "data.is_free_tier").Bool() {
+		accountType = "free"
+	}
+
+	result := Quota{
+		AccountLabel:      gjson.GetBytes(key, "data.label").String(),
+		AccountType:       accountType,
+		TotalCredits:      totalCredits,
+		TotalUsage:        totalUsage,
+		RemainingCredits:  max(0, totalCredits-totalUsage),
+		UsedPercent:       usedPercent,
+		KeyUsage:          gjson.GetBytes(key, "data.usage").Float(),
+		RateLimitRequests: gjson.GetBytes(key, "data.rate_limit.requests").Int(),
+		RateLimitInterval: gjson.GetBytes(key, "data.rate_limit.interval").String(),
+	}
+
+	if limit := gjson.GetBytes(key, "data.limit"); limit.Exists() && limit.Type != gjson.Null {
+		value := limit.Float()
+		result.KeyLimit = &value
+		if value > 0 {
+			keyUsedPercent := helpers.ClampPercent(result.KeyUsage / value * 100)
+			result.KeyUsedPercent = &keyUsedPercent
+		}
+	}
+
+	return result, nil
+}
+
+func get(ctx context.Context, client httpclient.Doer, apiKey string, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OpenRouter request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OpenRouter quota: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenRouter response: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to fetch OpenRouter quota. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	return body, nil
+}
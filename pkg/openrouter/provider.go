@@ -0,0 +1,78 @@
+package openrouter
+
+import (
+	"cmp"
+	"context"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// Provider exposes OpenRouter through the common provider interface.
+type Provider struct {
+	// BaseURL overrides DefaultBaseURL, for example to go through a proxy.
+	BaseURL string
+	// HTTPClient sends the requests. Nil means httpclient.Default.
+	HTTPClient httpclient.Doer
+}
+
+// ID implements provider.Provider.
+func (Provider) ID() string { return "openrouter" }
+
+// Name implements provider.Provider.
+func (Provider) Name() string { return "OpenRouter" }
+
+// Enabled implements provider.Provider.
+func (Provider) Enabled(creds credentials.Credentials) bool {
+	return credentials.HasValue(creds.OpenRouterAPIKey)
+}
+
+// WithBaseURL implements provider.BaseURLSetter.
+func (p Provider) WithBaseURL(baseURL string) provider.Provider {
+	p.BaseURL = baseURL
+	return p
+}
+
+// Endpoint implements provider.Endpointer.
+func (p Provider) Endpoint() string {
+	return cmp.Or(p.BaseURL, DefaultBaseURL)
+}
+
+// Fetch implements provider.Provider.
+func (p Provider) Fetch(ctx context.Context, creds credentials.Credentials) (provider.Quota, error) {
+	quota, err := GetQuota(ctx, httpclient.OrDefault(p.HTTPClient), creds, cmp.Or(p.BaseURL, DefaultBaseURL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+// Windows implements provider.Quota. Credits never reset, so the windows
+// carry no reset time.
+func (q Quota) Windows() []provider.Window {
+	windows := []provider.Window{
+		{
+			ID:          "credits",
+			Name:        "Credits",
+			UsedPercent: new(q.UsedPercent),
+			Used:        new(q.TotalUsage),
+			Limit:       new(q.TotalCredits),
+			ResetAt:     "unknown",
+		},
+	}
+
+	if q.KeyLimit != nil {
+		windows = append(windows, provider.Window{
+			ID:          "key_limit",
+			Name:        "API Key Limit",
+			UsedPercent: q.KeyUsedPercent,
+			Used:        new(q.KeyUsage),
+			Limit:       q.KeyLimit,
+			ResetAt:     "unknown",
+		})
+	}
+
+	return windows
+}
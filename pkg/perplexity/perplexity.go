@@ -0,0 +1,185 @@
+package perplexity
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultBaseURL is the Perplexity API base URL.
+const DefaultBaseURL = "https://api.perplexity.ai"
+
+// DefaultModels are probed when AIQUOTA_PERPLEXITY_MODELS is not set.
+var DefaultModels = []string{"sonar"}
+
+// Limit is one rate limit of a model as reported by Perplexity.
+type Limit struct {
+	Limit       int64   `json:"limit"`
+	Remaining   int64   `json:"remaining"`
+	UsedPercent float64 `json:"usedPercent"`
+	ResetAt     string  `json:"resetAt"`
+	ResetIn     string  `json:"resetIn"`
+}
+
+// ModelLimits holds the rate limits of the account tier for one model.
+type ModelLimits struct {
+	Model             string `json:"model"`
+	RequestsPerMinute Limit  `json:"requestsPerMinute"`
+	TokensPerMinute   Limit  `json:"tokensPerMinute"`
+}
+
+// Quota contains the remaining Perplexity API credits and the tier rate
+// limits of the probed sonar models.
+//
+// Rate limits depend on the usage tier and are only reported in the
+// x-ratelimit-* headers of completions, so each model is probed with a
+// one-token request, which bills the per-request fee. CreditsUSD is nil when
+// the account does not report a balance.
+type Quota struct {
+	CreditsUSD *float64      `json:"creditsUsd"`
+	Models     []ModelLimits `json:"models"`
+}
+
+// GetQuota fetches the remaining credits and the rate limits of every model
+// in creds.PerplexityModels, or DefaultModels.
+func GetQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (Quota, error) {
+	if creds.PerplexityAPIKey == nil || *creds.PerplexityAPIKey == "" {
+		return Quota{}, fmt.Errorf("missing Perplexity API key in credentials")
+	}
+
+	apiKey := *creds.PerplexityAPIKey
+	credits, err := getCredits(ctx, client, apiKey, baseURL)
+	if err != nil {
+		return Quota{}, err
+	}
+
+	models := creds.PerplexityModels
+	if len(models) == 0 {
+		models = DefaultModels
+	}
+
+	result := Quota{CreditsUSD: credits, Models: make([]ModelLimits, 0, len(models))}
+	for _, model := range models {
+		limits, err := probe(ctx, client, apiKey, baseURL, model)
+		if err != nil {
+			return Quota{}, err
+		}
+
+		result.Models = append(result.Models, limits)
+	}
+
+	return result, nil
+}
+
+// getCredits reads the remaining prepaid credits. Accounts without a
+// credit balance answer 404, which is not an error.
+func getCredits(ctx context.Context, client httpclient.Doer, apiKey string, baseURL string) (*float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/credits", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Perplexity request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Perplexity quota: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Perplexity response: %w", err)
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to fetch Perplexity quota. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	balance := gjson.GetBytes(body, "balance")
+	if !balance.Exists() {
+		return nil, nil
+	}
+
+	return new(balance.Float()), nil
+}
+
+// probe sends a minimal completion for model and parses the rate limit
+// headers of the response. A 429 still carries the headers and means the
+// limit is spent, so it is not an error.
+func probe(ctx context.Context, client httpclient.Doer, apiKey string, baseURL string, model string) (ModelLimits, error) {
+	payload, err := json.Marshal(map[string]any{
+		"model":      model,
+		"messages":   []map[string]string{{"role": "user", "content": "."}},
+		"max_tokens": 1,
+	})
+	if err != nil {
+		return ModelLimits{}, fmt.Errorf("failed to encode Perplexity request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return ModelLimits{}, fmt.Errorf("failed to create Perplexity request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return ModelLimits{}, fmt.Errorf("failed to fetch Perplexity quota: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return ModelLimits{}, fmt.Errorf("failed to read Perplexity response: %w", err)
+	}
+
+	ok := response.StatusCode >= 200 && response.StatusCode < 300
+	if !ok && response.StatusCode != http.StatusTooManyRequests {
+		return ModelLimits{}, fmt.Errorf("failed to fetch Perplexity quota for %s. Status: %d, Response: %s", model, response.StatusCode, string(body))
+	}
+
+	now := time.Now()
+	return ModelLimits{
+		Model:             model,
+		RequestsPerMinute: parseLimit(response.Header, "requests", now),
+		TokensPerMinute:   parseLimit(response.Header, "tokens", now),
+	}, nil
+}
+
+func parseLimit(header http.Header, kind string, now time.Time) Limit {
+	limit, _ := strconv.ParseInt(header.Get("x-ratelimit-limit-"+kind), 10, 64)
+	remaining, _ := strconv.ParseInt(header.Get("x-ratelimit-remaining-"+kind), 10, 64)
+
+	result := Limit{Limit: limit, Remaining: remaining, ResetAt: "unknown", ResetIn: "unknown"}
+	if limit > 0 {
+		result.UsedPercent = helpers.ClampPercent(float64(limit-remaining) / float64(limit) * 100)
+	}
+
+	// Resets are relative durations such as "1s" when present.
+	if reset, err := time.ParseDuration(header.Get("x-ratelimit-reset-" + kind)); err == nil {
+		result.ResetAt = now.Add(reset).UTC().Format(time.RFC3339)
+		result.ResetIn = helpers.FormatTimeUntil(result.ResetAt)
+	}
+
+	return result
+}
@@ -0,0 +1,201 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+)
+
+// Provider is a source of quota information.
+type Provider interface {
+	// ID returns the stable machine identifier, e.g. "copilot".
+	ID() string
+	// Name returns the human-readable provider name.
+	Name() string
+	// Enabled reports whether the credentials contain what the provider needs.
+	Enabled(creds credentials.Credentials) bool
+	// Fetch queries the provider for its current quota.
+	Fetch(ctx context.Context, creds credentials.Credentials) (Quota, error)
+}
+
+// BaseURLSetter is implemented by providers whose API base URL can be
+// overridden, for example to route requests through a corporate gateway.
+type BaseURLSetter interface {
+	// WithBaseURL returns a copy of the provider that talks to baseURL.
+	WithBaseURL(baseURL string) Provider
+}
+
+// Endpointer is implemented by providers that can report which API they
+// talk to, for diagnostics.
+type Endpointer interface {
+	// Endpoint returns the API base URL in use, after any override.
+	Endpoint() string
+}
+
+// Quota is implemented by every provider quota type.
+type Quota interface {
+	// Windows returns the provider usage windows in a common shape.
+	Windows() []Window
+}
+
+// TokenUsage is what one model consumed in a billing period.
+type TokenUsage struct {
+	Model             string
+	InputTokens       int64
+	CachedInputTokens int64
+	OutputTokens      int64
+	// Requests counts the calls of tools billed per use, such as web search.
+	Requests int64
+}
+
+// TokenReporter is implemented by quotas that report token consumption, which
+// the report prices into an estimated cost.
+type TokenReporter interface {
+	// TokenUsage returns the consumption per model and the billing period it
+	// covers. A zero periodStart means the period is unknown.
+	TokenUsage() (usage []TokenUsage, periodStart time.Time, periodEnd time.Time)
+}
+
+// Window is a provider-agnostic view of a single usage window. Remaining is
+// set for prepaid balances, which have no usage or limit.
+type Window struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	UsedPercent *float64 `json:"usedPercent"`
+	Used        *float64 `json:"used"`
+	Limit       *float64 `json:"limit"`
+	Remaining   *float64 `json:"remaining,omitempty"`
+	ResetAt     string   `json:"resetAt"`
+}
+
+// DefaultTimeout is the per-provider fetch timeout used when none is set.
+const DefaultTimeout = 10 * time.Second
+
+// ErrTimeout is reported for providers that did not answer in time.
+var ErrTimeout = errors.New("timed out")
+
+// ErrInterrupted is reported for providers still in flight when the fetch
+// was canceled, for example by Ctrl-C.
+var ErrInterrupted = errors.New("interrupted")
+
+// FetchOptions controls how providers are fetched.
+type FetchOptions struct {
+	// Timeout bounds each provider fetch. Zero means DefaultTimeout.
+	Timeout time.Duration
+	// Timeouts overrides Timeout for specific provider IDs.
+	Timeouts map[string]time.Duration
+	// Retry controls how provider requests are retried. Nil means
+	// httpclient.DefaultPolicy.
+	Retry *httpclient.Policy
+	// Dump, when set, receives every provider response.
+	Dump *httpclient.Dump
+	// Replay, when set, answers provider requests instead of the network.
+	Replay *httpclient.Replay
+	// Validators, when set, makes provider requests conditional on the
+	// responses they stored.
+	Validators *httpclient.Validators
+	// OnResult, when set, is called as each provider finishes with its
+	// result and how long it took. Calls come from concurrent goroutines.
+	OnResult func(result Result, elapsed time.Duration)
+}
+
+// timeoutFor returns the fetch timeout that applies to a provider.
+func (o FetchOptions) timeoutFor(id string) time.Duration {
+	if timeout, ok := o.Timeouts[id]; ok && timeout > 0 {
+		return timeout
+	}
+
+	if o.Timeout > 0 {
+		return o.Timeout
+	}
+
+	return DefaultTimeout
+}
+
+// Result is the outcome of fetching a single provider.
+type Result struct {
+	Provider Provider
+	Quota    Quota
+	Err      error
+}
+
+// Enabled returns the providers that can run with the given credentials.
+func Enabled(providers []Provider, creds credentials.Credentials) []Provider {
+	enabled := make([]Provider, 0, len(providers))
+	for _, p := range providers {
+		if p.Enabled(creds) {
+			enabled = append(enabled, p)
+		}
+	}
+
+	return enabled
+}
+
+// FetchAll fetches all providers concurrently and returns their results in
+// the same order as the input. Providers that exceed their timeout, or the
+// deadline of ctx, report an error wrapping ErrTimeout.
+func FetchAll(ctx context.Context, creds credentials.Credentials, providers []Provider, opts FetchOptions) []Result {
+	results := make([]Result, len(providers))
+	if opts.Retry != nil {
+		ctx = httpclient.WithPolicy(ctx, *opts.Retry)
+	}
+	if opts.Dump != nil {
+		ctx = httpclient.WithDump(ctx, opts.Dump)
+	}
+	if opts.Replay != nil {
+		ctx = httpclient.WithReplay(ctx, opts.Replay)
+	}
+	if opts.Validators != nil {
+		ctx = httpclient.WithValidators(ctx, opts.Validators)
+	}
+
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		wg.Go(func() {
+			start := time.Now()
+			results[i] = fetch(ctx, creds, p, opts.timeoutFor(p.ID()))
+			if opts.OnResult != nil {
+				opts.OnResult(results[i], time.Since(start))
+			}
+		})
+	}
+	wg.Wait()
+
+	return results
+}
+
+func fetch(ctx context.Context, creds credentials.Credentials, p Provider, timeout time.Duration) Result {
+	fetchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	fetchCtx = httpclient.WithProvider(fetchCtx, p.ID())
+
+	start := time.Now()
+	quota, err := p.Fetch(fetchCtx, creds)
+	if err != nil && errors.Is(fetchCtx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("%w after %s", ErrTimeout, timeout)
+		if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+			err = fmt.Errorf("%w: overall deadline exceeded", ErrTimeout)
+		}
+	}
+	if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+		err = ErrInterrupted
+	}
+	// Error messages often echo response bodies, which can carry keys or
+	// account IDs.
+	err = httpclient.RedactError(err)
+
+	if err != nil {
+		slog.InfoContext(fetchCtx, "provider failed", "provider", p.ID(), "duration", time.Since(start), "error", err)
+	} else {
+		slog.InfoContext(fetchCtx, "provider fetched", "provider", p.ID(), "duration", time.Since(start))
+	}
+
+	return Result{Provider: p, Quota: quota, Err: err}
+}
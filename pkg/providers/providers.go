@@ -0,0 +1,66 @@
+package providers
+
+import (
+	"github.com/eduardolat/aiquota/pkg/anthropic"
+	"github.com/eduardolat/aiquota/pkg/azure"
+	"github.com/eduardolat/aiquota/pkg/bedrock"
+	"github.com/eduardolat/aiquota/pkg/codex"
+	"github.com/eduardolat/aiquota/pkg/cohere"
+	"github.com/eduardolat/aiquota/pkg/copilot"
+	"github.com/eduardolat/aiquota/pkg/cursor"
+	"github.com/eduardolat/aiquota/pkg/deepseek"
+	"github.com/eduardolat/aiquota/pkg/fireworks"
+	"github.com/eduardolat/aiquota/pkg/gemini"
+	"github.com/eduardolat/aiquota/pkg/githubmodels"
+	"github.com/eduardolat/aiquota/pkg/groq"
+	"github.com/eduardolat/aiquota/pkg/mistral"
+	"github.com/eduardolat/aiquota/pkg/moonshot"
+	"github.com/eduardolat/aiquota/pkg/openai"
+	"github.com/eduardolat/aiquota/pkg/openrouter"
+	"github.com/eduardolat/aiquota/pkg/perplexity"
+	"github.com/eduardolat/aiquota/pkg/provider"
+	"github.com/eduardolat/aiquota/pkg/replicate"
+	"github.com/eduardolat/aiquota/pkg/together"
+	"github.com/eduardolat/aiquota/pkg/xai"
+	"github.com/eduardolat/aiquota/pkg/zai"
+)
+
+// All returns every known provider in report order.
+func All() []provider.Provider {
+	return []provider.Provider{
+		copilot.Provider{},
+		copilot.OrgProvider{},
+		zai.Provider{},
+		codex.Provider{},
+		replicate.Provider{},
+		anthropic.Provider{},
+		anthropic.AdminProvider{},
+		gemini.Provider{},
+		openrouter.Provider{},
+		cursor.Provider{},
+		mistral.Provider{},
+		deepseek.Provider{},
+		groq.Provider{},
+		xai.Provider{},
+		together.Provider{},
+		fireworks.Provider{},
+		cohere.Provider{},
+		perplexity.Provider{},
+		moonshot.Provider{},
+		openai.Provider{},
+		azure.Provider{},
+		bedrock.Provider{},
+		githubmodels.Provider{},
+	}
+}
+
+// Get returns the provider with the given ID.
+func Get(id string) (provider.Provider, bool) {
+	for _, p := range All() {
+		if p.ID() == id {
+			return p, true
+		}
+	}
+
+	return nil, false
+}
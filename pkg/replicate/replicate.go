@@ -0,0 +1,135 @@
+package replicate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// DefaultBaseURL is the Replicate API base URL.
+	DefaultBaseURL = "https://api.replicate.com/v1"
+
+	// maxPredictionPages bounds how many prediction pages are walked per run.
+	maxPredictionPages = 20
+)
+
+// Quota contains Replicate account and usage information.
+//
+// Replicate bills by usage and exposes no spend limit through its API, so
+// usage is reported as the number of predictions created in the current
+// calendar month instead of a percentage.
+type Quota struct {
+	AccountUser        string `json:"accountUser"`
+	AccountType        string `json:"accountType"`
+	Predictions        int64  `json:"predictions"`
+	PredictionsPartial bool   `json:"predictionsPartial"`
+	PeriodStart        string `json:"periodStart"`
+	ResetAt            string `json:"resetAt"`
+	ResetIn            string `json:"resetIn"`
+}
+
+// GetQuota fetches Replicate account and prediction usage information.
+func GetQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (Quota, error) {
+	if creds.ReplicateAPIKey == nil || *creds.ReplicateAPIKey == "" {
+		return Quota{}, fmt.Errorf("missing Replicate API key in credentials")
+	}
+
+	account, err := get(ctx, client, *creds.ReplicateAPIKey, baseURL+"/account")
+	if err != nil {
+		return Quota{}, err
+	}
+
+	now := time.Now().UTC()
+	periodStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := periodStart.AddDate(0, 1, 0)
+
+	predictions, partial, err := countPredictionsSince(ctx, client, baseURL, *creds.ReplicateAPIKey, periodStart)
+	if err != nil {
+		return Quota{}, err
+	}
+
+	resetAt := periodEnd.Format(time.RFC3339)
+
+	return Quota{
+		AccountUser:        gjson.GetBytes(account, "username").String(),
+		AccountType:        gjson.GetBytes(account, "type").String(),
+		Predictions:        predictions,
+		PredictionsPartial: partial,
+		PeriodStart:        periodStart.Format(time.RFC3339),
+		ResetAt:            resetAt,
+		ResetIn:            helpers.FormatTimeUntil(resetAt),
+	}, nil
+}
+
+// countPredictionsSince walks the prediction list, which Replicate returns
+// newest first, until it reaches a prediction created before since. The
+// returned bool reports whether the page limit was hit before that point.
+func countPredictionsSince(ctx context.Context, client httpclient.Doer, baseURL string, apiKey string, since time.Time) (int64, bool, error) {
+	var count int64
+	url := baseURL + "/predictions"
+
+	for range maxPredictionPages {
+		body, err := get(ctx, client, apiKey, url)
+		if err != nil {
+			return 0, false, err
+		}
+
+		for _, prediction := range gjson.GetBytes(body, "results").Array() {
+			createdAt, err := time.Parse(time.RFC3339, prediction.Get("created_at").String())
+			if err != nil {
+				continue
+			}
+
+			if createdAt.Before(since) {
+				return count, false, nil
+			}
+
+			count++
+		}
+
+		next := gjson.GetBytes(body, "next")
+		if !next.Exists() || next.Type == gjson.Null || next.String() == "" {
+			return count, false, nil
+		}
+
+		url = next.String()
+	}
+
+	return count, true, nil
+}
+
+func get(ctx context.Context, client httpclient.Doer, apiKey string, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Replicate request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Replicate quota: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Replicate response: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to fetch Replicate quota. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	return body, nil
+}
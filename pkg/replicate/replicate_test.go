@@ -0,0 +1,131 @@
+package replicate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+)
+
+// fixtureServer serves the recorded responses in testdata, with {{base}}
+// replaced by the server URL so next links point back at it. Prediction
+// pages are served by cursor.
+func fixtureServer(t *testing.T, pages map[string]string) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer r8_test" {
+			t.Errorf("Authorization = %q, want the API key", got)
+		}
+
+		name := "account.json"
+		if r.URL.Path == "/predictions" {
+			name = pages[r.URL.Query().Get("cursor")]
+		}
+		if name == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		content, err := os.ReadFile("testdata/" + name)
+		if err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(strings.ReplaceAll(string(content), "{{base}}", server.URL)))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestCountPredictionsSinceStopsAtMonthStart(t *testing.T) {
+	server := fixtureServer(t, map[string]string{
+		"":                 "predictions_page1.json",
+		"cD0yMDI2LTEwLTA1": "predictions_page2.json",
+	})
+
+	since := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	count, partial, err := countPredictionsSince(context.Background(), server.Client(), server.URL, "r8_test", since)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Three predictions on the first page and one on the second are from
+	// October; the September one ends the walk before its next link.
+	if count != 4 || partial {
+		t.Errorf("got %d predictions, partial %v, want 4, false", count, partial)
+	}
+}
+
+func TestCountPredictionsSinceFollowsNext(t *testing.T) {
+	server := fixtureServer(t, map[string]string{
+		"":                 "predictions_page1.json",
+		"cD0yMDI2LTEwLTA1": "predictions_page2.json",
+	})
+
+	// With an earlier cutoff every prediction counts, and the walk follows
+	// the next link of the second page to a cursor the server lacks.
+	since := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+	_, _, err := countPredictionsSince(context.Background(), server.Client(), server.URL, "r8_test", since)
+	if err == nil || !strings.Contains(err.Error(), "Status: 404") {
+		t.Fatalf("err = %v, want the 404 of the third page", err)
+	}
+}
+
+func TestCountPredictionsSinceReportsPageLimit(t *testing.T) {
+	// Page 1 links to page 2, which links to page 1 again, so the walk only
+	// ends at the page limit.
+	server := fixtureServer(t, map[string]string{
+		"":                 "predictions_page1.json",
+		"cD0yMDI2LTEwLTA1": "predictions_page2.json",
+		"cD0yMDI2LTA5LTMw": "predictions_page1.json",
+	})
+
+	since := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+	count, partial, err := countPredictionsSince(context.Background(), server.Client(), server.URL, "r8_test", since)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Pages alternate between 3 and 2 predictions.
+	if want := int64(maxPredictionPages / 2 * 5); count != want || !partial {
+		t.Errorf("got %d predictions, partial %v, want %d, true", count, partial, want)
+	}
+}
+
+func TestGetQuotaParsesAccount(t *testing.T) {
+	// The count depends on the current month, so the pages loop to keep the
+	// walk from running out of fixtures whatever the date.
+	server := fixtureServer(t, map[string]string{
+		"":                 "predictions_page1.json",
+		"cD0yMDI2LTEwLTA1": "predictions_page2.json",
+		"cD0yMDI2LTA5LTMw": "predictions_page1.json",
+	})
+
+	quota, err := GetQuota(context.Background(), server.Client(), credentials.Credentials{ReplicateAPIKey: new("r8_test")}, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if quota.AccountUser != "octocat" || quota.AccountType != "user" {
+		t.Errorf("account = %q (%q), want octocat (user)", quota.AccountUser, quota.AccountType)
+	}
+
+	start, err := time.Parse(time.RFC3339, quota.PeriodStart)
+	if err != nil || start.Day() != 1 || start.Hour() != 0 {
+		t.Errorf("period start = %q, want the start of the month", quota.PeriodStart)
+	}
+	if reset, err := time.Parse(time.RFC3339, quota.ResetAt); err != nil || !reset.Equal(start.AddDate(0, 1, 0)) {
+		t.Errorf("reset = %q, want a month after %q", quota.ResetAt, quota.PeriodStart)
+	}
+}
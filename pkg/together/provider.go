@@ -0,0 +1,82 @@
+package together
+
+import (
+	"cmp"
+	"context"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// Provider exposes Together AI through the common provider interface.
+type Provider struct {
+	// BaseURL overrides DefaultBaseURL, for example to go through a proxy.
+	BaseURL string
+	// HTTPClient sends the requests. Nil means httpclient.Default.
+	HTTPClient httpclient.Doer
+}
+
+// ID implements provider.Provider.
+func (Provider) ID() string { return "together" }
+
+// Name implements provider.Provider.
+func (Provider) Name() string { return "Together AI" }
+
+// Enabled implements provider.Provider.
+func (Provider) Enabled(creds credentials.Credentials) bool {
+	return credentials.HasValue(creds.TogetherAPIKey)
+}
+
+// WithBaseURL implements provider.BaseURLSetter.
+func (p Provider) WithBaseURL(baseURL string) provider.Provider {
+	p.BaseURL = baseURL
+	return p
+}
+
+// Endpoint implements provider.Endpointer.
+func (p Provider) Endpoint() string {
+	return cmp.Or(p.BaseURL, DefaultBaseURL)
+}
+
+// Fetch implements provider.Provider.
+func (p Provider) Fetch(ctx context.Context, creds credentials.Credentials) (provider.Quota, error) {
+	quota, err := GetQuota(ctx, httpclient.OrDefault(p.HTTPClient), creds, cmp.Or(p.BaseURL, DefaultBaseURL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+// Windows implements provider.Quota with the credit balance, when known,
+// and request and token windows for every probed model.
+func (q Quota) Windows() []provider.Window {
+	windows := make([]provider.Window, 0, 1+2*len(q.Models))
+	if q.BalanceUSD != nil {
+		windows = append(windows, provider.Window{
+			ID:        "balance",
+			Name:      "Credit Balance (USD)",
+			Remaining: q.BalanceUSD,
+			ResetAt:   "unknown",
+		})
+	}
+
+	for _, model := range q.Models {
+		windows = append(windows, window(model.Model+"_requests", model.Model+" Requests", model.Requests))
+		windows = append(windows, window(model.Model+"_tokens", model.Model+" Tokens", model.Tokens))
+	}
+
+	return windows
+}
+
+func window(id string, name string, limit Limit) provider.Window {
+	return provider.Window{
+		ID:          id,
+		Name:        name,
+		UsedPercent: new(limit.UsedPercent),
+		Used:        new(limit.Limit - limit.Remaining),
+		Limit:       new(limit.Limit),
+		ResetAt:     limit.ResetAt,
+	}
+}
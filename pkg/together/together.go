@@ -0,0 +1,185 @@
+package together
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultBaseURL is the Together AI API base URL.
+const DefaultBaseURL = "https://api.together.xyz"
+
+// DefaultModels are probed when AIQUOTA_TOGETHER_MODELS is not set.
+var DefaultModels = []string{"meta-llama/Llama-3.2-3B-Instruct-Turbo"}
+
+// Limit is one rate limit of a model as reported by Together AI.
+type Limit struct {
+	Limit       float64 `json:"limit"`
+	Remaining   float64 `json:"remaining"`
+	UsedPercent float64 `json:"usedPercent"`
+	ResetAt     string  `json:"resetAt"`
+	ResetIn     string  `json:"resetIn"`
+}
+
+// ModelLimits holds the tier rate limits Together AI applies to one model.
+type ModelLimits struct {
+	Model    string `json:"model"`
+	Requests Limit  `json:"requests"`
+	Tokens   Limit  `json:"tokens"`
+}
+
+// Quota contains the Together AI credit balance and the tier rate limits of
+// the probed models.
+//
+// Together AI reports rate limits in the x-ratelimit-* and x-tokenlimit-*
+// headers of inference responses, so each model is probed with a one-token
+// completion. The balance is nil when the account endpoint does not report
+// one.
+type Quota struct {
+	BalanceUSD *float64      `json:"balanceUsd"`
+	Models     []ModelLimits `json:"models"`
+}
+
+// GetQuota fetches the credit balance and the rate limits of every model in
+// creds.TogetherModels, or DefaultModels.
+func GetQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (Quota, error) {
+	if creds.TogetherAPIKey == nil || *creds.TogetherAPIKey == "" {
+		return Quota{}, fmt.Errorf("missing Together AI API key in credentials")
+	}
+
+	apiKey := *creds.TogetherAPIKey
+	balance, err := getBalance(ctx, client, apiKey, baseURL)
+	if err != nil {
+		return Quota{}, err
+	}
+
+	models := creds.TogetherModels
+	if len(models) == 0 {
+		models = DefaultModels
+	}
+
+	result := Quota{BalanceUSD: balance, Models: make([]ModelLimits, 0, len(models))}
+	for _, model := range models {
+		limits, err := probe(ctx, client, apiKey, baseURL, model)
+		if err != nil {
+			return Quota{}, err
+		}
+
+		result.Models = append(result.Models, limits)
+	}
+
+	return result, nil
+}
+
+// getBalance reads the prepaid credit balance of the account. Accounts
+// without one answer 404, which is not an error.
+func getBalance(ctx context.Context, client httpclient.Doer, apiKey string, baseURL string) (*float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/api/credits", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Together AI request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Together AI quota: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Together AI response: %w", err)
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to fetch Together AI quota. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	balance := gjson.GetBytes(body, "balance")
+	if !balance.Exists() {
+		return nil, nil
+	}
+
+	return new(balance.Float()), nil
+}
+
+// probe sends a minimal completion for model and parses the rate limit
+// headers of the response. A 429 still carries the headers and means the
+// limit is spent, so it is not an error.
+func probe(ctx context.Context, client httpclient.Doer, apiKey string, baseURL string, model string) (ModelLimits, error) {
+	payload, err := json.Marshal(map[string]any{
+		"model":      model,
+		"messages":   []map[string]string{{"role": "user", "content": "."}},
+		"max_tokens": 1,
+	})
+	if err != nil {
+		return ModelLimits{}, fmt.Errorf("failed to encode Together AI request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/v1/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return ModelLimits{}, fmt.Errorf("failed to create Together AI request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return ModelLimits{}, fmt.Errorf("failed to fetch Together AI quota: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return ModelLimits{}, fmt.Errorf("failed to read Together AI response: %w", err)
+	}
+
+	ok := response.StatusCode >= 200 && response.StatusCode < 300
+	if !ok && response.StatusCode != http.StatusTooManyRequests {
+		return ModelLimits{}, fmt.Errorf("failed to fetch Together AI quota for %s. Status: %d, Response: %s", model, response.StatusCode, string(body))
+	}
+
+	now := time.Now()
+	return ModelLimits{
+		Model:    model,
+		Requests: parseLimit(response.Header, "x-ratelimit-", now),
+		Tokens:   parseLimit(response.Header, "x-tokenlimit-", now),
+	}, nil
+}
+
+func parseLimit(header http.Header, prefix string, now time.Time) Limit {
+	limit, _ := strconv.ParseFloat(header.Get(prefix+"limit"), 64)
+	remaining, _ := strconv.ParseFloat(header.Get(prefix+"remaining"), 64)
+
+	result := Limit{Limit: limit, Remaining: remaining, ResetAt: "unknown", ResetIn: "unknown"}
+	if limit > 0 {
+		result.UsedPercent = helpers.ClampPercent((limit - remaining) / limit * 100)
+	}
+
+	// Resets are given in seconds.
+	if reset, err := strconv.ParseFloat(header.Get(prefix+"reset"), 64); err == nil {
+		result.ResetAt = now.Add(time.Duration(reset * float64(time.Second))).UTC().Format(time.RFC3339)
+		result.ResetIn = helpers.FormatTimeUntil(result.ResetAt)
+	}
+
+	return result
+}
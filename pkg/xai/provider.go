@@ -0,0 +1,90 @@
+package xai
+
+import (
+	"cmp"
+	"context"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// Provider exposes xAI through the common provider interface.
+type Provider struct {
+	// BaseURL overrides DefaultBaseURL, for example to go through a proxy.
+	BaseURL string
+	// ManagementBaseURL overrides DefaultManagementBaseURL.
+	ManagementBaseURL string
+	// HTTPClient sends the requests. Nil means httpclient.Default.
+	HTTPClient httpclient.Doer
+}
+
+// ID implements provider.Provider.
+func (Provider) ID() string { return "xai" }
+
+// Name implements provider.Provider.
+func (Provider) Name() string { return "xAI" }
+
+// Enabled implements provider.Provider.
+func (Provider) Enabled(creds credentials.Credentials) bool {
+	return credentials.HasValue(creds.XAIAPIKey)
+}
+
+// WithBaseURL implements provider.BaseURLSetter.
+func (p Provider) WithBaseURL(baseURL string) provider.Provider {
+	p.BaseURL = baseURL
+	return p
+}
+
+// Endpoint implements provider.Endpointer.
+func (p Provider) Endpoint() string {
+	return cmp.Or(p.BaseURL, DefaultBaseURL)
+}
+
+// Fetch implements provider.Provider.
+func (p Provider) Fetch(ctx context.Context, creds credentials.Credentials) (provider.Quota, error) {
+	quota, err := GetQuota(
+		ctx,
+		httpclient.OrDefault(p.HTTPClient),
+		creds,
+		cmp.Or(p.BaseURL, DefaultBaseURL),
+		cmp.Or(p.ManagementBaseURL, DefaultManagementBaseURL),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+// Windows implements provider.Quota with the credit balance, when known,
+// and per-minute request and token windows for every probed model.
+func (q Quota) Windows() []provider.Window {
+	windows := make([]provider.Window, 0, 1+2*len(q.Models))
+	if q.BalanceUSD != nil {
+		windows = append(windows, provider.Window{
+			ID:        "balance",
+			Name:      "Credit Balance (USD)",
+			Remaining: q.BalanceUSD,
+			ResetAt:   "unknown",
+		})
+	}
+
+	for _, model := range q.Models {
+		windows = append(windows, window(model.Model+"_requests", model.Model+" Requests/Minute", model.RequestsPerMinute))
+		windows = append(windows, window(model.Model+"_tokens", model.Model+" Tokens/Minute", model.TokensPerMinute))
+	}
+
+	return windows
+}
+
+func window(id string, name string, limit Limit) provider.Window {
+	return provider.Window{
+		ID:          id,
+		Name:        name,
+		UsedPercent: new(limit.UsedPercent),
+		Used:        new(float64(limit.Limit - limit.Remaining)),
+		Limit:       new(float64(limit.Limit)),
+		ResetAt:     limit.ResetAt,
+	}
+}
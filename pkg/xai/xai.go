@@ -0,0 +1,218 @@
+package xai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// DefaultBaseURL is the xAI inference API base URL.
+	DefaultBaseURL = "https://api.x.ai/v1"
+
+	// DefaultManagementBaseURL is the xAI management API base URL, which
+	// serves billing.
+	DefaultManagementBaseURL = "https://management-api.x.ai/v1"
+)
+
+// DefaultModels are probed when AIQUOTA_XAI_MODELS is not set.
+var DefaultModels = []string{"grok-3"}
+
+// Limit is one rate limit of a model as reported by xAI.
+type Limit struct {
+	Limit       int64   `json:"limit"`
+	Remaining   int64   `json:"remaining"`
+	UsedPercent float64 `json:"usedPercent"`
+	ResetAt     string  `json:"resetAt"`
+	ResetIn     string  `json:"resetIn"`
+}
+
+// ModelLimits holds the rate limits xAI applies to one model.
+type ModelLimits struct {
+	Model             string `json:"model"`
+	RequestsPerMinute Limit  `json:"requestsPerMinute"`
+	TokensPerMinute   Limit  `json:"tokensPerMinute"`
+}
+
+// Quota contains the xAI team credit balance and the rate limits of the
+// probed models.
+//
+// Rate limits are only reported in the x-ratelimit-* headers of inference
+// responses, so each model is probed with a one-token completion. The
+// balance comes from the management API and is nil when the key may not
+// read team billing.
+type Quota struct {
+	TeamID        string        `json:"teamId"`
+	KeyName       string        `json:"keyName"`
+	KeyBlocked    bool          `json:"keyBlocked"`
+	BalanceUSD    *float64      `json:"balanceUsd"`
+	BalanceStatus string        `json:"balanceStatus,omitempty"`
+	Models        []ModelLimits `json:"models"`
+}
+
+// GetQuota fetches the API key details, the prepaid team balance and the
+// rate limits of every model in creds.XAIModels, or DefaultModels.
+func GetQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string, managementBaseURL string) (Quota, error) {
+	if creds.XAIAPIKey == nil || *creds.XAIAPIKey == "" {
+		return Quota{}, fmt.Errorf("missing xAI API key in credentials")
+	}
+
+	apiKey := *creds.XAIAPIKey
+	body, _, err := get(ctx, client, apiKey, baseURL+"/api-key")
+	if err != nil {
+		return Quota{}, err
+	}
+
+	result := Quota{
+		TeamID:  gjson.GetBytes(body, "team_id").String(),
+		KeyName: gjson.GetBytes(body, "name").String(),
+		KeyBlocked: gjson.GetBytes(body, "api_key_blocked").Bool() ||
+			gjson.GetBytes(body, "api_key_disabled").Bool() ||
+			gjson.GetBytes(body, "team_blocked").Bool(),
+	}
+
+	// A management key is needed for billing unless the API key itself has
+	// access, so a rejected request only leaves the balance out.
+	managementKey := apiKey
+	if credentials.HasValue(creds.XAIManagementKey) {
+		managementKey = *creds.XAIManagementKey
+	}
+
+	if result.TeamID != "" {
+		balanceURL := managementBaseURL + "/billing/teams/" + url.PathEscape(result.TeamID) + "/prepaid/balance"
+		body, status, err := get(ctx, client, managementKey, balanceURL)
+		switch {
+		case status == http.StatusUnauthorized || status == http.StatusForbidden:
+			result.BalanceStatus = "set AIQUOTA_XAI_MANAGEMENT_KEY to read the team balance"
+		case err != nil:
+			return Quota{}, err
+		default:
+			// The total is in US cents, with prepaid credit as a negative
+			// amount.
+			cents, _ := strconv.ParseFloat(gjson.GetBytes(body, "total.val").String(), 64)
+			result.BalanceUSD = new(-cents / 100)
+		}
+	}
+
+	models := creds.XAIModels
+	if len(models) == 0 {
+		models = DefaultModels
+	}
+
+	result.Models = make([]ModelLimits, 0, len(models))
+	for _, model := range models {
+		limits, err := probe(ctx, client, apiKey, baseURL, model)
+		if err != nil {
+			return Quota{}, err
+		}
+
+		result.Models = append(result.Models, limits)
+	}
+
+	return result, nil
+}
+
+// get sends an authenticated GET request and returns the body of a
+// successful response. The status is returned even on failure.
+func get(ctx context.Context, client httpclient.Doer, apiKey string, requestURL string) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create xAI request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch xAI quota: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, response.StatusCode, fmt.Errorf("failed to read xAI response: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, response.StatusCode, fmt.Errorf("failed to fetch xAI quota. Status: %d, Response: %s", response.StatusCode, string(body))
+	}
+
+	return body, response.StatusCode, nil
+}
+
+// probe sends a minimal completion for model and parses the rate limit
+// headers of the response. A 429 still carries the headers and means the
+// limit is spent, so it is not an error.
+func probe(ctx context.Context, client httpclient.Doer, apiKey string, baseURL string, model string) (ModelLimits, error) {
+	payload, err := json.Marshal(map[string]any{
+		"model":      model,
+		"messages":   []map[string]string{{"role": "user", "content": "."}},
+		"max_tokens": 1,
+	})
+	if err != nil {
+		return ModelLimits{}, fmt.Errorf("failed to encode xAI request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return ModelLimits{}, fmt.Errorf("failed to create xAI request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return ModelLimits{}, fmt.Errorf("failed to fetch xAI quota: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return ModelLimits{}, fmt.Errorf("failed to read xAI response: %w", err)
+	}
+
+	ok := response.StatusCode >= 200 && response.StatusCode < 300
+	if !ok && response.StatusCode != http.StatusTooManyRequests {
+		return ModelLimits{}, fmt.Errorf("failed to fetch xAI quota for %s. Status: %d, Response: %s", model, response.StatusCode, string(body))
+	}
+
+	now := time.Now()
+	return ModelLimits{
+		Model:             model,
+		RequestsPerMinute: parseLimit(response.Header, "requests", now),
+		TokensPerMinute:   parseLimit(response.Header, "tokens", now),
+	}, nil
+}
+
+func parseLimit(header http.Header, kind string, now time.Time) Limit {
+	limit, _ := strconv.ParseInt(header.Get("x-ratelimit-limit-"+kind), 10, 64)
+	remaining, _ := strconv.ParseInt(header.Get("x-ratelimit-remaining-"+kind), 10, 64)
+
+	result := Limit{Limit: limit, Remaining: remaining, ResetAt: "unknown", ResetIn: "unknown"}
+	if limit > 0 {
+		result.UsedPercent = helpers.ClampPercent(float64(limit-remaining) / float64(limit) * 100)
+	}
+
+	// Resets are relative durations such as "12s" when present.
+	if reset, err := time.ParseDuration(header.Get("x-ratelimit-reset-" + kind)); err == nil {
+		result.ResetAt = now.Add(reset).UTC().Format(time.RFC3339)
+		result.ResetIn = helpers.FormatTimeUntil(result.ResetAt)
+	}
+
+	return result
+}
@@ -0,0 +1,91 @@
+package zai
+
+import (
+	"cmp"
+	"context"
+	"time"
+
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
+	"github.com/eduardolat/aiquota/pkg/provider"
+)
+
+// Provider exposes Z.ai through the common provider interface.
+type Provider struct {
+	// BaseURL overrides DefaultBaseURL, for example to go through a proxy.
+	BaseURL string
+	// HTTPClient sends the requests. Nil means httpclient.Default.
+	HTTPClient httpclient.Doer
+}
+
+// ID implements provider.Provider.
+func (Provider) ID() string { return "zai" }
+
+// Name implements provider.Provider.
+func (Provider) Name() string { return "Z.ai" }
+
+// Enabled implements provider.Provider.
+func (Provider) Enabled(creds credentials.Credentials) bool {
+	return credentials.HasValue(creds.ZAIAPIKey)
+}
+
+// WithBaseURL implements provider.BaseURLSetter.
+func (p Provider) WithBaseURL(baseURL string) provider.Provider {
+	p.BaseURL = baseURL
+	return p
+}
+
+// Endpoint implements provider.Endpointer.
+func (p Provider) Endpoint() string {
+	return cmp.Or(p.BaseURL, DefaultBaseURL)
+}
+
+// Fetch implements provider.Provider.
+func (p Provider) Fetch(ctx context.Context, creds credentials.Credentials) (provider.Quota, error) {
+	quota, err := GetQuota(ctx, httpclient.OrDefault(p.HTTPClient), creds, cmp.Or(p.BaseURL, DefaultBaseURL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+// TokenUsage implements provider.TokenReporter. Z.ai does not split the
+// token quota into input and output, so its tokens are reported as input of
+// TokenModel, and the MCP details as tool calls. The quota window has no
+// known start, so the period is unknown.
+func (q Quota) TokenUsage() ([]provider.TokenUsage, time.Time, time.Time) {
+	var usage []provider.TokenUsage
+	if q.TokenQuota.Used != nil {
+		usage = append(usage, provider.TokenUsage{Model: TokenModel, InputTokens: int64(*q.TokenQuota.Used)})
+	}
+
+	for _, detail := range q.MCPQuota.Details {
+		usage = append(usage, provider.TokenUsage{Model: detail.ModelCode, Requests: int64(detail.Usage)})
+	}
+
+	return usage, time.Time{}, time.Time{}
+}
+
+// Windows implements provider.Quota. The prompts window is only present on
+// plans that report a prompt count.
+func (q Quota) Windows() []provider.Window {
+	windows := []provider.Window{q.TokenQuota.window("tokens", "Token Quota")}
+	if q.PromptQuota != nil {
+		windows = append(windows, q.PromptQuota.window("prompts", "Prompt Quota"))
+	}
+
+	return append(windows, q.MCPQuota.window("mcp", "MCP Quota"))
+}
+
+func (w QuotaWindow) window(id string, name string) provider.Window {
+	return provider.Window{
+		ID:          id,
+		Name:        name,
+		UsedPercent: new(w.UsedPercent),
+		Used:        w.Used,
+		Limit:       w.Limit,
+		Remaining:   w.Remaining,
+		ResetAt:     w.ResetAt,
+	}
+}
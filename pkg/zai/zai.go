@@ -6,17 +6,30 @@ import (
 	"io"
 	"net/http"
 
-	"github.com/eduardolat/aiquota/internal/credentials"
 	"github.com/eduardolat/aiquota/internal/helpers"
+	"github.com/eduardolat/aiquota/pkg/credentials"
+	"github.com/eduardolat/aiquota/pkg/httpclient"
 	"github.com/tidwall/gjson"
 )
 
-// QuotaWindow represents a usage window.
+// DefaultBaseURL is the Z.ai API base URL.
+const DefaultBaseURL = "https://api.z.ai"
+
+// TokenModel is the model the token quota is priced as in cost estimates,
+// the default of the GLM Coding Plan.
+const TokenModel = "glm-4.6"
+
+// QuotaWindow represents a usage window. Used, Limit and Remaining are the
+// absolute counts (tokens, prompts or calls) and are nil when Z.ai only
+// reports the percentage.
 type QuotaWindow struct {
-	UsedPercent      float64 `json:"usedPercent"`
-	RemainingPercent float64 `json:"remainingPercent"`
-	ResetAt          string  `json:"resetAt"`
-	ResetIn          string  `json:"resetIn"`
+	UsedPercent      float64  `json:"usedPercent"`
+	RemainingPercent float64  `json:"remainingPercent"`
+	Used             *float64 `json:"used,omitempty"`
+	Limit            *float64 `json:"limit,omitempty"`
+	Remaining        *float64 `json:"remaining,omitempty"`
+	ResetAt          string   `json:"resetAt"`
+	ResetIn          string   `json:"resetIn"`
 }
 
 // MCPQuota represents MCP quota information.
@@ -36,16 +49,19 @@ type Quota struct {
 	AccountID   string      `json:"accountId"`
 	AccountType string      `json:"accountType"`
 	TokenQuota  QuotaWindow `json:"tokenQuota"`
-	MCPQuota    MCPQuota    `json:"mcpQuota"`
+	// PromptQuota is the prompt-count window of the coding plans, nil for
+	// accounts without one.
+	PromptQuota *QuotaWindow `json:"promptQuota,omitempty"`
+	MCPQuota    MCPQuota     `json:"mcpQuota"`
 }
 
 // GetQuota fetches Z.ai quota information.
-func GetQuota(ctx context.Context, creds credentials.Credentials) (Quota, error) {
+func GetQuota(ctx context.Context, client httpclient.Doer, creds credentials.Credentials, baseURL string) (Quota, error) {
 	if creds.ZAIAPIKey == nil || *creds.ZAIAPIKey == "" {
 		return Quota{}, fmt.Errorf("missing Z.ai API key in credentials")
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.z.ai/api/monitor/usage/quota/limit", nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/api/monitor/usage/quota/limit", nil)
 	if err != nil {
 		return Quota{}, fmt.Errorf("failed to create Z.ai request: %w", err)
 	}
@@ -54,7 +70,7 @@ func GetQuota(ctx context.Context, creds credentials.Credentials) (Quota, error)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "OpenCode-Quota-Plugin/1.0")
 
-	response, err := http.DefaultClient.Do(req)
+	response, err := client.Do(req)
 	if err != nil {
 		return Quota{}, fmt.Errorf("failed to fetch Z.ai quota: %w", err)
 	}
@@ -78,34 +94,58 @@ func GetQuota(ctx context.Context, creds credentials.Credentials) (Quota, error)
 	}
 
 	limits := gjson.GetBytes(body, "data.limits").Array()
-	tokenLimit := findLimitByType(limits, "TOKENS_LIMIT")
 	timeLimit := findLimitByType(limits, "TIME_LIMIT")
 
-	tokenResetAt := unixMillisResultToISO(tokenLimit.Get("nextResetTime"))
-	tokenUsedPercent := helpers.ClampPercent(tokenLimit.Get("percentage").Float())
-
-	mcpResetAt := unixMillisResultToISO(timeLimit.Get("nextResetTime"))
-	mcpUsedPercent := helpers.ClampPercent(timeLimit.Get("percentage").Float())
-
-	return Quota{
+	quota := Quota{
 		AccountID:   maskToken(*creds.ZAIAPIKey),
 		AccountType: gjson.GetBytes(body, "data.level").String(),
-		TokenQuota: QuotaWindow{
-			UsedPercent:      tokenUsedPercent,
-			RemainingPercent: helpers.ClampPercent(100 - tokenUsedPercent),
-			ResetAt:          tokenResetAt,
-			ResetIn:          helpers.FormatTimeUntil(tokenResetAt),
-		},
+		TokenQuota:  parseWindow(findLimitByType(limits, "TOKENS_LIMIT")),
 		MCPQuota: MCPQuota{
-			QuotaWindow: QuotaWindow{
-				UsedPercent:      mcpUsedPercent,
-				RemainingPercent: helpers.ClampPercent(100 - mcpUsedPercent),
-				ResetAt:          mcpResetAt,
-				ResetIn:          helpers.FormatTimeUntil(mcpResetAt),
-			},
-			Details: parseUsageDetails(timeLimit.Get("usageDetails")),
+			QuotaWindow: parseWindow(timeLimit),
+			Details:     parseUsageDetails(timeLimit.Get("usageDetails")),
 		},
-	}, nil
+	}
+
+	if promptLimit := findLimitByType(limits, "PROMPTS_LIMIT"); promptLimit.Exists() {
+		quota.PromptQuota = new(parseWindow(promptLimit))
+	}
+
+	return quota, nil
+}
+
+// parseWindow reads one entry of data.limits. In those entries "usage" is
+// the window size and "currentValue" the amount used so far.
+func parseWindow(limit gjson.Result) QuotaWindow {
+	resetAt := unixMillisResultToISO(limit.Get("nextResetTime"))
+	window := QuotaWindow{
+		Used:      optionalFloat(limit.Get("currentValue")),
+		Limit:     optionalFloat(limit.Get("usage")),
+		Remaining: optionalFloat(limit.Get("remaining")),
+		ResetAt:   resetAt,
+		ResetIn:   helpers.FormatTimeUntil(resetAt),
+	}
+
+	if window.Remaining == nil && window.Used != nil && window.Limit != nil {
+		window.Remaining = new(max(*window.Limit-*window.Used, 0))
+	}
+
+	usedPercent := limit.Get("percentage").Float()
+	if !limit.Get("percentage").Exists() && window.Used != nil && window.Limit != nil && *window.Limit > 0 {
+		usedPercent = *window.Used / *window.Limit * 100
+	}
+
+	window.UsedPercent = helpers.ClampPercent(usedPercent)
+	window.RemainingPercent = helpers.ClampPercent(100 - window.UsedPercent)
+
+	return window
+}
+
+func optionalFloat(value gjson.Result) *float64 {
+	if !value.Exists() || value.Type == gjson.Null {
+		return nil
+	}
+
+	return new(value.Float())
 }
 
 func findLimitByType(limits []gjson.Result, limitType string) gjson.Result {